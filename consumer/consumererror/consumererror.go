@@ -17,6 +17,11 @@
 // error type/instance.
 package consumererror
 
+import (
+	"errors"
+	"time"
+)
+
 // permanent is an error that will be always returned if its source
 // receives the same inputs.
 type permanent struct {
@@ -39,3 +44,42 @@ func IsPermanent(err error) bool {
 	}
 	return false
 }
+
+// throttled is an error indicating that the data was refused because its
+// destination is temporarily out of capacity.
+type throttled struct {
+	error
+	retryAfter time.Duration
+}
+
+// Throttled wraps an error to indicate that the data was refused because its
+// destination is temporarily out of capacity, e.g.: a full queue or a memory
+// limit. The same data is expected to be accepted if sent again later, retryAfter
+// is a hint of how long the sender should wait before doing so, zero meaning
+// that there is no hint.
+func Throttled(err error, retryAfter time.Duration) error {
+	return throttled{error: err, retryAfter: retryAfter}
+}
+
+// Unwrap returns the wrapped error.
+func (t throttled) Unwrap() error {
+	return t.error
+}
+
+// IsThrottled checks if an error, or any error it wraps, was wrapped with the
+// Throttled function, that is used to indicate that the data was refused due
+// to lack of capacity and should be sent again later.
+func IsThrottled(err error) bool {
+	var t throttled
+	return errors.As(err, &t)
+}
+
+// RetryAfter returns the delay suggested by an error wrapped with the Throttled
+// function, or zero for any other error.
+func RetryAfter(err error) time.Duration {
+	var t throttled
+	if errors.As(err, &t) {
+		return t.retryAfter
+	}
+	return 0
+}
@@ -16,7 +16,9 @@ package consumererror
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -33,3 +35,25 @@ func TestIsPermanent_NilError(t *testing.T) {
 	var err error
 	require.False(t, IsPermanent(err))
 }
+
+func TestThrottled(t *testing.T) {
+	err := errors.New("testError")
+	require.False(t, IsThrottled(err))
+	require.Equal(t, time.Duration(0), RetryAfter(err))
+
+	err = Throttled(err, 5*time.Second)
+	require.True(t, IsThrottled(err))
+	require.False(t, IsPermanent(err))
+	require.Equal(t, 5*time.Second, RetryAfter(err))
+	require.Equal(t, "testError", err.Error())
+
+	wrapped := fmt.Errorf("exporting: %w", err)
+	require.True(t, IsThrottled(wrapped))
+	require.Equal(t, 5*time.Second, RetryAfter(wrapped))
+}
+
+func TestIsThrottled_NilError(t *testing.T) {
+	var err error
+	require.False(t, IsThrottled(err))
+	require.Equal(t, time.Duration(0), RetryAfter(err))
+}
@@ -20,8 +20,10 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/exporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegergrpcexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegerthrifthttpexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/loadbalancingexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/loggingexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/otlpexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/prometheusexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/zipkinexporter"
 	"github.com/open-telemetry/opentelemetry-service/extension"
@@ -30,15 +32,25 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/extension/zpagesextension"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/adaptivesamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/filterprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/metricstransformprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/probabilisticsamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/queuedprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/resourcedetectionprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/resourceprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/spanmetricsprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/carbonreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/otlpreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver/remotewritereceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/statsdreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/vmmetricsreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/zipkinreceiver"
 )
@@ -64,8 +76,12 @@ func Components() (
 		&jaegerreceiver.Factory{},
 		&zipkinreceiver.Factory{},
 		&prometheusreceiver.Factory{},
+		&remotewritereceiver.Factory{},
 		&opencensusreceiver.Factory{},
+		&otlpreceiver.Factory{},
 		&vmmetricsreceiver.Factory{},
+		&statsdreceiver.Factory{},
+		&carbonreceiver.Factory{},
 	)
 	if err != nil {
 		errs = append(errs, err)
@@ -73,11 +89,13 @@ func Components() (
 
 	exporters, err := exporter.Build(
 		&opencensusexporter.Factory{},
+		&otlpexporter.Factory{},
 		&prometheusexporter.Factory{},
 		&loggingexporter.Factory{},
 		&zipkinexporter.Factory{},
 		&jaegergrpcexporter.Factory{},
 		&jaegerthrifthttpexporter.Factory{},
+		&loadbalancingexporter.Factory{},
 	)
 	if err != nil {
 		errs = append(errs, err)
@@ -89,6 +107,12 @@ func Components() (
 		&nodebatcherprocessor.Factory{},
 		&tailsamplingprocessor.Factory{},
 		&probabilisticsamplerprocessor.Factory{},
+		&filterprocessor.Factory{},
+		&metricstransformprocessor.Factory{},
+		&resourceprocessor.Factory{},
+		&resourcedetectionprocessor.Factory{},
+		&spanmetricsprocessor.Factory{},
+		&adaptivesamplerprocessor.Factory{},
 	)
 	if err != nil {
 		errs = append(errs, err)
@@ -25,8 +25,10 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/exporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegergrpcexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/jaeger/jaegerthrifthttpexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/loadbalancingexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/loggingexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/otlpexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/prometheusexporter"
 	"github.com/open-telemetry/opentelemetry-service/exporter/zipkinexporter"
 	"github.com/open-telemetry/opentelemetry-service/extension"
@@ -34,15 +36,25 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/extension/pprofextension"
 	"github.com/open-telemetry/opentelemetry-service/extension/zpagesextension"
 	"github.com/open-telemetry/opentelemetry-service/processor"
+	"github.com/open-telemetry/opentelemetry-service/processor/adaptivesamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/filterprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/metricstransformprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/nodebatcherprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/probabilisticsamplerprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/queuedprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/resourcedetectionprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/resourceprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/spanmetricsprocessor"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/carbonreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/jaegerreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/otlpreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver/remotewritereceiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/statsdreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/vmmetricsreceiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/zipkinreceiver"
 )
@@ -54,11 +66,15 @@ func TestDefaultComponents(t *testing.T) {
 		"zpages":       &zpagesextension.Factory{},
 	}
 	expectedReceivers := map[string]receiver.Factory{
-		"jaeger":     &jaegerreceiver.Factory{},
-		"zipkin":     &zipkinreceiver.Factory{},
-		"prometheus": &prometheusreceiver.Factory{},
-		"opencensus": &opencensusreceiver.Factory{},
-		"vmmetrics":  &vmmetricsreceiver.Factory{},
+		"jaeger":                  &jaegerreceiver.Factory{},
+		"zipkin":                  &zipkinreceiver.Factory{},
+		"prometheus":              &prometheusreceiver.Factory{},
+		"prometheus_remote_write": &remotewritereceiver.Factory{},
+		"opencensus":              &opencensusreceiver.Factory{},
+		"otlp":                    &otlpreceiver.Factory{},
+		"vmmetrics":               &vmmetricsreceiver.Factory{},
+		"statsd":                  &statsdreceiver.Factory{},
+		"carbon":                  &carbonreceiver.Factory{},
 	}
 	expectedProcessors := map[string]processor.Factory{
 		"attributes":            &attributesprocessor.Factory{},
@@ -66,14 +82,22 @@ func TestDefaultComponents(t *testing.T) {
 		"batch":                 &nodebatcherprocessor.Factory{},
 		"tail_sampling":         &tailsamplingprocessor.Factory{},
 		"probabilistic_sampler": &probabilisticsamplerprocessor.Factory{},
+		"filter":                &filterprocessor.Factory{},
+		"metrics_transform":     &metricstransformprocessor.Factory{},
+		"resource":              &resourceprocessor.Factory{},
+		"resource_detection":    &resourcedetectionprocessor.Factory{},
+		"span_metrics":          &spanmetricsprocessor.Factory{},
+		"adaptive_sampler":      &adaptivesamplerprocessor.Factory{},
 	}
 	expectedExporters := map[string]exporter.Factory{
 		"opencensus":         &opencensusexporter.Factory{},
+		"otlp":               &otlpexporter.Factory{},
 		"prometheus":         &prometheusexporter.Factory{},
 		"logging":            &loggingexporter.Factory{},
 		"zipkin":             &zipkinexporter.Factory{},
 		"jaeger_grpc":        &jaegergrpcexporter.Factory{},
 		"jaeger_thrift_http": &jaegerthrifthttpexporter.Factory{},
+		"load_balancing":     &loadbalancingexporter.Factory{},
 	}
 
 	factories, err := Components()
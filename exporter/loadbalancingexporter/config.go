@@ -0,0 +1,67 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
+)
+
+// Config defines configuration for the load balancing exporter.
+type Config struct {
+	configmodels.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+
+	// Protocol holds the settings of the OpenCensus exporters sending the
+	// traces to each backend. The endpoint is ignored, it is set to the
+	// endpoint of each backend.
+	Protocol opencensusexporter.Config `mapstructure:"protocol"`
+
+	// Resolver provides the endpoints of the backends.
+	Resolver ResolverSettings `mapstructure:"resolver"`
+}
+
+// ResolverSettings defines how the endpoints of the backends are found.
+// Exactly one of the resolvers must be specified.
+type ResolverSettings struct {
+	// Static is a fixed list of backends.
+	Static *StaticResolver `mapstructure:"static"`
+
+	// DNS resolves the backends from the A records of a hostname.
+	DNS *DNSResolver `mapstructure:"dns"`
+}
+
+// StaticResolver defines a fixed list of backends.
+type StaticResolver struct {
+	// Endpoints are the host:port endpoints of the backends.
+	Endpoints []string `mapstructure:"endpoints"`
+}
+
+// DNSResolver defines the resolution of the backends from the A records of a
+// hostname, e.g. a headless Kubernetes service.
+type DNSResolver struct {
+	// Hostname is resolved to the IP addresses of the backends. Required.
+	Hostname string `mapstructure:"hostname"`
+
+	// Port is the port of the backends, the default is 55678.
+	Port string `mapstructure:"port"`
+
+	// Interval is the time between two resolutions, the default is 5s.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout is the timeout of a resolution, the default is 1s.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
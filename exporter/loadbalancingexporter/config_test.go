@@ -0,0 +1,77 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configgrpc"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+
+	factory := &Factory{}
+	factories.Exporters[typeStr] = factory
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters["load_balancing"]
+	assert.Equal(t, e0, factory.CreateDefaultConfig())
+
+	e1 := cfg.Exporters["load_balancing/static"]
+	assert.Equal(t, e1,
+		&Config{
+			ExporterSettings: configmodels.ExporterSettings{
+				NameVal: "load_balancing/static",
+				TypeVal: "load_balancing",
+			},
+			Protocol: opencensusexporter.Config{
+				GRPCSettings: configgrpc.GRPCSettings{Compression: "gzip"},
+				NumWorkers:   4,
+			},
+			Resolver: ResolverSettings{
+				Static: &StaticResolver{Endpoints: []string{"backend-1:55678", "backend-2:55678"}},
+			},
+		})
+
+	e2 := cfg.Exporters["load_balancing/dns"]
+	assert.Equal(t, e2,
+		&Config{
+			ExporterSettings: configmodels.ExporterSettings{
+				NameVal: "load_balancing/dns",
+				TypeVal: "load_balancing",
+			},
+			Resolver: ResolverSettings{
+				DNS: &DNSResolver{
+					Hostname: "tail-samplers.observability.svc.cluster.local",
+					Port:     "55680",
+					Interval: 30 * time.Second,
+					Timeout:  2 * time.Second,
+				},
+			},
+		})
+}
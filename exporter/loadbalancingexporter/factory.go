@@ -0,0 +1,73 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/opencensusexporter"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "load_balancing"
+)
+
+// Factory is the factory for the load balancing exporter.
+type Factory struct {
+}
+
+// Type gets the type of the Exporter config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for exporter.
+// Note: This isn't a valid configuration because a resolver is required.
+func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
+	return &Config{
+		ExporterSettings: configmodels.ExporterSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+	}
+}
+
+// CreateTraceExporter creates a trace exporter based on this config.
+func (f *Factory) CreateTraceExporter(logger *zap.Logger, config configmodels.Exporter) (exporter.TraceExporter, error) {
+	lbCfg := config.(*Config)
+	res, err := newResolver(logger, lbCfg.Resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	ocFactory := &opencensusexporter.Factory{}
+	newExporter := func(endpoint string) (exporter.TraceExporter, error) {
+		ocCfg := lbCfg.Protocol
+		ocCfg.TypeVal = ocFactory.Type()
+		ocCfg.NameVal = lbCfg.Name() + "/" + endpoint
+		ocCfg.Endpoint = endpoint
+		return ocFactory.CreateTraceExporter(logger, &ocCfg)
+	}
+	return newTraceExporter(config, logger, res, newExporter)
+}
+
+// CreateMetricsExporter creates a metrics exporter based on this config.
+func (f *Factory) CreateMetricsExporter(logger *zap.Logger, config configmodels.Exporter) (exporter.MetricsExporter, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
@@ -0,0 +1,71 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// pointsPerEndpoint is the number of points of each endpoint on the ring.
+// More points spread the traces more evenly between the endpoints.
+const pointsPerEndpoint = 100
+
+// hashRing assigns trace IDs to endpoints by consistent hashing: adding or
+// removing an endpoint only moves the traces of about one endpoint.
+type hashRing struct {
+	// points are sorted by hash.
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash     uint32
+	endpoint string
+}
+
+func newHashRing(endpoints []string) *hashRing {
+	points := make([]ringPoint, 0, len(endpoints)*pointsPerEndpoint)
+	for _, endpoint := range endpoints {
+		for i := 0; i < pointsPerEndpoint; i++ {
+			points = append(points, ringPoint{
+				hash:     crc32.ChecksumIEEE([]byte(endpoint + "#" + strconv.Itoa(i))),
+				endpoint: endpoint,
+			})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash == points[j].hash {
+			return points[i].endpoint < points[j].endpoint
+		}
+		return points[i].hash < points[j].hash
+	})
+	return &hashRing{points: points}
+}
+
+// endpointFor returns the endpoint of the trace, the endpoint of the first
+// point following the hash of the trace ID on the ring. It returns an empty
+// string if the ring has no endpoints.
+func (r *hashRing) endpointFor(traceID []byte) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	hash := crc32.ChecksumIEEE(traceID)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].endpoint
+}
@@ -0,0 +1,55 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func TestHashRing(t *testing.T) {
+	assert.Equal(t, "", newHashRing(nil).endpointFor([]byte{1, 2, 3}))
+
+	endpoints := []string{"10.0.0.1:55678", "10.0.0.2:55678", "10.0.0.3:55678", "10.0.0.4:55678"}
+	ring := newHashRing(endpoints)
+	smaller := newHashRing(endpoints[:3])
+
+	r := rand.New(rand.NewSource(1))
+	const numTraces = 10000
+	counts := make(map[string]int)
+	moved := 0
+	for i := 0; i < numTraces; i++ {
+		traceID := tracetranslator.UInt64ToByteTraceID(r.Uint64(), r.Uint64())
+		endpoint := ring.endpointFor(traceID)
+		assert.Equal(t, endpoint, ring.endpointFor(traceID), "the same trace must go to the same endpoint")
+		counts[endpoint]++
+
+		// Removing an endpoint only moves the traces of that endpoint.
+		if newEndpoint := smaller.endpointFor(traceID); newEndpoint != endpoint {
+			assert.Equal(t, endpoints[3], endpoint)
+			moved++
+		}
+	}
+
+	// The traces are spread between all the endpoints.
+	for _, endpoint := range endpoints {
+		assert.InDelta(t, numTraces/len(endpoints), counts[endpoint], numTraces/10, endpoint)
+	}
+	assert.Equal(t, counts[endpoints[3]], moved)
+}
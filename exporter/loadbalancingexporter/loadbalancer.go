@@ -0,0 +1,167 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+var errNoBackends = errors.New("no backends to export the traces to")
+
+// loadBalancer sends the spans of each trace to the same backend, chosen by
+// consistent hashing of the trace ID among the backends of the resolver.
+type loadBalancer struct {
+	logger      *zap.Logger
+	resolver    resolver
+	newExporter func(endpoint string) (exporter.TraceExporter, error)
+
+	// mutex protects the ring and the exporters, it is held for reading
+	// while exporting so that exporters are not shut down during an export.
+	mutex     sync.RWMutex
+	ring      *hashRing
+	exporters map[string]exporter.TraceExporter
+}
+
+// newTraceExporter creates the exporter and starts the resolution of the
+// backends.
+func newTraceExporter(
+	config configmodels.Exporter,
+	logger *zap.Logger,
+	res resolver,
+	newExporter func(endpoint string) (exporter.TraceExporter, error),
+) (exporter.TraceExporter, error) {
+	lb := &loadBalancer{
+		logger:      logger,
+		resolver:    res,
+		newExporter: newExporter,
+		ring:        newHashRing(nil),
+		exporters:   make(map[string]exporter.TraceExporter),
+	}
+	if err := res.start(lb.onBackendChanges); err != nil {
+		return nil, err
+	}
+
+	return exporterhelper.NewTraceExporter(
+		config,
+		lb.pushTraceData,
+		exporterhelper.WithTracing(true),
+		exporterhelper.WithMetrics(true),
+		exporterhelper.WithShutdown(lb.shutdown))
+}
+
+// onBackendChanges creates the exporters of the new backends and shuts down
+// the exporters of the removed ones.
+func (lb *loadBalancer) onBackendChanges(endpoints []string) {
+	lb.mutex.Lock()
+	var available []string
+	current := make(map[string]bool, len(endpoints))
+	for _, endpoint := range endpoints {
+		current[endpoint] = true
+		if _, ok := lb.exporters[endpoint]; ok {
+			available = append(available, endpoint)
+			continue
+		}
+		exp, err := lb.newExporter(endpoint)
+		if err != nil {
+			lb.logger.Error("Failed to create the exporter of a backend", zap.String("endpoint", endpoint), zap.Error(err))
+			continue
+		}
+		lb.exporters[endpoint] = exp
+		available = append(available, endpoint)
+	}
+	var removed []exporter.TraceExporter
+	for endpoint, exp := range lb.exporters {
+		if !current[endpoint] {
+			removed = append(removed, exp)
+			delete(lb.exporters, endpoint)
+		}
+	}
+	lb.ring = newHashRing(available)
+	lb.mutex.Unlock()
+
+	lb.logger.Info("Backends updated", zap.Strings("endpoints", available))
+	for _, exp := range removed {
+		if err := exp.Shutdown(); err != nil {
+			lb.logger.Warn("Failed to shut down the exporter of a removed backend", zap.Error(err))
+		}
+	}
+}
+
+func (lb *loadBalancer) pushTraceData(ctx context.Context, td consumerdata.TraceData) (int, error) {
+	lb.mutex.RLock()
+	defer lb.mutex.RUnlock()
+
+	// Group the spans by backend, keeping the order of the backends stable
+	// for the same batch.
+	var endpoints []string
+	spansByEndpoint := make(map[string][]*tracepb.Span)
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		endpoint := lb.ring.endpointFor(span.TraceId)
+		if endpoint == "" {
+			return len(td.Spans), errNoBackends
+		}
+		if _, ok := spansByEndpoint[endpoint]; !ok {
+			endpoints = append(endpoints, endpoint)
+		}
+		spansByEndpoint[endpoint] = append(spansByEndpoint[endpoint], span)
+	}
+
+	var errs []error
+	droppedSpans := 0
+	for _, endpoint := range endpoints {
+		spans := spansByEndpoint[endpoint]
+		err := lb.exporters[endpoint].ConsumeTraceData(ctx, consumerdata.TraceData{
+			Node:         td.Node,
+			Resource:     td.Resource,
+			Spans:        spans,
+			SourceFormat: td.SourceFormat,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			droppedSpans += len(spans)
+		}
+	}
+	return droppedSpans, oterr.CombineErrors(errs)
+}
+
+func (lb *loadBalancer) shutdown() error {
+	lb.resolver.shutdown()
+
+	lb.mutex.Lock()
+	defer lb.mutex.Unlock()
+	var errs []error
+	for endpoint, exp := range lb.exporters {
+		if err := exp.Shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(lb.exporters, endpoint)
+	}
+	lb.ring = newHashRing(nil)
+	return oterr.CombineErrors(errs)
+}
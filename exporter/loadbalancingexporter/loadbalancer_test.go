@@ -0,0 +1,135 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+// fakeResolver lets the tests change the endpoints.
+type fakeResolver struct {
+	endpoints []string
+	onChange  func([]string)
+	stopped   bool
+}
+
+func (r *fakeResolver) start(onChange func([]string)) error {
+	r.onChange = onChange
+	onChange(r.endpoints)
+	return nil
+}
+
+func (r *fakeResolver) shutdown() {
+	r.stopped = true
+}
+
+type shutdownTracker struct {
+	exportertest.SinkTraceExporter
+	shutdown bool
+}
+
+func (st *shutdownTracker) Shutdown() error {
+	st.shutdown = true
+	return nil
+}
+
+func newTestLoadBalancer(t *testing.T, endpoints []string) (exporter.TraceExporter, *fakeResolver, map[string]*shutdownTracker) {
+	res := &fakeResolver{endpoints: endpoints}
+	sinks := make(map[string]*shutdownTracker)
+	newExporter := func(endpoint string) (exporter.TraceExporter, error) {
+		if endpoint == "broken:1" {
+			return nil, errors.New("broken")
+		}
+		sink := &shutdownTracker{}
+		sinks[endpoint] = sink
+		return sink, nil
+	}
+	cfg := &Config{ExporterSettings: configmodels.ExporterSettings{TypeVal: typeStr, NameVal: typeStr}}
+	te, err := newTraceExporter(cfg, zap.NewNop(), res, newExporter)
+	require.NoError(t, err)
+	return te, res, sinks
+}
+
+func TestLoadBalancer_SplitsByTraceID(t *testing.T) {
+	te, _, sinks := newTestLoadBalancer(t, []string{"a:1", "b:1", "broken:1"})
+	require.Len(t, sinks, 2)
+
+	var spans []*tracepb.Span
+	for i := 0; i < 100; i++ {
+		traceID := tracetranslator.UInt64ToByteTraceID(uint64(i), uint64(i)*31)
+		// Two spans per trace.
+		spans = append(spans, &tracepb.Span{TraceId: traceID}, &tracepb.Span{TraceId: traceID}, nil)
+	}
+	td := consumerdata.TraceData{Spans: spans, SourceFormat: "test"}
+	require.NoError(t, te.ConsumeTraceData(context.Background(), td))
+
+	// Each backend receives a single batch with whole traces.
+	seen := make(map[string]string)
+	total := 0
+	for endpoint, sink := range sinks {
+		traces := sink.AllTraces()
+		require.Len(t, traces, 1, endpoint)
+		assert.Equal(t, "test", traces[0].SourceFormat)
+		for _, span := range traces[0].Spans {
+			if other, ok := seen[string(span.TraceId)]; ok {
+				assert.Equal(t, endpoint, other, "the spans of a trace must go to the same backend")
+			}
+			seen[string(span.TraceId)] = endpoint
+			total++
+		}
+	}
+	assert.Equal(t, 200, total)
+	assert.Len(t, seen, 100)
+}
+
+func TestLoadBalancer_BackendChanges(t *testing.T) {
+	te, res, sinks := newTestLoadBalancer(t, nil)
+
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{{TraceId: tracetranslator.UInt64ToByteTraceID(1, 2)}}}
+	assert.Error(t, te.ConsumeTraceData(context.Background(), td), "no backends")
+
+	res.onChange([]string{"a:1", "b:1"})
+	require.Len(t, sinks, 2)
+	a := sinks["a:1"]
+
+	// Removed backends are shut down, the others are kept.
+	res.onChange([]string{"a:1", "c:1"})
+	assert.Len(t, sinks, 3)
+	assert.False(t, a.shutdown)
+	assert.Equal(t, a, sinks["a:1"])
+	assert.True(t, sinks["b:1"].shutdown)
+
+	require.NoError(t, te.ConsumeTraceData(context.Background(), td))
+	assert.Len(t, append(sinks["a:1"].AllTraces(), sinks["c:1"].AllTraces()...), 1)
+	assert.Empty(t, sinks["b:1"].AllTraces())
+
+	require.NoError(t, te.Shutdown())
+	assert.True(t, res.stopped)
+	assert.True(t, sinks["a:1"].shutdown)
+	assert.True(t, sinks["c:1"].shutdown)
+}
@@ -0,0 +1,174 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDNSPort     = "55678"
+	defaultDNSInterval = 5 * time.Second
+	defaultDNSTimeout  = time.Second
+)
+
+var errNoResolver = errors.New("exactly one of the \"static\" or \"dns\" resolvers must be specified")
+
+// resolver provides the endpoints of the backends.
+type resolver interface {
+	// start calls onChange with the sorted endpoints, and again each time
+	// they change until shutdown is called.
+	start(onChange func(endpoints []string)) error
+
+	shutdown()
+}
+
+func newResolver(logger *zap.Logger, cfg ResolverSettings) (resolver, error) {
+	switch {
+	case cfg.Static != nil && cfg.DNS == nil:
+		if len(cfg.Static.Endpoints) == 0 {
+			return nil, errors.New("the static resolver requires at least one endpoint")
+		}
+		return &staticResolver{endpoints: cfg.Static.Endpoints}, nil
+	case cfg.DNS != nil && cfg.Static == nil:
+		if cfg.DNS.Hostname == "" {
+			return nil, errors.New("the dns resolver requires a hostname")
+		}
+		return newDNSResolver(logger, *cfg.DNS), nil
+	default:
+		return nil, errNoResolver
+	}
+}
+
+type staticResolver struct {
+	endpoints []string
+}
+
+var _ resolver = (*staticResolver)(nil)
+
+func (r *staticResolver) start(onChange func([]string)) error {
+	endpoints := append([]string(nil), r.endpoints...)
+	sort.Strings(endpoints)
+	onChange(endpoints)
+	return nil
+}
+
+func (r *staticResolver) shutdown() {
+}
+
+type dnsResolver struct {
+	logger   *zap.Logger
+	hostname string
+	port     string
+	interval time.Duration
+	timeout  time.Duration
+	// lookup is replaced by tests to fake the resolution.
+	lookup func(ctx context.Context, host string) ([]net.IPAddr, error)
+
+	onChange  func([]string)
+	endpoints []string
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+}
+
+var _ resolver = (*dnsResolver)(nil)
+
+func newDNSResolver(logger *zap.Logger, cfg DNSResolver) *dnsResolver {
+	r := &dnsResolver{
+		logger:   logger,
+		hostname: cfg.Hostname,
+		port:     cfg.Port,
+		interval: cfg.Interval,
+		timeout:  cfg.Timeout,
+		lookup:   net.DefaultResolver.LookupIPAddr,
+		stopCh:   make(chan struct{}),
+	}
+	if r.port == "" {
+		r.port = defaultDNSPort
+	}
+	if r.interval <= 0 {
+		r.interval = defaultDNSInterval
+	}
+	if r.timeout <= 0 {
+		r.timeout = defaultDNSTimeout
+	}
+	return r
+}
+
+// start resolves the hostname once before returning, a failure is only logged
+// since the next resolutions may succeed.
+func (r *dnsResolver) start(onChange func([]string)) error {
+	r.onChange = onChange
+	r.resolve()
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.resolve()
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *dnsResolver) shutdown() {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+}
+
+// resolve looks up the hostname and calls onChange if the endpoints changed.
+// The previous endpoints are kept if the lookup fails.
+func (r *dnsResolver) resolve() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	addrs, err := r.lookup(ctx, r.hostname)
+	if err != nil {
+		r.logger.Warn("Failed to resolve the backends", zap.String("hostname", r.hostname), zap.Error(err))
+		return
+	}
+
+	endpoints := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		endpoints = append(endpoints, net.JoinHostPort(addr.IP.String(), r.port))
+	}
+	sort.Strings(endpoints)
+	if equalStrings(endpoints, r.endpoints) {
+		return
+	}
+	r.endpoints = endpoints
+	r.onChange(endpoints)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewResolver(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     ResolverSettings
+		wantErr bool
+	}{
+		{name: "none", wantErr: true},
+		{
+			name:    "both",
+			cfg:     ResolverSettings{Static: &StaticResolver{Endpoints: []string{"a:1"}}, DNS: &DNSResolver{Hostname: "a"}},
+			wantErr: true,
+		},
+		{name: "static without endpoints", cfg: ResolverSettings{Static: &StaticResolver{}}, wantErr: true},
+		{name: "dns without hostname", cfg: ResolverSettings{DNS: &DNSResolver{}}, wantErr: true},
+		{name: "static", cfg: ResolverSettings{Static: &StaticResolver{Endpoints: []string{"a:1"}}}},
+		{name: "dns", cfg: ResolverSettings{DNS: &DNSResolver{Hostname: "a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := newResolver(zap.NewNop(), tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, res)
+		})
+	}
+}
+
+func TestStaticResolver(t *testing.T) {
+	res := &staticResolver{endpoints: []string{"b:1", "a:1"}}
+	var got []string
+	require.NoError(t, res.start(func(endpoints []string) { got = endpoints }))
+	assert.Equal(t, []string{"a:1", "b:1"}, got)
+	res.shutdown()
+}
+
+func TestDNSResolver(t *testing.T) {
+	res := newDNSResolver(zap.NewNop(), DNSResolver{Hostname: "backends"})
+	assert.Equal(t, defaultDNSPort, res.port)
+
+	var lookupErr error
+	addrs := []net.IPAddr{{IP: net.IPv4(10, 0, 0, 2)}, {IP: net.IPv4(10, 0, 0, 1)}}
+	res.lookup = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		assert.Equal(t, "backends", host)
+		return addrs, lookupErr
+	}
+	var changes [][]string
+	onChange := func(endpoints []string) { changes = append(changes, endpoints) }
+
+	// The first resolution happens when the resolver starts.
+	require.NoError(t, res.start(onChange))
+	res.shutdown()
+	require.Len(t, changes, 1)
+	assert.Equal(t, []string{"10.0.0.1:55678", "10.0.0.2:55678"}, changes[0])
+
+	// Unchanged endpoints aren't notified.
+	res.resolve()
+	assert.Len(t, changes, 1)
+
+	// Failed resolutions keep the previous endpoints.
+	lookupErr = errors.New("no such host")
+	res.resolve()
+	assert.Len(t, changes, 1)
+
+	lookupErr = nil
+	addrs = addrs[:1]
+	res.resolve()
+	require.Len(t, changes, 2)
+	assert.Equal(t, []string{"10.0.0.2:55678"}, changes[1])
+}
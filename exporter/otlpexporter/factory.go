@@ -0,0 +1,121 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/open-telemetry/opentelemetry-service/compression"
+	compressiongrpc "github.com/open-telemetry/opentelemetry-service/compression/grpc"
+	"github.com/open-telemetry/opentelemetry-service/config/configgrpc"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "otlp"
+)
+
+// Factory is the factory for OTLP exporter.
+type Factory struct {
+}
+
+// Type gets the type of the Exporter config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for exporter.
+func (f *Factory) CreateDefaultConfig() configmodels.Exporter {
+	return &Config{
+		ExporterSettings: configmodels.ExporterSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+		GRPCSettings: configgrpc.GRPCSettings{
+			Headers: map[string]string{},
+		},
+	}
+}
+
+// CreateTraceExporter creates a trace exporter based on this config.
+func (f *Factory) CreateTraceExporter(logger *zap.Logger, config configmodels.Exporter) (exporter.TraceExporter, error) {
+	oCfg := config.(*Config)
+	opts, err := f.GRPCDialOptions(oCfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewTraceExporter(config, opts...)
+}
+
+// CreateMetricsExporter creates a metrics exporter based on this config.
+func (f *Factory) CreateMetricsExporter(logger *zap.Logger, config configmodels.Exporter) (exporter.MetricsExporter, error) {
+	oCfg := config.(*Config)
+	opts, err := f.GRPCDialOptions(oCfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewMetricsExporter(config, opts...)
+}
+
+// GRPCDialOptions takes the OTLP exporter Config and generates the options
+// used to dial its endpoint.
+func (f *Factory) GRPCDialOptions(oCfg *Config) ([]grpc.DialOption, error) {
+	if oCfg.Endpoint == "" {
+		return nil, fmt.Errorf("%q config requires a non-empty \"endpoint\"", oCfg.Name())
+	}
+
+	var opts []grpc.DialOption
+	if oCfg.Compression != "" {
+		compressionKey := compressiongrpc.GetGRPCCompressionKey(oCfg.Compression)
+		if compressionKey == compression.Unsupported {
+			return nil, fmt.Errorf("OTLP exporter unsupported compression type %q", oCfg.Compression)
+		}
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(compressionKey)))
+	}
+
+	switch {
+	case oCfg.CertPemFile != "":
+		creds, err := credentials.NewClientTLSFromFile(oCfg.CertPemFile, "")
+		if err != nil {
+			return nil, fmt.Errorf("OTLP exporter unable to read TLS credentials from pem file %q: %v", oCfg.CertPemFile, err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(creds))
+	case oCfg.UseSecure:
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("OTLP exporter unable to read certificates from system pool: %v", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(certPool, "")))
+	default:
+		opts = append(opts, grpc.WithInsecure())
+	}
+
+	if oCfg.KeepaliveParameters != nil {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                oCfg.KeepaliveParameters.Time,
+			Timeout:             oCfg.KeepaliveParameters.Timeout,
+			PermitWithoutStream: oCfg.KeepaliveParameters.PermitWithoutStream,
+		}))
+	}
+	return opts, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/compression"
+	"github.com/open-telemetry/opentelemetry-service/config/configgrpc"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+}
+
+func TestCreateMetricsExporter(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPCSettings.Endpoint = testutils.GetAvailableLocalAddress(t)
+
+	oexp, err := factory.CreateMetricsExporter(zap.NewNop(), cfg)
+	require.Nil(t, err)
+	require.NotNil(t, oexp)
+	assert.Nil(t, oexp.Shutdown())
+}
+
+func TestCreateTraceExporter(t *testing.T) {
+	endpoint := testutils.GetAvailableLocalAddress(t)
+	tests := []struct {
+		name     string
+		config   Config
+		mustFail bool
+	}{
+		{
+			name:     "NoEndpoint",
+			config:   Config{},
+			mustFail: true,
+		},
+		{
+			name: "UseSecure",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint:  endpoint,
+					UseSecure: true,
+				},
+			},
+		},
+		{
+			name: "KeepaliveParameters",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint: endpoint,
+					KeepaliveParameters: &configgrpc.KeepaliveConfig{
+						Time:                30 * time.Second,
+						Timeout:             25 * time.Second,
+						PermitWithoutStream: true,
+					},
+				},
+			},
+		},
+		{
+			name: "Compression",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint:    endpoint,
+					Compression: compression.Gzip,
+				},
+			},
+		},
+		{
+			name: "Headers",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint: endpoint,
+					Headers: map[string]string{
+						"hdr1": "val1",
+						"hdr2": "val2",
+					},
+				},
+			},
+		},
+		{
+			name: "NumWorkers",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint: endpoint,
+				},
+				NumWorkers: 3,
+			},
+		},
+		{
+			name: "CompressionError",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint:    endpoint,
+					Compression: "unknown compression",
+				},
+			},
+			mustFail: true,
+		},
+		{
+			name: "CertPemFile",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint:    endpoint,
+					CertPemFile: "testdata/test_cert.pem",
+				},
+			},
+		},
+		{
+			name: "CertPemFileError",
+			config: Config{
+				GRPCSettings: configgrpc.GRPCSettings{
+					Endpoint:    endpoint,
+					CertPemFile: "nosuchfile",
+				},
+			},
+			mustFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := &Factory{}
+			tt.config.TypeVal = typeStr
+			tt.config.NameVal = typeStr
+			consumer, err := factory.CreateTraceExporter(zap.NewNop(), &tt.config)
+
+			if tt.mustFail {
+				assert.NotNil(t, err)
+			} else {
+				require.Nil(t, err)
+				require.NotNil(t, consumer)
+				assert.Nil(t, consumer.Shutdown())
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlpexporter exports traces and metrics to an endpoint that
+// implements the OpenTelemetry protocol (OTLP) over gRPC.
+package otlpexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	collectormetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/metrics/v1"
+	collectortrace "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exporterhelper"
+	"github.com/open-telemetry/opentelemetry-service/translator/otlp"
+)
+
+const (
+	defaultNumWorkers = 2
+
+	// retryAfterMetadataKey is the metadata used by the receivers of the
+	// service to tell how long to wait before sending throttled data again.
+	retryAfterMetadataKey = "retry-after"
+)
+
+var errAlreadyStopped = errors.New("OTLP exporter was already stopped")
+
+type otlpExporter struct {
+	conn          *grpc.ClientConn
+	traceClient   collectortrace.TraceServiceClient
+	metricsClient collectormetrics.MetricsServiceClient
+	metadata      metadata.MD
+	// workers holds one token per request that can be in flight, it is
+	// closed on shutdown.
+	workers chan struct{}
+}
+
+// NewTraceExporter creates an OTLP trace exporter.
+func NewTraceExporter(config configmodels.Exporter, opts ...grpc.DialOption) (exporter.TraceExporter, error) {
+	oe, err := newOTLPExporter(config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewTraceExporter(
+		config,
+		oe.pushTraceData,
+		exporterhelper.WithTracing(true),
+		exporterhelper.WithMetrics(true),
+		exporterhelper.WithShutdown(oe.shutdown))
+}
+
+// NewMetricsExporter creates an OTLP metrics exporter.
+func NewMetricsExporter(config configmodels.Exporter, opts ...grpc.DialOption) (exporter.MetricsExporter, error) {
+	oe, err := newOTLPExporter(config, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewMetricsExporter(
+		config,
+		oe.pushMetricsData,
+		exporterhelper.WithTracing(true),
+		exporterhelper.WithMetrics(true),
+		exporterhelper.WithShutdown(oe.shutdown))
+}
+
+func newOTLPExporter(config configmodels.Exporter, opts ...grpc.DialOption) (*otlpExporter, error) {
+	oCfg := config.(*Config)
+	conn, err := grpc.Dial(oCfg.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure OTLP exporter: %v", err)
+	}
+
+	numWorkers := defaultNumWorkers
+	if oCfg.NumWorkers > 0 {
+		numWorkers = oCfg.NumWorkers
+	}
+	oe := &otlpExporter{
+		conn:          conn,
+		traceClient:   collectortrace.NewTraceServiceClient(conn),
+		metricsClient: collectormetrics.NewMetricsServiceClient(conn),
+		workers:       make(chan struct{}, numWorkers),
+	}
+	if len(oCfg.Headers) > 0 {
+		oe.metadata = metadata.New(oCfg.Headers)
+	}
+	for i := 0; i < numWorkers; i++ {
+		oe.workers <- struct{}{}
+	}
+	return oe, nil
+}
+
+// shutdown waits for the requests in flight and closes the connection.
+func (oe *otlpExporter) shutdown() error {
+	for i := 0; i < cap(oe.workers); i++ {
+		<-oe.workers
+	}
+	close(oe.workers)
+	return oe.conn.Close()
+}
+
+func (oe *otlpExporter) pushTraceData(ctx context.Context, td consumerdata.TraceData) (int, error) {
+	req := &collectortrace.ExportTraceServiceRequest{
+		ResourceSpans: otlp.TraceDataToResourceSpans(td),
+	}
+	err := oe.export(ctx, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := oe.traceClient.Export(ctx, req, opts...)
+		return err
+	})
+	if err != nil {
+		return len(td.Spans), err
+	}
+	return 0, nil
+}
+
+func (oe *otlpExporter) pushMetricsData(ctx context.Context, md consumerdata.MetricsData) (int, error) {
+	req := &collectormetrics.ExportMetricsServiceRequest{
+		ResourceMetrics: otlp.MetricsDataToResourceMetrics(md),
+	}
+	err := oe.export(ctx, func(ctx context.Context, opts ...grpc.CallOption) error {
+		_, err := oe.metricsClient.Export(ctx, req, opts...)
+		return err
+	})
+	if err != nil {
+		return exporterhelper.NumTimeSeries(md), err
+	}
+	return 0, nil
+}
+
+// export sends a request once a worker is available and converts the error
+// returned by the endpoint so the pipeline knows if and when the data can be
+// sent again.
+func (oe *otlpExporter) export(ctx context.Context, send func(context.Context, ...grpc.CallOption) error) error {
+	if _, ok := <-oe.workers; !ok {
+		return errAlreadyStopped
+	}
+	defer func() { oe.workers <- struct{}{} }()
+
+	if oe.metadata != nil {
+		ctx = metadata.NewOutgoingContext(ctx, oe.metadata)
+	}
+	var header metadata.MD
+	err := send(ctx, grpc.Header(&header))
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return consumererror.Permanent(err)
+	case codes.ResourceExhausted:
+		return consumererror.Throttled(err, retryAfter(header))
+	}
+	return err
+}
+
+// retryAfter returns the delay set by the endpoint in the retry-after
+// metadata, or zero if there is none.
+func retryAfter(header metadata.MD) time.Duration {
+	values := header.Get(retryAfterMetadataKey)
+	if len(values) == 0 {
+		return 0
+	}
+	seconds, err := strconv.Atoi(values[0])
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,186 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	collectormetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/metrics/v1"
+	collectortrace "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configgrpc"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+)
+
+func TestPushTraceData(t *testing.T) {
+	client := &fakeTraceClient{}
+	oe := newTestExporter(t, map[string]string{"api-key": "secret"})
+	oe.traceClient = client
+
+	td := consumerdata.TraceData{
+		Spans: []*tracepb.Span{
+			{
+				TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				Name:    &tracepb.TruncatableString{Value: "operation"},
+			},
+		},
+	}
+	dropped, err := oe.pushTraceData(context.Background(), td)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+
+	require.Len(t, client.requests, 1)
+	spans := client.requests[0].ResourceSpans[0].InstrumentationLibrarySpans[0].Spans
+	require.Len(t, spans, 1)
+	assert.Equal(t, "operation", spans[0].Name)
+	assert.Equal(t, []string{"secret"}, client.md.Get("api-key"))
+
+	require.NoError(t, oe.shutdown())
+	dropped, err = oe.pushTraceData(context.Background(), td)
+	assert.Equal(t, errAlreadyStopped, err)
+	assert.Equal(t, 1, dropped)
+}
+
+func TestPushMetricsData_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		header        metadata.MD
+		wantPermanent bool
+		wantThrottled bool
+		wantDelay     time.Duration
+	}{
+		{
+			name:          "invalid_argument",
+			err:           status.Error(codes.InvalidArgument, "bad data"),
+			wantPermanent: true,
+		},
+		{
+			name:          "resource_exhausted",
+			err:           status.Error(codes.ResourceExhausted, "queue is full"),
+			header:        metadata.Pairs(retryAfterMetadataKey, "7"),
+			wantThrottled: true,
+			wantDelay:     7 * time.Second,
+		},
+		{
+			name: "unavailable",
+			err:  status.Error(codes.Unavailable, "unavailable"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oe := newTestExporter(t, nil)
+			oe.metricsClient = &fakeMetricsClient{err: tt.err, header: tt.header}
+			defer oe.shutdown()
+
+			_, err := oe.pushMetricsData(context.Background(), consumerdata.MetricsData{})
+			require.Error(t, err)
+			assert.Equal(t, tt.wantPermanent, consumererror.IsPermanent(err))
+			assert.Equal(t, tt.wantThrottled, consumererror.IsThrottled(err))
+			assert.Equal(t, tt.wantDelay, consumererror.RetryAfter(err))
+		})
+	}
+}
+
+func TestExport_LimitsConcurrentRequests(t *testing.T) {
+	oe := newTestExporter(t, nil)
+	defer oe.shutdown()
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	release := make(chan struct{})
+	send := func(context.Context, ...grpc.CallOption) error {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, oe.export(context.Background(), send))
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 2, maxInFlight)
+}
+
+func newTestExporter(t *testing.T, headers map[string]string) *otlpExporter {
+	cfg := &Config{
+		ExporterSettings: configmodels.ExporterSettings{TypeVal: typeStr, NameVal: typeStr},
+		GRPCSettings: configgrpc.GRPCSettings{
+			Endpoint: "localhost:0",
+			Headers:  headers,
+		},
+		NumWorkers: 2,
+	}
+	oe, err := newOTLPExporter(cfg, grpc.WithInsecure())
+	require.NoError(t, err)
+	return oe
+}
+
+type fakeTraceClient struct {
+	requests []*collectortrace.ExportTraceServiceRequest
+	md       metadata.MD
+}
+
+func (c *fakeTraceClient) Export(ctx context.Context, in *collectortrace.ExportTraceServiceRequest, opts ...grpc.CallOption) (*collectortrace.ExportTraceServiceResponse, error) {
+	c.requests = append(c.requests, in)
+	c.md, _ = metadata.FromOutgoingContext(ctx)
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+type fakeMetricsClient struct {
+	err    error
+	header metadata.MD
+}
+
+func (c *fakeMetricsClient) Export(ctx context.Context, in *collectormetrics.ExportMetricsServiceRequest, opts ...grpc.CallOption) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	for _, opt := range opts {
+		if h, ok := opt.(grpc.HeaderCallOption); ok {
+			*h.HeaderAddr = c.header
+		}
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package adaptivesampling contains the logic shared by the processors
+// sampling traces adaptively: the sampling probability of each service and
+// operation is adjusted continuously to sample a target number of traces per
+// second for each of them.
+package adaptivesampling
+
+import (
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplinghash"
+)
+
+// minProbability is the smallest probability that samples some trace IDs.
+const minProbability = 1.0 / samplinghash.NumHashBuckets
+
+// defaultMaxKeys bounds the number of services and operations sampled with
+// their own probability. Once it is reached, the traces of new keys share the
+// probability of an overflow key until keys are forgotten.
+const defaultMaxKeys = 10000
+
+// overflowKey is the key of the traces of new keys once the maximum number of
+// keys is reached. It can't collide with a real key since service names and
+// operations don't contain a NUL character.
+var overflowKey = key{service: "\x00overflow"}
+
+// Sampler samples traces with a probability per service and operation. The
+// probability of each key is adjusted at each interval from the number of
+// traces seen for the key during the interval, so that about the target
+// number of traces per second is sampled for each key.
+type Sampler struct {
+	mutex           sync.Mutex
+	tracesPerSecond float64
+	interval        time.Duration
+	hashSeed        uint32
+	keys            map[key]*keyState
+	maxKeys         int
+	lastAdjustment  time.Time
+	// now is replaced by tests to control the time.
+	now func() time.Time
+}
+
+type key struct {
+	service   string
+	operation string
+}
+
+type keyState struct {
+	// count is the number of traces seen for the key since the last
+	// adjustment.
+	count int64
+	// rate is the smoothed number of traces per second seen for the key, it
+	// is negative until the first adjustment.
+	rate        float64
+	probability float64
+}
+
+// NewSampler returns a Sampler targeting tracesPerSecond traces per key,
+// adjusting the probabilities at every interval. The hash seed is used to
+// hash the trace IDs as done by samplinghash.
+func NewSampler(tracesPerSecond float64, interval time.Duration, hashSeed uint32) *Sampler {
+	return &Sampler{
+		tracesPerSecond: tracesPerSecond,
+		interval:        interval,
+		hashSeed:        hashSeed,
+		keys:            make(map[key]*keyState),
+		maxKeys:         defaultMaxKeys,
+		now:             time.Now,
+	}
+}
+
+// Sample counts a trace of the service and operation and returns whether the
+// trace with the given ID is sampled, along with the probability used for the
+// decision. The decision is the same for all the calls made for a trace with
+// the same probability. A new key is sampled with a probability of 1 until the
+// next adjustment.
+func (s *Sampler) Sample(service, operation string, traceID []byte) (bool, float64) {
+	s.mutex.Lock()
+	s.adjustIfDue()
+	k := s.keyFor(service, operation)
+	state := s.keys[k]
+	if state == nil {
+		state = &keyState{rate: -1, probability: 1}
+		s.keys[k] = state
+	}
+	state.count++
+	probability := state.probability
+	s.mutex.Unlock()
+
+	scaledRate := samplinghash.ScaledSamplingRate(float32(probability * 100))
+	return samplinghash.IsSampled(traceID, s.hashSeed, scaledRate), probability
+}
+
+// Probability returns the current sampling probability of the service and
+// operation.
+func (s *Sampler) Probability(service, operation string) float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if state := s.keys[s.keyFor(service, operation)]; state != nil {
+		return state.probability
+	}
+	return 1
+}
+
+// keyFor returns the key of the service and operation, or the overflow key
+// if it is a new key and the maximum number of keys is reached. It must be
+// called with the mutex held.
+func (s *Sampler) keyFor(service, operation string) key {
+	k := key{service: service, operation: operation}
+	if _, ok := s.keys[k]; ok || len(s.keys) < s.maxKeys {
+		return k
+	}
+	return overflowKey
+}
+
+// adjustIfDue adjusts the probabilities if the interval elapsed since the
+// last adjustment. It must be called with the mutex held.
+func (s *Sampler) adjustIfDue() {
+	now := s.now()
+	if s.lastAdjustment.IsZero() {
+		s.lastAdjustment = now
+		return
+	}
+	elapsed := now.Sub(s.lastAdjustment)
+	if elapsed < s.interval {
+		return
+	}
+	s.lastAdjustment = now
+
+	for k, state := range s.keys {
+		if state.count == 0 {
+			// Forget the keys without traces so that the memory is bounded by
+			// the number of active keys.
+			delete(s.keys, k)
+			continue
+		}
+		rate := float64(state.count) / elapsed.Seconds()
+		if state.rate < 0 {
+			state.rate = rate
+		} else {
+			// Smooth the rate to avoid oscillating probabilities.
+			state.rate = (state.rate + rate) / 2
+		}
+		state.count = 0
+		state.probability = probabilityFor(s.tracesPerSecond, state.rate)
+	}
+}
+
+func probabilityFor(tracesPerSecond, rate float64) float64 {
+	if rate <= tracesPerSecond {
+		return 1
+	}
+	probability := tracesPerSecond / rate
+	if probability < minProbability {
+		return minProbability
+	}
+	return probability
+}
@@ -0,0 +1,118 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesampling
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func TestSampler(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewSampler(10, 10*time.Second, 0)
+	s.now = func() time.Time { return now }
+
+	sampleTraces := func(service, operation string, count int) int {
+		sampled := 0
+		for i := 0; i < count; i++ {
+			id := tracetranslator.UInt64ToByteTraceID(uint64(i), uint64(i)*7919)
+			if ok, _ := s.Sample(service, operation, id); ok {
+				sampled++
+			}
+		}
+		return sampled
+	}
+
+	// New keys are sampled until the first adjustment.
+	assert.Equal(t, 1000, sampleTraces("checkout", "pay", 1000))
+	assert.Equal(t, 50, sampleTraces("checkout", "list", 50))
+	assert.Equal(t, 1.0, s.Probability("checkout", "pay"))
+
+	// 100 traces per second are seen for the chatty operation, 5 for the
+	// quiet one.
+	now = now.Add(10 * time.Second)
+	sampleTraces("cart", "add", 1)
+	assert.InDelta(t, 0.1, s.Probability("checkout", "pay"), 1e-9)
+	assert.Equal(t, 1.0, s.Probability("checkout", "list"))
+
+	sampled := sampleTraces("checkout", "pay", 1000)
+	assert.InDelta(t, 100, sampled, 30)
+	_, probability := s.Sample("checkout", "pay", tracetranslator.UInt64ToByteTraceID(1, 2))
+	assert.InDelta(t, 0.1, probability, 1e-9)
+
+	// All the traces seen count for the rate, not only the sampled ones, and
+	// the rate is smoothed with the previous one.
+	sampleTraces("checkout", "pay", 4000)
+	now = now.Add(10 * time.Second)
+	sampleTraces("cart", "add", 1)
+	assert.InDelta(t, 10/((100+500.1)/2), s.Probability("checkout", "pay"), 1e-9)
+
+	// Keys without traces are forgotten at the next adjustment.
+	now = now.Add(10 * time.Second)
+	sampleTraces("cart", "add", 1)
+	_, ok := s.keys[key{service: "checkout", operation: "pay"}]
+	assert.False(t, ok)
+}
+
+func TestSampler_MaxKeys(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewSampler(1, time.Second, 0)
+	s.now = func() time.Time { return now }
+	s.maxKeys = 2
+
+	id := tracetranslator.UInt64ToByteTraceID(1, 2)
+	s.Sample("svc", "a", id)
+	s.Sample("svc", "b", id)
+	// The new keys share the overflow key once the limit is reached.
+	for i := 0; i < 10; i++ {
+		s.Sample("svc", fmt.Sprintf("op%d", i), id)
+	}
+	assert.Len(t, s.keys, 3)
+	assert.Equal(t, int64(10), s.keys[overflowKey].count)
+
+	// The overflow key is adjusted like the others.
+	now = now.Add(time.Second)
+	s.Sample("svc", "a", id)
+	assert.InDelta(t, 0.1, s.Probability("svc", "op42"), 1e-9)
+	assert.Equal(t, 1.0, s.Probability("svc", "a"))
+}
+
+func TestSampler_SameDecisionForTrace(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := NewSampler(1, time.Second, 0)
+	s.now = func() time.Time { return now }
+	for i := 0; i < 1000; i++ {
+		s.Sample("svc", "op", tracetranslator.UInt64ToByteTraceID(0, uint64(i)))
+	}
+	now = now.Add(time.Second)
+	id := tracetranslator.UInt64ToByteTraceID(3, 4)
+	first, _ := s.Sample("svc", "op", id)
+	for i := 0; i < 10; i++ {
+		sampled, _ := s.Sample("svc", "op", id)
+		assert.Equal(t, first, sampled)
+	}
+}
+
+func TestProbabilityFor(t *testing.T) {
+	assert.Equal(t, 1.0, probabilityFor(10, 0))
+	assert.Equal(t, 1.0, probabilityFor(10, 10))
+	assert.Equal(t, 0.5, probabilityFor(10, 20))
+	assert.Equal(t, minProbability, probabilityFor(1, 1e9))
+}
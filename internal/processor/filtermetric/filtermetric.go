@@ -0,0 +1,143 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filtermetric contains the logic shared by processors to decide if a
+// metric timeseries matches a set of properties, such as the metric name or
+// its label values.
+package filtermetric
+
+import (
+	"errors"
+	"fmt"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterset"
+)
+
+var errEmptyMatchProperties = errors.New("at least one of \"metric_names\" or \"labels\" must be specified")
+
+// MatchProperties specifies the set of properties of a metric timeseries to
+// match against. At least one of metric names or labels must be specified.
+// It is supported to have both specified, but this requires all of the
+// properties to match for a match to occur.
+type MatchProperties struct {
+	// MatchType specifies how metric names and label values are matched.
+	// The set of values are {strict, regexp}, strict is the default.
+	MatchType filterset.MatchType `mapstructure:"match_type"`
+
+	// MetricNames specify the list of metric names to match against.
+	// A match occurs if the metric name matches one in this list.
+	// Note: This is an optional field.
+	MetricNames []string `mapstructure:"metric_names"`
+
+	// Labels specifies the list of labels to match against.
+	// All of these labels must match for a match to occur.
+	// Note: This is an optional field.
+	Labels []Label `mapstructure:"labels"`
+}
+
+// Label specifies the label key and optional value to match against.
+type Label struct {
+	// Key specifies the label key.
+	Key string `mapstructure:"key"`
+
+	// Value specifies the value to match against.
+	// If it is not set, any value will match as long as the label is set.
+	Value string `mapstructure:"value"`
+}
+
+// Matcher decides if a metric timeseries matches a set of properties.
+type Matcher interface {
+	// MatchTimeSeries returns true if the timeseries of the given metric
+	// matches the properties of the matcher.
+	MatchTimeSeries(metric *metricspb.Metric, ts *metricspb.TimeSeries) bool
+}
+
+type propertiesMatcher struct {
+	// names is nil if not specified.
+	names  filterset.FilterSet
+	labels []labelMatcher
+}
+
+type labelMatcher struct {
+	key string
+	// values is nil if any value of the label matches.
+	values filterset.FilterSet
+}
+
+var _ Matcher = (*propertiesMatcher)(nil)
+
+// NewMatcher validates the properties and returns a Matcher for them.
+func NewMatcher(mp *MatchProperties) (Matcher, error) {
+	if len(mp.MetricNames) == 0 && len(mp.Labels) == 0 {
+		return nil, errEmptyMatchProperties
+	}
+
+	var err error
+	pm := &propertiesMatcher{}
+	if len(mp.MetricNames) > 0 {
+		if pm.names, err = filterset.NewFilterSet(mp.MetricNames, mp.MatchType); err != nil {
+			return nil, fmt.Errorf("error creating metric name filters: %v", err)
+		}
+	}
+	for i, label := range mp.Labels {
+		if label.Key == "" {
+			return nil, fmt.Errorf("missing required field \"key\" at the %d-th labels", i)
+		}
+		lm := labelMatcher{key: label.Key}
+		if label.Value != "" {
+			if lm.values, err = filterset.NewFilterSet([]string{label.Value}, mp.MatchType); err != nil {
+				return nil, fmt.Errorf("error creating label value filter at the %d-th labels: %v", i, err)
+			}
+		}
+		pm.labels = append(pm.labels, lm)
+	}
+	return pm, nil
+}
+
+// MatchTimeSeries implements the Matcher interface.
+func (pm *propertiesMatcher) MatchTimeSeries(metric *metricspb.Metric, ts *metricspb.TimeSeries) bool {
+	desc := metric.GetMetricDescriptor()
+	if pm.names != nil && !pm.names.Matches(desc.GetName()) {
+		return false
+	}
+	for _, lm := range pm.labels {
+		value, ok := LabelValue(desc, ts, lm.key)
+		if !ok {
+			return false
+		}
+		if lm.values != nil && !lm.values.Matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// LabelValue returns the value of the label with the given key in the
+// timeseries. The second return value is false if the metric doesn't have
+// the label or the timeseries doesn't have a value for it.
+func LabelValue(desc *metricspb.MetricDescriptor, ts *metricspb.TimeSeries, key string) (string, bool) {
+	for i, lk := range desc.GetLabelKeys() {
+		if lk.GetKey() != key {
+			continue
+		}
+		if i >= len(ts.GetLabelValues()) {
+			return "", false
+		}
+		lv := ts.LabelValues[i]
+		return lv.GetValue(), lv.GetHasValue()
+	}
+	return "", false
+}
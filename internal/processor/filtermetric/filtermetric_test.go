@@ -0,0 +1,114 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filtermetric
+
+import (
+	"testing"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMatcher_Invalid(t *testing.T) {
+	_, err := NewMatcher(&MatchProperties{})
+	assert.Equal(t, errEmptyMatchProperties, err)
+
+	_, err = NewMatcher(&MatchProperties{Labels: []Label{{Value: "v"}}})
+	assert.Error(t, err)
+
+	_, err = NewMatcher(&MatchProperties{MatchType: "regexp", MetricNames: []string{"("}})
+	assert.Error(t, err)
+
+	_, err = NewMatcher(&MatchProperties{MatchType: "regexp", Labels: []Label{{Key: "k", Value: "("}}})
+	assert.Error(t, err)
+}
+
+func TestMatchTimeSeries(t *testing.T) {
+	metric := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      "process/cpu_seconds",
+			LabelKeys: []*metricspb.LabelKey{{Key: "host"}, {Key: "env"}, {Key: "missing"}},
+		},
+	}
+	ts := &metricspb.TimeSeries{
+		LabelValues: []*metricspb.LabelValue{
+			{Value: "host-1", HasValue: true},
+			{Value: "", HasValue: false},
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		properties MatchProperties
+		want       bool
+	}{
+		{
+			name:       "name match",
+			properties: MatchProperties{MetricNames: []string{"process/cpu_seconds"}},
+			want:       true,
+		},
+		{
+			name:       "regexp name match",
+			properties: MatchProperties{MatchType: "regexp", MetricNames: []string{"process/.*"}},
+			want:       true,
+		},
+		{
+			name:       "name mismatch",
+			properties: MatchProperties{MetricNames: []string{"process/.*"}},
+			want:       false,
+		},
+		{
+			name:       "label value match",
+			properties: MatchProperties{Labels: []Label{{Key: "host", Value: "host-1"}}},
+			want:       true,
+		},
+		{
+			name:       "regexp label value match",
+			properties: MatchProperties{MatchType: "regexp", Labels: []Label{{Key: "host", Value: "host-.*"}}},
+			want:       true,
+		},
+		{
+			name:       "label without value",
+			properties: MatchProperties{Labels: []Label{{Key: "env"}}},
+			want:       false,
+		},
+		{
+			name:       "label missing from timeseries",
+			properties: MatchProperties{Labels: []Label{{Key: "missing"}}},
+			want:       false,
+		},
+		{
+			name:       "unknown label",
+			properties: MatchProperties{Labels: []Label{{Key: "unknown"}}},
+			want:       false,
+		},
+		{
+			name: "name and label",
+			properties: MatchProperties{
+				MetricNames: []string{"process/cpu_seconds"},
+				Labels:      []Label{{Key: "host", Value: "host-2"}},
+			},
+			want: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewMatcher(&tc.properties)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, m.MatchTimeSeries(metric, ts))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filterset provides a set of strings that can be matched either
+// exactly or against regular expressions. It is used by processors to select
+// spans and metrics by name.
+package filterset
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchType specifies how the strings of a FilterSet are matched.
+type MatchType string
+
+const (
+	// Strict matches strings that are exactly equal to one of the filters.
+	Strict MatchType = "strict"
+
+	// Regexp matches strings that match one of the filters, interpreted as
+	// regular expressions. The expressions are anchored at both ends.
+	Regexp MatchType = "regexp"
+)
+
+// FilterSet is a set of filters a string can be matched against.
+type FilterSet interface {
+	// Matches returns true if the given string matches any of the filters.
+	Matches(s string) bool
+}
+
+// NewFilterSet returns a FilterSet for the filters using the given match type.
+// An empty match type is handled as Strict.
+func NewFilterSet(filters []string, matchType MatchType) (FilterSet, error) {
+	switch MatchType(strings.ToLower(string(matchType))) {
+	case Strict, "":
+		fs := make(strictFilterSet, len(filters))
+		for _, f := range filters {
+			fs[f] = true
+		}
+		return fs, nil
+	case Regexp:
+		fs := make(regexpFilterSet, 0, len(filters))
+		for _, f := range filters {
+			re, err := regexp.Compile("^(?:" + f + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regexp %q: %v", f, err)
+			}
+			fs = append(fs, re)
+		}
+		return fs, nil
+	default:
+		return nil, fmt.Errorf("unsupported match type %q", matchType)
+	}
+}
+
+type strictFilterSet map[string]bool
+
+func (fs strictFilterSet) Matches(s string) bool {
+	return fs[s]
+}
+
+type regexpFilterSet []*regexp.Regexp
+
+func (fs regexpFilterSet) Matches(s string) bool {
+	for _, re := range fs {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFilterSet(t *testing.T) {
+	testCases := []struct {
+		name      string
+		filters   []string
+		matchType MatchType
+		matches   []string
+		misses    []string
+	}{
+		{
+			name:    "default is strict",
+			filters: []string{"health", "ready"},
+			matches: []string{"health", "ready"},
+			misses:  []string{"healthz", "", "Health"},
+		},
+		{
+			name:      "strict",
+			filters:   []string{"a.b"},
+			matchType: Strict,
+			matches:   []string{"a.b"},
+			misses:    []string{"axb"},
+		},
+		{
+			name:      "regexp",
+			filters:   []string{"process/.*", "go_gc"},
+			matchType: "REGEXP",
+			matches:   []string{"process/cpu_seconds", "go_gc"},
+			misses:    []string{"my/process/cpu", "go_gc_total"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs, err := NewFilterSet(tc.filters, tc.matchType)
+			require.NoError(t, err)
+			for _, s := range tc.matches {
+				assert.True(t, fs.Matches(s), s)
+			}
+			for _, s := range tc.misses {
+				assert.False(t, fs.Matches(s), s)
+			}
+		})
+	}
+}
+
+func TestNewFilterSet_Invalid(t *testing.T) {
+	_, err := NewFilterSet([]string{"("}, Regexp)
+	assert.Error(t, err)
+
+	_, err = NewFilterSet([]string{"a"}, "glob")
+	assert.Error(t, err)
+}
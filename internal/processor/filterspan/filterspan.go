@@ -0,0 +1,247 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filterspan contains the logic shared by processors to decide if a
+// span matches a set of properties, such as its service name or attributes.
+package filterspan
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/spf13/cast"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterset"
+)
+
+var errEmptyMatchProperties = errors.New("at least one of \"services\", \"span_names\", \"span_kinds\" or \"attributes\" must be specified")
+
+// MatchProperties specifies the set of properties in a span to match against.
+// At least one of services, span names, span kinds or attributes must be
+// specified. It is supported to have several of them specified, but this
+// requires all of the properties to match for a match to occur.
+type MatchProperties struct {
+	// MatchType specifies how services and span names are matched.
+	// The set of values are {strict, regexp}, strict is the default.
+	MatchType filterset.MatchType `mapstructure:"match_type"`
+
+	// Services specify the list of service name to match against.
+	// A match occurs if the span service name matches one in this list.
+	// Note: This is an optional field.
+	Services []string `mapstructure:"services"`
+
+	// SpanNames specify the list of span names to match against.
+	// A match occurs if the span name matches one in this list.
+	// Note: This is an optional field.
+	SpanNames []string `mapstructure:"span_names"`
+
+	// SpanKinds specify the list of span kinds to match against, e.g. "SERVER"
+	// or "CLIENT". A match occurs if the span kind is in this list.
+	// Note: This is an optional field.
+	SpanKinds []string `mapstructure:"span_kinds"`
+
+	// Attributes specifies the list of attributes to match against.
+	// All of these attributes must match exactly for a match to occur.
+	// Note: This is an optional field.
+	Attributes []Attribute `mapstructure:"attributes"`
+}
+
+// Attribute specifies the attribute key and optional value to match against.
+type Attribute struct {
+	// Key specifies the attribute key.
+	Key string `mapstructure:"key"`
+
+	// Value specifies the value to match against.
+	// If it is not set, any value will match.
+	Value interface{} `mapstructure:"value"`
+}
+
+// Matcher decides if a span matches a set of properties.
+type Matcher interface {
+	// MatchSpan returns true if the span, belonging to the service with the
+	// given name, matches the properties of the matcher.
+	MatchSpan(span *tracepb.Span, serviceName string) bool
+}
+
+type propertiesMatcher struct {
+	// services, spanNames and spanKinds are nil if not specified.
+	services   filterset.FilterSet
+	spanNames  filterset.FilterSet
+	spanKinds  map[tracepb.Span_SpanKind]bool
+	attributes []attributeMatcher
+}
+
+type attributeMatcher struct {
+	key string
+	// value is nil if any value of the attribute matches.
+	value *tracepb.AttributeValue
+}
+
+var _ Matcher = (*propertiesMatcher)(nil)
+
+// NewMatcher validates the properties and returns a Matcher for them.
+func NewMatcher(mp *MatchProperties) (Matcher, error) {
+	if len(mp.Services) == 0 && len(mp.SpanNames) == 0 && len(mp.SpanKinds) == 0 && len(mp.Attributes) == 0 {
+		return nil, errEmptyMatchProperties
+	}
+
+	var err error
+	pm := &propertiesMatcher{}
+	if len(mp.Services) > 0 {
+		if pm.services, err = filterset.NewFilterSet(mp.Services, mp.MatchType); err != nil {
+			return nil, fmt.Errorf("error creating service name filters: %v", err)
+		}
+	}
+	if len(mp.SpanNames) > 0 {
+		if pm.spanNames, err = filterset.NewFilterSet(mp.SpanNames, mp.MatchType); err != nil {
+			return nil, fmt.Errorf("error creating span name filters: %v", err)
+		}
+	}
+	if len(mp.SpanKinds) > 0 {
+		pm.spanKinds = make(map[tracepb.Span_SpanKind]bool, len(mp.SpanKinds))
+		for _, kind := range mp.SpanKinds {
+			k, ok := tracepb.Span_SpanKind_value[strings.ToUpper(kind)]
+			if !ok {
+				return nil, fmt.Errorf("unsupported span kind %q", kind)
+			}
+			pm.spanKinds[tracepb.Span_SpanKind(k)] = true
+		}
+	}
+
+	for i, attribute := range mp.Attributes {
+		if attribute.Key == "" {
+			return nil, fmt.Errorf("missing required field \"key\" at the %d-th attributes", i)
+		}
+		am := attributeMatcher{key: attribute.Key}
+		if attribute.Value != nil {
+			val, err := AttributeValue(attribute.Value)
+			if err != nil {
+				return nil, err
+			}
+			am.value = val
+		}
+		pm.attributes = append(pm.attributes, am)
+	}
+
+	return pm, nil
+}
+
+// MatchSpan implements the Matcher interface.
+func (mp *propertiesMatcher) MatchSpan(span *tracepb.Span, serviceName string) bool {
+	if mp.services != nil && !mp.services.Matches(serviceName) {
+		return false
+	}
+	if mp.spanNames != nil && !mp.spanNames.Matches(span.Name.GetValue()) {
+		return false
+	}
+	if mp.spanKinds != nil && !mp.spanKinds[span.Kind] {
+		return false
+	}
+	return mp.matchAttributes(span)
+}
+
+func (mp *propertiesMatcher) matchAttributes(span *tracepb.Span) bool {
+	if len(mp.attributes) == 0 {
+		return true
+	}
+	if span.Attributes == nil || len(span.Attributes.AttributeMap) == 0 {
+		return false
+	}
+	for _, am := range mp.attributes {
+		attr, found := span.Attributes.AttributeMap[am.key]
+		if !found {
+			return false
+		}
+		if am.value != nil && !EqualAttributeValues(am.value, attr) {
+			return false
+		}
+	}
+	return true
+}
+
+// SkipSpan determines if a span should be skipped by a processor given its
+// include and exclude matchers. Either matcher can be nil, in which case it is
+// ignored.
+func SkipSpan(include, exclude Matcher, span *tracepb.Span, serviceName string) bool {
+	if include != nil && !include.MatchSpan(span, serviceName) {
+		return true
+	}
+	return exclude != nil && exclude.MatchSpan(span, serviceName)
+}
+
+// AttributeValue converts a raw value from the configuration to the supported
+// trace attribute values.
+func AttributeValue(value interface{}) (*tracepb.AttributeValue, error) {
+	attrib := &tracepb.AttributeValue{}
+	switch val := value.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		attrib.Value = &tracepb.AttributeValue_IntValue{IntValue: cast.ToInt64(val)}
+	case float32, float64:
+		attrib.Value = &tracepb.AttributeValue_DoubleValue{DoubleValue: cast.ToFloat64(val)}
+	case string:
+		attrib.Value = &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: val},
+		}
+	case bool:
+		attrib.Value = &tracepb.AttributeValue_BoolValue{BoolValue: val}
+	default:
+		return nil, fmt.Errorf("error unsupported value type \"%T\"", value)
+	}
+	return attrib, nil
+}
+
+// EqualAttributeValues returns true if both attribute values have the same
+// type and value. The truncation count of string values is ignored.
+func EqualAttributeValues(a, b *tracepb.AttributeValue) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	switch av := a.Value.(type) {
+	case *tracepb.AttributeValue_StringValue:
+		bv, ok := b.Value.(*tracepb.AttributeValue_StringValue)
+		return ok && av.StringValue.GetValue() == bv.StringValue.GetValue()
+	case *tracepb.AttributeValue_IntValue:
+		bv, ok := b.Value.(*tracepb.AttributeValue_IntValue)
+		return ok && av.IntValue == bv.IntValue
+	case *tracepb.AttributeValue_DoubleValue:
+		bv, ok := b.Value.(*tracepb.AttributeValue_DoubleValue)
+		return ok && av.DoubleValue == bv.DoubleValue
+	case *tracepb.AttributeValue_BoolValue:
+		bv, ok := b.Value.(*tracepb.AttributeValue_BoolValue)
+		return ok && av.BoolValue == bv.BoolValue
+	default:
+		return false
+	}
+}
+
+// AttributeValueToString returns the string representation of the attribute
+// value. The second return value is false if the type of the value is not
+// supported.
+func AttributeValueToString(attr *tracepb.AttributeValue) (string, bool) {
+	switch value := attr.GetValue().(type) {
+	case *tracepb.AttributeValue_StringValue:
+		return value.StringValue.GetValue(), true
+	case *tracepb.AttributeValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10), true
+	case *tracepb.AttributeValue_DoubleValue:
+		return strconv.FormatFloat(value.DoubleValue, 'f', -1, 64), true
+	case *tracepb.AttributeValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue), true
+	default:
+		return "", false
+	}
+}
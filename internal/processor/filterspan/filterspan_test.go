@@ -0,0 +1,188 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterspan
+
+import (
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMatcher_Invalid(t *testing.T) {
+	_, err := NewMatcher(&MatchProperties{})
+	assert.Equal(t, errEmptyMatchProperties, err)
+
+	_, err = NewMatcher(&MatchProperties{Attributes: []Attribute{{Value: 1}}})
+	assert.Error(t, err)
+
+	_, err = NewMatcher(&MatchProperties{Attributes: []Attribute{{Key: "k", Value: []string{}}}})
+	assert.Error(t, err)
+
+	_, err = NewMatcher(&MatchProperties{SpanKinds: []string{"PRODUCER"}})
+	assert.Error(t, err)
+
+	_, err = NewMatcher(&MatchProperties{MatchType: "regexp", SpanNames: []string{"("}})
+	assert.Error(t, err)
+}
+
+func TestMatchSpan(t *testing.T) {
+	span := &tracepb.Span{
+		Name: &tracepb.TruncatableString{Value: "GET /health"},
+		Kind: tracepb.Span_SERVER,
+		Attributes: &tracepb.Span_Attributes{
+			AttributeMap: map[string]*tracepb.AttributeValue{
+				"string": {Value: &tracepb.AttributeValue_StringValue{
+					StringValue: &tracepb.TruncatableString{Value: "value", TruncatedByteCount: 10},
+				}},
+				"int":    {Value: &tracepb.AttributeValue_IntValue{IntValue: 123}},
+				"double": {Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: 1.5}},
+				"bool":   {Value: &tracepb.AttributeValue_BoolValue{BoolValue: true}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		properties MatchProperties
+		service    string
+		want       bool
+	}{
+		{
+			name:       "service match",
+			properties: MatchProperties{Services: []string{"a", "svc"}},
+			service:    "svc",
+			want:       true,
+		},
+		{
+			name:       "service mismatch",
+			properties: MatchProperties{Services: []string{"a"}},
+			service:    "svc",
+			want:       false,
+		},
+		{
+			name:       "regexp service match",
+			properties: MatchProperties{MatchType: "regexp", Services: []string{"s.*"}},
+			service:    "svc",
+			want:       true,
+		},
+		{
+			name:       "span name match",
+			properties: MatchProperties{SpanNames: []string{"GET /health"}},
+			want:       true,
+		},
+		{
+			name:       "regexp span name match",
+			properties: MatchProperties{MatchType: "regexp", SpanNames: []string{".*/health"}},
+			want:       true,
+		},
+		{
+			name:       "span name mismatch",
+			properties: MatchProperties{SpanNames: []string{"GET"}},
+			want:       false,
+		},
+		{
+			name:       "span kind match",
+			properties: MatchProperties{SpanKinds: []string{"client", "server"}},
+			want:       true,
+		},
+		{
+			name:       "span kind mismatch",
+			properties: MatchProperties{SpanKinds: []string{"CLIENT"}},
+			want:       false,
+		},
+		{
+			name: "attribute values match",
+			properties: MatchProperties{Attributes: []Attribute{
+				{Key: "string", Value: "value"},
+				{Key: "int", Value: 123},
+				{Key: "double", Value: 1.5},
+				{Key: "bool", Value: true},
+			}},
+			want: true,
+		},
+		{
+			name:       "attribute key only",
+			properties: MatchProperties{Attributes: []Attribute{{Key: "string"}}},
+			want:       true,
+		},
+		{
+			name:       "attribute missing",
+			properties: MatchProperties{Attributes: []Attribute{{Key: "missing"}}},
+			want:       false,
+		},
+		{
+			name:       "attribute type mismatch",
+			properties: MatchProperties{Attributes: []Attribute{{Key: "int", Value: "123"}}},
+			want:       false,
+		},
+		{
+			name: "service and attributes",
+			properties: MatchProperties{
+				Services:   []string{"svc"},
+				Attributes: []Attribute{{Key: "bool", Value: false}},
+			},
+			service: "svc",
+			want:    false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := NewMatcher(&tc.properties)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, m.MatchSpan(span, tc.service))
+		})
+	}
+}
+
+func TestSkipSpan(t *testing.T) {
+	include, err := NewMatcher(&MatchProperties{Services: []string{"in"}})
+	require.NoError(t, err)
+	exclude, err := NewMatcher(&MatchProperties{Services: []string{"out"}})
+	require.NoError(t, err)
+
+	span := &tracepb.Span{}
+	assert.False(t, SkipSpan(nil, nil, span, "any"))
+	assert.False(t, SkipSpan(include, nil, span, "in"))
+	assert.True(t, SkipSpan(include, nil, span, "other"))
+	assert.True(t, SkipSpan(nil, exclude, span, "out"))
+	assert.False(t, SkipSpan(nil, exclude, span, "other"))
+	assert.True(t, SkipSpan(include, exclude, span, "out"))
+}
+
+func TestAttributeValueToString(t *testing.T) {
+	tests := []struct {
+		attr *tracepb.AttributeValue
+		want string
+		ok   bool
+	}{
+		{
+			attr: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "GET"}}},
+			want: "GET",
+			ok:   true,
+		},
+		{attr: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: 404}}, want: "404", ok: true},
+		{attr: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: 0.5}}, want: "0.5", ok: true},
+		{attr: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: true}}, want: "true", ok: true},
+		{attr: &tracepb.AttributeValue{}, ok: false},
+		{attr: nil, ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := AttributeValueToString(tt.attr)
+		assert.Equal(t, tt.want, got)
+		assert.Equal(t, tt.ok, ok)
+	}
+}
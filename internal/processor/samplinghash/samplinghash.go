@@ -0,0 +1,101 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package samplinghash contains the trace ID hashing shared by the processors
+// sampling traces by a percentage, so that they make the same decisions for
+// the same trace IDs and hash seed.
+package samplinghash
+
+const (
+	// The constants help translate user friendly percentages to numbers direct used in sampling.
+
+	// NumHashBuckets is the number of buckets the hashes are split into.
+	NumHashBuckets = 0x4000 // Using a power of 2 to avoid division.
+	// BitMaskHashBuckets is the mask selecting the bucket of a hash.
+	BitMaskHashBuckets = NumHashBuckets - 1
+	// PercentageScaleFactor converts a percentage to a number of buckets.
+	PercentageScaleFactor = NumHashBuckets / 100.0
+)
+
+// ScaledSamplingRate returns the number of hash buckets that are sampled for
+// the sampling percentage.
+func ScaledSamplingRate(samplingPercentage float32) uint32 {
+	return uint32(samplingPercentage * PercentageScaleFactor)
+}
+
+// IsSampled returns true if the trace ID hashes to one of the sampled buckets.
+// If one assumes random trace ids hashing may seems avoidable, however, traces
+// can be coming from sources with various different criteria to generate trace
+// id and perhaps were already sampled without hashing. Hashing here prevents
+// bias due to such systems.
+func IsSampled(traceID []byte, hashSeed, scaledSamplingRate uint32) bool {
+	if scaledSamplingRate >= NumHashBuckets {
+		return true
+	}
+	return Hash(traceID, hashSeed)&BitMaskHashBuckets < scaledSamplingRate
+}
+
+// Hash computes the 32 bits murmur3 hash of the key with the given seed.
+func Hash(key []byte, seed uint32) (hash uint32) {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+		c3 = 0x85ebca6b
+		c4 = 0xc2b2ae35
+		r1 = 15
+		r2 = 13
+		m  = 5
+		n  = 0xe6546b64
+	)
+
+	hash = seed
+	iByte := 0
+	for ; iByte+4 <= len(key); iByte += 4 {
+		k := uint32(key[iByte]) | uint32(key[iByte+1])<<8 | uint32(key[iByte+2])<<16 | uint32(key[iByte+3])<<24
+		k *= c1
+		k = (k << r1) | (k >> (32 - r1))
+		k *= c2
+		hash ^= k
+		hash = (hash << r2) | (hash >> (32 - r2))
+		hash = hash*m + n
+	}
+
+	// TraceId and SpanId have lengths that are multiple of 4 so the code below is never expected to
+	// be hit when sampling traces. However, it is preserved here to keep it as a correct murmur3 implementation.
+	// This is enforced via tests.
+	var remainingBytes uint32
+	switch len(key) - iByte {
+	case 3:
+		remainingBytes += uint32(key[iByte+2]) << 16
+		fallthrough
+	case 2:
+		remainingBytes += uint32(key[iByte+1]) << 8
+		fallthrough
+	case 1:
+		remainingBytes += uint32(key[iByte])
+		remainingBytes *= c1
+		remainingBytes = (remainingBytes << r1) | (remainingBytes >> (32 - r1))
+		remainingBytes = remainingBytes * c2
+		hash ^= remainingBytes
+	}
+
+	hash ^= uint32(len(key))
+	hash ^= hash >> 16
+	hash *= c3
+	hash ^= hash >> 13
+	hash *= c4
+	hash ^= hash >> 16
+
+	return
+}
@@ -0,0 +1,45 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package samplingprobability contains the span attribute recording the
+// probability used to sample the spans, shared by the sampling processors so
+// that backends can extrapolate counts.
+package samplingprobability
+
+import (
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+)
+
+// AttributeKey is the attribute set on the sampled spans with the probability
+// used to sample them.
+const AttributeKey = "sampling.probability"
+
+// WithProbability returns a copy of the span with the sampling probability
+// added to its attributes. The span is copied since it may be shared with
+// other pipelines.
+func WithProbability(span *tracepb.Span, probability float64) *tracepb.Span {
+	spanCopy := *span
+	attrs := &tracepb.Span_Attributes{AttributeMap: make(map[string]*tracepb.AttributeValue)}
+	if span.Attributes != nil {
+		attrs.DroppedAttributesCount = span.Attributes.DroppedAttributesCount
+		for k, v := range span.Attributes.AttributeMap {
+			attrs.AttributeMap[k] = v
+		}
+	}
+	attrs.AttributeMap[AttributeKey] = &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: probability},
+	}
+	spanCopy.Attributes = attrs
+	return &spanCopy
+}
@@ -0,0 +1,46 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package samplingprobability
+
+import (
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProbability(t *testing.T) {
+	kind := &tracepb.AttributeValue{Value: &tracepb.AttributeValue_StringValue{
+		StringValue: &tracepb.TruncatableString{Value: "server"},
+	}}
+	span := &tracepb.Span{
+		Name: &tracepb.TruncatableString{Value: "GET"},
+		Attributes: &tracepb.Span_Attributes{
+			AttributeMap:           map[string]*tracepb.AttributeValue{"kind": kind},
+			DroppedAttributesCount: 2,
+		},
+	}
+
+	got := WithProbability(span, 0.25)
+	assert.Equal(t, span.Name, got.Name)
+	assert.Equal(t, int32(2), got.Attributes.DroppedAttributesCount)
+	assert.Equal(t, kind, got.Attributes.AttributeMap["kind"])
+	assert.Equal(t, 0.25, got.Attributes.AttributeMap[AttributeKey].GetDoubleValue())
+	// The original span is not modified.
+	assert.Len(t, span.Attributes.AttributeMap, 1)
+
+	got = WithProbability(&tracepb.Span{}, 1)
+	assert.Equal(t, 1.0, got.Attributes.AttributeMap[AttributeKey].GetDoubleValue())
+}
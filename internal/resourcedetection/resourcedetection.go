@@ -0,0 +1,47 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package resourcedetection runs OpenCensus resource detectors and converts
+// the detected resource to the proto used by the pipelines. It is shared by
+// the components that annotate data with the resource of the host.
+package resourcedetection
+
+import (
+	"context"
+
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"go.opencensus.io/resource"
+)
+
+// Detect runs the detectors, merging their results with the labels of the
+// first detectors taking precedence, and returns the result as a Resource
+// proto. It returns nil if nothing was detected.
+func Detect(ctx context.Context, detectors ...resource.Detector) (*resourcepb.Resource, error) {
+	res, err := resource.MultiDetector(detectors...)(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil || (res.Type == "" && len(res.Labels) == 0) {
+		return nil, nil
+	}
+
+	rsc := &resourcepb.Resource{
+		Type:   res.Type,
+		Labels: make(map[string]string, len(res.Labels)),
+	}
+	for k, v := range res.Labels {
+		rsc.Labels[k] = v
+	}
+	return rsc, nil
+}
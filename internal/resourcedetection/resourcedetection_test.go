@@ -0,0 +1,53 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcedetection
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/resource"
+)
+
+func staticDetector(res *resource.Resource, err error) resource.Detector {
+	return func(context.Context) (*resource.Resource, error) {
+		return res, err
+	}
+}
+
+func TestDetect(t *testing.T) {
+	labels := map[string]string{"region": "us-east-1"}
+	res, err := Detect(context.Background(),
+		staticDetector(nil, nil),
+		staticDetector(&resource.Resource{Type: "host", Labels: labels}, nil),
+		staticDetector(&resource.Resource{Type: "k8s", Labels: map[string]string{"region": "eu-west-1", "pod": "p1"}}, nil),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, &resourcepb.Resource{
+		Type:   "host",
+		Labels: map[string]string{"region": "us-east-1", "pod": "p1"},
+	}, res)
+
+	res, err = Detect(context.Background(), staticDetector(&resource.Resource{}, nil))
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	_, err = Detect(context.Background(), staticDetector(nil, errors.New("detection failed")))
+	assert.Error(t, err)
+}
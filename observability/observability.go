@@ -48,6 +48,9 @@ var TagKeyReceiver, _ = tag.NewKey("otelsvc_receiver")
 // TagKeyExporter defines tag key for Exporter.
 var TagKeyExporter, _ = tag.NewKey("otelsvc_exporter")
 
+// TagKeyDropReason defines tag key for the reason why data was dropped.
+var TagKeyDropReason, _ = tag.NewKey("otelsvc_drop_reason")
+
 // ViewReceiverReceivedSpans defines the view for the receiver received spans metric.
 var ViewReceiverReceivedSpans = &view.View{
 	Name:        mReceiverReceivedSpans.Name(),
@@ -63,7 +66,7 @@ var ViewReceiverDroppedSpans = &view.View{
 	Description: mReceiverDroppedSpans.Description(),
 	Measure:     mReceiverDroppedSpans,
 	Aggregation: view.Sum(),
-	TagKeys:     []tag.Key{TagKeyReceiver},
+	TagKeys:     []tag.Key{TagKeyReceiver, TagKeyDropReason},
 }
 
 // ViewReceiverReceivedTimeSeries defines the view for the receiver received timeseries metric.
@@ -140,6 +143,14 @@ func ContextWithReceiverName(ctx context.Context, receiverName string) context.C
 	return ctx
 }
 
+// ContextWithDropReason adds the tag "otelsvc_drop_reason" and the given reason as the value,
+// and returns the newly created context. Use it to record, with RecordMetricsForTraceReceiver,
+// the spans that a receiver dropped for the given reason.
+func ContextWithDropReason(ctx context.Context, reason string) context.Context {
+	ctx, _ = tag.New(ctx, tag.Upsert(TagKeyDropReason, reason, tag.WithTTL(tag.TTLNoPropagation)))
+	return ctx
+}
+
 // RecordMetricsForTraceReceiver records the number of spans received and dropped by the receiver.
 // Use it with a context.Context generated using ContextWithReceiverName().
 func RecordMetricsForTraceReceiver(ctxWithTraceReceiverName context.Context, receivedSpans int, droppedSpans int) {
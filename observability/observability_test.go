@@ -46,6 +46,15 @@ func TestTracePieplineRecordedMetrics(t *testing.T) {
 	err = observabilitytest.CheckValueViewReceiverDroppedSpans(receiverName, 13)
 	require.Nil(t, err, "When check receiver dropped spans")
 
+	reasonCtx := observability.ContextWithDropReason(receiverCtx, "zero_trace_id")
+	observability.RecordMetricsForTraceReceiver(reasonCtx, 0, 5)
+
+	err = observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(receiverName, "zero_trace_id", 5)
+	require.Nil(t, err, "When check receiver dropped spans with reason")
+
+	err = observabilitytest.CheckValueViewReceiverDroppedSpans(receiverName, 13)
+	require.Nil(t, err, "When check receiver dropped spans without reason")
+
 	err = observabilitytest.CheckValueViewExporterReceivedSpans(receiverName, exporterName, 27)
 	require.Nil(t, err, "When check exporter received spans")
 
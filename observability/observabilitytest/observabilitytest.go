@@ -84,6 +84,17 @@ func CheckValueViewReceiverDroppedSpans(receiverName string, value int) error {
 		wantsTagsForReceiverView(receiverName), int64(value))
 }
 
+// CheckValueViewReceiverDroppedSpansWithReason checks that for the current exported value in the
+// ViewReceiverDroppedSpans for {TagKeyReceiver: receiverName, TagKeyDropReason: reason} is equal to "value".
+// In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
+func CheckValueViewReceiverDroppedSpansWithReason(receiverName string, reason string, value int) error {
+	return checkValueForView(observability.ViewReceiverDroppedSpans.Name,
+		[]tag.Tag{
+			{Key: observability.TagKeyReceiver, Value: receiverName},
+			{Key: observability.TagKeyDropReason, Value: reason},
+		}, int64(value))
+}
+
 // CheckValueViewReceiverReceivedTimeSeries checks that for the current exported value in the ViewReceiverReceivedTimeSeries
 // for {TagKeyReceiver: receiverName, TagKeyExporter: exporterTagName} is equal to "value".
 // In tests that this function is called it is required to also call SetupRecordedMetricsTest as first thing.
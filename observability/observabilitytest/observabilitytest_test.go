@@ -54,6 +54,14 @@ func TestCheckValueViewTraceReceiverViews(t *testing.T) {
 	require.NotNil(t, err, "When check for unexpected value")
 	err = observabilitytest.CheckValueViewReceiverDroppedSpans(receiverName, 17)
 	require.NotNil(t, err, "When check for unexpected value")
+
+	// Test dropped spans with a reason
+	reasonCtx := observability.ContextWithDropReason(receiverCtx, "decode_error")
+	observability.RecordMetricsForTraceReceiver(reasonCtx, 0, 3)
+	err = observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(receiverName, "decode_error", 3)
+	require.Nil(t, err, "When check receiver dropped spans with reason")
+	err = observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(receiverName, "zero_trace_id", 3)
+	require.NotNil(t, err, "When check for unexpected reason")
 }
 
 func TestCheckValueViewMetricsReceiverViews(t *testing.T) {
@@ -0,0 +1,101 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplerprocessor
+
+import (
+	"context"
+	"fmt"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/adaptivesampling"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplingprobability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type adaptiveSamplerProcessor struct {
+	nextConsumer consumer.TraceConsumer
+	sampler      *adaptivesampling.Sampler
+	decisions    *decisionCache
+}
+
+var _ processor.TraceProcessor = (*adaptiveSamplerProcessor)(nil)
+
+// NewTraceProcessor returns a processor.TraceProcessor that samples the traces
+// of each service and operation with a probability adjusted to sample the
+// configured number of traces per second for each of them.
+func NewTraceProcessor(nextConsumer consumer.TraceConsumer, cfg Config) (processor.TraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if cfg.TracesPerSecond <= 0 {
+		return nil, fmt.Errorf("error creating %q processor: traces_per_second must be positive", cfg.Name())
+	}
+	if cfg.AdjustmentInterval <= 0 {
+		return nil, fmt.Errorf("error creating %q processor: adjustment_interval must be positive", cfg.Name())
+	}
+	if cfg.DecisionCacheSize < 0 {
+		return nil, fmt.Errorf("error creating %q processor: decision_cache_size must not be negative", cfg.Name())
+	}
+
+	return &adaptiveSamplerProcessor{
+		nextConsumer: nextConsumer,
+		sampler:      adaptivesampling.NewSampler(cfg.TracesPerSecond, cfg.AdjustmentInterval, cfg.HashSeed),
+		decisions:    newDecisionCache(cfg.DecisionCacheSize),
+	}, nil
+}
+
+func (asp *adaptiveSamplerProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	serviceName := td.Node.GetServiceInfo().GetName()
+
+	// The key of a trace is the operation of its root span, or of its first
+	// span if the root span isn't in the batch.
+	operations := make(map[string]string)
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		id := string(span.TraceId)
+		if _, ok := operations[id]; !ok || len(span.ParentSpanId) == 0 {
+			operations[id] = span.Name.GetValue()
+		}
+	}
+
+	decisions := make(map[string]decision, len(operations))
+	for id, operation := range operations {
+		operation := operation
+		traceID := []byte(id)
+		decisions[id] = asp.decisions.GetOrDecide(id, func() decision {
+			sampled, probability := asp.sampler.Sample(serviceName, operation, traceID)
+			return decision{sampled: sampled, probability: probability}
+		})
+	}
+
+	sampledSpans := make([]*tracepb.Span, 0, len(td.Spans))
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		if d := decisions[string(span.TraceId)]; d.sampled {
+			sampledSpans = append(sampledSpans, samplingprobability.WithProbability(span, d.probability))
+		}
+	}
+
+	td.Spans = sampledSpans
+	return asp.nextConsumer.ConsumeTraceData(ctx, td)
+}
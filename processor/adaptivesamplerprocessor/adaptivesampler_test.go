@@ -0,0 +1,128 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplerprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplingprobability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func TestNewTraceProcessor(t *testing.T) {
+	cfg := Config{TracesPerSecond: 1, AdjustmentInterval: time.Second}
+	_, err := NewTraceProcessor(nil, cfg)
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+
+	_, err = NewTraceProcessor(exportertest.NewNopTraceExporter(), Config{AdjustmentInterval: time.Second})
+	assert.Error(t, err)
+	_, err = NewTraceProcessor(exportertest.NewNopTraceExporter(), Config{TracesPerSecond: 1})
+	assert.Error(t, err)
+
+	tp, err := NewTraceProcessor(exportertest.NewNopTraceExporter(), cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, tp)
+}
+
+func TestAdaptiveSampler(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tp, err := NewTraceProcessor(sink, Config{TracesPerSecond: 10, AdjustmentInterval: time.Hour})
+	require.NoError(t, err)
+
+	span := &tracepb.Span{
+		TraceId: tracetranslator.UInt64ToByteTraceID(1, 2),
+		Name:    &tracepb.TruncatableString{Value: "pay"},
+	}
+	td := consumerdata.TraceData{
+		Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "checkout"}},
+		Spans: []*tracepb.Span{span, nil},
+	}
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+
+	// New operations are sampled with a probability of 1 until the first
+	// adjustment.
+	traces := sink.AllTraces()
+	require.Len(t, traces, 1)
+	require.Len(t, traces[0].Spans, 1)
+	attr := traces[0].Spans[0].Attributes.AttributeMap[samplingprobability.AttributeKey]
+	assert.Equal(t, 1.0, attr.GetDoubleValue())
+	// The received span must not be modified.
+	assert.Nil(t, span.Attributes)
+}
+
+func TestAdaptiveSampler_OneDecisionPerTrace(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tp, err := NewTraceProcessor(sink, Config{TracesPerSecond: 500, AdjustmentInterval: 50 * time.Millisecond, DecisionCacheSize: 10000})
+	require.NoError(t, err)
+	asp := tp.(*adaptiveSamplerProcessor)
+
+	newSpan := func(traceID uint64, parent bool, name string) *tracepb.Span {
+		span := &tracepb.Span{
+			TraceId: tracetranslator.UInt64ToByteTraceID(traceID, traceID*7919),
+			Name:    &tracepb.TruncatableString{Value: name},
+		}
+		if parent {
+			span.ParentSpanId = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+		}
+		return span
+	}
+	// consume returns the spans sampled in the batch.
+	consume := func(service string, spans ...*tracepb.Span) []*tracepb.Span {
+		td := consumerdata.TraceData{
+			Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: service}},
+			Spans: spans,
+		}
+		require.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+		traces := sink.AllTraces()
+		return traces[len(traces)-1].Spans
+	}
+
+	for i := uint64(1); i <= 500; i++ {
+		consume("checkout", newSpan(i, true, "db"), newSpan(i, false, "pay"), newSpan(i, true, "db"))
+	}
+	time.Sleep(60 * time.Millisecond)
+	consume("checkout", newSpan(1000, false, "pay"))
+
+	// The traces are counted once, for the operation of their root span.
+	assert.True(t, asp.sampler.Probability("checkout", "pay") < 1)
+	assert.Equal(t, 1.0, asp.sampler.Probability("checkout", "db"))
+
+	// All the spans of a trace follow the decision made for the trace, even
+	// when they are received in later batches of other services.
+	for i := uint64(2000); i < 2500; i++ {
+		first := consume("checkout", newSpan(i, false, "pay"), newSpan(i, true, "db"))
+		later := consume("cart", newSpan(i, true, "add"))
+		if len(first) == 0 {
+			assert.Len(t, later, 0)
+			continue
+		}
+		require.Len(t, first, 2)
+		require.Len(t, later, 1)
+		probability := first[0].Attributes.AttributeMap[samplingprobability.AttributeKey].GetDoubleValue()
+		assert.Equal(t, probability, first[1].Attributes.AttributeMap[samplingprobability.AttributeKey].GetDoubleValue())
+		assert.Equal(t, probability, later[0].Attributes.AttributeMap[samplingprobability.AttributeKey].GetDoubleValue())
+	}
+	assert.Equal(t, 1.0, asp.sampler.Probability("cart", "add"))
+}
@@ -0,0 +1,40 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplerprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config has the configuration of the adaptive sampler processor.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+	// TracesPerSecond is the target number of traces per second sampled for
+	// each service and operation of the root span of the traces. This is
+	// required.
+	TracesPerSecond float64 `mapstructure:"traces_per_second"`
+	// AdjustmentInterval is the interval at which the sampling probabilities
+	// are adjusted to the throughput.
+	AdjustmentInterval time.Duration `mapstructure:"adjustment_interval"`
+	// HashSeed allows one to configure the hashing seed, see the
+	// probabilistic_sampler processor.
+	HashSeed uint32 `mapstructure:"hash_seed"`
+	// DecisionCacheSize is the number of decisions of recent traces kept in
+	// memory, so that the spans of a trace received in different batches
+	// follow the same decision. The cache is disabled if it is 0.
+	DecisionCacheSize int `mapstructure:"decision_cache_size"`
+}
@@ -0,0 +1,75 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplerprocessor
+
+import (
+	"container/list"
+	"sync"
+)
+
+// decision is the sampling decision made for a trace.
+type decision struct {
+	sampled     bool
+	probability float64
+}
+
+// decisionCache is a bounded LRU cache of the decisions of recent traces, so
+// that the spans of a trace received in different batches follow the same
+// decision and the trace is counted once.
+type decisionCache struct {
+	sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	// lru holds the *decisionCacheEntry, the most recently used at the front.
+	lru *list.List
+}
+
+type decisionCacheEntry struct {
+	id string
+	decision
+}
+
+func newDecisionCache(capacity int) *decisionCache {
+	return &decisionCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		lru:      list.New(),
+	}
+}
+
+// GetOrDecide returns the decision cached for the trace, or calls decide and
+// caches its result if there is none, evicting the least recently used
+// decision if the cache is full. decide is called with the lock held so that
+// concurrent batches of a trace don't both make a decision.
+func (dc *decisionCache) GetOrDecide(id string, decide func() decision) decision {
+	if dc.capacity <= 0 {
+		return decide()
+	}
+
+	dc.Lock()
+	defer dc.Unlock()
+	if elem, ok := dc.entries[id]; ok {
+		dc.lru.MoveToFront(elem)
+		return elem.Value.(*decisionCacheEntry).decision
+	}
+	d := decide()
+	if dc.lru.Len() >= dc.capacity {
+		oldest := dc.lru.Back()
+		dc.lru.Remove(oldest)
+		delete(dc.entries, oldest.Value.(*decisionCacheEntry).id)
+	}
+	dc.entries[id] = dc.lru.PushFront(&decisionCacheEntry{id: id, decision: d})
+	return d
+}
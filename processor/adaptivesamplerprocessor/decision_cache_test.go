@@ -0,0 +1,51 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adaptivesamplerprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionCache(t *testing.T) {
+	dc := newDecisionCache(2)
+	calls := 0
+	decide := func(sampled bool) func() decision {
+		return func() decision {
+			calls++
+			return decision{sampled: sampled, probability: 0.5}
+		}
+	}
+
+	assert.True(t, dc.GetOrDecide("a", decide(true)).sampled)
+	assert.False(t, dc.GetOrDecide("b", decide(false)).sampled)
+	// The cached decision is returned without deciding again.
+	assert.True(t, dc.GetOrDecide("a", decide(false)).sampled)
+	assert.Equal(t, 2, calls)
+
+	// The least recently used decision, b, is evicted.
+	dc.GetOrDecide("c", decide(true))
+	assert.True(t, dc.GetOrDecide("b", decide(true)).sampled)
+	assert.Equal(t, 4, calls)
+	assert.True(t, dc.GetOrDecide("c", decide(false)).sampled)
+	assert.Equal(t, 4, calls)
+}
+
+func TestDecisionCache_Disabled(t *testing.T) {
+	dc := newDecisionCache(0)
+	assert.True(t, dc.GetOrDecide("a", func() decision { return decision{sampled: true} }).sampled)
+	assert.False(t, dc.GetOrDecide("a", func() decision { return decision{} }).sampled)
+}
@@ -16,9 +16,12 @@ package processor
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 )
 
@@ -42,7 +45,7 @@ func (mfc metricsFanOutConnector) ConsumeMetricsData(ctx context.Context, md con
 			errs = append(errs, err)
 		}
 	}
-	return oterr.CombineErrors(errs)
+	return combineErrors(errs, len(mfc))
 }
 
 // NewTraceFanOutConnector wraps multiple trace consumers in a single one.
@@ -62,5 +65,34 @@ func (tfc traceFanOutConnector) ConsumeTraceData(ctx context.Context, td consume
 			errs = append(errs, err)
 		}
 	}
+	return combineErrors(errs, len(tfc))
+}
+
+// combineErrors combines the errors returned by the numConsumers wrapped
+// consumers. The combined error is only throttled if all of them throttled
+// the data: if any consumer accepted it, sending it again later would
+// duplicate it.
+func combineErrors(errs []error, numConsumers int) error {
+	var retryAfter time.Duration
+	numThrottled := 0
+	for _, err := range errs {
+		if consumererror.IsThrottled(err) {
+			numThrottled++
+			if d := consumererror.RetryAfter(err); d > retryAfter {
+				retryAfter = d
+			}
+		}
+	}
+
+	if numThrottled == numConsumers && numThrottled > 1 {
+		return consumererror.Throttled(oterr.CombineErrors(errs), retryAfter)
+	}
+	if numThrottled < numConsumers {
+		for i, err := range errs {
+			if consumererror.IsThrottled(err) {
+				errs[i] = errors.New(err.Error())
+			}
+		}
+	}
 	return oterr.CombineErrors(errs)
 }
@@ -16,14 +16,18 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/require"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 )
 
 func TestTraceProcessorMultiplexing(t *testing.T) {
@@ -152,9 +156,55 @@ func TestMetricsProcessorWhenOneErrors(t *testing.T) {
 	}
 }
 
+func TestTraceProcessorWhenAllThrottle(t *testing.T) {
+	processors := []consumer.TraceConsumer{
+		&mockTraceConsumer{Err: consumererror.Throttled(errors.New("queue is full"), time.Second)},
+		&mockTraceConsumer{Err: consumererror.Throttled(errors.New("queue is full"), time.Minute)},
+	}
+
+	tfc := NewTraceFanOutConnector(processors)
+	err := tfc.ConsumeTraceData(context.Background(), consumerdata.TraceData{})
+	require.True(t, consumererror.IsThrottled(err))
+	require.Equal(t, time.Minute, consumererror.RetryAfter(err))
+
+	tfc = NewTraceFanOutConnector(processors[:1])
+	err = tfc.ConsumeTraceData(context.Background(), consumerdata.TraceData{})
+	require.True(t, consumererror.IsThrottled(err))
+	require.Equal(t, time.Second, consumererror.RetryAfter(err))
+}
+
+func TestTraceProcessorWhenOneThrottles(t *testing.T) {
+	throttled := &mockTraceConsumer{Err: consumererror.Throttled(errors.New("queue is full"), time.Second)}
+	tests := []struct {
+		name       string
+		processors []consumer.TraceConsumer
+	}{
+		{
+			name:       "other_accepts",
+			processors: []consumer.TraceConsumer{&mockTraceConsumer{}, throttled},
+		},
+		{
+			name:       "other_fails",
+			processors: []consumer.TraceConsumer{&mockTraceConsumer{MustFail: true}, throttled},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Throttling the data would make the client send it again to the
+			// consumers which already accepted it.
+			tfc := NewTraceFanOutConnector(tt.processors)
+			err := tfc.ConsumeTraceData(context.Background(), consumerdata.TraceData{})
+			require.Error(t, err)
+			require.False(t, consumererror.IsThrottled(err))
+		})
+	}
+}
+
 type mockTraceConsumer struct {
 	TotalSpans int
 	MustFail   bool
+	Err        error
 }
 
 var _ consumer.TraceConsumer = &mockTraceConsumer{}
@@ -165,7 +215,7 @@ func (p *mockTraceConsumer) ConsumeTraceData(ctx context.Context, td consumerdat
 		return fmt.Errorf("this processor must fail")
 	}
 
-	return nil
+	return p.Err
 }
 
 type mockMetricsConsumer struct {
@@ -0,0 +1,68 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filtermetric"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
+)
+
+// Config specifies the properties used to filter spans and metrics.
+// For each data type, the data is first compared against the include
+// properties and then against the exclude properties if they are specified.
+// Data that is not included, or that is excluded, is dropped.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Spans specifies the filters applied to spans in traces pipelines.
+	// At least one of `include` or `exclude` must be set to use the
+	// processor in a traces pipeline.
+	Spans SpanFilters `mapstructure:"spans"`
+
+	// Metrics specifies the filters applied to metric timeseries in metrics
+	// pipelines. At least one of `include` or `exclude` must be set to use
+	// the processor in a metrics pipeline.
+	Metrics MetricFilters `mapstructure:"metrics"`
+}
+
+// SpanFilters specifies the spans to keep and to drop.
+type SpanFilters struct {
+	// Include specifies the properties a span must match to be kept.
+	// This is an optional field. If it is not set all spans are kept unless
+	// they match the `exclude` properties.
+	Include *filterspan.MatchProperties `mapstructure:"include"`
+
+	// Exclude specifies the properties of the spans to drop.
+	// Note: The `exclude` properties are checked after the `include`
+	// properties, if they exist.
+	// This is an optional field.
+	Exclude *filterspan.MatchProperties `mapstructure:"exclude"`
+}
+
+// MetricFilters specifies the metric timeseries to keep and to drop. Metrics
+// left without any timeseries after filtering are dropped.
+type MetricFilters struct {
+	// Include specifies the properties a timeseries must match to be kept.
+	// This is an optional field. If it is not set all timeseries are kept
+	// unless they match the `exclude` properties.
+	Include *filtermetric.MatchProperties `mapstructure:"include"`
+
+	// Exclude specifies the properties of the timeseries to drop.
+	// Note: The `exclude` properties are checked after the `include`
+	// properties, if they exist.
+	// This is an optional field.
+	Exclude *filtermetric.MatchProperties `mapstructure:"exclude"`
+}
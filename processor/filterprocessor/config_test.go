@@ -0,0 +1,85 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filtermetric"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.NoError(t, err)
+
+	factory := &Factory{}
+	factories.Processors[typeStr] = factory
+
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors["filter/spans"], &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: "filter/spans",
+		},
+		Spans: SpanFilters{
+			Exclude: &filterspan.MatchProperties{
+				MatchType: "regexp",
+				SpanNames: []string{".*/health", ".*/ready"},
+			},
+		},
+	})
+
+	assert.Equal(t, cfg.Processors["filter/spans_service"], &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: "filter/spans_service",
+		},
+		Spans: SpanFilters{
+			Include: &filterspan.MatchProperties{
+				Services:  []string{"frontend", "checkout"},
+				SpanKinds: []string{"SERVER", "CLIENT"},
+			},
+			Exclude: &filterspan.MatchProperties{
+				Attributes: []filterspan.Attribute{{Key: "synthetic", Value: true}},
+			},
+		},
+	})
+
+	assert.Equal(t, cfg.Processors["filter/metrics"], &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: "filter/metrics",
+		},
+		Metrics: MetricFilters{
+			Include: &filtermetric.MatchProperties{
+				MatchType:   "regexp",
+				MetricNames: []string{"process/.*"},
+			},
+			Exclude: &filtermetric.MatchProperties{
+				Labels: []filtermetric.Label{{Key: "env", Value: "test"}},
+			},
+		},
+	})
+}
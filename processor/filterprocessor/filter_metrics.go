@@ -0,0 +1,161 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"context"
+	"fmt"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filtermetric"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type filterMetricsProcessor struct {
+	nextConsumer consumer.MetricsConsumer
+	include      filtermetric.Matcher
+	exclude      filtermetric.Matcher
+	includeTags  []tag.Mutator
+	excludeTags  []tag.Mutator
+}
+
+var _ processor.MetricsProcessor = (*filterMetricsProcessor)(nil)
+
+// newFilterMetricsProcessor returns a processor that drops the metric
+// timeseries that are not included or that are excluded by the configuration.
+func newFilterMetricsProcessor(nextConsumer consumer.MetricsConsumer, cfg *Config) (*filterMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+
+	fmp := &filterMetricsProcessor{
+		nextConsumer: nextConsumer,
+		includeTags:  ruleStatsTags(cfg.Name(), ruleInclude),
+		excludeTags:  ruleStatsTags(cfg.Name(), ruleExclude),
+	}
+
+	var err error
+	if cfg.Metrics.Include != nil {
+		if fmp.include, err = filtermetric.NewMatcher(cfg.Metrics.Include); err != nil {
+			return nil, fmt.Errorf("error creating \"filter\" processor due to invalid \"include\" in \"metrics\" of processor %q: %v", cfg.Name(), err)
+		}
+	}
+	if cfg.Metrics.Exclude != nil {
+		if fmp.exclude, err = filtermetric.NewMatcher(cfg.Metrics.Exclude); err != nil {
+			return nil, fmt.Errorf("error creating \"filter\" processor due to invalid \"exclude\" in \"metrics\" of processor %q: %v", cfg.Name(), err)
+		}
+	}
+	return fmp, nil
+}
+
+func (fmp *filterMetricsProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	droppedByInclude, droppedByExclude := 0, 0
+	metricsDroppedByInclude, metricsDroppedByExclude := 0, 0
+	// The metrics may be shared with other pipelines so filtered metrics are
+	// copied instead of being modified in place.
+	metrics := make([]*metricspb.Metric, 0, len(md.Metrics))
+	for _, metric := range md.Metrics {
+		if metric == nil {
+			continue
+		}
+		if len(metric.Timeseries) == 0 {
+			// Metrics without timeseries are only checked against the rules
+			// that don't depend on the timeseries labels.
+			switch fmp.matchRule(metric, &metricspb.TimeSeries{}) {
+			case ruleInclude:
+				metricsDroppedByInclude++
+			case ruleExclude:
+				metricsDroppedByExclude++
+			default:
+				metrics = append(metrics, metric)
+			}
+			continue
+		}
+
+		timeseries := make([]*metricspb.TimeSeries, 0, len(metric.Timeseries))
+		tsDroppedByInclude, tsDroppedByExclude := 0, 0
+		for _, ts := range metric.Timeseries {
+			if ts == nil {
+				continue
+			}
+			switch fmp.matchRule(metric, ts) {
+			case ruleInclude:
+				tsDroppedByInclude++
+			case ruleExclude:
+				tsDroppedByExclude++
+			default:
+				timeseries = append(timeseries, ts)
+			}
+		}
+		droppedByInclude += tsDroppedByInclude
+		droppedByExclude += tsDroppedByExclude
+
+		switch {
+		case len(timeseries) == 0:
+			// Drop metrics whose timeseries were all filtered out. They are
+			// counted for the include rule if it dropped any of them.
+			if tsDroppedByInclude > 0 {
+				metricsDroppedByInclude++
+			} else if tsDroppedByExclude > 0 {
+				metricsDroppedByExclude++
+			}
+		case len(timeseries) == len(metric.Timeseries):
+			metrics = append(metrics, metric)
+		default:
+			metrics = append(metrics, &metricspb.Metric{
+				MetricDescriptor: metric.MetricDescriptor,
+				Resource:         metric.Resource,
+				Timeseries:       timeseries,
+			})
+		}
+	}
+
+	if droppedByInclude > 0 {
+		_ = stats.RecordWithTags(ctx, fmp.includeTags, statTimeSeriesFiltered.M(int64(droppedByInclude)))
+	}
+	if droppedByExclude > 0 {
+		_ = stats.RecordWithTags(ctx, fmp.excludeTags, statTimeSeriesFiltered.M(int64(droppedByExclude)))
+	}
+	if metricsDroppedByInclude > 0 {
+		_ = stats.RecordWithTags(ctx, fmp.includeTags, statMetricsFiltered.M(int64(metricsDroppedByInclude)))
+	}
+	if metricsDroppedByExclude > 0 {
+		_ = stats.RecordWithTags(ctx, fmp.excludeTags, statMetricsFiltered.M(int64(metricsDroppedByExclude)))
+	}
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	md.Metrics = metrics
+	return fmp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// matchRule returns the rule that drops the timeseries, or an empty string if
+// the timeseries is kept.
+func (fmp *filterMetricsProcessor) matchRule(metric *metricspb.Metric, ts *metricspb.TimeSeries) string {
+	if fmp.include != nil && !fmp.include.MatchTimeSeries(metric, ts) {
+		return ruleInclude
+	}
+	if fmp.exclude != nil && fmp.exclude.MatchTimeSeries(metric, ts) {
+		return ruleExclude
+	}
+	return ""
+}
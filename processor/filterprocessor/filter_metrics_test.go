@@ -0,0 +1,132 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"context"
+	"testing"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filtermetric"
+)
+
+func newMetric(name string, envs ...string) *metricspb.Metric {
+	metric := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      name,
+			LabelKeys: []*metricspb.LabelKey{{Key: "env"}},
+		},
+	}
+	for _, env := range envs {
+		metric.Timeseries = append(metric.Timeseries, &metricspb.TimeSeries{
+			LabelValues: []*metricspb.LabelValue{{Value: env, HasValue: true}},
+		})
+	}
+	return metric
+}
+
+func TestFilterMetricsProcessor(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Metrics.Include = &filtermetric.MatchProperties{
+		MatchType:   "regexp",
+		MetricNames: []string{"process/.*"},
+	}
+	cfg.Metrics.Exclude = &filtermetric.MatchProperties{
+		Labels: []filtermetric.Label{{Key: "env", Value: "test"}},
+	}
+
+	sink := &exportertest.SinkMetricsExporter{}
+	fmp, err := newFilterMetricsProcessor(sink, cfg)
+	require.NoError(t, err)
+
+	untouched := newMetric("process/memory", "prod")
+	partial := newMetric("process/cpu_seconds", "prod", "test")
+	empty := newMetric("process/open_fds")
+	md := consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{
+			untouched,
+			partial,
+			empty,
+			newMetric("process/threads", "test"),
+			newMetric("go/gc", "prod"),
+			newMetric("go/goroutines"),
+			nil,
+		},
+	}
+	require.NoError(t, fmp.ConsumeMetricsData(context.Background(), md))
+
+	// Batches left without any metrics are not forwarded.
+	md.Metrics = []*metricspb.Metric{newMetric("go/gc", "prod")}
+	require.NoError(t, fmp.ConsumeMetricsData(context.Background(), md))
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 3)
+	assert.Equal(t, untouched, got[0].Metrics[0])
+	assert.Equal(t, partial.MetricDescriptor, got[0].Metrics[1].MetricDescriptor)
+	assert.Equal(t, partial.Timeseries[:1], got[0].Metrics[1].Timeseries)
+	// Metrics without timeseries are kept unless a rule excludes them.
+	assert.Equal(t, empty, got[0].Metrics[2])
+	// The original metrics must not be modified.
+	assert.Len(t, partial.Timeseries, 2)
+}
+
+func TestFilterMetricsProcessor_MetricsFiltered(t *testing.T) {
+	views := MetricViews(telemetry.Basic)
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Metrics.Include = &filtermetric.MatchProperties{
+		MatchType:   "regexp",
+		MetricNames: []string{"process/.*"},
+	}
+	cfg.Metrics.Exclude = &filtermetric.MatchProperties{
+		Labels: []filtermetric.Label{{Key: "env", Value: "test"}},
+	}
+	fmp, err := newFilterMetricsProcessor(&exportertest.SinkMetricsExporter{}, cfg)
+	require.NoError(t, err)
+
+	md := consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{
+			newMetric("process/memory", "prod", "test"),
+			newMetric("process/threads", "test"),
+			newMetric("go/gc", "prod"),
+			newMetric("go/goroutines"),
+		},
+	}
+	require.NoError(t, fmp.ConsumeMetricsData(context.Background(), md))
+
+	// The metrics left without timeseries are counted like the metrics
+	// dropped because of their name.
+	rows, err := view.RetrieveData(statMetricsFiltered.Name())
+	require.NoError(t, err)
+	got := make(map[string]float64)
+	for _, row := range rows {
+		for _, tag := range row.Tags {
+			if tag.Key == tagRuleKey {
+				got[tag.Value] = row.Data.(*view.SumData).Value
+			}
+		}
+	}
+	assert.Equal(t, map[string]float64{ruleInclude: 2, ruleExclude: 1}, got)
+}
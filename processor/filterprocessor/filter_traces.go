@@ -0,0 +1,102 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"context"
+	"fmt"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type filterTraceProcessor struct {
+	nextConsumer consumer.TraceConsumer
+	include      filterspan.Matcher
+	exclude      filterspan.Matcher
+	includeTags  []tag.Mutator
+	excludeTags  []tag.Mutator
+}
+
+var _ processor.TraceProcessor = (*filterTraceProcessor)(nil)
+
+// newFilterTraceProcessor returns a processor that drops the spans that are
+// not included or that are excluded by the configuration.
+func newFilterTraceProcessor(nextConsumer consumer.TraceConsumer, cfg *Config) (*filterTraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+
+	ftp := &filterTraceProcessor{
+		nextConsumer: nextConsumer,
+		includeTags:  ruleStatsTags(cfg.Name(), ruleInclude),
+		excludeTags:  ruleStatsTags(cfg.Name(), ruleExclude),
+	}
+
+	var err error
+	if cfg.Spans.Include != nil {
+		if ftp.include, err = filterspan.NewMatcher(cfg.Spans.Include); err != nil {
+			return nil, fmt.Errorf("error creating \"filter\" processor due to invalid \"include\" in \"spans\" of processor %q: %v", cfg.Name(), err)
+		}
+	}
+	if cfg.Spans.Exclude != nil {
+		if ftp.exclude, err = filterspan.NewMatcher(cfg.Spans.Exclude); err != nil {
+			return nil, fmt.Errorf("error creating \"filter\" processor due to invalid \"exclude\" in \"spans\" of processor %q: %v", cfg.Name(), err)
+		}
+	}
+	return ftp, nil
+}
+
+func (ftp *filterTraceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	serviceName := td.Node.GetServiceInfo().GetName()
+
+	// The spans slice may be shared with other pipelines so a new one is
+	// created instead of filtering in place.
+	droppedByInclude, droppedByExclude := 0, 0
+	spans := make([]*tracepb.Span, 0, len(td.Spans))
+	for _, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		switch {
+		case ftp.include != nil && !ftp.include.MatchSpan(span, serviceName):
+			droppedByInclude++
+		case ftp.exclude != nil && ftp.exclude.MatchSpan(span, serviceName):
+			droppedByExclude++
+		default:
+			spans = append(spans, span)
+		}
+	}
+
+	if droppedByInclude > 0 {
+		_ = stats.RecordWithTags(ctx, ftp.includeTags, statSpansFiltered.M(int64(droppedByInclude)))
+	}
+	if droppedByExclude > 0 {
+		_ = stats.RecordWithTags(ctx, ftp.excludeTags, statSpansFiltered.M(int64(droppedByExclude)))
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+
+	td.Spans = spans
+	return ftp.nextConsumer.ConsumeTraceData(ctx, td)
+}
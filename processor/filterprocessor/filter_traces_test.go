@@ -0,0 +1,73 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
+)
+
+func newSpan(name string, kind tracepb.Span_SpanKind) *tracepb.Span {
+	return &tracepb.Span{
+		Name: &tracepb.TruncatableString{Value: name},
+		Kind: kind,
+	}
+}
+
+func TestFilterTraceProcessor(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Spans.Include = &filterspan.MatchProperties{
+		Services: []string{"frontend"},
+	}
+	cfg.Spans.Exclude = &filterspan.MatchProperties{
+		MatchType: "regexp",
+		SpanNames: []string{".*/health"},
+		SpanKinds: []string{"SERVER"},
+	}
+
+	sink := &exportertest.SinkTraceExporter{}
+	ftp, err := newFilterTraceProcessor(sink, cfg)
+	require.NoError(t, err)
+
+	kept := newSpan("GET /health", tracepb.Span_CLIENT)
+	td := consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "frontend"}},
+		Spans: []*tracepb.Span{
+			newSpan("GET /health", tracepb.Span_SERVER),
+			nil,
+			kept,
+		},
+	}
+	require.NoError(t, ftp.ConsumeTraceData(context.Background(), td))
+
+	// Spans of other services are not included.
+	td.Node = &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "backend"}}
+	require.NoError(t, ftp.ConsumeTraceData(context.Background(), td))
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	assert.Equal(t, []*tracepb.Span{kept}, got[0].Spans)
+	// The original batch must not be modified.
+	assert.Len(t, td.Spans, 3)
+}
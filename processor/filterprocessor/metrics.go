@@ -0,0 +1,87 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filterprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/collector/telemetry"
+)
+
+// Variables related to metrics specific to the filter processor.
+var (
+	tagFilterKey, _ = tag.NewKey("filter")
+	tagRuleKey, _   = tag.NewKey("rule")
+
+	statSpansFiltered      = stats.Int64("spans_filtered", "Count of spans dropped by the filter processor", stats.UnitDimensionless)
+	statTimeSeriesFiltered = stats.Int64("timeseries_filtered", "Count of metric timeseries dropped by the filter processor", stats.UnitDimensionless)
+	statMetricsFiltered    = stats.Int64("metrics_filtered", "Count of metrics dropped by the filter processor", stats.UnitDimensionless)
+)
+
+// Values of the rule tag, identifying which part of the configuration dropped
+// the data.
+const (
+	ruleInclude = "include"
+	ruleExclude = "exclude"
+)
+
+// ruleStatsTags returns the tags used to record the data dropped by the given
+// rule of the named processor.
+func ruleStatsTags(processorName, rule string) []tag.Mutator {
+	return []tag.Mutator{
+		tag.Upsert(tagFilterKey, processorName),
+		tag.Upsert(tagRuleKey, rule),
+	}
+}
+
+// MetricViews returns the metrics views related to filtering.
+func MetricViews(level telemetry.Level) []*view.View {
+	if level == telemetry.None {
+		return nil
+	}
+
+	filterTagKeys := []tag.Key{tagFilterKey, tagRuleKey}
+
+	spansFilteredView := &view.View{
+		Name:        statSpansFiltered.Name(),
+		Measure:     statSpansFiltered,
+		Description: statSpansFiltered.Description(),
+		TagKeys:     filterTagKeys,
+		Aggregation: view.Sum(),
+	}
+	timeSeriesFilteredView := &view.View{
+		Name:        statTimeSeriesFiltered.Name(),
+		Measure:     statTimeSeriesFiltered,
+		Description: statTimeSeriesFiltered.Description(),
+		TagKeys:     filterTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	metricsFilteredView := &view.View{
+		Name:        statMetricsFiltered.Name(),
+		Measure:     statMetricsFiltered,
+		Description: statMetricsFiltered.Description(),
+		TagKeys:     filterTagKeys,
+		Aggregation: view.Sum(),
+	}
+
+	return []*view.View{
+		spansFilteredView,
+		timeSeriesFilteredView,
+		metricsFilteredView,
+	}
+}
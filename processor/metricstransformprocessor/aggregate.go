@@ -0,0 +1,284 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+// aggregateLabels drops all the labels of the metric that are not in the label
+// set of the operation and combines the timeseries that end up with the same
+// label values. The points of the combined timeseries are aggregated per
+// timestamp. It returns false, leaving the metric unmodified, if
+// the points of the metric cannot be aggregated with the type of the operation.
+func aggregateLabels(metric *metricspb.Metric, op operation) bool {
+	var keptIdxs []int
+	var keptKeys []*metricspb.LabelKey
+	for i, lk := range metric.MetricDescriptor.LabelKeys {
+		if op.labelSet[lk.GetKey()] {
+			keptIdxs = append(keptIdxs, i)
+			keptKeys = append(keptKeys, lk)
+		}
+	}
+
+	// Group the timeseries by the values of the kept labels, preserving the
+	// order in which the groups are first seen.
+	var groupKeys []string
+	groups := make(map[string][]*metricspb.TimeSeries)
+	for _, ts := range metric.Timeseries {
+		key := groupKey(ts, keptIdxs)
+		if _, ok := groups[key]; !ok {
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key] = append(groups[key], ts)
+	}
+
+	timeseries := make([]*metricspb.TimeSeries, 0, len(groupKeys))
+	for _, key := range groupKeys {
+		ts, ok := mergeTimeSeries(groups[key], keptIdxs, op.aggregationType)
+		if !ok {
+			return false
+		}
+		timeseries = append(timeseries, ts)
+	}
+
+	metric.MetricDescriptor.LabelKeys = keptKeys
+	metric.Timeseries = timeseries
+	return true
+}
+
+func groupKey(ts *metricspb.TimeSeries, idxs []int) string {
+	var sb strings.Builder
+	for _, idx := range idxs {
+		// Missing values and values that are not set are distinguished from
+		// empty values.
+		if idx < len(ts.LabelValues) && ts.LabelValues[idx].GetHasValue() {
+			sb.WriteByte('+')
+			sb.WriteString(ts.LabelValues[idx].Value)
+		}
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
+
+func mergeTimeSeries(group []*metricspb.TimeSeries, keptIdxs []int, aggType AggregationType) (*metricspb.TimeSeries, bool) {
+	first := group[0]
+	merged := &metricspb.TimeSeries{
+		StartTimestamp: first.StartTimestamp,
+		LabelValues:    make([]*metricspb.LabelValue, 0, len(keptIdxs)),
+	}
+	for _, idx := range keptIdxs {
+		if idx < len(first.LabelValues) {
+			merged.LabelValues = append(merged.LabelValues, first.LabelValues[idx])
+		} else {
+			merged.LabelValues = append(merged.LabelValues, &metricspb.LabelValue{})
+		}
+	}
+
+	// The points are aggregated per timestamp: the points of series reported
+	// at different times are not combined with each other.
+	var timestamps []*timestamp.Timestamp
+	pointsByTimestamp := make(map[timestampKey][]*metricspb.Point)
+	for _, ts := range group {
+		if isEarlier(ts.StartTimestamp, merged.StartTimestamp) {
+			merged.StartTimestamp = ts.StartTimestamp
+		}
+		for _, point := range ts.Points {
+			if point == nil {
+				continue
+			}
+			key := newTimestampKey(point.Timestamp)
+			if _, ok := pointsByTimestamp[key]; !ok {
+				timestamps = append(timestamps, point.Timestamp)
+			}
+			pointsByTimestamp[key] = append(pointsByTimestamp[key], point)
+		}
+	}
+	sort.SliceStable(timestamps, func(i, j int) bool {
+		return isBefore(timestamps[i], timestamps[j])
+	})
+
+	for _, t := range timestamps {
+		point, ok := aggregatePoints(pointsByTimestamp[newTimestampKey(t)], aggType)
+		if !ok {
+			return nil, false
+		}
+		merged.Points = append(merged.Points, point)
+	}
+	return merged, true
+}
+
+// timestampKey identifies a timestamp, points without timestamp share the zero
+// key.
+type timestampKey struct {
+	set     bool
+	seconds int64
+	nanos   int32
+}
+
+func newTimestampKey(t *timestamp.Timestamp) timestampKey {
+	if t == nil {
+		return timestampKey{}
+	}
+	return timestampKey{set: true, seconds: t.Seconds, nanos: t.Nanos}
+}
+
+func isEarlier(t, other *timestamp.Timestamp) bool {
+	if t == nil {
+		return false
+	}
+	if other == nil {
+		return true
+	}
+	return t.Seconds < other.Seconds || (t.Seconds == other.Seconds && t.Nanos < other.Nanos)
+}
+
+// isBefore is like isEarlier but points without timestamp are considered to
+// be the earliest.
+func isBefore(t, other *timestamp.Timestamp) bool {
+	if t == nil {
+		return other != nil
+	}
+	return isEarlier(t, other) && other != nil
+}
+
+func aggregatePoints(points []*metricspb.Point, aggType AggregationType) (*metricspb.Point, bool) {
+	first := points[0]
+	if len(points) == 1 {
+		return first, true
+	}
+	ts := first.Timestamp
+
+	switch first.Value.(type) {
+	case *metricspb.Point_Int64Value:
+		values := make([]float64, 0, len(points))
+		for _, point := range points {
+			v, ok := point.Value.(*metricspb.Point_Int64Value)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, float64(v.Int64Value))
+		}
+		// The mean is rounded to the nearest integer rather than truncated.
+		return &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_Int64Value{Int64Value: int64(math.Round(aggregateValues(values, aggType)))},
+		}, true
+
+	case *metricspb.Point_DoubleValue:
+		values := make([]float64, 0, len(points))
+		for _, point := range points {
+			v, ok := point.Value.(*metricspb.Point_DoubleValue)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, v.DoubleValue)
+		}
+		return &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_DoubleValue{DoubleValue: aggregateValues(values, aggType)},
+		}, true
+
+	case *metricspb.Point_DistributionValue:
+		if aggType != Sum {
+			return nil, false
+		}
+		dv, ok := mergeDistributions(points)
+		if !ok {
+			return nil, false
+		}
+		return &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_DistributionValue{DistributionValue: dv},
+		}, true
+
+	default:
+		return nil, false
+	}
+}
+
+func aggregateValues(values []float64, aggType AggregationType) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		switch aggType {
+		case Sum, Mean:
+			result += v
+		case Max:
+			result = math.Max(result, v)
+		case Min:
+			result = math.Min(result, v)
+		}
+	}
+	if aggType == Mean {
+		result /= float64(len(values))
+	}
+	return result
+}
+
+// mergeDistributions adds up distributions that have the same bucket options.
+func mergeDistributions(points []*metricspb.Point) (*metricspb.DistributionValue, bool) {
+	first := points[0].GetDistributionValue()
+	if first == nil {
+		return nil, false
+	}
+	merged := &metricspb.DistributionValue{
+		BucketOptions: first.BucketOptions,
+		Buckets:       make([]*metricspb.DistributionValue_Bucket, len(first.Buckets)),
+	}
+	for i := range merged.Buckets {
+		merged.Buckets[i] = &metricspb.DistributionValue_Bucket{}
+	}
+
+	for _, point := range points {
+		dv := point.GetDistributionValue()
+		if dv == nil || len(dv.Buckets) != len(merged.Buckets) {
+			return nil, false
+		}
+		if !equalBounds(dv.GetBucketOptions().GetExplicit().GetBounds(), first.GetBucketOptions().GetExplicit().GetBounds()) {
+			return nil, false
+		}
+		// Combine the sum of squared deviations using the parallel algorithm:
+		// https://en.wikipedia.org/wiki/Algorithms_for_calculating_variance#Parallel_algorithm
+		if merged.Count > 0 && dv.Count > 0 {
+			delta := dv.Sum/float64(dv.Count) - merged.Sum/float64(merged.Count)
+			total := float64(merged.Count + dv.Count)
+			merged.SumOfSquaredDeviation += delta * delta * float64(merged.Count) * float64(dv.Count) / total
+		}
+		merged.SumOfSquaredDeviation += dv.SumOfSquaredDeviation
+		merged.Count += dv.Count
+		merged.Sum += dv.Sum
+		for i, bucket := range dv.Buckets {
+			merged.Buckets[i].Count += bucket.GetCount()
+		}
+	}
+	return merged, true
+}
+
+func equalBounds(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
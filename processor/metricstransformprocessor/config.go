@@ -0,0 +1,137 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterset"
+)
+
+// Config specifies the list of transforms applied to metrics.
+// Each metric is compared against the metric name of every transform, in the
+// order specified in the configuration, and all the matching transforms are
+// applied to it.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Transforms specifies the list of transforms to apply.
+	// This is a required field.
+	Transforms []Transform `mapstructure:"transforms"`
+}
+
+// Transform specifies how to select metrics and what to do with them.
+type Transform struct {
+	// MetricName specifies the name of the metrics to transform.
+	// This is a required field.
+	MetricName string `mapstructure:"metric_name"`
+
+	// MatchType specifies how MetricName is matched.
+	// The set of values are {strict, regexp}, strict is the default.
+	// For regexp, the expression is anchored at both ends.
+	MatchType filterset.MatchType `mapstructure:"match_type"`
+
+	// NewName specifies the new name of the metric. When MatchType is regexp,
+	// capture groups of MetricName can be referenced, e.g. "$1".
+	// This is an optional field. If it is not set the metric is not renamed.
+	NewName string `mapstructure:"new_name"`
+
+	// Operations specifies the list of operations to apply to the metric, in
+	// the order specified in the configuration.
+	// This is an optional field.
+	Operations []Operation `mapstructure:"operations"`
+}
+
+// Operation specifies an operation on the labels or the values of a metric.
+type Operation struct {
+	// Action specifies the type of operation to perform.
+	// The set of values are {update_label, aggregate_labels, scale_value}.
+	// update_label     - Renames the label `label` to `new_label` and/or
+	//                    renames its values using `value_actions`.
+	// aggregate_labels - Drops all the labels but the ones in `label_set` and
+	//                    aggregates the timeseries that collide as a result
+	//                    using `aggregation_type`.
+	// scale_value      - Multiplies the values of the metric by `scale`.
+	//                    Integer metrics are converted to double metrics.
+	// This is a required field.
+	Action OperationAction `mapstructure:"action"`
+
+	// Label specifies the label to update.
+	// This is a required field for update_label.
+	Label string `mapstructure:"label"`
+
+	// NewLabel specifies the new key of the label.
+	// This is an optional field for update_label.
+	NewLabel string `mapstructure:"new_label"`
+
+	// ValueActions specifies the label values to rename.
+	// This is an optional field for update_label.
+	ValueActions []ValueAction `mapstructure:"value_actions"`
+
+	// LabelSet specifies the labels to keep.
+	// This is an optional field for aggregate_labels. If it is empty all the
+	// labels are aggregated away.
+	LabelSet []string `mapstructure:"label_set"`
+
+	// AggregationType specifies how the values of colliding timeseries are
+	// combined. The set of values are {sum, mean, max, min}.
+	// Distribution metrics only support sum.
+	// This is a required field for aggregate_labels.
+	AggregationType AggregationType `mapstructure:"aggregation_type"`
+
+	// Scale specifies the factor to multiply the values by, e.g.
+	// 0.00000095367431640625 to convert bytes to MiB.
+	// This is a required field for scale_value.
+	Scale float64 `mapstructure:"scale"`
+}
+
+// ValueAction specifies a label value to rename.
+type ValueAction struct {
+	// Value specifies the current label value.
+	Value string `mapstructure:"value"`
+
+	// NewValue specifies the new label value.
+	NewValue string `mapstructure:"new_value"`
+}
+
+// OperationAction is the enum to capture the types of operations.
+type OperationAction string
+
+const (
+	// UpdateLabel renames a label and/or its values.
+	UpdateLabel OperationAction = "update_label"
+
+	// AggregateLabels drops labels and aggregates the resulting timeseries.
+	AggregateLabels OperationAction = "aggregate_labels"
+
+	// ScaleValue multiplies the values of a metric.
+	ScaleValue OperationAction = "scale_value"
+)
+
+// AggregationType is the enum to capture the ways to combine timeseries.
+type AggregationType string
+
+const (
+	// Sum adds the values of the timeseries.
+	Sum AggregationType = "sum"
+
+	// Mean averages the values of the timeseries.
+	Mean AggregationType = "mean"
+
+	// Max keeps the largest value of the timeseries.
+	Max AggregationType = "max"
+
+	// Min keeps the smallest value of the timeseries.
+	Min AggregationType = "min"
+)
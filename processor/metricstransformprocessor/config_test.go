@@ -0,0 +1,83 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/config"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.NoError(t, err)
+
+	factory := &Factory{}
+	factories.Processors[typeStr] = factory
+
+	cfg, err := config.LoadConfigFile(t, path.Join(".", "testdata", "config.yaml"), factories)
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	assert.Equal(t, cfg.Processors["metrics_transform/rename"], &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: "metrics_transform/rename",
+		},
+		Transforms: []Transform{
+			{
+				MetricName: "process/(.*)",
+				MatchType:  "regexp",
+				NewName:    "otelsvc.process.$1",
+			},
+		},
+	})
+
+	assert.Equal(t, cfg.Processors["metrics_transform/operations"], &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: "metrics_transform/operations",
+		},
+		Transforms: []Transform{
+			{
+				MetricName: "system/cpu_seconds",
+				Operations: []Operation{
+					{
+						Action:   UpdateLabel,
+						Label:    "cpu",
+						NewLabel: "core",
+						ValueActions: []ValueAction{
+							{Value: "cpu0", NewValue: "core0"},
+						},
+					},
+					{
+						Action:          AggregateLabels,
+						LabelSet:        []string{"core", "state"},
+						AggregationType: Sum,
+					},
+					{
+						Action: ScaleValue,
+						Scale:  0.016666666666666666,
+					},
+				},
+			},
+		},
+	})
+}
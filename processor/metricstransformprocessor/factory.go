@@ -0,0 +1,167 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterset"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+const (
+	// typeStr is the value of "type" key in configuration.
+	typeStr = "metrics_transform"
+)
+
+// Factory is the factory for the Metrics Transform processor.
+type Factory struct {
+}
+
+// Type gets the type of the config created by this factory.
+func (f *Factory) Type() string {
+	return typeStr
+}
+
+// CreateDefaultConfig creates the default configuration for the processor.
+// Note: This isn't a valid configuration because the processor would do no work.
+func (f *Factory) CreateDefaultConfig() configmodels.Processor {
+	return &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: typeStr,
+		},
+	}
+}
+
+// CreateTraceProcessor creates a trace processor based on this config.
+func (f *Factory) CreateTraceProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.TraceConsumer,
+	cfg configmodels.Processor,
+) (processor.TraceProcessor, error) {
+	return nil, configerror.ErrDataTypeIsNotSupported
+}
+
+// CreateMetricsProcessor creates a metrics processor based on this config.
+func (f *Factory) CreateMetricsProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	cfg configmodels.Processor,
+) (processor.MetricsProcessor, error) {
+	oCfg := cfg.(*Config)
+	transforms, err := buildTransforms(*oCfg)
+	if err != nil {
+		return nil, err
+	}
+	return newMetricsTransformProcessor(logger, nextConsumer, transforms)
+}
+
+// buildTransforms validates the input configuration has all of the required
+// fields for the processor and returns the list of transforms to apply.
+// An error is returned if there are any invalid inputs.
+func buildTransforms(config Config) ([]transform, error) {
+	if len(config.Transforms) == 0 {
+		return nil, fmt.Errorf("error creating \"metrics_transform\" processor due to missing required field \"transforms\" of processor %q", config.Name())
+	}
+
+	transforms := make([]transform, 0, len(config.Transforms))
+	for i, t := range config.Transforms {
+		if t.MetricName == "" {
+			return nil, fmt.Errorf("error creating \"metrics_transform\" processor due to missing required field \"metric_name\" at the %d-th transforms of processor %q", i, config.Name())
+		}
+		tr := transform{
+			metricName: t.MetricName,
+			newName:    t.NewName,
+		}
+		switch filterset.MatchType(strings.ToLower(string(t.MatchType))) {
+		case filterset.Strict, "":
+		case filterset.Regexp:
+			re, err := regexp.Compile("^(?:" + t.MetricName + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("error creating \"metrics_transform\" processor due to invalid regexp %q at the %d-th transforms of processor %q: %v", t.MetricName, i, config.Name(), err)
+			}
+			tr.metricNameRegexp = re
+		default:
+			return nil, fmt.Errorf("error creating \"metrics_transform\" processor due to unsupported match type %q at the %d-th transforms of processor %q", t.MatchType, i, config.Name())
+		}
+
+		for j, op := range t.Operations {
+			o, err := buildOperation(op)
+			if err != nil {
+				return nil, fmt.Errorf("error creating \"metrics_transform\" processor due to %v at the %d-th operations of the %d-th transforms of processor %q", err, j, i, config.Name())
+			}
+			tr.operations = append(tr.operations, o)
+		}
+
+		if tr.newName == "" && len(tr.operations) == 0 {
+			return nil, fmt.Errorf("error creating \"metrics_transform\" processor due to missing both \"new_name\" and \"operations\" at the %d-th transforms of processor %q", i, config.Name())
+		}
+		transforms = append(transforms, tr)
+	}
+	return transforms, nil
+}
+
+func buildOperation(op Operation) (operation, error) {
+	o := operation{action: OperationAction(strings.ToLower(string(op.Action)))}
+	switch o.action {
+	case UpdateLabel:
+		if op.Label == "" {
+			return operation{}, fmt.Errorf("missing required field \"label\"")
+		}
+		if op.NewLabel == "" && len(op.ValueActions) == 0 {
+			return operation{}, fmt.Errorf("missing both \"new_label\" and \"value_actions\"")
+		}
+		o.label = op.Label
+		o.newLabel = op.NewLabel
+		if len(op.ValueActions) > 0 {
+			o.newValues = make(map[string]string, len(op.ValueActions))
+			for _, va := range op.ValueActions {
+				o.newValues[va.Value] = va.NewValue
+			}
+		}
+
+	case AggregateLabels:
+		o.aggregationType = AggregationType(strings.ToLower(string(op.AggregationType)))
+		switch o.aggregationType {
+		case Sum, Mean, Max, Min:
+		case "":
+			return operation{}, fmt.Errorf("missing required field \"aggregation_type\"")
+		default:
+			return operation{}, fmt.Errorf("unsupported aggregation type %q", op.AggregationType)
+		}
+		o.labelSet = make(map[string]bool, len(op.LabelSet))
+		for _, label := range op.LabelSet {
+			o.labelSet[label] = true
+		}
+
+	case ScaleValue:
+		if op.Scale <= 0 {
+			return operation{}, fmt.Errorf("field \"scale\" must be positive")
+		}
+		o.scale = op.Scale
+
+	default:
+		return operation{}, fmt.Errorf("unsupported action %q", op.Action)
+	}
+	return o, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configerror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := &Factory{}
+	assert.Equal(t, factory.Type(), typeStr)
+}
+
+func TestFactory_CreateDefaultConfig(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg)
+	assert.Equal(t, typeStr, cfg.Type())
+	assert.Equal(t, typeStr, cfg.Name())
+}
+
+func TestFactory_CreateTraceProcessor(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	assert.Nil(t, tp)
+	assert.Equal(t, configerror.ErrDataTypeIsNotSupported, err)
+}
+
+func TestFactory_CreateMetricsProcessor(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Transforms = []Transform{{MetricName: "name", NewName: "new_name"}}
+
+	mp, err := factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, mp)
+
+	mp, err = factory.CreateMetricsProcessor(zap.NewNop(), nil, cfg)
+	assert.Error(t, err)
+	assert.Nil(t, mp)
+}
+
+func TestFactory_CreateMetricsProcessor_InvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name       string
+		transforms []Transform
+	}{
+		{
+			name: "missing transforms",
+		},
+		{
+			name:       "missing metric name",
+			transforms: []Transform{{NewName: "new_name"}},
+		},
+		{
+			name:       "nothing to do",
+			transforms: []Transform{{MetricName: "name"}},
+		},
+		{
+			name:       "invalid regexp",
+			transforms: []Transform{{MetricName: "(", MatchType: "regexp", NewName: "new_name"}},
+		},
+		{
+			name:       "invalid match type",
+			transforms: []Transform{{MetricName: "name", MatchType: "glob", NewName: "new_name"}},
+		},
+		{
+			name:       "invalid action",
+			transforms: []Transform{{MetricName: "name", Operations: []Operation{{Action: "delete"}}}},
+		},
+		{
+			name:       "update label without label",
+			transforms: []Transform{{MetricName: "name", Operations: []Operation{{Action: UpdateLabel, NewLabel: "new"}}}},
+		},
+		{
+			name:       "update label without changes",
+			transforms: []Transform{{MetricName: "name", Operations: []Operation{{Action: UpdateLabel, Label: "old"}}}},
+		},
+		{
+			name:       "aggregate labels without type",
+			transforms: []Transform{{MetricName: "name", Operations: []Operation{{Action: AggregateLabels}}}},
+		},
+		{
+			name:       "aggregate labels with invalid type",
+			transforms: []Transform{{MetricName: "name", Operations: []Operation{{Action: AggregateLabels, AggregationType: "median"}}}},
+		},
+		{
+			name:       "scale value without scale",
+			transforms: []Transform{{MetricName: "name", Operations: []Operation{{Action: ScaleValue}}}},
+		},
+	}
+
+	factory := &Factory{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Transforms = tc.transforms
+			mp, err := factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+			assert.Error(t, err)
+			assert.Nil(t, mp)
+		})
+	}
+}
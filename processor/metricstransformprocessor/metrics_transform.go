@@ -0,0 +1,244 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"context"
+	"regexp"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type metricsTransformProcessor struct {
+	logger       *zap.Logger
+	nextConsumer consumer.MetricsConsumer
+	transforms   []transform
+}
+
+// transform is the validated form of a Transform from the configuration.
+type transform struct {
+	metricName string
+	// metricNameRegexp is nil for strict matching.
+	metricNameRegexp *regexp.Regexp
+	newName          string
+	operations       []operation
+}
+
+// operation is the validated form of an Operation from the configuration.
+type operation struct {
+	action          OperationAction
+	label           string
+	newLabel        string
+	newValues       map[string]string
+	labelSet        map[string]bool
+	aggregationType AggregationType
+	scale           float64
+}
+
+var _ processor.MetricsProcessor = (*metricsTransformProcessor)(nil)
+
+// newMetricsTransformProcessor returns a processor that applies the transforms
+// to the metrics passing through it.
+func newMetricsTransformProcessor(
+	logger *zap.Logger,
+	nextConsumer consumer.MetricsConsumer,
+	transforms []transform,
+) (*metricsTransformProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &metricsTransformProcessor{
+		logger:       logger,
+		nextConsumer: nextConsumer,
+		transforms:   transforms,
+	}, nil
+}
+
+func (mtp *metricsTransformProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	// The metrics may be shared with other pipelines so transformed metrics
+	// are copied instead of being modified in place.
+	metrics := make([]*metricspb.Metric, 0, len(md.Metrics))
+	for _, metric := range md.Metrics {
+		if metric == nil || metric.MetricDescriptor == nil {
+			metrics = append(metrics, metric)
+			continue
+		}
+		metrics = append(metrics, mtp.transformMetric(metric))
+	}
+	md.Metrics = metrics
+	return mtp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// transformMetric applies all the matching transforms to the metric. The
+// metric is returned unmodified if no transform matches, otherwise a modified
+// copy is returned.
+func (mtp *metricsTransformProcessor) transformMetric(metric *metricspb.Metric) *metricspb.Metric {
+	out := metric
+	for _, t := range mtp.transforms {
+		newName, matched := t.match(out.MetricDescriptor.Name)
+		if !matched {
+			continue
+		}
+		if out == metric {
+			out = proto.Clone(metric).(*metricspb.Metric)
+		}
+		if newName != "" {
+			out.MetricDescriptor.Name = newName
+		}
+		for _, op := range t.operations {
+			switch op.action {
+			case UpdateLabel:
+				if !updateLabel(out, op) {
+					mtp.logger.Debug("Label cannot be renamed to an existing label",
+						zap.String("metric", out.MetricDescriptor.Name),
+						zap.String("label", op.label),
+						zap.String("new_label", op.newLabel))
+				}
+			case AggregateLabels:
+				if !aggregateLabels(out, op) {
+					mtp.logger.Debug("Metric cannot be aggregated",
+						zap.String("metric", out.MetricDescriptor.Name),
+						zap.String("aggregation_type", string(op.aggregationType)))
+				}
+			case ScaleValue:
+				scaleValue(out, op.scale)
+			}
+		}
+	}
+	return out
+}
+
+// match returns whether the name matches the transform and the new name of
+// the metric, empty if the metric is not to be renamed.
+func (t *transform) match(name string) (string, bool) {
+	if t.metricNameRegexp == nil {
+		return t.newName, name == t.metricName
+	}
+	submatches := t.metricNameRegexp.FindStringSubmatchIndex(name)
+	if submatches == nil {
+		return "", false
+	}
+	if t.newName == "" {
+		return "", true
+	}
+	return string(t.metricNameRegexp.ExpandString(nil, t.newName, name, submatches)), true
+}
+
+func labelIndex(desc *metricspb.MetricDescriptor, key string) int {
+	for i, lk := range desc.LabelKeys {
+		if lk.GetKey() == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// updateLabel renames the label and its values. It returns false, leaving the
+// metric unmodified, if the label would be renamed to another existing label.
+func updateLabel(metric *metricspb.Metric, op operation) bool {
+	idx := labelIndex(metric.MetricDescriptor, op.label)
+	if idx < 0 {
+		return true
+	}
+	if op.newLabel != "" {
+		if newIdx := labelIndex(metric.MetricDescriptor, op.newLabel); newIdx >= 0 && newIdx != idx {
+			return false
+		}
+		metric.MetricDescriptor.LabelKeys[idx].Key = op.newLabel
+	}
+	if op.newValues == nil {
+		return true
+	}
+	for _, ts := range metric.Timeseries {
+		if idx >= len(ts.GetLabelValues()) {
+			continue
+		}
+		lv := ts.LabelValues[idx]
+		if lv == nil || !lv.HasValue {
+			continue
+		}
+		if newValue, ok := op.newValues[lv.Value]; ok {
+			lv.Value = newValue
+		}
+	}
+	return true
+}
+
+func scaleValue(metric *metricspb.Metric, scale float64) {
+	switch metric.MetricDescriptor.Type {
+	case metricspb.MetricDescriptor_GAUGE_INT64:
+		metric.MetricDescriptor.Type = metricspb.MetricDescriptor_GAUGE_DOUBLE
+	case metricspb.MetricDescriptor_CUMULATIVE_INT64:
+		metric.MetricDescriptor.Type = metricspb.MetricDescriptor_CUMULATIVE_DOUBLE
+	}
+
+	for _, ts := range metric.Timeseries {
+		for _, point := range ts.GetPoints() {
+			switch value := point.Value.(type) {
+			case *metricspb.Point_Int64Value:
+				point.Value = &metricspb.Point_DoubleValue{DoubleValue: float64(value.Int64Value) * scale}
+			case *metricspb.Point_DoubleValue:
+				value.DoubleValue *= scale
+			case *metricspb.Point_DistributionValue:
+				scaleDistribution(value.DistributionValue, scale)
+			case *metricspb.Point_SummaryValue:
+				scaleSummary(value.SummaryValue, scale)
+			}
+		}
+	}
+}
+
+func scaleDistribution(dv *metricspb.DistributionValue, scale float64) {
+	if dv == nil {
+		return
+	}
+	dv.Sum *= scale
+	dv.SumOfSquaredDeviation *= scale * scale
+	if explicit := dv.GetBucketOptions().GetExplicit(); explicit != nil {
+		for i := range explicit.Bounds {
+			explicit.Bounds[i] *= scale
+		}
+	}
+	for _, bucket := range dv.Buckets {
+		if bucket.GetExemplar() != nil {
+			bucket.Exemplar.Value *= scale
+		}
+	}
+}
+
+func scaleSummary(sv *metricspb.SummaryValue, scale float64) {
+	if sv == nil {
+		return
+	}
+	if sv.Sum != nil {
+		sv.Sum.Value *= scale
+	}
+	if sv.Snapshot == nil {
+		return
+	}
+	if sv.Snapshot.Sum != nil {
+		sv.Snapshot.Sum.Value *= scale
+	}
+	for _, p := range sv.Snapshot.PercentileValues {
+		p.Value *= scale
+	}
+}
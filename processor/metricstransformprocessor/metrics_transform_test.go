@@ -0,0 +1,362 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metricstransformprocessor
+
+import (
+	"context"
+	"testing"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func labelKeys(keys ...string) []*metricspb.LabelKey {
+	lks := make([]*metricspb.LabelKey, 0, len(keys))
+	for _, key := range keys {
+		lks = append(lks, &metricspb.LabelKey{Key: key})
+	}
+	return lks
+}
+
+func labelValues(values ...string) []*metricspb.LabelValue {
+	lvs := make([]*metricspb.LabelValue, 0, len(values))
+	for _, value := range values {
+		lvs = append(lvs, &metricspb.LabelValue{Value: value, HasValue: true})
+	}
+	return lvs
+}
+
+func int64TimeSeries(start, ts int64, value int64, values ...string) *metricspb.TimeSeries {
+	return &metricspb.TimeSeries{
+		StartTimestamp: &timestamp.Timestamp{Seconds: start},
+		LabelValues:    labelValues(values...),
+		Points: []*metricspb.Point{
+			{
+				Timestamp: &timestamp.Timestamp{Seconds: ts},
+				Value:     &metricspb.Point_Int64Value{Int64Value: value},
+			},
+		},
+	}
+}
+
+func doubleTimeSeries(start, ts int64, value float64, values ...string) *metricspb.TimeSeries {
+	return &metricspb.TimeSeries{
+		StartTimestamp: &timestamp.Timestamp{Seconds: start},
+		LabelValues:    labelValues(values...),
+		Points: []*metricspb.Point{
+			{
+				Timestamp: &timestamp.Timestamp{Seconds: ts},
+				Value:     &metricspb.Point_DoubleValue{DoubleValue: value},
+			},
+		},
+	}
+}
+
+func runTransforms(t *testing.T, transforms []Transform, metrics ...*metricspb.Metric) []*metricspb.Metric {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Transforms = transforms
+
+	sink := &exportertest.SinkMetricsExporter{}
+	mp, err := factory.CreateMetricsProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+
+	// Keep a copy of the input to check that it is not modified.
+	originals := make([]*metricspb.Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		originals = append(originals, proto.Clone(metric).(*metricspb.Metric))
+	}
+
+	require.NoError(t, mp.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{Metrics: metrics}))
+	assert.Equal(t, originals, metrics)
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	return got[0].Metrics
+}
+
+func TestMetricsTransform_Rename(t *testing.T) {
+	metrics := runTransforms(t,
+		[]Transform{
+			{MetricName: "process/(.*)", MatchType: "regexp", NewName: "otelsvc.process.$1"},
+			{MetricName: "system/cpu_seconds", NewName: "system.cpu.seconds"},
+		},
+		&metricspb.Metric{MetricDescriptor: &metricspb.MetricDescriptor{Name: "process/cpu_seconds"}},
+		&metricspb.Metric{MetricDescriptor: &metricspb.MetricDescriptor{Name: "system/cpu_seconds"}},
+		&metricspb.Metric{MetricDescriptor: &metricspb.MetricDescriptor{Name: "my/process/cpu_seconds"}},
+		nil,
+	)
+
+	require.Len(t, metrics, 4)
+	assert.Equal(t, "otelsvc.process.cpu_seconds", metrics[0].MetricDescriptor.Name)
+	assert.Equal(t, "system.cpu.seconds", metrics[1].MetricDescriptor.Name)
+	assert.Equal(t, "my/process/cpu_seconds", metrics[2].MetricDescriptor.Name)
+	assert.Nil(t, metrics[3])
+}
+
+func TestMetricsTransform_UpdateLabel(t *testing.T) {
+	metrics := runTransforms(t,
+		[]Transform{
+			{
+				MetricName: "system/cpu_seconds",
+				Operations: []Operation{
+					{
+						Action:       UpdateLabel,
+						Label:        "cpu",
+						NewLabel:     "core",
+						ValueActions: []ValueAction{{Value: "cpu0", NewValue: "core0"}},
+					},
+				},
+			},
+		},
+		&metricspb.Metric{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "system/cpu_seconds",
+				LabelKeys: labelKeys("state", "cpu"),
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				int64TimeSeries(1, 2, 10, "idle", "cpu0"),
+				int64TimeSeries(1, 2, 10, "idle", "cpu1"),
+			},
+		},
+	)
+
+	require.Len(t, metrics, 1)
+	assert.Equal(t, labelKeys("state", "core"), metrics[0].MetricDescriptor.LabelKeys)
+	assert.Equal(t, labelValues("idle", "core0"), metrics[0].Timeseries[0].LabelValues)
+	assert.Equal(t, labelValues("idle", "cpu1"), metrics[0].Timeseries[1].LabelValues)
+}
+
+func TestMetricsTransform_UpdateLabelCollision(t *testing.T) {
+	metric := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      "system/cpu_seconds",
+			LabelKeys: labelKeys("state", "cpu"),
+		},
+		Timeseries: []*metricspb.TimeSeries{
+			int64TimeSeries(1, 2, 10, "idle", "cpu0"),
+		},
+	}
+	metrics := runTransforms(t,
+		[]Transform{
+			{
+				MetricName: "system/cpu_seconds",
+				Operations: []Operation{
+					{
+						Action:       UpdateLabel,
+						Label:        "cpu",
+						NewLabel:     "state",
+						ValueActions: []ValueAction{{Value: "cpu0", NewValue: "core0"}},
+					},
+				},
+			},
+		},
+		metric,
+	)
+
+	require.Len(t, metrics, 1)
+	assert.Equal(t, metric, metrics[0])
+}
+
+func TestMetricsTransform_AggregateLabels(t *testing.T) {
+	newMetric := func() *metricspb.Metric {
+		return &metricspb.Metric{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "system/cpu_seconds",
+				Type:      metricspb.MetricDescriptor_CUMULATIVE_DOUBLE,
+				LabelKeys: labelKeys("cpu", "state"),
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				doubleTimeSeries(3, 10, 1, "cpu0", "idle"),
+				doubleTimeSeries(1, 10, 2, "cpu1", "idle"),
+				doubleTimeSeries(2, 10, 6, "cpu2", "idle"),
+				doubleTimeSeries(2, 20, 4, "cpu0", "busy"),
+				doubleTimeSeries(2, 10, 8, "cpu1", "busy"),
+				doubleTimeSeries(2, 20, 2, "cpu2", "busy"),
+			},
+		}
+	}
+
+	testCases := []struct {
+		aggType AggregationType
+		idle    float64
+		busy    float64
+	}{
+		{aggType: Sum, idle: 9, busy: 6},
+		{aggType: Mean, idle: 3, busy: 3},
+		{aggType: Max, idle: 6, busy: 4},
+		{aggType: Min, idle: 1, busy: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.aggType), func(t *testing.T) {
+			metrics := runTransforms(t,
+				[]Transform{
+					{
+						MetricName: "system/cpu_seconds",
+						Operations: []Operation{
+							{Action: AggregateLabels, LabelSet: []string{"state"}, AggregationType: tc.aggType},
+						},
+					},
+				},
+				newMetric(),
+			)
+
+			require.Len(t, metrics, 1)
+			assert.Equal(t, labelKeys("state"), metrics[0].MetricDescriptor.LabelKeys)
+			assert.Equal(t, []*metricspb.TimeSeries{
+				{
+					StartTimestamp: &timestamp.Timestamp{Seconds: 1},
+					LabelValues:    labelValues("idle"),
+					Points: []*metricspb.Point{
+						{
+							Timestamp: &timestamp.Timestamp{Seconds: 10},
+							Value:     &metricspb.Point_DoubleValue{DoubleValue: tc.idle},
+						},
+					},
+				},
+				{
+					StartTimestamp: &timestamp.Timestamp{Seconds: 2},
+					LabelValues:    labelValues("busy"),
+					// The points are aggregated per timestamp.
+					Points: []*metricspb.Point{
+						{
+							Timestamp: &timestamp.Timestamp{Seconds: 10},
+							Value:     &metricspb.Point_DoubleValue{DoubleValue: 8},
+						},
+						{
+							Timestamp: &timestamp.Timestamp{Seconds: 20},
+							Value:     &metricspb.Point_DoubleValue{DoubleValue: tc.busy},
+						},
+					},
+				},
+			}, metrics[0].Timeseries)
+		})
+	}
+}
+
+func TestMetricsTransform_AggregateInt64Mean(t *testing.T) {
+	metric := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      "connections",
+			Type:      metricspb.MetricDescriptor_GAUGE_INT64,
+			LabelKeys: labelKeys("host"),
+		},
+		Timeseries: []*metricspb.TimeSeries{
+			int64TimeSeries(1, 10, 1, "a"),
+			int64TimeSeries(1, 10, 2, "b"),
+		},
+	}
+
+	metrics := runTransforms(t,
+		[]Transform{
+			{MetricName: "connections", Operations: []Operation{{Action: AggregateLabels, AggregationType: Mean}}},
+		},
+		metric,
+	)
+	require.Len(t, metrics, 1)
+	require.Len(t, metrics[0].Timeseries, 1)
+	// The mean is rounded rather than truncated.
+	assert.Equal(t, int64(2), metrics[0].Timeseries[0].Points[0].GetInt64Value())
+}
+
+func TestMetricsTransform_AggregateDistributions(t *testing.T) {
+	bucketOptions := &metricspb.DistributionValue_BucketOptions{
+		Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+			Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{Bounds: []float64{10}},
+		},
+	}
+	distribution := func(values ...string) *metricspb.TimeSeries {
+		return &metricspb.TimeSeries{
+			LabelValues: labelValues(values...),
+			Points: []*metricspb.Point{
+				{
+					Timestamp: &timestamp.Timestamp{Seconds: 10},
+					Value: &metricspb.Point_DistributionValue{
+						DistributionValue: &metricspb.DistributionValue{
+							Count:         2,
+							Sum:           20,
+							BucketOptions: bucketOptions,
+							Buckets:       []*metricspb.DistributionValue_Bucket{{Count: 1}, {Count: 1}},
+						},
+					},
+				},
+			},
+		}
+	}
+	newMetric := func() *metricspb.Metric {
+		return &metricspb.Metric{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "latency",
+				Type:      metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION,
+				LabelKeys: labelKeys("host"),
+			},
+			Timeseries: []*metricspb.TimeSeries{distribution("a"), distribution("b")},
+		}
+	}
+
+	metrics := runTransforms(t,
+		[]Transform{
+			{MetricName: "latency", Operations: []Operation{{Action: AggregateLabels, AggregationType: Sum}}},
+		},
+		newMetric(),
+	)
+	require.Len(t, metrics, 1)
+	require.Len(t, metrics[0].Timeseries, 1)
+	assert.Empty(t, metrics[0].MetricDescriptor.LabelKeys)
+	assert.Equal(t, &metricspb.DistributionValue{
+		Count:         4,
+		Sum:           40,
+		BucketOptions: bucketOptions,
+		Buckets:       []*metricspb.DistributionValue_Bucket{{Count: 2}, {Count: 2}},
+	}, metrics[0].Timeseries[0].Points[0].GetDistributionValue())
+
+	// Distributions can only be summed, the metric is left untouched otherwise.
+	metrics = runTransforms(t,
+		[]Transform{
+			{MetricName: "latency", Operations: []Operation{{Action: AggregateLabels, AggregationType: Max}}},
+		},
+		newMetric(),
+	)
+	assert.Equal(t, []*metricspb.Metric{newMetric()}, metrics)
+}
+
+func TestMetricsTransform_ScaleValue(t *testing.T) {
+	metrics := runTransforms(t,
+		[]Transform{
+			{
+				MetricName: "process/memory_alloc",
+				Operations: []Operation{{Action: ScaleValue, Scale: 1.0 / 1024}},
+			},
+		},
+		&metricspb.Metric{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name: "process/memory_alloc",
+				Type: metricspb.MetricDescriptor_GAUGE_INT64,
+			},
+			Timeseries: []*metricspb.TimeSeries{int64TimeSeries(1, 2, 2048)},
+		},
+	)
+
+	require.Len(t, metrics, 1)
+	assert.Equal(t, metricspb.MetricDescriptor_GAUGE_DOUBLE, metrics[0].MetricDescriptor.Type)
+	assert.Equal(t, []*metricspb.TimeSeries{doubleTimeSeries(1, 2, 2)}, metrics[0].Timeseries)
+}
@@ -26,4 +26,15 @@ type Config struct {
 	// have different sampling rates: if they use the same seed all passing one layer may pass the other even if they have
 	// different sampling rates, configuring different seeds avoids that.
 	HashSeed uint32 `mapstructure:"hash_seed"`
+	// SamplingPriority is the name of the span attribute forcing the sampling
+	// decision, e.g. "sampling.priority" set by Jaeger and OpenTracing clients:
+	// spans with a value greater than 0 are sampled and spans with the value 0
+	// are not, regardless of the percentage. Other values are ignored. The
+	// priority is ignored if the name is empty.
+	SamplingPriority string `mapstructure:"sampling_priority"`
+	// HashAttribute is the name of the span attribute hashed instead of the
+	// trace ID, e.g. a user ID, to sample the spans by another key than their
+	// trace. Spans without the attribute are sampled by their trace ID.
+	// Note: This is an optional field.
+	HashAttribute string `mapstructure:"hash_attribute"`
 }
@@ -46,6 +46,8 @@ func TestLoadConfig(t *testing.T) {
 			},
 			SamplingPercentage: 15.3,
 			HashSeed:           22,
+			SamplingPriority:   "priority",
+			HashAttribute:      "user.id",
 		})
 
 }
@@ -26,6 +26,8 @@ import (
 const (
 	// The value of "type" trace-samplers in configuration.
 	typeStr = "probabilistic_sampler"
+
+	defaultSamplingPriority = "sampling.priority"
 )
 
 // Factory is the factory for trace-sample processor.
@@ -44,6 +46,7 @@ func (f *Factory) CreateDefaultConfig() configmodels.Processor {
 			TypeVal: typeStr,
 			NameVal: typeStr,
 		},
+		SamplingPriority: defaultSamplingPriority,
 	}
 }
 
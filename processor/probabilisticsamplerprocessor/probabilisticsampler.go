@@ -16,26 +16,28 @@ package probabilisticsamplerprocessor
 
 import (
 	"context"
+	"math"
+	"strconv"
 
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplinghash"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplingprobability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
-)
-
-const (
-	// The constants help translate user friendly percentages to numbers direct used in sampling.
-	numHashBuckets        = 0x4000 // Using a power of 2 to avoid division.
-	bitMaskHashBuckets    = numHashBuckets - 1
-	percentageScaleFactor = numHashBuckets / 100.0
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 )
 
 type tracesamplerprocessor struct {
 	nextConsumer       consumer.TraceConsumer
 	scaledSamplingRate uint32
 	hashSeed           uint32
+	// probability is the sampling percentage as a probability.
+	probability      float64
+	samplingPriority string
+	hashAttribute    string
 }
 
 var _ processor.TraceProcessor = (*tracesamplerprocessor)(nil)
@@ -50,88 +52,139 @@ func NewTraceProcessor(nextConsumer consumer.TraceConsumer, cfg Config) (process
 	return &tracesamplerprocessor{
 		nextConsumer: nextConsumer,
 		// Adjust sampling percentage on private so recalculations are avoided.
-		scaledSamplingRate: uint32(cfg.SamplingPercentage * percentageScaleFactor),
+		scaledSamplingRate: samplinghash.ScaledSamplingRate(cfg.SamplingPercentage),
 		hashSeed:           cfg.HashSeed,
+		probability:        math.Min(float64(cfg.SamplingPercentage)/100, 1),
+		samplingPriority:   cfg.SamplingPriority,
+		hashAttribute:      cfg.HashAttribute,
 	}, nil
 }
 
 func (tsp *tracesamplerprocessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
-	scaledSamplingRate := tsp.scaledSamplingRate
-	if scaledSamplingRate >= numHashBuckets {
+	forced := tsp.forcedDecisions(td.Spans)
+	if len(forced) == 0 && tsp.scaledSamplingRate >= samplinghash.NumHashBuckets {
 		return tsp.nextConsumer.ConsumeTraceData(ctx, td)
 	}
 
-	sampledTraceData := consumerdata.TraceData{
-		Node:         td.Node,
-		Resource:     td.Resource,
-		SourceFormat: td.SourceFormat,
-	}
-
+	modified := false
 	sampledSpans := make([]*tracepb.Span, 0, len(td.Spans))
 	for _, span := range td.Spans {
-		// If one assumes random trace ids hashing may seems avoidable, however, traces can be coming from sources
-		// with various different criteria to generate trace id and perhaps were already sampled without hashing.
-		// Hashing here prevents bias due to such systems.
-		if hash(span.TraceId, tsp.hashSeed)&bitMaskHashBuckets < scaledSamplingRate {
-			sampledSpans = append(sampledSpans, span)
+		if span == nil {
+			modified = true
+			continue
 		}
+		if keep, ok := forced[string(span.TraceId)]; ok {
+			if keep {
+				sampledSpans = append(sampledSpans, span)
+			} else {
+				modified = true
+			}
+			continue
+		}
+		if !samplinghash.IsSampled(tsp.hashKey(span), tsp.hashSeed, tsp.scaledSamplingRate) {
+			modified = true
+			continue
+		}
+		if tsp.probability < 1 {
+			span = samplingprobability.WithProbability(span, tsp.probability)
+			modified = true
+		}
+		sampledSpans = append(sampledSpans, span)
 	}
 
-	sampledTraceData.Spans = sampledSpans
+	if !modified {
+		return tsp.nextConsumer.ConsumeTraceData(ctx, td)
+	}
 
-	return tsp.nextConsumer.ConsumeTraceData(ctx, sampledTraceData)
+	return tsp.nextConsumer.ConsumeTraceData(ctx, consumerdata.TraceData{
+		Node:         td.Node,
+		Resource:     td.Resource,
+		Spans:        sampledSpans,
+		SourceFormat: td.SourceFormat,
+	})
 }
 
-// hash is a murmur3 hash function, see http://en.wikipedia.org/wiki/MurmurHash.
-func hash(key []byte, seed uint32) (hash uint32) {
-	const (
-		c1 = 0xcc9e2d51
-		c2 = 0x1b873593
-		c3 = 0x85ebca6b
-		c4 = 0xc2b2ae35
-		r1 = 15
-		r2 = 13
-		m  = 5
-		n  = 0xe6546b64
-	)
-
-	hash = seed
-	iByte := 0
-	for ; iByte+4 <= len(key); iByte += 4 {
-		k := uint32(key[iByte]) | uint32(key[iByte+1])<<8 | uint32(key[iByte+2])<<16 | uint32(key[iByte+3])<<24
-		k *= c1
-		k = (k << r1) | (k >> (32 - r1))
-		k *= c2
-		hash ^= k
-		hash = (hash << r2) | (hash >> (32 - r2))
-		hash = hash*m + n
+// forcedDecisions returns the decision forced for each trace of the batch by
+// its spans, if any: a trace is kept if any of its spans has a positive
+// sampling priority or the Zipkin debug flag, and dropped if any has a
+// sampling priority of 0. The decision applies to all the spans of the trace,
+// including those without a priority.
+func (tsp *tracesamplerprocessor) forcedDecisions(spans []*tracepb.Span) map[string]bool {
+	var forced map[string]bool
+	for _, span := range spans {
+		if span == nil {
+			continue
+		}
+		keep := isDebug(span)
+		if !keep {
+			priority, ok := tsp.priority(span)
+			if !ok {
+				continue
+			}
+			keep = priority > 0
+		}
+		if forced == nil {
+			forced = make(map[string]bool)
+		}
+		traceID := string(span.TraceId)
+		forced[traceID] = forced[traceID] || keep
 	}
+	return forced
+}
 
-	// TraceId and SpanId have lengths that are multiple of 4 so the code below is never expected to
-	// be hit when sampling traces. However, it is preserved here to keep it as a correct murmur3 implementation.
-	// This is enforced via tests.
-	var remainingBytes uint32
-	switch len(key) - iByte {
-	case 3:
-		remainingBytes += uint32(key[iByte+2]) << 16
-		fallthrough
-	case 2:
-		remainingBytes += uint32(key[iByte+1]) << 8
-		fallthrough
-	case 1:
-		remainingBytes += uint32(key[iByte])
-		remainingBytes *= c1
-		remainingBytes = (remainingBytes << r1) | (remainingBytes >> (32 - r1))
-		remainingBytes = remainingBytes * c2
-		hash ^= remainingBytes
+// isDebug returns true if Zipkin flagged the span as debug.
+func isDebug(span *tracepb.Span) bool {
+	if span.Attributes == nil {
+		return false
 	}
+	attr := span.Attributes.AttributeMap[tracetranslator.TagZipkinDebug]
+	return attr.GetBoolValue()
+}
 
-	hash ^= uint32(len(key))
-	hash ^= hash >> 16
-	hash *= c3
-	hash ^= hash >> 13
-	hash *= c4
-	hash ^= hash >> 16
+// priority returns the sampling priority of the span, if any. Strings are
+// parsed since some protocols, e.g. Zipkin, only carry strings.
+func (tsp *tracesamplerprocessor) priority(span *tracepb.Span) (float64, bool) {
+	if tsp.samplingPriority == "" || span.Attributes == nil {
+		return 0, false
+	}
+	attr := span.Attributes.AttributeMap[tsp.samplingPriority]
+	var priority float64
+	switch value := attr.GetValue().(type) {
+	case *tracepb.AttributeValue_IntValue:
+		priority = float64(value.IntValue)
+	case *tracepb.AttributeValue_DoubleValue:
+		priority = value.DoubleValue
+	case *tracepb.AttributeValue_StringValue:
+		var err error
+		if priority, err = strconv.ParseFloat(value.StringValue.GetValue(), 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return priority, priority >= 0
+}
 
-	return
+// hashKey returns the bytes hashed to sample the span: the value of the hash
+// attribute if it is configured and set on the span, the trace ID otherwise.
+func (tsp *tracesamplerprocessor) hashKey(span *tracepb.Span) []byte {
+	if tsp.hashAttribute == "" || span.Attributes == nil {
+		return span.TraceId
+	}
+	attr, ok := span.Attributes.AttributeMap[tsp.hashAttribute]
+	if !ok || attr == nil {
+		return span.TraceId
+	}
+	switch value := attr.Value.(type) {
+	case *tracepb.AttributeValue_StringValue:
+		return []byte(value.StringValue.GetValue())
+	case *tracepb.AttributeValue_IntValue:
+		return []byte(strconv.FormatInt(value.IntValue, 10))
+	case *tracepb.AttributeValue_DoubleValue:
+		return []byte(strconv.FormatFloat(value.DoubleValue, 'g', -1, 64))
+	case *tracepb.AttributeValue_BoolValue:
+		return []byte(strconv.FormatBool(value.BoolValue))
+	default:
+		return span.TraceId
+	}
 }
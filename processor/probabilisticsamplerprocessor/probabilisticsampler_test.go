@@ -23,10 +23,14 @@ import (
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplinghash"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplingprobability"
 	"github.com/open-telemetry/opentelemetry-service/processor"
 	processormetrics "github.com/open-telemetry/opentelemetry-service/processor"
 	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
@@ -71,7 +75,8 @@ func TestNewTraceProcessor(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			if !tt.wantErr {
 				// The truncation below with uint32 cannot be defined at initialization (compiler error), performing it at runtime.
-				tt.want.(*tracesamplerprocessor).scaledSamplingRate = uint32(tt.cfg.SamplingPercentage * percentageScaleFactor)
+				tt.want.(*tracesamplerprocessor).scaledSamplingRate = samplinghash.ScaledSamplingRate(tt.cfg.SamplingPercentage)
+				tt.want.(*tracesamplerprocessor).probability = float64(tt.cfg.SamplingPercentage) / 100
 			}
 			got, err := NewTraceProcessor(tt.nextConsumer, tt.cfg)
 			if (err != nil) != tt.wantErr {
@@ -172,23 +177,130 @@ func Test_tracesamplerprocessor_SamplingPercentageRange(t *testing.T) {
 	}
 }
 
-// Test_hash ensures that the hash function supports different key lengths even if in
-// practice it is only expected to receive keys with length 16 (trace id length in OC proto).
-func Test_hash(t *testing.T) {
-	// Statistically a random selection of such small number of keys should not result in
-	// collisions, but, of course it is possible that they happen, a different random source
-	// should avoid that.
+func Test_tracesamplerprocessor_SamplingPriority(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tsp, err := NewTraceProcessor(sink, Config{SamplingPercentage: 0, SamplingPriority: "sampling.priority"})
+	require.NoError(t, err)
+
+	newSpan := func(traceID uint64, key string, value *tracepb.AttributeValue) *tracepb.Span {
+		span := &tracepb.Span{TraceId: tracetranslator.UInt64ToByteTraceID(1, traceID)}
+		if value != nil {
+			span.Attributes = &tracepb.Span_Attributes{
+				AttributeMap: map[string]*tracepb.AttributeValue{key: value},
+			}
+		}
+		return span
+	}
+	intValue := func(v int64) *tracepb.AttributeValue {
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: v}}
+	}
+	stringValue := func(v string) *tracepb.AttributeValue {
+		return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: v},
+		}}
+	}
+	debugValue := &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: true}}
+
+	td := consumerdata.TraceData{Spans: []*tracepb.Span{
+		newSpan(1, "sampling.priority", intValue(1)),
+		// The priority set on a span applies to the whole trace.
+		newSpan(1, "", nil),
+		newSpan(2, "sampling.priority", stringValue("2")),
+		newSpan(3, "sampling.priority", intValue(0)),
+		newSpan(3, "", nil),
+		newSpan(4, "sampling.priority", stringValue("high")),
+		newSpan(5, "", nil),
+		newSpan(6, tracetranslator.TagZipkinDebug, debugValue),
+		// A positive priority wins over a priority of 0.
+		newSpan(7, "sampling.priority", intValue(0)),
+		newSpan(7, "sampling.priority", intValue(1)),
+	}}
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), td))
+
+	// Only the traces with a positive priority or flagged as debug are
+	// sampled with a percentage of 0, and they are not annotated.
+	traces := sink.AllTraces()
+	require.Len(t, traces, 1)
+	assert.Equal(t, []*tracepb.Span{td.Spans[0], td.Spans[1], td.Spans[2], td.Spans[7], td.Spans[8], td.Spans[9]}, traces[0].Spans)
+
+	// A priority of 0 drops the trace even if everything is sampled.
+	sink = &exportertest.SinkTraceExporter{}
+	tsp, err = NewTraceProcessor(sink, Config{SamplingPercentage: 100, SamplingPriority: "sampling.priority"})
+	require.NoError(t, err)
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), td))
+	traces = sink.AllTraces()
+	require.Len(t, traces, 1)
+	assert.Len(t, traces[0].Spans, 8)
+	for _, span := range traces[0].Spans {
+		assert.NotEqual(t, tracetranslator.UInt64ToByteTraceID(1, 3), span.TraceId)
+	}
+}
+
+func Test_tracesamplerprocessor_Unchanged(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tsp, err := NewTraceProcessor(sink, Config{SamplingPercentage: 100, SamplingPriority: "sampling.priority"})
+	require.NoError(t, err)
+
+	td := genRandomTestData(1, 10, "test-svc")[0]
+	td.Spans[0].Attributes = &tracepb.Span_Attributes{AttributeMap: map[string]*tracepb.AttributeValue{
+		"sampling.priority": {Value: &tracepb.AttributeValue_IntValue{IntValue: 1}},
+	}}
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), td))
+
+	// Nothing is dropped nor annotated so the data is forwarded as is.
+	traces := sink.AllTraces()
+	require.Len(t, traces, 1)
+	assert.Equal(t, td, traces[0])
+	for _, span := range traces[0].Spans[1:] {
+		assert.Nil(t, span.Attributes)
+	}
+}
+
+func Test_tracesamplerprocessor_SamplingProbabilityAttribute(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tsp, err := NewTraceProcessor(sink, Config{SamplingPercentage: 50})
+	require.NoError(t, err)
+	for _, td := range genRandomTestData(100, 1, "test-svc") {
+		require.NoError(t, tsp.ConsumeTraceData(context.Background(), td))
+	}
+	for _, td := range sink.AllTraces() {
+		for _, span := range td.Spans {
+			attr := span.Attributes.AttributeMap[samplingprobability.AttributeKey]
+			assert.Equal(t, 0.5, attr.GetDoubleValue())
+		}
+	}
+}
+
+func Test_tracesamplerprocessor_HashAttribute(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tsp, err := NewTraceProcessor(sink, Config{SamplingPercentage: 50, HashAttribute: "user.id"})
+	require.NoError(t, err)
+
+	// All the spans of a user get the same decision whatever their trace.
 	r := rand.New(rand.NewSource(1))
-	fullKey := tracetranslator.UInt64ToByteTraceID(r.Uint64(), r.Uint64())
-	seen := make(map[uint32]bool)
-	for i := 1; i <= len(fullKey); i++ {
-		key := fullKey[:i]
-		hash := hash(key, 1)
-		if seen[hash] {
-			t.Fatal("Unexpected duplicated hash")
+	for user := 0; user < 20; user++ {
+		var spans []*tracepb.Span
+		for i := 0; i < 10; i++ {
+			spans = append(spans, &tracepb.Span{
+				TraceId: tracetranslator.UInt64ToByteTraceID(r.Uint64(), r.Uint64()),
+				Attributes: &tracepb.Span_Attributes{AttributeMap: map[string]*tracepb.AttributeValue{
+					"user.id": {Value: &tracepb.AttributeValue_IntValue{IntValue: int64(user)}},
+				}},
+			})
+		}
+		require.NoError(t, tsp.ConsumeTraceData(context.Background(), consumerdata.TraceData{Spans: spans}))
+	}
+	sampledUsers := 0
+	for _, td := range sink.AllTraces() {
+		switch len(td.Spans) {
+		case 0:
+		case 10:
+			sampledUsers++
+		default:
+			t.Fatalf("got %d sampled spans for a user, want 0 or 10", len(td.Spans))
 		}
-		seen[hash] = true
 	}
+	assert.True(t, sampledUsers > 0 && sampledUsers < 20, "got %d sampled users", sampledUsers)
 }
 
 // genRandomTestData generates a slice of consumerdata.TraceData with the numBatches elements which one with
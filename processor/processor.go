@@ -33,6 +33,40 @@ type MetricsProcessor interface {
 	// TODO: Add processor specific functions.
 }
 
+// PipelineConsumers gives access to the first consumer of each pipeline by the
+// name of the pipeline.
+type PipelineConsumers interface {
+	// TraceConsumer returns the consumer of the traces pipeline with the given
+	// name or nil if there is no such pipeline.
+	TraceConsumer(pipelineName string) consumer.TraceConsumer
+
+	// MetricsConsumer returns the consumer of the metrics pipeline with the
+	// given name or nil if there is no such pipeline.
+	MetricsConsumer(pipelineName string) consumer.MetricsConsumer
+
+	// TraceExporter returns the exporter with the given name or nil if there
+	// is no such exporter or it isn't used by any traces pipeline.
+	TraceExporter(exporterName string) consumer.TraceConsumer
+
+	// MetricsExporter returns the exporter with the given name or nil if
+	// there is no such exporter or it isn't used by any metrics pipeline.
+	MetricsExporter(exporterName string) consumer.MetricsConsumer
+}
+
+// PipelinesConnector is implemented by processors that send data to other
+// pipelines than the one they belong to, e.g. a processor producing metrics
+// from spans. ConnectPipelines is called once all the pipelines are built.
+type PipelinesConnector interface {
+	ConnectPipelines(pipelines PipelineConsumers) error
+}
+
+// Shutdowner is implemented by processors that must stop their background
+// work, e.g. a periodic flush, when the service shuts down. Shutdown is called
+// once the receivers are stopped and before the exporters are shut down.
+type Shutdowner interface {
+	Shutdown() error
+}
+
 // Processor is a data consumer.
 type Processor interface {
 	consumer.DataConsumer
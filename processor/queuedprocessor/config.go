@@ -32,4 +32,7 @@ type Config struct {
 	RetryOnFailure bool `mapstructure:"retry_on_failure"`
 	// BackoffDelay is the amount of time a worker waits after a failed send before retrying.
 	BackoffDelay time.Duration `mapstructure:"backoff_delay"`
+	// MemoryLimitMiB is the maximum memory, in MiB, allocated by the process above which
+	// batches are refused until garbage collection frees enough memory. 0 means no limit.
+	MemoryLimitMiB uint32 `mapstructure:"memory_limit_mib"`
 }
@@ -51,5 +51,6 @@ func TestLoadConfig(t *testing.T) {
 			QueueSize:      10,
 			RetryOnFailure: true,
 			BackoffDelay:   time.Second * 5,
+			MemoryLimitMiB: 512,
 		})
 }
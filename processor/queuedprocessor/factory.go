@@ -65,6 +65,7 @@ func (f *Factory) CreateTraceProcessor(
 		Options.WithQueueSize(oCfg.QueueSize),
 		Options.WithRetryOnProcessingFailures(oCfg.RetryOnFailure),
 		Options.WithBackoffDelay(oCfg.BackoffDelay),
+		Options.WithMemoryLimitMiB(oCfg.MemoryLimitMiB),
 	), nil
 }
 
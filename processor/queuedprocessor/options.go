@@ -35,6 +35,7 @@ type options struct {
 	numWorkers               int
 	queueSize                int
 	backoffDelay             time.Duration
+	memoryLimitMiB           uint32
 	extraFormatTypes         []string
 	retryOnProcessingFailure bool
 	batchingEnabled          bool
@@ -82,6 +83,13 @@ func (options) WithBackoffDelay(backoffDelay time.Duration) Option {
 	}
 }
 
+// WithMemoryLimitMiB creates an Option that initializes the memory limit above which batches are refused
+func (options) WithMemoryLimitMiB(memoryLimitMiB uint32) Option {
+	return func(b *options) {
+		b.memoryLimitMiB = memoryLimitMiB
+	}
+}
+
 // WithExtraFormatTypes creates an Option that initializes the extra list of format types
 func (options) WithExtraFormatTypes(extraFormatTypes []string) Option {
 	return func(b *options) {
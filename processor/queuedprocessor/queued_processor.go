@@ -16,7 +16,10 @@ package queuedprocessor
 
 import (
 	"context"
+	"errors"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jaegertracing/jaeger/pkg/queue"
@@ -43,10 +46,29 @@ type queuedSpanProcessor struct {
 	backoffDelay             time.Duration
 	stopCh                   chan struct{}
 	stopOnce                 sync.Once
+
+	// memoryLimit is the number of bytes allocated by the process above
+	// which batches are refused, 0 meaning no limit.
+	memoryLimit uint64
+	// memAlloc returns the number of bytes allocated by the process.
+	memAlloc func() uint64
+	// overMemoryLimit is set to 1 while the memory allocated by the process is
+	// over memoryLimit. It is updated periodically since reading the memory
+	// statistics stops the world.
+	overMemoryLimit int32
 }
 
 var _ consumer.TraceConsumer = (*queuedSpanProcessor)(nil)
 
+var (
+	errQueueIsFull         = errors.New("queue is full")
+	errMemoryLimitExceeded = errors.New("memory limit exceeded")
+)
+
+// defaultRetryAfter is the delay suggested to the senders of batches refused
+// due to a full queue or the memory limit if no backoff delay was configured.
+const defaultRetryAfter = 5 * time.Second
+
 type queueItem struct {
 	queuedTime time.Time
 	td         consumerdata.TraceData
@@ -65,7 +87,7 @@ func NewQueuedSpanProcessor(sender consumer.TraceConsumer, opts ...Option) consu
 		sp.processItemFromQueue(value)
 	})
 
-	// Start a timer to report the queue length.
+	// Start a timer to report the queue length and check the memory limit.
 	ctx, _ := tag.New(context.Background(), tag.Upsert(processor.TagExporterNameKey, sp.name))
 	ticker := time.NewTicker(1 * time.Second)
 	go func(ctx context.Context) {
@@ -77,6 +99,7 @@ func NewQueuedSpanProcessor(sender consumer.TraceConsumer, opts ...Option) consu
 			case <-ticker.C:
 				length := int64(sp.queue.Size())
 				stats.Record(ctx, statQueueLength.M(length))
+				sp.checkMemoryLimit()
 			}
 		}
 	}(ctx)
@@ -101,7 +124,40 @@ func newQueuedSpanProcessor(sender consumer.TraceConsumer, opts options) *queued
 		retryOnProcessingFailure: opts.retryOnProcessingFailure,
 		backoffDelay:             opts.backoffDelay,
 		stopCh:                   make(chan struct{}),
+		memoryLimit:              uint64(opts.memoryLimitMiB) * 1024 * 1024,
+		memAlloc:                 readMemAlloc,
+	}
+}
+
+func readMemAlloc() uint64 {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.Alloc
+}
+
+// checkMemoryLimit updates whether the memory allocated by the process is over
+// the configured limit.
+func (sp *queuedSpanProcessor) checkMemoryLimit() {
+	if sp.memoryLimit == 0 {
+		return
+	}
+	var over int32
+	if sp.memAlloc() > sp.memoryLimit {
+		over = 1
+	}
+	if atomic.SwapInt32(&sp.overMemoryLimit, over) != over && over == 1 {
+		sp.logger.Warn("Memory limit exceeded, refusing data",
+			zap.String("processor", sp.name),
+			zap.Uint64("memory_limit", sp.memoryLimit))
+	}
+}
+
+// retryAfter returns the delay suggested to the senders of the refused batches.
+func (sp *queuedSpanProcessor) retryAfter() time.Duration {
+	if sp.backoffDelay <= 0 {
+		return defaultRetryAfter
 	}
+	return sp.backoffDelay
 }
 
 // Stop halts the span processor and all its goroutines.
@@ -124,9 +180,17 @@ func (sp *queuedSpanProcessor) ConsumeTraceData(ctx context.Context, td consumer
 	numSpans := len(td.Spans)
 	stats.RecordWithTags(context.Background(), statsTags, processor.StatReceivedSpanCount.M(int64(numSpans)))
 
+	// Let the receivers tell their clients to back off and send the batch
+	// again instead of silently losing it.
+	if atomic.LoadInt32(&sp.overMemoryLimit) == 1 {
+		sp.onItemDropped(item, statsTags)
+		return consumererror.Throttled(errMemoryLimitExceeded, sp.retryAfter())
+	}
+
 	addedToQueue := sp.queue.Produce(item)
 	if !addedToQueue {
 		sp.onItemDropped(item, statsTags)
+		return consumererror.Throttled(errQueueIsFull, sp.retryAfter())
 	}
 	return nil
 }
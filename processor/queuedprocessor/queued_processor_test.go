@@ -65,6 +65,73 @@ func TestQueuedProcessor_noEnqueueOnPermanentError(t *testing.T) {
 	require.Equal(t, 1, qp.queue.Size())
 }
 
+func TestQueuedProcessor_throttledWhenQueueIsFull(t *testing.T) {
+	ctx := context.Background()
+	td := consumerdata.TraceData{
+		Spans: make([]*tracepb.Span, 7),
+	}
+
+	// Block the only worker so the queue fills up.
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	qp := NewQueuedSpanProcessor(
+		consumerFunc(func(ctx context.Context, td consumerdata.TraceData) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		}),
+		Options.WithBackoffDelay(time.Minute),
+		Options.WithNumWorkers(1),
+		Options.WithQueueSize(1),
+	).(*queuedSpanProcessor)
+	defer func() {
+		close(release)
+		qp.Stop()
+	}()
+
+	require.Nil(t, qp.ConsumeTraceData(ctx, td))
+	<-started
+	require.Nil(t, qp.ConsumeTraceData(ctx, td))
+
+	err := qp.ConsumeTraceData(ctx, td)
+	require.Error(t, err)
+	require.True(t, consumererror.IsThrottled(err))
+	require.Equal(t, time.Minute, consumererror.RetryAfter(err))
+}
+
+func TestQueuedProcessor_throttledOverMemoryLimit(t *testing.T) {
+	ctx := context.Background()
+	td := consumerdata.TraceData{
+		Spans: make([]*tracepb.Span, 7),
+	}
+
+	var alloc uint64
+	qp := newQueuedSpanProcessor(consumerFunc(func(context.Context, consumerdata.TraceData) error { return nil }), Options.apply(
+		Options.WithBackoffDelay(time.Minute),
+		Options.WithMemoryLimitMiB(1),
+	))
+	qp.memAlloc = func() uint64 { return alloc }
+
+	alloc = 2 * 1024 * 1024
+	qp.checkMemoryLimit()
+	err := qp.ConsumeTraceData(ctx, td)
+	require.True(t, consumererror.IsThrottled(err))
+	require.Equal(t, time.Minute, consumererror.RetryAfter(err))
+	require.Equal(t, 0, qp.queue.Size())
+
+	// The batches are accepted again once the memory is freed.
+	alloc = 512 * 1024
+	qp.checkMemoryLimit()
+	require.NoError(t, qp.ConsumeTraceData(ctx, td))
+	require.Equal(t, 1, qp.queue.Size())
+}
+
+type consumerFunc func(ctx context.Context, td consumerdata.TraceData) error
+
+func (f consumerFunc) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	return f(ctx, td)
+}
+
 type waitGroupTraceConsumer struct {
 	sync.WaitGroup
 	consumeTraceDataError error
@@ -0,0 +1,56 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcedetectionprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config specifies the detectors used to detect the resource and how it is
+// merged into the Resource of the data.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Detectors specifies the list of detectors to run when the processor is
+	// created. The set of values are {env, system, container, file}.
+	// env       - Reads the labels from the OTEL_RESOURCE environment variable
+	//             as a list of key=value pairs separated by commas, and the
+	//             type from the OTEL_RESOURCE_TYPE environment variable.
+	// system    - Detects the hostname and the operating system.
+	// container - Detects the container ID from /proc/self/cgroup.
+	// file      - Reads the resource from the JSON file set in `file`.
+	// The results are merged in the order specified in the configuration: if
+	// several detectors find the same label, the first one takes precedence.
+	// This is a required field.
+	Detectors []string `mapstructure:"detectors"`
+
+	// Override specifies if the detected labels replace the labels with the
+	// same keys already present in the Resource of the data. The type of the
+	// Resource is replaced as well if it is detected.
+	// The default is true.
+	Override bool `mapstructure:"override"`
+
+	// File specifies the path of the file read by the `file` detector. The
+	// file holds a JSON object with the optional "type" and "labels" keys,
+	// e.g. {"type": "host", "labels": {"region": "us-east-1"}}.
+	// This is a required field if the `file` detector is used.
+	File string `mapstructure:"file"`
+
+	// Timeout specifies the maximum time spent running the detectors.
+	// The default is 5s.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
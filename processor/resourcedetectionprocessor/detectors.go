@@ -0,0 +1,229 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcedetectionprocessor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.opencensus.io/resource"
+	"go.opencensus.io/resource/resourcekeys"
+)
+
+const (
+	// Names of the detectors in the configuration.
+	envDetector       = "env"
+	systemDetector    = "system"
+	containerDetector = "container"
+	fileDetector      = "file"
+
+	// Environment variables read by the env detector.
+	envVarLabels = "OTEL_RESOURCE"
+	envVarType   = "OTEL_RESOURCE_TYPE"
+
+	// osTypeLabel is the label holding the operating system detected by the
+	// system detector.
+	osTypeLabel = "os.type"
+	// containerIDLabel is the label holding the container ID detected by the
+	// container detector.
+	containerIDLabel = "container.id"
+)
+
+// cgroupPath is the file read by the container detector, it is a variable so
+// that tests can change it.
+var cgroupPath = "/proc/self/cgroup"
+
+// containerIDRegex matches the container ID at the end of a cgroup path, e.g.
+// "/docker/<id>" or "/system.slice/docker-<id>.scope".
+var containerIDRegex = regexp.MustCompile(`([0-9a-f]{64})(?:\.scope)?$`)
+
+// hostname is a variable so that tests can change it.
+var hostname = os.Hostname
+
+// buildDetectors returns the detectors named in the configuration.
+func buildDetectors(cfg Config) ([]resource.Detector, error) {
+	if len(cfg.Detectors) == 0 {
+		return nil, fmt.Errorf("error creating %q processor due to missing required field \"detectors\" of processor %q", typeStr, cfg.Name())
+	}
+	detectors := make([]resource.Detector, 0, len(cfg.Detectors))
+	for _, name := range cfg.Detectors {
+		switch strings.ToLower(name) {
+		case envDetector:
+			detectors = append(detectors, detectEnv)
+		case systemDetector:
+			detectors = append(detectors, detectSystem)
+		case containerDetector:
+			detectors = append(detectors, detectContainer)
+		case fileDetector:
+			if cfg.File == "" {
+				return nil, fmt.Errorf("error creating %q processor due to missing required field \"file\" for the \"file\" detector of processor %q", typeStr, cfg.Name())
+			}
+			detectors = append(detectors, fileDetectorFor(cfg.File))
+		default:
+			return nil, fmt.Errorf("error creating %q processor due to unsupported detector %q of processor %q", typeStr, name, cfg.Name())
+		}
+	}
+	return detectors, nil
+}
+
+// detectEnv reads the resource from the OTEL_RESOURCE and OTEL_RESOURCE_TYPE
+// environment variables.
+func detectEnv(context.Context) (*resource.Resource, error) {
+	res := &resource.Resource{
+		Type: strings.TrimSpace(os.Getenv(envVarType)),
+	}
+	labels, err := decodeLabels(os.Getenv(envVarLabels))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s environment variable: %v", envVarLabels, err)
+	}
+	res.Labels = labels
+	if res.Type == "" && len(res.Labels) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// decodeLabels decodes a list of labels of the form
+// `<key1>=<value1>,<key2>=<value2>,...`. Values can be quoted, in which case
+// they can contain commas.
+func decodeLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	s = strings.TrimSpace(s)
+	for len(s) > 0 {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("missing \"=\" in %q", s)
+		}
+		key := strings.TrimSpace(s[:eq])
+		if key == "" {
+			return nil, fmt.Errorf("empty key in %q", s)
+		}
+		s = strings.TrimSpace(s[eq+1:])
+
+		var value string
+		if strings.HasPrefix(s, "\"") {
+			quoted := quotedPrefix(s)
+			var err error
+			if value, err = strconv.Unquote(quoted); err != nil {
+				return nil, fmt.Errorf("invalid quoted value for key %q: %v", key, err)
+			}
+			s = strings.TrimSpace(s[len(quoted):])
+			if len(s) > 0 && s[0] != ',' {
+				return nil, fmt.Errorf("unexpected characters after the value for key %q", key)
+			}
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		labels[key] = value
+		s = strings.TrimSpace(strings.TrimPrefix(s, ","))
+	}
+	return labels, nil
+}
+
+// quotedPrefix returns the prefix of s up to and including the first unescaped
+// closing quote, or s if there is none.
+func quotedPrefix(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1]
+		}
+	}
+	return s
+}
+
+// detectSystem detects the hostname and the operating system.
+func detectSystem(context.Context) (*resource.Resource, error) {
+	name, err := hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the hostname: %v", err)
+	}
+	return &resource.Resource{
+		Type: resourcekeys.HostType,
+		Labels: map[string]string{
+			resourcekeys.HostKeyHostName: name,
+			osTypeLabel:                  runtime.GOOS,
+		},
+	}, nil
+}
+
+// detectContainer detects the ID of the container the process runs in from
+// its cgroups. No resource is returned if the process doesn't run in a
+// container.
+func detectContainer(context.Context) (*resource.Resource, error) {
+	f, err := os.Open(cgroupPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", cgroupPath, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Each line has the form "hierarchy-ID:controller-list:cgroup-path".
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if match := containerIDRegex.FindStringSubmatch(parts[2]); match != nil {
+			return &resource.Resource{
+				Type:   resourcekeys.ContainerType,
+				Labels: map[string]string{containerIDLabel: match[1]},
+			}, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", cgroupPath, err)
+	}
+	return nil, nil
+}
+
+// fileResource is the format of the file read by the file detector.
+type fileResource struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels"`
+}
+
+// fileDetectorFor returns a detector that reads the resource from the file.
+func fileDetectorFor(path string) resource.Detector {
+	return func(context.Context) (*resource.Resource, error) {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read resource file: %v", err)
+		}
+		var fr fileResource
+		if err := json.Unmarshal(data, &fr); err != nil {
+			return nil, fmt.Errorf("failed to parse resource file %s: %v", path, err)
+		}
+		return &resource.Resource{Type: fr.Type, Labels: fr.Labels}, nil
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcedetectionprocessor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/resource"
+)
+
+func TestDecodeLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:  "empty",
+			input: "  ",
+			want:  map[string]string{},
+		},
+		{
+			name:  "unquoted",
+			input: "region=us-east-1, cluster = prod-east",
+			want:  map[string]string{"region": "us-east-1", "cluster": "prod-east"},
+		},
+		{
+			name:  "quoted",
+			input: `zone="a,b",name="with \"quotes\"",k8s.pod.name=pod-1,`,
+			want:  map[string]string{"zone": "a,b", "name": `with "quotes"`, "k8s.pod.name": "pod-1"},
+		},
+		{
+			name:  "empty value",
+			input: "region=",
+			want:  map[string]string{"region": ""},
+		},
+		{
+			name:    "missing equal",
+			input:   "region",
+			wantErr: true,
+		},
+		{
+			name:    "empty key",
+			input:   "=value",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			input:   `region="us-east-1`,
+			wantErr: true,
+		},
+		{
+			name:    "characters after quote",
+			input:   `region="us"east`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeLabels(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectEnv(t *testing.T) {
+	defer os.Unsetenv(envVarLabels)
+	defer os.Unsetenv(envVarType)
+
+	os.Unsetenv(envVarLabels)
+	os.Unsetenv(envVarType)
+	res, err := detectEnv(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	os.Setenv(envVarLabels, "region=us-east-1,cluster=prod-east")
+	os.Setenv(envVarType, "host")
+	res, err = detectEnv(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &resource.Resource{
+		Type:   "host",
+		Labels: map[string]string{"region": "us-east-1", "cluster": "prod-east"},
+	}, res)
+
+	os.Setenv(envVarLabels, "region")
+	_, err = detectEnv(context.Background())
+	assert.Error(t, err)
+}
+
+func TestDetectSystem(t *testing.T) {
+	defer func(h func() (string, error)) { hostname = h }(hostname)
+
+	hostname = func() (string, error) { return "my-host", nil }
+	res, err := detectSystem(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &resource.Resource{
+		Type: "host",
+		Labels: map[string]string{
+			"host.hostname": "my-host",
+			"os.type":       runtime.GOOS,
+		},
+	}, res)
+
+	hostname = func() (string, error) { return "", errors.New("no hostname") }
+	_, err = detectSystem(context.Background())
+	assert.Error(t, err)
+}
+
+func TestDetectContainer(t *testing.T) {
+	defer func(p string) { cgroupPath = p }(cgroupPath)
+
+	cgroupPath = path.Join(".", "testdata", "cgroup_docker")
+	res, err := detectContainer(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &resource.Resource{
+		Type:   "container",
+		Labels: map[string]string{"container.id": "3c6e3a5e7a8a1a4f45f1b2a4b3bb5a43a5d5bd4f79c0e4f7fd7a9b7d5f3a9e21"},
+	}, res)
+
+	cgroupPath = path.Join(".", "testdata", "cgroup_host")
+	res, err = detectContainer(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, res)
+
+	cgroupPath = path.Join(".", "testdata", "cgroup_missing")
+	res, err = detectContainer(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestDetectFile(t *testing.T) {
+	res, err := fileDetectorFor(path.Join(".", "testdata", "resource.json"))(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &resource.Resource{
+		Type:   "host",
+		Labels: map[string]string{"region": "us-east-1", "cluster": "prod-east"},
+	}, res)
+
+	_, err = fileDetectorFor(path.Join(".", "testdata", "config.yaml"))(context.Background())
+	assert.Error(t, err)
+}
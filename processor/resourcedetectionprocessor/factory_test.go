@@ -0,0 +1,93 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcedetectionprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := &Factory{}
+	assert.Equal(t, factory.Type(), typeStr)
+}
+
+func TestFactory_CreateDefaultConfig(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg)
+	assert.Equal(t, typeStr, cfg.Type())
+	assert.Equal(t, typeStr, cfg.Name())
+}
+
+func TestFactory_CreateProcessors(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	// The default configuration is invalid since it would do no work.
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, tp)
+
+	mp, err := factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, mp)
+
+	cfg.Detectors = []string{"file"}
+	cfg.File = path.Join(".", "testdata", "resource.json")
+
+	tp, err = factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, tp)
+
+	mp, err = factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, mp)
+}
+
+func TestFactory_CreateProcessorsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "unknown detector",
+			cfg:  Config{Detectors: []string{"ec2"}},
+		},
+		{
+			name: "missing file",
+			cfg:  Config{Detectors: []string{"file"}},
+		},
+		{
+			name: "unreadable file",
+			cfg:  Config{Detectors: []string{"file"}, File: path.Join(".", "testdata", "missing.json")},
+		},
+	}
+
+	factory := &Factory{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), &tt.cfg)
+			assert.Error(t, err)
+			assert.Nil(t, tp)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcedetectionprocessor
+
+import (
+	"context"
+
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/golang/protobuf/proto"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/resourcedetection"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+// detectResource runs the detectors of the configuration and returns the
+// merged result as a Resource proto. It returns nil if nothing was detected.
+func detectResource(logger *zap.Logger, cfg Config) (*resourcepb.Resource, error) {
+	detectors, err := buildDetectors(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	res, err := resourcedetection.Detect(ctx, detectors...)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		logger.Info("No resource detected", zap.String("processor", cfg.Name()))
+		return nil, nil
+	}
+	logger.Info("Resource detected",
+		zap.String("processor", cfg.Name()),
+		zap.String("type", res.Type),
+		zap.Any("labels", res.Labels))
+	return res, nil
+}
+
+type resourceTraceProcessor struct {
+	nextConsumer consumer.TraceConsumer
+	resource     *resourcepb.Resource
+	override     bool
+}
+
+var _ processor.TraceProcessor = (*resourceTraceProcessor)(nil)
+
+// newResourceTraceProcessor returns a processor that merges the detected
+// resource into the Resource of traces. To construct the processor, the use
+// of the factory methods are required in order to detect the resource.
+func newResourceTraceProcessor(nextConsumer consumer.TraceConsumer, res *resourcepb.Resource, override bool) (*resourceTraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &resourceTraceProcessor{
+		nextConsumer: nextConsumer,
+		resource:     res,
+		override:     override,
+	}, nil
+}
+
+func (rtp *resourceTraceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	td.Resource = mergeResource(td.Resource, rtp.resource, rtp.override)
+	return rtp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+type resourceMetricsProcessor struct {
+	nextConsumer consumer.MetricsConsumer
+	resource     *resourcepb.Resource
+	override     bool
+}
+
+var _ processor.MetricsProcessor = (*resourceMetricsProcessor)(nil)
+
+// newResourceMetricsProcessor returns a processor that merges the detected
+// resource into the Resource of metrics. To construct the processor, the use
+// of the factory methods are required in order to detect the resource.
+func newResourceMetricsProcessor(nextConsumer consumer.MetricsConsumer, res *resourcepb.Resource, override bool) (*resourceMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &resourceMetricsProcessor{
+		nextConsumer: nextConsumer,
+		resource:     res,
+		override:     override,
+	}, nil
+}
+
+func (rmp *resourceMetricsProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	md.Resource = mergeResource(md.Resource, rmp.resource, rmp.override)
+	return rmp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// mergeResource merges the detected resource into the resource of the data.
+// If override is true the detected type and labels replace the existing ones,
+// otherwise only the missing ones are added. Since the resource of the data
+// and the detected one may be shared with other pipelines, a copy is returned
+// only if the resource of the data has to be modified.
+func mergeResource(to, from *resourcepb.Resource, override bool) *resourcepb.Resource {
+	if from == nil {
+		return to
+	}
+	if to == nil {
+		return from
+	}
+	if !needsMerge(to, from, override) {
+		return to
+	}
+
+	merged := proto.Clone(to).(*resourcepb.Resource)
+	if from.Type != "" && (override || merged.Type == "") {
+		merged.Type = from.Type
+	}
+	if len(from.Labels) > 0 && merged.Labels == nil {
+		merged.Labels = make(map[string]string, len(from.Labels))
+	}
+	for k, v := range from.Labels {
+		if _, exists := merged.Labels[k]; override || !exists {
+			merged.Labels[k] = v
+		}
+	}
+	return merged
+}
+
+// needsMerge returns whether merging the detected resource changes the
+// resource of the data.
+func needsMerge(to, from *resourcepb.Resource, override bool) bool {
+	if from.Type != "" && from.Type != to.Type && (override || to.Type == "") {
+		return true
+	}
+	for k, v := range from.Labels {
+		existing, exists := to.Labels[k]
+		if !exists || (override && existing != v) {
+			return true
+		}
+	}
+	return false
+}
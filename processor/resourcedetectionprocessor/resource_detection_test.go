@@ -0,0 +1,175 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourcedetectionprocessor
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+func TestNewProcessors_NilNextConsumer(t *testing.T) {
+	tp, err := newResourceTraceProcessor(nil, nil, true)
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+	assert.Nil(t, tp)
+
+	mp, err := newResourceMetricsProcessor(nil, nil, true)
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+	assert.Nil(t, mp)
+}
+
+func TestDetectResource(t *testing.T) {
+	defer os.Unsetenv(envVarLabels)
+	os.Setenv(envVarLabels, "region=eu-west-1,team=payments")
+
+	// The labels of the first detector take precedence.
+	cfg := Config{
+		Detectors: []string{"env", "file"},
+		File:      path.Join(".", "testdata", "resource.json"),
+	}
+	res, err := detectResource(zap.NewNop(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, &resourcepb.Resource{
+		Type: "host",
+		Labels: map[string]string{
+			"region":  "eu-west-1",
+			"team":    "payments",
+			"cluster": "prod-east",
+		},
+	}, res)
+
+	os.Unsetenv(envVarLabels)
+	res, err = detectResource(zap.NewNop(), Config{Detectors: []string{"env"}})
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestMergeResource(t *testing.T) {
+	detected := &resourcepb.Resource{
+		Type:   "host",
+		Labels: map[string]string{"region": "us-east-1", "cluster": "prod-east"},
+	}
+
+	tests := []struct {
+		name     string
+		to       *resourcepb.Resource
+		from     *resourcepb.Resource
+		override bool
+		want     *resourcepb.Resource
+		// unchanged is true if the resource of the data doesn't need to be
+		// modified and so must not be copied.
+		unchanged bool
+	}{
+		{
+			name:     "nothing detected",
+			to:       &resourcepb.Resource{Type: "k8s"},
+			override: true,
+			want:     &resourcepb.Resource{Type: "k8s"},
+		},
+		{
+			name:     "no resource",
+			from:     detected,
+			override: true,
+			want:     detected,
+		},
+		{
+			name:     "override",
+			to:       &resourcepb.Resource{Type: "k8s", Labels: map[string]string{"region": "eu-west-1", "pod": "p1"}},
+			from:     detected,
+			override: true,
+			want: &resourcepb.Resource{
+				Type:   "host",
+				Labels: map[string]string{"region": "us-east-1", "cluster": "prod-east", "pod": "p1"},
+			},
+		},
+		{
+			name:     "no override",
+			to:       &resourcepb.Resource{Type: "k8s", Labels: map[string]string{"region": "eu-west-1", "pod": "p1"}},
+			from:     detected,
+			override: false,
+			want: &resourcepb.Resource{
+				Type:   "k8s",
+				Labels: map[string]string{"region": "eu-west-1", "cluster": "prod-east", "pod": "p1"},
+			},
+		},
+		{
+			name:     "already merged",
+			to:       &resourcepb.Resource{Type: "host", Labels: map[string]string{"region": "us-east-1", "cluster": "prod-east", "pod": "p1"}},
+			from:     detected,
+			override: true,
+			want: &resourcepb.Resource{
+				Type:   "host",
+				Labels: map[string]string{"region": "us-east-1", "cluster": "prod-east", "pod": "p1"},
+			},
+			unchanged: true,
+		},
+		{
+			name:     "no override without type",
+			to:       &resourcepb.Resource{},
+			from:     detected,
+			override: false,
+			want:     detected,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeResource(tt.to, tt.from, tt.override)
+			assert.Equal(t, tt.want, got)
+			switch {
+			case tt.from == nil || tt.to == nil:
+				// Nothing to merge, the resource is returned as is.
+			case tt.unchanged:
+				assert.True(t, got == tt.to, "the resource must not be copied")
+			default:
+				assert.False(t, got == tt.to, "the resource must be copied")
+				assert.False(t, got == tt.from, "the detected resource must be copied")
+			}
+		})
+	}
+}
+
+func TestResourceProcessors(t *testing.T) {
+	detected := &resourcepb.Resource{Labels: map[string]string{"region": "us-east-1"}}
+	original := &resourcepb.Resource{Labels: map[string]string{"region": "eu-west-1"}}
+
+	ttn := &exportertest.SinkTraceExporter{}
+	tp, err := newResourceTraceProcessor(ttn, detected, false)
+	require.NoError(t, err)
+	require.NoError(t, tp.ConsumeTraceData(context.Background(), consumerdata.TraceData{Resource: original}))
+	traces := ttn.AllTraces()
+	require.Len(t, traces, 1)
+	assert.Equal(t, "eu-west-1", traces[0].Resource.Labels["region"])
+
+	tmn := &exportertest.SinkMetricsExporter{}
+	mp, err := newResourceMetricsProcessor(tmn, detected, true)
+	require.NoError(t, err)
+	require.NoError(t, mp.ConsumeMetricsData(context.Background(), consumerdata.MetricsData{Resource: original}))
+	metrics := tmn.AllMetrics()
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "us-east-1", metrics[0].Resource.Labels["region"])
+
+	// The original resource must not be modified since it may be shared.
+	assert.Equal(t, "eu-west-1", original.Labels["region"])
+}
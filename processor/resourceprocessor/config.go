@@ -0,0 +1,96 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceprocessor
+
+import (
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config specifies the list of actions applied to the Node and the Resource
+// of the data passing through the processor.
+// The list of actions is applied in order specified in the configuration.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// Actions specifies the list of keys to act on.
+	// This is a required field.
+	Actions []ActionKeyValue `mapstructure:"actions"`
+}
+
+// ActionKeyValue specifies the key to act upon.
+type ActionKeyValue struct {
+	// Target specifies what to act upon.
+	// The set of values are {resource_labels, node_attributes, service_name}.
+	// resource_labels - The labels of the Resource of the data. For metrics,
+	//                   the labels of the Resource of each metric are also
+	//                   modified if the metric has one.
+	// node_attributes - The attributes of the Node of the data.
+	// service_name    - The service name of the Node of the data. The key
+	//                   is ignored for this target.
+	// This is an optional field, resource_labels is the default.
+	Target Target `mapstructure:"target"`
+
+	// Key specifies the label or attribute to act upon.
+	// This is a required field for all targets but service_name.
+	Key string `mapstructure:"key"`
+
+	// Value specifies the value to populate for the key.
+	// This is a required field for the insert, update and upsert actions.
+	Value string `mapstructure:"value"`
+
+	// Action specifies the type of action to perform.
+	// The set of values are {INSERT, UPDATE, UPSERT, DELETE}.
+	// Both lower case and upper case are supported.
+	// INSERT - Inserts the key/value when the key does not exist.
+	//          For the service name, it is set when it is empty.
+	// UPDATE - Updates an existing key with a value.
+	//          For the service name, it is set when it is not empty.
+	// UPSERT - Performs insert or update action depending on the key
+	//          existing or not.
+	// DELETE - Deletes the key. For the service name, it is cleared.
+	// This is a required field.
+	Action Action `mapstructure:"action"`
+}
+
+// Target is the enum to capture the parts of the data that can be modified.
+type Target string
+
+const (
+	// ResourceLabels targets the labels of the Resource.
+	ResourceLabels Target = "resource_labels"
+
+	// NodeAttributes targets the attributes of the Node.
+	NodeAttributes Target = "node_attributes"
+
+	// ServiceName targets the service name of the Node.
+	ServiceName Target = "service_name"
+)
+
+// Action is the enum to capture the four types of actions to perform on a key.
+type Action string
+
+const (
+	// INSERT adds the key/value when the key does not exist.
+	INSERT Action = "insert"
+
+	// UPDATE updates an existing key with a value.
+	UPDATE Action = "update"
+
+	// UPSERT performs the INSERT or UPDATE action.
+	UPSERT Action = "upsert"
+
+	// DELETE deletes the key.
+	DELETE Action = "delete"
+)
@@ -0,0 +1,94 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestFactory_Type(t *testing.T) {
+	factory := &Factory{}
+	assert.Equal(t, factory.Type(), typeStr)
+}
+
+func TestFactory_CreateDefaultConfig(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg)
+	assert.Equal(t, typeStr, cfg.Type())
+	assert.Equal(t, typeStr, cfg.Name())
+}
+
+func TestFactory_CreateProcessors(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+
+	// The default configuration is invalid since it would do no work.
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, tp)
+	mp, err := factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	assert.Error(t, err)
+	assert.Nil(t, mp)
+
+	cfg.Actions = []ActionKeyValue{{Key: "cluster", Value: "prod", Action: "UPSERT"}}
+	tp, err = factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, tp)
+	mp, err = factory.CreateMetricsProcessor(zap.NewNop(), exportertest.NewNopMetricsExporter(), cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, mp)
+}
+
+func TestFactory_InvalidActions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		action ActionKeyValue
+	}{
+		{
+			name:   "missing key",
+			action: ActionKeyValue{Value: "v", Action: INSERT},
+		},
+		{
+			name:   "missing value",
+			action: ActionKeyValue{Key: "k", Action: UPSERT},
+		},
+		{
+			name:   "invalid action",
+			action: ActionKeyValue{Key: "k", Value: "v", Action: "replace"},
+		},
+		{
+			name:   "invalid target",
+			action: ActionKeyValue{Target: "span_attributes", Key: "k", Value: "v", Action: INSERT},
+		},
+	}
+
+	factory := &Factory{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Actions = []ActionKeyValue{tc.action}
+			tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), cfg)
+			assert.Error(t, err)
+			assert.Nil(t, tp)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceprocessor
+
+import (
+	"context"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+type resourceTraceProcessor struct {
+	nextConsumer consumer.TraceConsumer
+	actions      []ActionKeyValue
+}
+
+var _ processor.TraceProcessor = (*resourceTraceProcessor)(nil)
+
+// newResourceTraceProcessor returns a processor that modifies the Node and
+// the Resource of traces. To construct the processor, the use of the factory
+// methods are required in order to validate the inputs.
+func newResourceTraceProcessor(nextConsumer consumer.TraceConsumer, actions []ActionKeyValue) (*resourceTraceProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &resourceTraceProcessor{
+		nextConsumer: nextConsumer,
+		actions:      actions,
+	}, nil
+}
+
+func (rtp *resourceTraceProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	td.Node, td.Resource = applyActions(rtp.actions, td.Node, td.Resource)
+	return rtp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+type resourceMetricsProcessor struct {
+	nextConsumer consumer.MetricsConsumer
+	actions      []ActionKeyValue
+	// metricActions are the actions that apply to the Resource of each metric.
+	metricActions []ActionKeyValue
+}
+
+var _ processor.MetricsProcessor = (*resourceMetricsProcessor)(nil)
+
+// newResourceMetricsProcessor returns a processor that modifies the Node and
+// the Resource of metrics. To construct the processor, the use of the factory
+// methods are required in order to validate the inputs.
+func newResourceMetricsProcessor(nextConsumer consumer.MetricsConsumer, actions []ActionKeyValue) (*resourceMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	var metricActions []ActionKeyValue
+	for _, action := range actions {
+		if action.Target == ResourceLabels {
+			metricActions = append(metricActions, action)
+		}
+	}
+	return &resourceMetricsProcessor{
+		nextConsumer:  nextConsumer,
+		actions:       actions,
+		metricActions: metricActions,
+	}, nil
+}
+
+func (rmp *resourceMetricsProcessor) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	md.Node, md.Resource = applyActions(rmp.actions, md.Node, md.Resource)
+
+	if len(rmp.metricActions) > 0 {
+		// The metrics may be shared with other pipelines so the metrics with
+		// a Resource are copied instead of being modified in place.
+		metrics := make([]*metricspb.Metric, 0, len(md.Metrics))
+		for _, metric := range md.Metrics {
+			if metric != nil && metric.Resource != nil {
+				metricCopy := *metric
+				_, metricCopy.Resource = applyActions(rmp.metricActions, nil, metric.Resource)
+				metric = &metricCopy
+			}
+			metrics = append(metrics, metric)
+		}
+		md.Metrics = metrics
+	}
+
+	return rmp.nextConsumer.ConsumeMetricsData(ctx, md)
+}
+
+// applyActions applies the actions to the node and the resource. Since they
+// may be shared with other pipelines, copies are returned if they need to be
+// modified.
+func applyActions(actions []ActionKeyValue, node *commonpb.Node, resource *resourcepb.Resource) (*commonpb.Node, *resourcepb.Resource) {
+	nodeCopied, resourceCopied := false, false
+	for _, action := range actions {
+		creates := action.Action == INSERT || action.Action == UPSERT
+
+		switch action.Target {
+		case ResourceLabels:
+			if resource == nil && !creates {
+				continue
+			}
+			if !resourceCopied {
+				resource = cloneResource(resource)
+				resourceCopied = true
+			}
+			if resource.Labels == nil {
+				resource.Labels = make(map[string]string)
+			}
+			applyAction(action, resource.Labels)
+
+		case NodeAttributes:
+			if node == nil && !creates {
+				continue
+			}
+			if !nodeCopied {
+				node = cloneNode(node)
+				nodeCopied = true
+			}
+			if node.Attributes == nil {
+				node.Attributes = make(map[string]string)
+			}
+			applyAction(action, node.Attributes)
+
+		case ServiceName:
+			if node.GetServiceInfo() == nil && !creates {
+				continue
+			}
+			if !nodeCopied {
+				node = cloneNode(node)
+				nodeCopied = true
+			}
+			if node.ServiceInfo == nil {
+				node.ServiceInfo = &commonpb.ServiceInfo{}
+			}
+			applyServiceNameAction(action, node.ServiceInfo)
+		}
+	}
+	return node, resource
+}
+
+func cloneNode(node *commonpb.Node) *commonpb.Node {
+	if node == nil {
+		return &commonpb.Node{}
+	}
+	return proto.Clone(node).(*commonpb.Node)
+}
+
+func cloneResource(resource *resourcepb.Resource) *resourcepb.Resource {
+	if resource == nil {
+		return &resourcepb.Resource{}
+	}
+	return proto.Clone(resource).(*resourcepb.Resource)
+}
+
+func applyAction(action ActionKeyValue, m map[string]string) {
+	_, exists := m[action.Key]
+	switch action.Action {
+	case INSERT:
+		if !exists {
+			m[action.Key] = action.Value
+		}
+	case UPDATE:
+		if exists {
+			m[action.Key] = action.Value
+		}
+	case UPSERT:
+		m[action.Key] = action.Value
+	case DELETE:
+		delete(m, action.Key)
+	}
+}
+
+func applyServiceNameAction(action ActionKeyValue, serviceInfo *commonpb.ServiceInfo) {
+	exists := serviceInfo.Name != ""
+	switch action.Action {
+	case INSERT:
+		if !exists {
+			serviceInfo.Name = action.Value
+		}
+	case UPDATE:
+		if exists {
+			serviceInfo.Name = action.Value
+		}
+	case UPSERT:
+		serviceInfo.Name = action.Value
+	case DELETE:
+		serviceInfo.Name = ""
+	}
+}
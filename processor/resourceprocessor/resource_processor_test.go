@@ -0,0 +1,129 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resourceprocessor
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+var testActions = []ActionKeyValue{
+	{Target: ResourceLabels, Key: "cluster", Value: "prod", Action: UPSERT},
+	{Target: ResourceLabels, Key: "region", Value: "us-east-1", Action: INSERT},
+	{Target: ResourceLabels, Key: "zone", Value: "a", Action: UPDATE},
+	{Target: ResourceLabels, Key: "secret", Action: DELETE},
+	{Target: NodeAttributes, Key: "k8s.namespace", Value: "checkout", Action: INSERT},
+	{Target: ServiceName, Value: "renamed", Action: UPDATE},
+}
+
+func TestResourceProcessor_Traces(t *testing.T) {
+	testCases := []struct {
+		name         string
+		node         *commonpb.Node
+		resource     *resourcepb.Resource
+		wantNode     *commonpb.Node
+		wantResource *resourcepb.Resource
+	}{
+		{
+			name: "nil node and resource",
+			wantNode: &commonpb.Node{
+				Attributes: map[string]string{"k8s.namespace": "checkout"},
+			},
+			wantResource: &resourcepb.Resource{
+				Labels: map[string]string{"cluster": "prod", "region": "us-east-1"},
+			},
+		},
+		{
+			name: "existing node and resource",
+			node: &commonpb.Node{
+				ServiceInfo: &commonpb.ServiceInfo{Name: "svc"},
+				Attributes:  map[string]string{"k8s.namespace": "default"},
+			},
+			resource: &resourcepb.Resource{
+				Type:   "k8s",
+				Labels: map[string]string{"cluster": "dev", "region": "eu", "zone": "b", "secret": "s"},
+			},
+			wantNode: &commonpb.Node{
+				ServiceInfo: &commonpb.ServiceInfo{Name: "renamed"},
+				Attributes:  map[string]string{"k8s.namespace": "default"},
+			},
+			wantResource: &resourcepb.Resource{
+				Type:   "k8s",
+				Labels: map[string]string{"cluster": "prod", "region": "eu", "zone": "a"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &exportertest.SinkTraceExporter{}
+			tp, err := newResourceTraceProcessor(sink, testActions)
+			require.NoError(t, err)
+
+			td := consumerdata.TraceData{Node: tc.node, Resource: tc.resource}
+			require.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+
+			got := sink.AllTraces()
+			require.Len(t, got, 1)
+			assert.Equal(t, tc.wantNode, got[0].Node)
+			assert.Equal(t, tc.wantResource, got[0].Resource)
+			// The input must not be modified since it may be shared.
+			assert.Equal(t, tc.node, td.Node)
+			assert.Equal(t, tc.resource, td.Resource)
+		})
+	}
+}
+
+func TestResourceProcessor_Metrics(t *testing.T) {
+	factory := &Factory{}
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Actions = []ActionKeyValue{
+		{Key: "cluster", Value: "prod", Action: UPSERT},
+		{Target: ServiceName, Value: "unknown", Action: INSERT},
+	}
+	sink := &exportertest.SinkMetricsExporter{}
+	mp, err := factory.CreateMetricsProcessor(zap.NewNop(), sink, cfg)
+	require.NoError(t, err)
+
+	withResource := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{Name: "with_resource"},
+		Resource:         &resourcepb.Resource{Labels: map[string]string{"host": "h"}},
+	}
+	withoutResource := &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{Name: "without_resource"},
+	}
+	md := consumerdata.MetricsData{Metrics: []*metricspb.Metric{withResource, withoutResource}}
+	require.NoError(t, mp.ConsumeMetricsData(context.Background(), md))
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "unknown"}}, got[0].Node)
+	assert.Equal(t, &resourcepb.Resource{Labels: map[string]string{"cluster": "prod"}}, got[0].Resource)
+	require.Len(t, got[0].Metrics, 2)
+	assert.Equal(t, map[string]string{"host": "h", "cluster": "prod"}, got[0].Metrics[0].Resource.Labels)
+	assert.Equal(t, withoutResource, got[0].Metrics[1])
+	// The input must not be modified since it may be shared.
+	assert.Equal(t, map[string]string{"host": "h"}, withResource.Resource.Labels)
+}
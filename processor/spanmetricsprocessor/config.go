@@ -0,0 +1,53 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanmetricsprocessor
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config specifies how spans are aggregated into metrics and where the
+// metrics are sent.
+type Config struct {
+	configmodels.ProcessorSettings `mapstructure:",squash"`
+
+	// MetricsPipeline specifies the name of the metrics pipeline the metrics
+	// are sent to, e.g. "metrics/spans".
+	// This is a required field.
+	MetricsPipeline string `mapstructure:"metrics_pipeline"`
+
+	// Dimensions specifies the span attributes added as labels to the
+	// metrics, in addition to the service name, span name, span kind and
+	// status code. Spans without the attribute have an unset label value.
+	// Note: Each distinct value creates new timeseries, so attributes with
+	// a high cardinality, e.g. user IDs, must be avoided.
+	Dimensions []string `mapstructure:"dimensions"`
+
+	// LatencyBuckets specifies the bounds in milliseconds of the buckets of
+	// the latency distribution. They must be in increasing order.
+	// The default is {2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}.
+	LatencyBuckets []float64 `mapstructure:"latency_buckets"`
+
+	// FlushInterval specifies how often the metrics are sent to the metrics
+	// pipeline. The default is 15s.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	// MaxSeries specifies the maximum number of distinct sets of label
+	// values that are aggregated. Spans creating new sets of label values
+	// once the limit is reached are not counted. The default is 10000.
+	MaxSeries int `mapstructure:"max_series"`
+}
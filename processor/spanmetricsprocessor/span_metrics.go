@@ -0,0 +1,389 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanmetricsprocessor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/processor"
+)
+
+const (
+	// Names of the metrics produced by the processor.
+	callsMetricName   = "calls_total"
+	errorsMetricName  = "errors_total"
+	latencyMetricName = "latency"
+
+	// Names of the labels always present on the metrics.
+	serviceLabel    = "service"
+	operationLabel  = "operation"
+	spanKindLabel   = "span_kind"
+	statusCodeLabel = "status_code"
+
+	// keySeparator separates the label values in the key of the aggregates.
+	keySeparator = "\x00"
+)
+
+type spanMetricsProcessor struct {
+	logger          *zap.Logger
+	nextConsumer    consumer.TraceConsumer
+	metricsPipeline string
+	dimensions      []string
+	latencyBounds   []float64
+	flushInterval   time.Duration
+	maxSeries       int
+	startTime       time.Time
+
+	// metricsConsumer is the first consumer of the metrics pipeline, it is
+	// set when the processor is connected to the pipelines.
+	metricsConsumer consumer.MetricsConsumer
+
+	// descriptors are the descriptors of the calls, errors and latency metrics.
+	callsDescriptor   *metricspb.MetricDescriptor
+	errorsDescriptor  *metricspb.MetricDescriptor
+	latencyDescriptor *metricspb.MetricDescriptor
+
+	mutex      sync.Mutex
+	aggregates map[string]*aggregate
+	// overflowSpans is the number of spans not aggregated since the last
+	// flush because the maximum number of series was reached.
+	overflowSpans int64
+
+	done         chan struct{}
+	shutdownOnce sync.Once
+	flushWG      sync.WaitGroup
+}
+
+// aggregate holds the cumulative values for a set of label values.
+type aggregate struct {
+	labelValues []*metricspb.LabelValue
+	calls       int64
+	errors      int64
+
+	// The latency distribution, the mean and sum of squared deviation are
+	// updated using Welford's algorithm.
+	latencyCount  int64
+	latencySum    float64
+	latencyMean   float64
+	latencySSD    float64
+	latencyCounts []int64
+}
+
+var _ processor.TraceProcessor = (*spanMetricsProcessor)(nil)
+var _ processor.PipelinesConnector = (*spanMetricsProcessor)(nil)
+var _ processor.Shutdowner = (*spanMetricsProcessor)(nil)
+
+// newSpanMetricsProcessor returns a processor that aggregates spans into
+// metrics. The metrics are sent once the processor is connected to the
+// metrics pipeline.
+func newSpanMetricsProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer, cfg Config) (*spanMetricsProcessor, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if cfg.MetricsPipeline == "" {
+		return nil, fmt.Errorf("error creating %q processor due to missing required field \"metrics_pipeline\" of processor %q", typeStr, cfg.Name())
+	}
+	if !sort.Float64sAreSorted(cfg.LatencyBuckets) {
+		return nil, fmt.Errorf("error creating %q processor due to \"latency_buckets\" not being in increasing order in processor %q", typeStr, cfg.Name())
+	}
+	for i, dimension := range cfg.Dimensions {
+		if dimension == "" {
+			return nil, fmt.Errorf("error creating %q processor due to empty dimension at the %d-th dimensions of processor %q", typeStr, i, cfg.Name())
+		}
+	}
+
+	latencyBounds := cfg.LatencyBuckets
+	if len(latencyBounds) == 0 {
+		latencyBounds = defaultLatencyBuckets
+	}
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+	maxSeries := cfg.MaxSeries
+	if maxSeries <= 0 {
+		maxSeries = defaultMaxSeries
+	}
+
+	labelKeys := []*metricspb.LabelKey{
+		{Key: serviceLabel},
+		{Key: operationLabel},
+		{Key: spanKindLabel},
+		{Key: statusCodeLabel},
+	}
+	for _, dimension := range cfg.Dimensions {
+		labelKeys = append(labelKeys, &metricspb.LabelKey{Key: dimension})
+	}
+
+	return &spanMetricsProcessor{
+		logger:          logger,
+		nextConsumer:    nextConsumer,
+		metricsPipeline: cfg.MetricsPipeline,
+		dimensions:      cfg.Dimensions,
+		latencyBounds:   latencyBounds,
+		flushInterval:   flushInterval,
+		maxSeries:       maxSeries,
+		startTime:       time.Now(),
+		callsDescriptor: &metricspb.MetricDescriptor{
+			Name:        callsMetricName,
+			Description: "Number of spans",
+			Unit:        "1",
+			Type:        metricspb.MetricDescriptor_CUMULATIVE_INT64,
+			LabelKeys:   labelKeys,
+		},
+		errorsDescriptor: &metricspb.MetricDescriptor{
+			Name:        errorsMetricName,
+			Description: "Number of spans with an error status",
+			Unit:        "1",
+			Type:        metricspb.MetricDescriptor_CUMULATIVE_INT64,
+			LabelKeys:   labelKeys,
+		},
+		latencyDescriptor: &metricspb.MetricDescriptor{
+			Name:        latencyMetricName,
+			Description: "Duration of the spans",
+			Unit:        "ms",
+			Type:        metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION,
+			LabelKeys:   labelKeys,
+		},
+		aggregates: make(map[string]*aggregate),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+// ConnectPipelines implements processor.PipelinesConnector. It starts sending
+// the metrics to the metrics pipeline.
+func (smp *spanMetricsProcessor) ConnectPipelines(pipelines processor.PipelineConsumers) error {
+	mc := pipelines.MetricsConsumer(smp.metricsPipeline)
+	if mc == nil {
+		return fmt.Errorf("metrics pipeline %q not found", smp.metricsPipeline)
+	}
+	smp.metricsConsumer = mc
+
+	smp.flushWG.Add(1)
+	go func() {
+		defer smp.flushWG.Done()
+		ticker := time.NewTicker(smp.flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				smp.flush(context.Background())
+			case <-smp.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops sending the metrics to the metrics pipeline, waiting for an
+// ongoing flush to complete.
+func (smp *spanMetricsProcessor) Shutdown() error {
+	smp.shutdownOnce.Do(func() {
+		close(smp.done)
+	})
+	smp.flushWG.Wait()
+	return nil
+}
+
+func (smp *spanMetricsProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	serviceName := td.Node.GetServiceInfo().GetName()
+
+	smp.mutex.Lock()
+	for _, span := range td.Spans {
+		if span != nil {
+			smp.aggregateSpan(serviceName, span)
+		}
+	}
+	smp.mutex.Unlock()
+
+	return smp.nextConsumer.ConsumeTraceData(ctx, td)
+}
+
+// aggregateSpan adds the span to the aggregate of its label values. It must be
+// called with the mutex held.
+func (smp *spanMetricsProcessor) aggregateSpan(serviceName string, span *tracepb.Span) {
+	statusCode := span.GetStatus().GetCode()
+	labelValues := make([]string, 0, 4+len(smp.dimensions))
+	labelValues = append(labelValues,
+		serviceName,
+		span.Name.GetValue(),
+		span.Kind.String(),
+		strconv.Itoa(int(statusCode)),
+	)
+	hasValues := make([]bool, len(smp.dimensions))
+	for i, dimension := range smp.dimensions {
+		value := ""
+		if attr, ok := span.GetAttributes().GetAttributeMap()[dimension]; ok && attr != nil {
+			value, _ = filterspan.AttributeValueToString(attr)
+			hasValues[i] = true
+		}
+		labelValues = append(labelValues, value)
+	}
+
+	key := strings.Join(labelValues, keySeparator)
+	agg, ok := smp.aggregates[key]
+	if !ok {
+		if len(smp.aggregates) >= smp.maxSeries {
+			smp.overflowSpans++
+			return
+		}
+		agg = &aggregate{latencyCounts: make([]int64, len(smp.latencyBounds)+1)}
+		for i, value := range labelValues {
+			hasValue := i < 4 || hasValues[i-4]
+			agg.labelValues = append(agg.labelValues, &metricspb.LabelValue{Value: value, HasValue: hasValue})
+		}
+		smp.aggregates[key] = agg
+	}
+
+	agg.calls++
+	if statusCode != 0 {
+		agg.errors++
+	}
+	if span.StartTime != nil && span.EndTime != nil {
+		agg.addLatency(spanLatency(span), smp.latencyBounds)
+	}
+}
+
+// spanLatency returns the duration of the span in milliseconds.
+func spanLatency(span *tracepb.Span) float64 {
+	nanos := (span.EndTime.Seconds-span.StartTime.Seconds)*1e9 + int64(span.EndTime.Nanos-span.StartTime.Nanos)
+	return float64(nanos) / 1e6
+}
+
+func (agg *aggregate) addLatency(latency float64, bounds []float64) {
+	agg.latencyCount++
+	agg.latencySum += latency
+	delta := latency - agg.latencyMean
+	agg.latencyMean += delta / float64(agg.latencyCount)
+	agg.latencySSD += delta * (latency - agg.latencyMean)
+
+	// The bucket i holds the values in [bounds[i-1], bounds[i]).
+	agg.latencyCounts[sort.Search(len(bounds), func(i int) bool { return latency < bounds[i] })]++
+}
+
+// flush sends the metrics with the current values to the metrics pipeline.
+func (smp *spanMetricsProcessor) flush(ctx context.Context) {
+	md, overflowSpans := smp.buildMetrics(time.Now())
+	if overflowSpans > 0 {
+		smp.logger.Warn("Spans not aggregated because the maximum number of series was reached",
+			zap.Int("max_series", smp.maxSeries),
+			zap.Int64("spans", overflowSpans))
+	}
+	if md == nil {
+		return
+	}
+	if err := smp.metricsConsumer.ConsumeMetricsData(ctx, *md); err != nil {
+		smp.logger.Warn("Failed to send span metrics",
+			zap.String("pipeline", smp.metricsPipeline),
+			zap.Error(err))
+	}
+}
+
+// buildMetrics returns the metrics with the current values, or nil if no span
+// was received yet, and the number of spans not aggregated since the last call
+// because the maximum number of series was reached.
+func (smp *spanMetricsProcessor) buildMetrics(now time.Time) (*consumerdata.MetricsData, int64) {
+	smp.mutex.Lock()
+	defer smp.mutex.Unlock()
+
+	overflowSpans := smp.overflowSpans
+	smp.overflowSpans = 0
+	if len(smp.aggregates) == 0 {
+		return nil, overflowSpans
+	}
+
+	startTimestamp := internal.TimeToTimestamp(smp.startTime)
+	timestamp := internal.TimeToTimestamp(now)
+
+	// Sort the keys so that the timeseries are always in the same order.
+	keys := make([]string, 0, len(smp.aggregates))
+	for key := range smp.aggregates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	calls := &metricspb.Metric{MetricDescriptor: smp.callsDescriptor}
+	errors := &metricspb.Metric{MetricDescriptor: smp.errorsDescriptor}
+	latency := &metricspb.Metric{MetricDescriptor: smp.latencyDescriptor}
+	for _, key := range keys {
+		agg := smp.aggregates[key]
+		calls.Timeseries = append(calls.Timeseries, &metricspb.TimeSeries{
+			StartTimestamp: startTimestamp,
+			LabelValues:    agg.labelValues,
+			Points: []*metricspb.Point{{
+				Timestamp: timestamp,
+				Value:     &metricspb.Point_Int64Value{Int64Value: agg.calls},
+			}},
+		})
+		errors.Timeseries = append(errors.Timeseries, &metricspb.TimeSeries{
+			StartTimestamp: startTimestamp,
+			LabelValues:    agg.labelValues,
+			Points: []*metricspb.Point{{
+				Timestamp: timestamp,
+				Value:     &metricspb.Point_Int64Value{Int64Value: agg.errors},
+			}},
+		})
+		if agg.latencyCount > 0 {
+			latency.Timeseries = append(latency.Timeseries, &metricspb.TimeSeries{
+				StartTimestamp: startTimestamp,
+				LabelValues:    agg.labelValues,
+				Points: []*metricspb.Point{{
+					Timestamp: timestamp,
+					Value:     &metricspb.Point_DistributionValue{DistributionValue: agg.distribution(smp.latencyBounds)},
+				}},
+			})
+		}
+	}
+
+	metrics := []*metricspb.Metric{calls, errors}
+	if len(latency.Timeseries) > 0 {
+		metrics = append(metrics, latency)
+	}
+	return &consumerdata.MetricsData{Metrics: metrics}, overflowSpans
+}
+
+func (agg *aggregate) distribution(bounds []float64) *metricspb.DistributionValue {
+	buckets := make([]*metricspb.DistributionValue_Bucket, len(agg.latencyCounts))
+	for i, count := range agg.latencyCounts {
+		buckets[i] = &metricspb.DistributionValue_Bucket{Count: count}
+	}
+	return &metricspb.DistributionValue{
+		Count:                 agg.latencyCount,
+		Sum:                   agg.latencySum,
+		SumOfSquaredDeviation: agg.latencySSD,
+		BucketOptions: &metricspb.DistributionValue_BucketOptions{
+			Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+				Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{Bounds: bounds},
+			},
+		},
+		Buckets: buckets,
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanmetricsprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+)
+
+type testPipelines struct {
+	metrics map[string]consumer.MetricsConsumer
+}
+
+func (tp *testPipelines) TraceConsumer(string) consumer.TraceConsumer {
+	return nil
+}
+
+func (tp *testPipelines) MetricsConsumer(name string) consumer.MetricsConsumer {
+	return tp.metrics[name]
+}
+
+func (tp *testPipelines) TraceExporter(string) consumer.TraceConsumer {
+	return nil
+}
+
+func (tp *testPipelines) MetricsExporter(string) consumer.MetricsConsumer {
+	return nil
+}
+
+func TestNewSpanMetricsProcessor_InvalidConfig(t *testing.T) {
+	_, err := newSpanMetricsProcessor(zap.NewNop(), nil, Config{MetricsPipeline: "metrics"})
+	assert.Equal(t, oterr.ErrNilNextConsumer, err)
+
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "missing metrics pipeline",
+			cfg:  Config{},
+		},
+		{
+			name: "unsorted latency buckets",
+			cfg:  Config{MetricsPipeline: "metrics", LatencyBuckets: []float64{10, 5}},
+		},
+		{
+			name: "empty dimension",
+			cfg:  Config{MetricsPipeline: "metrics", Dimensions: []string{""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			smp, err := newSpanMetricsProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), tt.cfg)
+			assert.Error(t, err)
+			assert.Nil(t, smp)
+		})
+	}
+}
+
+func TestConnectPipelines(t *testing.T) {
+	smp, err := newSpanMetricsProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), Config{
+		MetricsPipeline: "metrics/spans",
+		FlushInterval:   time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Error(t, smp.ConnectPipelines(&testPipelines{}))
+
+	sink := &exportertest.SinkMetricsExporter{}
+	require.NoError(t, smp.ConnectPipelines(&testPipelines{
+		metrics: map[string]consumer.MetricsConsumer{"metrics/spans": sink},
+	}))
+
+	td := consumerdata.TraceData{
+		Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Spans: []*tracepb.Span{newSpan("GET", 0, 5)},
+	}
+	require.NoError(t, smp.ConsumeTraceData(context.Background(), td))
+
+	// The metrics are flushed periodically.
+	assert.Eventually(t, func() bool { return len(sink.AllMetrics()) > 0 }, time.Second, time.Millisecond)
+}
+
+func TestConsumeTraceData(t *testing.T) {
+	next := &exportertest.SinkTraceExporter{}
+	smp, err := newSpanMetricsProcessor(zap.NewNop(), next, Config{
+		MetricsPipeline: "metrics",
+		Dimensions:      []string{"http.method"},
+		LatencyBuckets:  []float64{10, 100},
+	})
+	require.NoError(t, err)
+
+	get := newSpan("GET /users", 0, 5)
+	get.Attributes = &tracepb.Span_Attributes{AttributeMap: map[string]*tracepb.AttributeValue{
+		"http.method": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "GET"}}},
+	}}
+	slowGet := newSpan("GET /users", 0, 150)
+	slowGet.Attributes = get.Attributes
+	failed := newSpan("GET /users", 0, 20)
+	failed.Attributes = get.Attributes
+	failed.Status = &tracepb.Status{Code: 13}
+	noAttribute := newSpan("GET /users", 0, 50)
+	noTimes := newSpan("GET /users", 0, 0)
+	noTimes.StartTime, noTimes.EndTime = nil, nil
+
+	td := consumerdata.TraceData{
+		Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Spans: []*tracepb.Span{get, slowGet, failed, noAttribute, noTimes, nil},
+	}
+	require.NoError(t, smp.ConsumeTraceData(context.Background(), td))
+
+	// The spans are forwarded unchanged.
+	require.Len(t, next.AllTraces(), 1)
+	assert.Equal(t, td, next.AllTraces()[0])
+
+	md, overflowSpans := smp.buildMetrics(time.Unix(100, 0))
+	require.NotNil(t, md)
+	assert.Equal(t, int64(0), overflowSpans)
+	require.Len(t, md.Metrics, 3)
+
+	calls, errors, latency := md.Metrics[0], md.Metrics[1], md.Metrics[2]
+	assert.Equal(t, callsMetricName, calls.MetricDescriptor.Name)
+	assert.Equal(t, errorsMetricName, errors.MetricDescriptor.Name)
+	assert.Equal(t, latencyMetricName, latency.MetricDescriptor.Name)
+
+	var labelKeys []string
+	for _, key := range calls.MetricDescriptor.LabelKeys {
+		labelKeys = append(labelKeys, key.Key)
+	}
+	assert.Equal(t, []string{"service", "operation", "span_kind", "status_code", "http.method"}, labelKeys)
+
+	// The timeseries are sorted by label values: the spans without the
+	// dimension come first, then the ones with status 0 and finally the one
+	// with status 13.
+	require.Len(t, calls.Timeseries, 3)
+	assert.Equal(t, []*metricspb.LabelValue{
+		{Value: "svc", HasValue: true},
+		{Value: "GET /users", HasValue: true},
+		{Value: "SERVER", HasValue: true},
+		{Value: "0", HasValue: true},
+		{Value: "", HasValue: false},
+	}, calls.Timeseries[0].LabelValues)
+	assert.Equal(t, "GET", calls.Timeseries[1].LabelValues[4].Value)
+	assert.Equal(t, "13", calls.Timeseries[2].LabelValues[3].Value)
+
+	assert.Equal(t, []int64{2, 2, 1}, int64Values(calls))
+	assert.Equal(t, []int64{0, 0, 1}, int64Values(errors))
+
+	// The span without times has no latency.
+	require.Len(t, latency.Timeseries, 3)
+	dist := latency.Timeseries[1].Points[0].GetDistributionValue()
+	assert.Equal(t, int64(2), dist.Count)
+	assert.Equal(t, 155.0, dist.Sum)
+	assert.Equal(t, 10512.5, dist.SumOfSquaredDeviation)
+	assert.Equal(t, []*metricspb.DistributionValue_Bucket{{Count: 1}, {Count: 0}, {Count: 1}}, dist.Buckets)
+	assert.Equal(t, []float64{10, 100}, dist.BucketOptions.GetExplicit().Bounds)
+	assert.Equal(t, int64(1), latency.Timeseries[0].Points[0].GetDistributionValue().Count)
+
+	for _, ts := range calls.Timeseries {
+		assert.Equal(t, &timestamp.Timestamp{Seconds: 100}, ts.Points[0].Timestamp)
+		assert.NotNil(t, ts.StartTimestamp)
+	}
+
+	// The metrics are cumulative.
+	require.NoError(t, smp.ConsumeTraceData(context.Background(), td))
+	md, _ = smp.buildMetrics(time.Unix(200, 0))
+	assert.Equal(t, []int64{4, 4, 2}, int64Values(md.Metrics[0]))
+}
+
+func TestBuildMetrics_NoSpans(t *testing.T) {
+	smp, err := newSpanMetricsProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), Config{MetricsPipeline: "metrics"})
+	require.NoError(t, err)
+	md, overflowSpans := smp.buildMetrics(time.Now())
+	assert.Nil(t, md)
+	assert.Equal(t, int64(0), overflowSpans)
+}
+
+func TestConsumeTraceData_MaxSeries(t *testing.T) {
+	smp, err := newSpanMetricsProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), Config{
+		MetricsPipeline: "metrics",
+		MaxSeries:       2,
+	})
+	require.NoError(t, err)
+
+	td := consumerdata.TraceData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Spans: []*tracepb.Span{
+			newSpan("GET /users", 0, 5),
+			newSpan("GET /orders", 0, 5),
+			newSpan("GET /items", 0, 5),
+			newSpan("GET /users", 0, 5),
+		},
+	}
+	require.NoError(t, smp.ConsumeTraceData(context.Background(), td))
+
+	// The span creating a third series is not counted, the existing series
+	// are still updated.
+	md, overflowSpans := smp.buildMetrics(time.Now())
+	require.NotNil(t, md)
+	assert.Equal(t, int64(1), overflowSpans)
+	assert.Equal(t, []int64{1, 2}, int64Values(md.Metrics[0]))
+
+	_, overflowSpans = smp.buildMetrics(time.Now())
+	assert.Equal(t, int64(0), overflowSpans)
+}
+
+func TestShutdown(t *testing.T) {
+	smp, err := newSpanMetricsProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), Config{
+		MetricsPipeline: "metrics/spans",
+		FlushInterval:   time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	sink := &exportertest.SinkMetricsExporter{}
+	require.NoError(t, smp.ConnectPipelines(&testPipelines{
+		metrics: map[string]consumer.MetricsConsumer{"metrics/spans": sink},
+	}))
+	require.NoError(t, smp.Shutdown())
+	// Shutdown can be called more than once.
+	require.NoError(t, smp.Shutdown())
+
+	td := consumerdata.TraceData{
+		Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Spans: []*tracepb.Span{newSpan("GET", 0, 5)},
+	}
+	require.NoError(t, smp.ConsumeTraceData(context.Background(), td))
+
+	// No metrics are flushed once the processor is shut down.
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, sink.AllMetrics(), 0)
+}
+
+func newSpan(name string, startMillis, endMillis int64) *tracepb.Span {
+	return &tracepb.Span{
+		Name:      &tracepb.TruncatableString{Value: name},
+		Kind:      tracepb.Span_SERVER,
+		StartTime: &timestamp.Timestamp{Seconds: 1000, Nanos: int32(startMillis * 1e6)},
+		EndTime:   &timestamp.Timestamp{Seconds: 1000, Nanos: int32(endMillis * 1e6)},
+	}
+}
+
+func int64Values(metric *metricspb.Metric) []int64 {
+	var values []int64
+	for _, ts := range metric.Timeseries {
+		values = append(values, ts.Points[0].GetInt64Value())
+	}
+	return values
+}
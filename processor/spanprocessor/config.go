@@ -16,18 +16,39 @@ package spanprocessor
 
 import (
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
 )
 
 // Config is the configuration for the span processor.
 type Config struct {
 	configmodels.ProcessorSettings `mapstructure:",squash"`
 
+	// Include specifies the set of span properties that must be present in order
+	// for this processor to apply to it.
+	// Note: If `exclude` is specified, the span is compared against those
+	// properties after the `include` properties.
+	// This is an optional field. If neither `include` and `exclude` are set, all spans
+	// are processed.
+	Include *filterspan.MatchProperties `mapstructure:"include"`
+
+	// Exclude specifies when this processor will not be applied to the Spans
+	// which match the specified properties.
+	// This is an optional field. If neither `include` and `exclude` are set, all spans
+	// are processed.
+	Exclude *filterspan.MatchProperties `mapstructure:"exclude"`
+
 	// Rename specifies the components required to re-name a span.
 	// The `from_attributes` field needs to be set for this processor to be properly
 	// configured.
 	// Note: The field name is `Rename` to avoid collision with the Name() method
 	// from configmodels.ProcessorSettings.NamedEntity
 	Rename Name `mapstructure:"name"`
+
+	// Status specifies the list of rules used to set or clear the status of a
+	// span based on its attributes. Rules are evaluated in the order specified
+	// in the configuration and only the first matching rule is applied.
+	// At least one of `name.from_attributes` or `status` has to be set.
+	Status []StatusRule `mapstructure:"status"`
 }
 
 // Name specifies the attributes to use to re-name a span.
@@ -44,3 +65,85 @@ type Name struct {
 	// specified in the configuration. This field is required and cannot be empty.
 	FromAttributes []string `mapstructure:"from_attributes"`
 }
+
+// StatusRule specifies the conditions under which the status of a span is
+// set or cleared.
+type StatusRule struct {
+	// Conditions specifies the list of conditions on span attributes that must
+	// all hold for the rule to be applied.
+	// This is a required field.
+	Conditions []Condition `mapstructure:"conditions"`
+
+	// Action specifies what to do with the status of matching spans.
+	// The set of values are {SET, CLEAR}.
+	// Both lower case and upper case are supported.
+	// SET   - Sets the status code and message of the span, replacing any
+	//         existing status. This is the default action.
+	// CLEAR - Removes the status from the span.
+	Action StatusAction `mapstructure:"action"`
+
+	// Code specifies the status code to set. Both the canonical code names,
+	// e.g. "INTERNAL", and their numeric values are supported.
+	// This is a required field for the SET action.
+	Code string `mapstructure:"code"`
+
+	// Message specifies the status message to set.
+	// This is an optional field.
+	Message string `mapstructure:"message"`
+
+	// MessageFromAttribute specifies the attribute from the span to use to
+	// populate the status message. If the attribute doesn't exist the
+	// `message` value is used instead.
+	// This is an optional field.
+	MessageFromAttribute string `mapstructure:"message_from_attribute"`
+}
+
+// StatusAction is the enum to capture the types of actions to perform on the
+// status of a span.
+type StatusAction string
+
+const (
+	// SET sets the status code and message of a span.
+	SET StatusAction = "set"
+
+	// CLEAR removes the status of a span.
+	CLEAR StatusAction = "clear"
+)
+
+// Condition specifies a comparison between a span attribute and a value.
+type Condition struct {
+	// Key specifies the attribute to compare.
+	// This is a required field.
+	Key string `mapstructure:"key"`
+
+	// Op specifies the comparison operator.
+	// The set of values are {exists, eq, ne, gt, gte, lt, lte}.
+	// The ordering operators compare numerically and also accept string
+	// attributes holding numbers, e.g. a Zipkin tag "http.status_code": "503".
+	// This is a required field.
+	Op Operator `mapstructure:"op"`
+
+	// Value specifies the value to compare the attribute against.
+	// This is a required field for all operators but `exists`.
+	Value interface{} `mapstructure:"value"`
+}
+
+// Operator is the enum to capture the comparisons supported by a Condition.
+type Operator string
+
+const (
+	// EXISTS matches when the attribute is present, regardless of its value.
+	EXISTS Operator = "exists"
+	// EQ matches when the attribute is equal to the value.
+	EQ Operator = "eq"
+	// NE matches when the attribute is present and not equal to the value.
+	NE Operator = "ne"
+	// GT matches when the attribute is greater than the value.
+	GT Operator = "gt"
+	// GTE matches when the attribute is greater than or equal to the value.
+	GTE Operator = "gte"
+	// LT matches when the attribute is less than the value.
+	LT Operator = "lt"
+	// LTE matches when the attribute is less than or equal to the value.
+	LTE Operator = "lte"
+)
@@ -22,6 +22,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -59,4 +60,42 @@ func TestLoadConfig(t *testing.T) {
 			Separator:      "",
 		},
 	})
+
+	p2 := config.Processors["span/status"]
+	assert.Equal(t, p2, &Config{
+		ProcessorSettings: configmodels.ProcessorSettings{
+			TypeVal: typeStr,
+			NameVal: "span/status",
+		},
+		Include: &filterspan.MatchProperties{
+			Services: []string{"frontend", "checkout"},
+		},
+		Exclude: &filterspan.MatchProperties{
+			Attributes: []filterspan.Attribute{
+				{Key: "synthetic", Value: true},
+			},
+		},
+		Status: []StatusRule{
+			{
+				Conditions: []Condition{
+					{Key: "http.status_code", Op: GTE, Value: 500},
+				},
+				Code:                 "INTERNAL",
+				Message:              "server error",
+				MessageFromAttribute: "http.status_text",
+			},
+			{
+				Conditions: []Condition{
+					{Key: "error", Op: EQ, Value: true},
+				},
+				Code: "UNKNOWN",
+			},
+			{
+				Conditions: []Condition{
+					{Key: "http.status_code", Op: LT, Value: 400},
+				},
+				Action: CLEAR,
+			},
+		},
+	})
 }
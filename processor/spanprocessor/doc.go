@@ -13,5 +13,5 @@
 // limitations under the License.
 
 // Package spanprocessor contains logic to modify top level settings of a span, such
-// as its name.
+// as its name and status.
 package spanprocessor
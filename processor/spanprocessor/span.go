@@ -16,13 +16,14 @@ package spanprocessor
 
 import (
 	"context"
-	"strconv"
+	"fmt"
 	"strings"
 
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
 )
@@ -30,6 +31,9 @@ import (
 type spanProcessor struct {
 	nextConsumer consumer.TraceConsumer
 	config       Config
+	include      filterspan.Matcher
+	exclude      filterspan.Matcher
+	statusRules  []statusRule
 }
 
 // NewTraceProcessor returns the span processor.
@@ -43,12 +47,37 @@ func NewTraceProcessor(nextConsumer consumer.TraceConsumer, config Config) (proc
 		config:       config,
 	}
 
+	var err error
+	if config.Include != nil {
+		if sp.include, err = filterspan.NewMatcher(config.Include); err != nil {
+			return nil, fmt.Errorf("error creating \"span\" processor due to invalid \"include\" of processor %q: %v", config.Name(), err)
+		}
+	}
+	if config.Exclude != nil {
+		if sp.exclude, err = filterspan.NewMatcher(config.Exclude); err != nil {
+			return nil, fmt.Errorf("error creating \"span\" processor due to invalid \"exclude\" of processor %q: %v", config.Name(), err)
+		}
+	}
+	if sp.statusRules, err = buildStatusRules(config); err != nil {
+		return nil, err
+	}
+
 	return sp, nil
 }
 
 func (sp *spanProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	serviceName := td.Node.GetServiceInfo().GetName()
 	for _, span := range td.Spans {
-		if span == nil || span.Attributes == nil || len(span.Attributes.AttributeMap) == 0 {
+		if span == nil {
+			continue
+		}
+		if filterspan.SkipSpan(sp.include, sp.exclude, span, serviceName) {
+			continue
+		}
+		if len(sp.statusRules) > 0 {
+			applyStatusRules(sp.statusRules, span)
+		}
+		if len(sp.config.Rename.FromAttributes) == 0 || span.Attributes == nil || len(span.Attributes.AttributeMap) == 0 {
 			continue
 		}
 		// Name the span using attribute values.
@@ -91,18 +120,11 @@ func (sp *spanProcessor) nameSpan(span *tracepb.Span) {
 			continue
 		}
 
-		switch value := attribute.Value.(type) {
-		case *tracepb.AttributeValue_StringValue:
-			sb.WriteString(value.StringValue.GetValue())
-		case *tracepb.AttributeValue_BoolValue:
-			sb.WriteString(strconv.FormatBool(value.BoolValue))
-		case *tracepb.AttributeValue_DoubleValue:
-			sb.WriteString(strconv.FormatFloat(value.DoubleValue, 'f', -1, 64))
-		case *tracepb.AttributeValue_IntValue:
-			sb.WriteString(strconv.FormatInt(value.IntValue, 10))
-		default:
-			sb.WriteString("<unknown-attribute-type>")
+		value, ok := filterspan.AttributeValueToString(attribute)
+		if !ok {
+			value = "<unknown-attribute-type>"
 		}
+		sb.WriteString(value)
 	}
 	span.Name = &tracepb.TruncatableString{Value: sb.String()}
 }
@@ -0,0 +1,239 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/spf13/cast"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
+)
+
+// statusRule is the validated form of a StatusRule from the configuration.
+type statusRule struct {
+	conditions           []condition
+	clear                bool
+	code                 int32
+	message              string
+	messageFromAttribute string
+}
+
+type condition struct {
+	key string
+	op  Operator
+	// str is the value from the configuration formatted as a string, it is
+	// used for equality checks against non-numeric attributes.
+	str string
+	// num is only valid if isNum is true.
+	num   float64
+	isNum bool
+}
+
+var canonicalCodesMap = map[string]int32{
+	// https://github.com/googleapis/googleapis/blob/bee79fbe03254a35db125dc6d2f1e9b752b390fe/google/rpc/code.proto#L33-L186
+	"OK":                  0,
+	"CANCELLED":           1,
+	"UNKNOWN":             2,
+	"INVALID_ARGUMENT":    3,
+	"DEADLINE_EXCEEDED":   4,
+	"NOT_FOUND":           5,
+	"ALREADY_EXISTS":      6,
+	"PERMISSION_DENIED":   7,
+	"RESOURCE_EXHAUSTED":  8,
+	"FAILED_PRECONDITION": 9,
+	"ABORTED":             10,
+	"OUT_OF_RANGE":        11,
+	"UNIMPLEMENTED":       12,
+	"INTERNAL":            13,
+	"UNAVAILABLE":         14,
+	"DATA_LOSS":           15,
+	"UNAUTHENTICATED":     16,
+}
+
+// buildStatusRules validates the status rules from the configuration and
+// returns them in the form used by the processor.
+func buildStatusRules(config Config) ([]statusRule, error) {
+	rules := make([]statusRule, 0, len(config.Status))
+	for i, r := range config.Status {
+		if len(r.Conditions) == 0 {
+			return nil, fmt.Errorf("error creating \"span\" processor due to missing required field \"conditions\" at the %d-th status rule of processor %q", i, config.Name())
+		}
+
+		rule := statusRule{
+			message:              r.Message,
+			messageFromAttribute: r.MessageFromAttribute,
+		}
+		switch StatusAction(strings.ToLower(string(r.Action))) {
+		case SET, "":
+			code, err := parseStatusCode(r.Code)
+			if err != nil {
+				return nil, fmt.Errorf("error creating \"span\" processor due to %v at the %d-th status rule of processor %q", err, i, config.Name())
+			}
+			rule.code = code
+		case CLEAR:
+			rule.clear = true
+		default:
+			return nil, fmt.Errorf("error creating \"span\" processor due to unsupported action %q at the %d-th status rule of processor %q", r.Action, i, config.Name())
+		}
+
+		for j, c := range r.Conditions {
+			cond, err := buildCondition(c)
+			if err != nil {
+				return nil, fmt.Errorf("error creating \"span\" processor due to %v at the %d-th condition of the %d-th status rule of processor %q", err, j, i, config.Name())
+			}
+			rule.conditions = append(rule.conditions, cond)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseStatusCode(code string) (int32, error) {
+	if code == "" {
+		return 0, fmt.Errorf("missing required field \"code\"")
+	}
+	if c, ok := canonicalCodesMap[strings.ToUpper(code)]; ok {
+		return c, nil
+	}
+	c, err := strconv.ParseInt(code, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid status code %q", code)
+	}
+	return int32(c), nil
+}
+
+func buildCondition(c Condition) (condition, error) {
+	if c.Key == "" {
+		return condition{}, fmt.Errorf("missing required field \"key\"")
+	}
+	cond := condition{
+		key: c.Key,
+		op:  Operator(strings.ToLower(string(c.Op))),
+	}
+	switch cond.op {
+	case EXISTS:
+		return cond, nil
+	case EQ, NE, GT, GTE, LT, LTE:
+	default:
+		return condition{}, fmt.Errorf("unsupported operator %q", c.Op)
+	}
+
+	if c.Value == nil {
+		return condition{}, fmt.Errorf("missing required field \"value\" for operator %q", c.Op)
+	}
+	cond.str = cast.ToString(c.Value)
+	if _, isBool := c.Value.(bool); !isBool {
+		if num, err := cast.ToFloat64E(c.Value); err == nil {
+			cond.num = num
+			cond.isNum = true
+		}
+	}
+	if !cond.isNum && cond.op != EQ && cond.op != NE {
+		return condition{}, fmt.Errorf("operator %q requires a numeric value", c.Op)
+	}
+	return cond, nil
+}
+
+// applyStatusRules sets or clears the status of the span using the first rule
+// whose conditions all match the span attributes.
+func applyStatusRules(rules []statusRule, span *tracepb.Span) {
+	var attrs map[string]*tracepb.AttributeValue
+	if span.Attributes != nil {
+		attrs = span.Attributes.AttributeMap
+	}
+	for _, rule := range rules {
+		if !rule.matches(attrs) {
+			continue
+		}
+		if rule.clear {
+			span.Status = nil
+			return
+		}
+		message := rule.message
+		if rule.messageFromAttribute != "" {
+			if attr, found := attrs[rule.messageFromAttribute]; found && attr != nil {
+				message, _ = filterspan.AttributeValueToString(attr)
+			}
+		}
+		span.Status = &tracepb.Status{Code: rule.code, Message: message}
+		return
+	}
+}
+
+func (r *statusRule) matches(attrs map[string]*tracepb.AttributeValue) bool {
+	for _, cond := range r.conditions {
+		attr, found := attrs[cond.key]
+		if !found || attr == nil || !cond.matches(attr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *condition) matches(attr *tracepb.AttributeValue) bool {
+	if c.op == EXISTS {
+		return true
+	}
+
+	num, isNum := attributeValueToFloat(attr)
+	if !c.isNum || !isNum {
+		// Only equality can be checked when either side isn't a number.
+		str, _ := filterspan.AttributeValueToString(attr)
+		switch c.op {
+		case EQ:
+			return str == c.str
+		case NE:
+			return str != c.str
+		default:
+			return false
+		}
+	}
+
+	switch c.op {
+	case EQ:
+		return num == c.num
+	case NE:
+		return num != c.num
+	case GT:
+		return num > c.num
+	case GTE:
+		return num >= c.num
+	case LT:
+		return num < c.num
+	case LTE:
+		return num <= c.num
+	}
+	return false
+}
+
+// attributeValueToFloat returns the numeric value of the attribute. String
+// attributes are parsed since some protocols, e.g. Zipkin, only carry strings.
+func attributeValueToFloat(attr *tracepb.AttributeValue) (float64, bool) {
+	switch value := attr.Value.(type) {
+	case *tracepb.AttributeValue_IntValue:
+		return float64(value.IntValue), true
+	case *tracepb.AttributeValue_DoubleValue:
+		return value.DoubleValue, true
+	case *tracepb.AttributeValue_StringValue:
+		num, err := strconv.ParseFloat(value.StringValue.GetValue(), 64)
+		return num, err == nil
+	default:
+		return 0, false
+	}
+}
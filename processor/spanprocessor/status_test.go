@@ -0,0 +1,243 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanprocessor
+
+import (
+	"context"
+	"testing"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/filterspan"
+)
+
+func stringAttribute(value string) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: value}},
+	}
+}
+
+func intAttribute(value int64) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: value}}
+}
+
+func boolAttribute(value bool) *tracepb.AttributeValue {
+	return &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: value}}
+}
+
+func TestSpanProcessor_Status(t *testing.T) {
+	testCases := []struct {
+		name       string
+		attributes map[string]*tracepb.AttributeValue
+		status     *tracepb.Status
+		want       *tracepb.Status
+	}{
+		{
+			name:       "int status code",
+			attributes: map[string]*tracepb.AttributeValue{"http.status_code": intAttribute(503)},
+			want:       &tracepb.Status{Code: 13, Message: "server error"},
+		},
+		{
+			name: "string status code with message attribute",
+			attributes: map[string]*tracepb.AttributeValue{
+				"http.status_code": stringAttribute("500"),
+				"http.status_text": stringAttribute("Internal Server Error"),
+			},
+			want: &tracepb.Status{Code: 13, Message: "Internal Server Error"},
+		},
+		{
+			name:       "error tag",
+			attributes: map[string]*tracepb.AttributeValue{"error": boolAttribute(true)},
+			want:       &tracepb.Status{Code: 2},
+		},
+		{
+			name:       "error tag as string",
+			attributes: map[string]*tracepb.AttributeValue{"error": stringAttribute("true")},
+			want:       &tracepb.Status{Code: 2},
+		},
+		{
+			name:       "clear status",
+			attributes: map[string]*tracepb.AttributeValue{"http.status_code": intAttribute(200)},
+			status:     &tracepb.Status{Code: 2},
+			want:       nil,
+		},
+		{
+			name:       "no matching rule",
+			attributes: map[string]*tracepb.AttributeValue{"http.status_code": intAttribute(404)},
+			status:     &tracepb.Status{Code: 5},
+			want:       &tracepb.Status{Code: 5},
+		},
+		{
+			name:       "non numeric status code",
+			attributes: map[string]*tracepb.AttributeValue{"http.status_code": stringAttribute("bad")},
+			want:       nil,
+		},
+		{
+			name: "no attributes",
+			want: nil,
+		},
+	}
+
+	factory := Factory{}
+	oCfg := factory.CreateDefaultConfig().(*Config)
+	oCfg.Status = []StatusRule{
+		{
+			Conditions:           []Condition{{Key: "http.status_code", Op: GTE, Value: 500}},
+			Code:                 "INTERNAL",
+			Message:              "server error",
+			MessageFromAttribute: "http.status_text",
+		},
+		{
+			Conditions: []Condition{{Key: "error", Op: EQ, Value: true}},
+			Code:       "2",
+		},
+		{
+			Conditions: []Condition{{Key: "http.status_code", Op: LT, Value: 400}},
+			Action:     "CLEAR",
+		},
+	}
+
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), oCfg)
+	require.Nil(t, err)
+	require.NotNil(t, tp)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			span := &tracepb.Span{
+				Attributes: &tracepb.Span_Attributes{AttributeMap: tc.attributes},
+				Status:     tc.status,
+			}
+			td := consumerdata.TraceData{Spans: []*tracepb.Span{span}}
+			assert.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+			assert.Equal(t, tc.want, span.Status)
+		})
+	}
+}
+
+func TestSpanProcessor_StatusIncludeExclude(t *testing.T) {
+	factory := Factory{}
+	oCfg := factory.CreateDefaultConfig().(*Config)
+	oCfg.Include = &filterspan.MatchProperties{Services: []string{"checkout"}}
+	oCfg.Exclude = &filterspan.MatchProperties{
+		Attributes: []filterspan.Attribute{{Key: "synthetic", Value: true}},
+	}
+	oCfg.Status = []StatusRule{
+		{
+			Conditions: []Condition{{Key: "error", Op: EXISTS}},
+			Code:       "unknown",
+		},
+	}
+
+	tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), oCfg)
+	require.Nil(t, err)
+	require.NotNil(t, tp)
+
+	testCases := []struct {
+		name       string
+		service    string
+		attributes map[string]*tracepb.AttributeValue
+		want       *tracepb.Status
+	}{
+		{
+			name:       "included",
+			service:    "checkout",
+			attributes: map[string]*tracepb.AttributeValue{"error": boolAttribute(true)},
+			want:       &tracepb.Status{Code: 2},
+		},
+		{
+			name:       "other service",
+			service:    "frontend",
+			attributes: map[string]*tracepb.AttributeValue{"error": boolAttribute(true)},
+		},
+		{
+			name:    "excluded",
+			service: "checkout",
+			attributes: map[string]*tracepb.AttributeValue{
+				"error":     boolAttribute(true),
+				"synthetic": boolAttribute(true),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			span := &tracepb.Span{
+				Attributes: &tracepb.Span_Attributes{AttributeMap: tc.attributes},
+			}
+			td := consumerdata.TraceData{
+				Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: tc.service}},
+				Spans: []*tracepb.Span{span},
+			}
+			assert.NoError(t, tp.ConsumeTraceData(context.Background(), td))
+			assert.Equal(t, tc.want, span.Status)
+		})
+	}
+}
+
+func TestSpanProcessor_InvalidStatusRules(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule StatusRule
+	}{
+		{
+			name: "missing conditions",
+			rule: StatusRule{Code: "INTERNAL"},
+		},
+		{
+			name: "missing code",
+			rule: StatusRule{Conditions: []Condition{{Key: "error", Op: EXISTS}}},
+		},
+		{
+			name: "invalid code",
+			rule: StatusRule{Conditions: []Condition{{Key: "error", Op: EXISTS}}, Code: "BROKEN"},
+		},
+		{
+			name: "invalid action",
+			rule: StatusRule{Conditions: []Condition{{Key: "error", Op: EXISTS}}, Action: "toggle"},
+		},
+		{
+			name: "missing key",
+			rule: StatusRule{Conditions: []Condition{{Op: EXISTS}}, Code: "INTERNAL"},
+		},
+		{
+			name: "invalid operator",
+			rule: StatusRule{Conditions: []Condition{{Key: "error", Op: "like", Value: "x"}}, Code: "INTERNAL"},
+		},
+		{
+			name: "missing value",
+			rule: StatusRule{Conditions: []Condition{{Key: "error", Op: EQ}}, Code: "INTERNAL"},
+		},
+		{
+			name: "non numeric value",
+			rule: StatusRule{Conditions: []Condition{{Key: "error", Op: GT, Value: "abc"}}, Code: "INTERNAL"},
+		},
+	}
+
+	factory := Factory{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oCfg := factory.CreateDefaultConfig().(*Config)
+			oCfg.Status = []StatusRule{tc.rule}
+			tp, err := factory.CreateTraceProcessor(zap.NewNop(), exportertest.NewNopTraceExporter(), oCfg)
+			assert.Error(t, err)
+			assert.Nil(t, tp)
+		})
+	}
+}
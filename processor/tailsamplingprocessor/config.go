@@ -34,6 +34,41 @@ const (
 	StringAttribute PolicyType = "string_attribute"
 	// RateLimiting allows all traces until the specified limits are satisfied.
 	RateLimiting PolicyType = "rate_limiting"
+	// Latency samples traces lasting at least a threshold, from the earliest
+	// start to the latest end of their spans.
+	Latency PolicyType = "latency"
+	// ErrorStatus samples traces with a span having an error status code.
+	ErrorStatus PolicyType = "error_status"
+	// SpanCount samples traces with a number of spans in a specified range.
+	SpanCount PolicyType = "span_count"
+	// Probabilistic samples a percentage of the traces by hashing their trace ID.
+	Probabilistic PolicyType = "probabilistic"
+	// Adaptive samples traces with a probability per service and operation
+	// adjusted to sample a target number of traces per second for each.
+	Adaptive PolicyType = "adaptive"
+	// And samples traces that all of the sub-policies sample.
+	And PolicyType = "and"
+	// Or samples traces that any of the sub-policies samples.
+	Or PolicyType = "or"
+	// Not samples traces that the sub-policy doesn't sample.
+	Not PolicyType = "not"
+	// Composite samples traces that any of the sub-policies samples, within a
+	// budget of spans per second allocated across the sub-policies.
+	Composite PolicyType = "composite"
+)
+
+// PolicyMode indicates how the decision of a policy is used to make the final
+// sampling decision of a trace.
+type PolicyMode string
+
+const (
+	// Sample is the default mode: a trace is sampled if any of the policies in
+	// this mode decides to sample it.
+	Sample PolicyMode = "sample"
+	// NeverSample makes a policy take precedence over all the others: a trace
+	// that the policy decides to sample is never sampled, whatever the
+	// decisions of the other policies.
+	NeverSample PolicyMode = "never_sample"
 )
 
 // PolicyCfg holds the common configuration to all policies.
@@ -42,12 +77,43 @@ type PolicyCfg struct {
 	Name string `mapstructure:"name"`
 	// Type of the policy this will be used to match the proper configuration of the policy.
 	Type PolicyType `mapstructure:"type"`
+	// Mode specifies how the decision of the policy is used for the final
+	// decision. The set of values are {sample, never_sample}, sample is the
+	// default.
+	Mode PolicyMode `mapstructure:"mode"`
+	// Exporters are the names of the exporters receiving the traces sampled
+	// by the policy. The exporters must be used by a traces pipeline.
+	// Note: This is an optional field.
+	Exporters []string `mapstructure:"exporters"`
+	// Pipeline is the name of the traces pipeline receiving the traces
+	// sampled by the policy. If neither exporters nor a pipeline are
+	// specified, the traces are sent to the next consumer of the processor.
+	// Note: This is an optional field.
+	Pipeline string `mapstructure:"pipeline"`
 	// Configs for numeric attribute filter sampling policy evaluator.
 	NumericAttributeCfg NumericAttributeCfg `mapstructure:"numeric_attribute"`
 	// Configs for string attribute filter sampling policy evaluator.
 	StringAttributeCfg StringAttributeCfg `mapstructure:"string_attribute"`
 	// Configs for rate limiting filter sampling policy evaluator.
 	RateLimitingCfg RateLimitingCfg `mapstructure:"rate_limiting"`
+	// Configs for latency sampling policy evaluator.
+	LatencyCfg LatencyCfg `mapstructure:"latency"`
+	// Configs for error status sampling policy evaluator.
+	ErrorStatusCfg ErrorStatusCfg `mapstructure:"error_status"`
+	// Configs for span count sampling policy evaluator.
+	SpanCountCfg SpanCountCfg `mapstructure:"span_count"`
+	// Configs for probabilistic sampling policy evaluator.
+	ProbabilisticCfg ProbabilisticCfg `mapstructure:"probabilistic"`
+	// Configs for adaptive sampling policy evaluator.
+	AdaptiveCfg AdaptiveCfg `mapstructure:"adaptive"`
+	// Configs for the and sampling policy evaluator.
+	AndCfg AndCfg `mapstructure:"and"`
+	// Configs for the or sampling policy evaluator.
+	OrCfg OrCfg `mapstructure:"or"`
+	// Configs for the not sampling policy evaluator.
+	NotCfg NotCfg `mapstructure:"not"`
+	// Configs for the composite sampling policy evaluator.
+	CompositeCfg CompositeCfg `mapstructure:"composite"`
 }
 
 // NumericAttributeCfg holds the configurable settings to create a numeric attribute filter
@@ -77,6 +143,105 @@ type RateLimitingCfg struct {
 	SpansPerSecond int64 `mapstructure:"spans_per_second"`
 }
 
+// LatencyCfg holds the configurable settings to create a latency sampling
+// policy evaluator.
+type LatencyCfg struct {
+	// Threshold is the minimum duration of a trace to be sampled.
+	Threshold time.Duration `mapstructure:"threshold"`
+}
+
+// ErrorStatusCfg holds the configurable settings to create an error status
+// sampling policy evaluator.
+type ErrorStatusCfg struct {
+	// Codes is the set of status codes that are considered errors. If it is
+	// empty, any code other than 0 (OK) is an error.
+	Codes []int32 `mapstructure:"codes"`
+}
+
+// SpanCountCfg holds the configurable settings to create a span count
+// sampling policy evaluator.
+type SpanCountCfg struct {
+	// MinSpans is the minimum number of spans of a trace to be sampled.
+	MinSpans int64 `mapstructure:"min_spans"`
+	// MaxSpans is the maximum number of spans of a trace to be sampled. There
+	// is no maximum if it is 0.
+	MaxSpans int64 `mapstructure:"max_spans"`
+}
+
+// ProbabilisticCfg holds the configurable settings to create a probabilistic
+// sampling policy evaluator. The trace IDs are hashed in the same way as by the
+// probabilistic_sampler processor, so both make the same decisions for the same
+// hash seed and percentage.
+type ProbabilisticCfg struct {
+	// HashSeed allows one to configure the hashing seed, see the
+	// probabilistic_sampler processor.
+	HashSeed uint32 `mapstructure:"hash_seed"`
+	// SamplingPercentage is the percentage rate at which traces are going to
+	// be sampled.
+	SamplingPercentage float32 `mapstructure:"sampling_percentage"`
+}
+
+// AdaptiveCfg holds the configurable settings to create an adaptive sampling
+// policy evaluator.
+type AdaptiveCfg struct {
+	// TracesPerSecond is the target number of traces per second sampled for
+	// each service and operation of the root spans.
+	TracesPerSecond float64 `mapstructure:"traces_per_second"`
+	// AdjustmentInterval is the interval at which the sampling probabilities
+	// are adjusted to the throughput, the default is 10s.
+	AdjustmentInterval time.Duration `mapstructure:"adjustment_interval"`
+	// HashSeed allows one to configure the hashing seed, see the
+	// probabilistic_sampler processor.
+	HashSeed uint32 `mapstructure:"hash_seed"`
+}
+
+// AndCfg holds the sub-policies of an and sampling policy evaluator. The mode
+// of the sub-policies is ignored.
+type AndCfg struct {
+	// SubPolicies are the policies that must all sample a trace.
+	SubPolicies []PolicyCfg `mapstructure:"policies"`
+}
+
+// OrCfg holds the sub-policies of an or sampling policy evaluator. The mode of
+// the sub-policies is ignored.
+type OrCfg struct {
+	// SubPolicies are the policies of which any must sample a trace.
+	SubPolicies []PolicyCfg `mapstructure:"policies"`
+}
+
+// NotCfg holds the sub-policy of a not sampling policy evaluator. The mode of
+// the sub-policy is ignored.
+type NotCfg struct {
+	// SubPolicy is the policy whose decision is inverted.
+	SubPolicy *PolicyCfg `mapstructure:"policy"`
+}
+
+// CompositeCfg holds the configurable settings to create a composite
+// sampling policy evaluator. The sub-policies are evaluated in order and a
+// trace is sampled by the first one that samples it within its budget.
+type CompositeCfg struct {
+	// MaxTotalSpansPerSecond sets the limit on the number of spans sampled
+	// each second by all the sub-policies.
+	MaxTotalSpansPerSecond int64 `mapstructure:"max_total_spans_per_second"`
+	// SubPolicies are the policies evaluated in order. The mode of the
+	// sub-policies is ignored.
+	SubPolicies []PolicyCfg `mapstructure:"policies"`
+	// RateAllocation sets the share of max_total_spans_per_second allocated
+	// to sub-policies by name. Sub-policies without an allocation are only
+	// limited by max_total_spans_per_second.
+	RateAllocation []RateAllocationCfg `mapstructure:"rate_allocation"`
+}
+
+// RateAllocationCfg allocates a percentage of the spans per second budget of a
+// composite policy to one of its sub-policies.
+type RateAllocationCfg struct {
+	// Policy is the name of the sub-policy.
+	Policy string `mapstructure:"policy"`
+	// Percent is the percentage of max_total_spans_per_second allocated to
+	// the sub-policy.
+	Percent int64 `mapstructure:"percent"`
+}
+
 // Config holds the configuration for tail-based sampling.
 type Config struct {
 	configmodels.ProcessorSettings `mapstructure:",squash"`
@@ -89,6 +254,16 @@ type Config struct {
 	// ExpectedNewTracesPerSec sets the expected number of new traces sending to the tail sampling processor
 	// per second. This helps with allocating data structures with closer to actual usage size.
 	ExpectedNewTracesPerSec uint64 `mapstructure:"expected_new_traces_per_sec"`
+	// MaxBufferedBytes bounds the approximate serialized size of the spans of
+	// the traces waiting for a decision. When it is exceeded, the oldest
+	// traces are removed from memory, as when num_traces is exceeded. There
+	// is no bound if it is 0.
+	MaxBufferedBytes uint64 `mapstructure:"max_buffered_bytes"`
+	// DecisionCacheSize is the number of final decisions of recent traces kept
+	// in memory after the trace data is removed, so that spans arriving late
+	// follow the decision made for their trace. The cache is disabled if it
+	// is 0.
+	DecisionCacheSize int `mapstructure:"decision_cache_size"`
 	// PolicyCfgs sets the tail-based sampling policy which makes a sampling decision
 	// for a given trace when requested.
 	PolicyCfgs []PolicyCfg `mapstructure:"policies"`
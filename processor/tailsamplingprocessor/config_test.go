@@ -46,6 +46,8 @@ func TestLoadConfig(t *testing.T) {
 			DecisionWait:            10 * time.Second,
 			NumTraces:               100,
 			ExpectedNewTracesPerSec: 10,
+			DecisionCacheSize:       1000,
+			MaxBufferedBytes:        10000000,
 			PolicyCfgs: []PolicyCfg{
 				{
 					Name: "test-policy-1",
@@ -66,6 +68,65 @@ func TestLoadConfig(t *testing.T) {
 					Type:            RateLimiting,
 					RateLimitingCfg: RateLimitingCfg{SpansPerSecond: 35},
 				},
+				{
+					Name:               "test-policy-5",
+					Type:               StringAttribute,
+					Mode:               NeverSample,
+					StringAttributeCfg: StringAttributeCfg{Key: "http.url", Values: []string{"/health"}},
+				},
+				{
+					Name: "test-policy-6",
+					Type: Composite,
+					CompositeCfg: CompositeCfg{
+						MaxTotalSpansPerSecond: 2000,
+						SubPolicies: []PolicyCfg{
+							{
+								Name: "checkout-errors",
+								Type: And,
+								AndCfg: AndCfg{SubPolicies: []PolicyCfg{
+									{Type: StringAttribute, StringAttributeCfg: StringAttributeCfg{Key: "service", Values: []string{"checkout"}}},
+									{Type: NumericAttribute, NumericAttributeCfg: NumericAttributeCfg{Key: "http.status_code", MinValue: 500, MaxValue: 599}},
+								}},
+							},
+							{
+								Name: "others",
+								Type: Not,
+								NotCfg: NotCfg{SubPolicy: &PolicyCfg{
+									Type:               StringAttribute,
+									StringAttributeCfg: StringAttributeCfg{Key: "service", Values: []string{"checkout"}},
+								}},
+							},
+						},
+						RateAllocation: []RateAllocationCfg{{Policy: "others", Percent: 5}},
+					},
+				},
+				{
+					Name:       "test-policy-7",
+					Type:       Latency,
+					Pipeline:   "traces",
+					LatencyCfg: LatencyCfg{Threshold: 5 * time.Second},
+				},
+				{
+					Name:           "test-policy-8",
+					Type:           ErrorStatus,
+					Exporters:      []string{"exampleexporter"},
+					ErrorStatusCfg: ErrorStatusCfg{Codes: []int32{13, 14}},
+				},
+				{
+					Name:         "test-policy-9",
+					Type:         SpanCount,
+					SpanCountCfg: SpanCountCfg{MinSpans: 2, MaxSpans: 1000},
+				},
+				{
+					Name:             "test-policy-10",
+					Type:             Probabilistic,
+					ProbabilisticCfg: ProbabilisticCfg{HashSeed: 22, SamplingPercentage: 15.3},
+				},
+				{
+					Name:        "test-policy-11",
+					Type:        Adaptive,
+					AdaptiveCfg: AdaptiveCfg{TracesPerSecond: 5, AdjustmentInterval: 30 * time.Second, HashSeed: 22},
+				},
 			},
 		})
 }
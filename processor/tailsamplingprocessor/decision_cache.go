@@ -0,0 +1,142 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsamplingprocessor
+
+import (
+	"container/list"
+	"sync"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/sampling"
+)
+
+// cachedDecision is the final decision made for a trace.
+type cachedDecision struct {
+	decision sampling.Decision
+	// policies are the policies that sampled the trace.
+	policies []*Policy
+	// spanAttributes are added by the policies to the spans of the trace.
+	spanAttributes map[string]*tracepb.AttributeValue
+}
+
+// decisionCache is a bounded LRU cache of the final decisions of recent
+// traces. It outlives the trace data so that spans arriving after the trace
+// was removed from memory follow the decision made for the trace.
+//
+// It also remembers, up to its capacity, the traces that were removed from
+// memory without a cached decision, either because their decision was evicted
+// from the cache or because they were dropped before a decision was made, to
+// tell the late spans of these traces from the spans of new traces.
+type decisionCache struct {
+	sync.Mutex
+	capacity int
+	entries  map[traceKey]*list.Element
+	// lru holds the *decisionCacheEntry, the most recently used at the front.
+	lru *list.List
+
+	// evicted maps the evicted traces to their slot in evictedRing, which
+	// holds the most recently evicted traces.
+	evicted     map[traceKey]int
+	evictedRing []traceKey
+	evictedNext int
+}
+
+type decisionCacheEntry struct {
+	id traceKey
+	cachedDecision
+}
+
+func newDecisionCache(capacity int) *decisionCache {
+	return &decisionCache{
+		capacity: capacity,
+		entries:  make(map[traceKey]*list.Element, capacity),
+		lru:      list.New(),
+		evicted:  make(map[traceKey]int),
+	}
+}
+
+// Get returns the decision cached for the trace, if any.
+func (dc *decisionCache) Get(id traceKey) (cachedDecision, bool) {
+	dc.Lock()
+	defer dc.Unlock()
+	elem, ok := dc.entries[id]
+	if !ok {
+		return cachedDecision{}, false
+	}
+	dc.lru.MoveToFront(elem)
+	return elem.Value.(*decisionCacheEntry).cachedDecision, true
+}
+
+// Put caches the decision for the trace, evicting the least recently used
+// decision if the cache is full.
+func (dc *decisionCache) Put(id traceKey, decision cachedDecision) {
+	dc.Lock()
+	defer dc.Unlock()
+	if elem, ok := dc.entries[id]; ok {
+		elem.Value.(*decisionCacheEntry).cachedDecision = decision
+		dc.lru.MoveToFront(elem)
+		return
+	}
+	if dc.lru.Len() >= dc.capacity {
+		oldest := dc.lru.Back()
+		dc.lru.Remove(oldest)
+		oldestID := oldest.Value.(*decisionCacheEntry).id
+		delete(dc.entries, oldestID)
+		dc.markEvicted(oldestID)
+	}
+	dc.entries[id] = dc.lru.PushFront(&decisionCacheEntry{id: id, cachedDecision: decision})
+}
+
+// MarkEvicted records that the trace was removed from memory without a
+// decision.
+func (dc *decisionCache) MarkEvicted(id traceKey) {
+	dc.Lock()
+	defer dc.Unlock()
+	dc.markEvicted(id)
+}
+
+// WasEvicted returns whether the trace was removed from memory without a
+// decision or had its decision evicted from the cache.
+func (dc *decisionCache) WasEvicted(id traceKey) bool {
+	dc.Lock()
+	defer dc.Unlock()
+	_, ok := dc.evicted[id]
+	return ok
+}
+
+// markEvicted must be called with the lock held.
+func (dc *decisionCache) markEvicted(id traceKey) {
+	if len(dc.evictedRing) < dc.capacity {
+		dc.evicted[id] = len(dc.evictedRing)
+		dc.evictedRing = append(dc.evictedRing, id)
+		return
+	}
+	oldest := dc.evictedRing[dc.evictedNext]
+	// The oldest trace may have been evicted again since, in a newer slot.
+	if dc.evicted[oldest] == dc.evictedNext {
+		delete(dc.evicted, oldest)
+	}
+	dc.evicted[id] = dc.evictedNext
+	dc.evictedRing[dc.evictedNext] = id
+	dc.evictedNext = (dc.evictedNext + 1) % dc.capacity
+}
+
+// Len returns the number of cached decisions.
+func (dc *decisionCache) Len() int {
+	dc.Lock()
+	defer dc.Unlock()
+	return dc.lru.Len()
+}
@@ -0,0 +1,114 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tailsamplingprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/sampling"
+)
+
+func TestDecisionCache(t *testing.T) {
+	dc := newDecisionCache(2)
+	dc.Put("a", cachedDecision{decision: sampling.Sampled})
+	dc.Put("b", cachedDecision{decision: sampling.NotSampled})
+
+	// Getting "a" makes "b" the least recently used.
+	cached, ok := dc.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, sampling.Sampled, cached.decision)
+
+	dc.Put("c", cachedDecision{decision: sampling.Sampled})
+	assert.Equal(t, 2, dc.Len())
+	_, ok = dc.Get("b")
+	assert.False(t, ok)
+	_, ok = dc.Get("a")
+	assert.True(t, ok)
+
+	// Updating an entry doesn't evict anything.
+	dc.Put("c", cachedDecision{decision: sampling.NotSampled})
+	assert.Equal(t, 2, dc.Len())
+	cached, ok = dc.Get("c")
+	require.True(t, ok)
+	assert.Equal(t, sampling.NotSampled, cached.decision)
+}
+
+func TestDecisionCache_Evicted(t *testing.T) {
+	dc := newDecisionCache(2)
+	dc.Put("a", cachedDecision{decision: sampling.Sampled})
+	dc.Put("b", cachedDecision{decision: sampling.Sampled})
+	assert.False(t, dc.WasEvicted("a"))
+	assert.False(t, dc.WasEvicted("new"))
+
+	// Traces whose decision is evicted from the cache and the ones dropped
+	// without a decision are remembered, up to the capacity of the cache.
+	dc.Put("c", cachedDecision{decision: sampling.Sampled})
+	assert.True(t, dc.WasEvicted("a"))
+	dc.MarkEvicted("dropped")
+	assert.True(t, dc.WasEvicted("dropped"))
+
+	dc.MarkEvicted("a")
+	dc.MarkEvicted("other")
+	assert.False(t, dc.WasEvicted("dropped"))
+	assert.True(t, dc.WasEvicted("a"))
+	assert.True(t, dc.WasEvicted("other"))
+}
+
+func TestDecisionCache_LateSpans(t *testing.T) {
+	for _, decision := range []sampling.Decision{sampling.Sampled, sampling.NotSampled} {
+		sink := &exportertest.SinkTraceExporter{}
+		mpe := &mockPolicyEvaluator{NextDecision: decision}
+		tsp := &tailSamplingSpanProcessor{
+			ctx:             context.Background(),
+			nextConsumer:    sink,
+			maxNumTraces:    1,
+			logger:          zap.NewNop(),
+			decisionBatcher: newSyncIDBatcher(1),
+			policies:        []*Policy{{Name: "mock-policy", Evaluator: mpe, ctx: context.TODO()}},
+			deleteChan:      make(chan traceKey, 1),
+			policyTicker:    &manualTTicker{},
+			decisionCache:   newDecisionCache(10),
+		}
+
+		_, batches := generateIdsAndBatches(2)
+		require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+		tsp.samplingPolicyOnTick()
+		tsp.samplingPolicyOnTick()
+		require.Equal(t, 1, mpe.EvaluationCount)
+
+		// The second trace removes the first one from memory.
+		require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[1]))
+		_, ok := tsp.idToTrace.Load(traceKey(batches[0].Spans[0].TraceId))
+		require.False(t, ok)
+
+		// The late span follows the cached decision instead of starting a new
+		// trace.
+		sent := len(sink.AllTraces())
+		require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+		_, ok = tsp.idToTrace.Load(traceKey(batches[0].Spans[0].TraceId))
+		assert.False(t, ok)
+		if decision == sampling.Sampled {
+			assert.Len(t, sink.AllTraces(), sent+1)
+		} else {
+			assert.Len(t, sink.AllTraces(), sent)
+		}
+	}
+}
@@ -42,8 +42,9 @@ func (f *Factory) Type() string {
 // CreateDefaultConfig creates the default configuration for processor.
 func (f *Factory) CreateDefaultConfig() configmodels.Processor {
 	return &Config{
-		DecisionWait: 30 * time.Second,
-		NumTraces:    50000,
+		DecisionWait:      30 * time.Second,
+		NumTraces:         50000,
+		DecisionCacheSize: 50000,
 	}
 }
 
@@ -37,9 +37,18 @@ var (
 
 	statCountTracesSampled = stats.Int64("count_traces_sampled", "Count of traces that were sampled or not", stats.UnitDimensionless)
 
+	statCountFinalDecision     = stats.Int64("count_final_decision", "Count of traces that were sampled or not after combining the decisions of all policies", stats.UnitDimensionless)
+	statCountSampledTraceVotes = stats.Int64("count_sampled_trace_votes", "Count of sampled traces that a policy decided to sample", stats.UnitDimensionless)
+
 	statDroppedTooEarlyCount    = stats.Int64("sampling_trace_dropped_too_early", "Count of traces that needed to be dropped the configured wait time", stats.UnitDimensionless)
 	statNewTraceIDReceivedCount = stats.Int64("new_trace_id_received", "Counts the arrival of new traces", stats.UnitDimensionless)
 	statTracesOnMemoryGauge     = stats.Int64("sampling_traces_on_memory", "Tracks the number of traces current on memory", stats.UnitDimensionless)
+
+	statTracesEvictedForMemoryCount = stats.Int64("sampling_trace_evicted_for_memory", "Count of traces removed from memory because max_buffered_bytes was exceeded", stats.UnitDimensionless)
+	statBufferedBytesGauge          = stats.Int64("sampling_buffered_bytes", "Tracks the approximate size of the spans waiting for a decision", stats.UnitBytes)
+
+	statDecisionCacheHitCount  = stats.Int64("sampling_decision_cache_hit", "Count of late spans of traces no longer on memory handled with a cached decision, counted once per trace and batch", stats.UnitDimensionless)
+	statDecisionCacheMissCount = stats.Int64("sampling_decision_cache_miss", "Count of late spans of traces no longer on memory without a cached decision, counted once per trace and batch", stats.UnitDimensionless)
 )
 
 // SamplingProcessorMetricViews return the metrics views according to given telemetry level.
@@ -96,6 +105,21 @@ func SamplingProcessorMetricViews(level telemetry.Level) []*view.View {
 		Aggregation: view.Sum(),
 	}
 
+	countFinalDecisionView := &view.View{
+		Name:        statCountFinalDecision.Name(),
+		Measure:     statCountFinalDecision,
+		Description: statCountFinalDecision.Description(),
+		TagKeys:     []tag.Key{tagSampledKey},
+		Aggregation: view.Sum(),
+	}
+	countSampledTraceVotesView := &view.View{
+		Name:        statCountSampledTraceVotes.Name(),
+		Measure:     statCountSampledTraceVotes,
+		Description: statCountSampledTraceVotes.Description(),
+		TagKeys:     policyTagKeys,
+		Aggregation: view.Sum(),
+	}
+
 	countTraceDroppedTooEarlyView := &view.View{
 		Name:        statDroppedTooEarlyCount.Name(),
 		Measure:     statDroppedTooEarlyCount,
@@ -115,6 +139,32 @@ func SamplingProcessorMetricViews(level telemetry.Level) []*view.View {
 		Aggregation: view.LastValue(),
 	}
 
+	countTracesEvictedForMemoryView := &view.View{
+		Name:        statTracesEvictedForMemoryCount.Name(),
+		Measure:     statTracesEvictedForMemoryCount,
+		Description: statTracesEvictedForMemoryCount.Description(),
+		Aggregation: view.Sum(),
+	}
+	trackBufferedBytesView := &view.View{
+		Name:        statBufferedBytesGauge.Name(),
+		Measure:     statBufferedBytesGauge,
+		Description: statBufferedBytesGauge.Description(),
+		Aggregation: view.LastValue(),
+	}
+
+	countDecisionCacheHitView := &view.View{
+		Name:        statDecisionCacheHitCount.Name(),
+		Measure:     statDecisionCacheHitCount,
+		Description: statDecisionCacheHitCount.Description(),
+		Aggregation: view.Sum(),
+	}
+	countDecisionCacheMissView := &view.View{
+		Name:        statDecisionCacheMissCount.Name(),
+		Measure:     statDecisionCacheMissCount,
+		Description: statDecisionCacheMissCount.Description(),
+		Aggregation: view.Sum(),
+	}
+
 	return []*view.View{
 		decisionLatencyView,
 		overallDecisionLatencyView,
@@ -125,9 +175,17 @@ func SamplingProcessorMetricViews(level telemetry.Level) []*view.View {
 		countPolicyEvaluationErrorView,
 
 		countTracesSampledView,
+		countFinalDecisionView,
+		countSampledTraceVotesView,
 
 		countTraceDroppedTooEarlyView,
 		countTraceIDArrivalView,
 		trackTracesOnMemorylView,
+
+		countTracesEvictedForMemoryView,
+		trackBufferedBytesView,
+
+		countDecisionCacheHitView,
+		countDecisionCacheMissView,
 	}
 }
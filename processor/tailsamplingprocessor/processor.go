@@ -18,17 +18,22 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/proto"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/adaptivesampling"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplingprobability"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
@@ -43,8 +48,24 @@ type Policy struct {
 	Name string
 	// Evaluator that decides if a trace is sampled or not by this policy instance.
 	Evaluator sampling.PolicyEvaluator
+	// Mode specifies how the decision of the policy is used for the final
+	// decision of the trace. An empty mode is the same as Sample.
+	Mode PolicyMode
+	// Exporters and Pipeline are the names of the destinations of the traces
+	// sampled by the policy. The next consumer is used if there are none.
+	Exporters []string
+	Pipeline  string
 	// ctx used to carry metric tags of each policy.
 	ctx context.Context
+	// destinations are set once the pipelines are connected.
+	destinations []destination
+}
+
+// destination is a consumer of sampled traces identified by a name unique
+// among the exporters and pipelines.
+type destination struct {
+	name     string
+	consumer consumer.TraceConsumer
 }
 
 // traceKey is defined since sync.Map requires a comparable type, isolating it on its own
@@ -65,13 +86,29 @@ type tailSamplingSpanProcessor struct {
 	decisionBatcher idbatcher.Batcher
 	deleteChan      chan traceKey
 	numTracesOnMap  uint64
+	// decisionCache is nil if the decisions are not cached.
+	decisionCache *decisionCache
+	// maxBufferedBytes is the budget of bufferedBytes, 0 if there is none.
+	maxBufferedBytes int64
+	// bufferedBytes is the approximate serialized size of the spans of the
+	// traces waiting for a decision. It is only tracked if there is a budget.
+	bufferedBytes int64
 }
 
 const (
 	sourceFormat = "tail_sampling"
+
+	// policiesAttributeKey is the span attribute holding the names of the
+	// policies that decided to sample the trace, separated by commas.
+	policiesAttributeKey = "tail_sampling.policies"
+
+	// defaultAdjustmentInterval is the interval of the adaptive policies if
+	// none is configured.
+	defaultAdjustmentInterval = 10 * time.Second
 )
 
 var _ processor.TraceProcessor = (*tailSamplingSpanProcessor)(nil)
+var _ processor.PipelinesConnector = (*tailSamplingSpanProcessor)(nil)
 
 // NewTraceProcessor returns a processor.TraceProcessor that will perform tail sampling according to the given
 // configuration.
@@ -97,25 +134,37 @@ func NewTraceProcessor(logger *zap.Logger, nextConsumer consumer.TraceConsumer,
 		if err != nil {
 			return nil, err
 		}
+		switch policyCfg.Mode {
+		case "", Sample, NeverSample:
+		default:
+			return nil, fmt.Errorf("unknown mode %q of sampling policy %s", policyCfg.Mode, policyCfg.Name)
+		}
 		policy := &Policy{
 			Name:      policyCfg.Name,
 			Evaluator: eval,
+			Mode:      policyCfg.Mode,
+			Exporters: policyCfg.Exporters,
+			Pipeline:  policyCfg.Pipeline,
 			ctx:       policyCtx,
 		}
 		policies = append(policies, policy)
 	}
 
 	tsp := &tailSamplingSpanProcessor{
-		ctx:             ctx,
-		nextConsumer:    nextConsumer,
-		maxNumTraces:    cfg.NumTraces,
-		logger:          logger,
-		decisionBatcher: inBatcher,
-		policies:        policies,
+		ctx:              ctx,
+		nextConsumer:     nextConsumer,
+		maxNumTraces:     cfg.NumTraces,
+		maxBufferedBytes: int64(cfg.MaxBufferedBytes),
+		logger:           logger,
+		decisionBatcher:  inBatcher,
+		policies:         policies,
 	}
 
 	tsp.policyTicker = &policyTicker{onTick: tsp.samplingPolicyOnTick}
 	tsp.deleteChan = make(chan traceKey, cfg.NumTraces)
+	if cfg.DecisionCacheSize > 0 {
+		tsp.decisionCache = newDecisionCache(cfg.DecisionCacheSize)
+	}
 
 	return tsp, nil
 }
@@ -133,11 +182,124 @@ func getPolicyEvaluator(cfg *PolicyCfg) (sampling.PolicyEvaluator, error) {
 	case RateLimiting:
 		rlfCfg := cfg.RateLimitingCfg
 		return sampling.NewRateLimiting(rlfCfg.SpansPerSecond), nil
+	case Latency:
+		if cfg.LatencyCfg.Threshold <= 0 {
+			return nil, fmt.Errorf("threshold of sampling policy %s must be positive", cfg.Name)
+		}
+		return sampling.NewLatency(cfg.LatencyCfg.Threshold), nil
+	case ErrorStatus:
+		return sampling.NewErrorStatus(cfg.ErrorStatusCfg.Codes), nil
+	case SpanCount:
+		scCfg := cfg.SpanCountCfg
+		if scCfg.MaxSpans != 0 && scCfg.MaxSpans < scCfg.MinSpans {
+			return nil, fmt.Errorf("max_spans of sampling policy %s is lower than min_spans", cfg.Name)
+		}
+		return sampling.NewSpanCount(scCfg.MinSpans, scCfg.MaxSpans), nil
+	case Probabilistic:
+		pCfg := cfg.ProbabilisticCfg
+		if pCfg.SamplingPercentage < 0 || pCfg.SamplingPercentage > 100 {
+			return nil, fmt.Errorf("sampling_percentage of sampling policy %s must be between 0 and 100", cfg.Name)
+		}
+		return sampling.NewProbabilistic(pCfg.HashSeed, pCfg.SamplingPercentage), nil
+	case Adaptive:
+		aCfg := cfg.AdaptiveCfg
+		if aCfg.TracesPerSecond <= 0 {
+			return nil, fmt.Errorf("traces_per_second of sampling policy %s must be positive", cfg.Name)
+		}
+		interval := aCfg.AdjustmentInterval
+		if interval <= 0 {
+			interval = defaultAdjustmentInterval
+		}
+		sampler := adaptivesampling.NewSampler(aCfg.TracesPerSecond, interval, aCfg.HashSeed)
+		return sampling.NewAdaptive(sampler), nil
+	case And:
+		subPolicies, err := getSubPolicyEvaluators(cfg, cfg.AndCfg.SubPolicies)
+		if err != nil {
+			return nil, err
+		}
+		return sampling.NewAnd(subPolicies), nil
+	case Or:
+		subPolicies, err := getSubPolicyEvaluators(cfg, cfg.OrCfg.SubPolicies)
+		if err != nil {
+			return nil, err
+		}
+		return sampling.NewOr(subPolicies), nil
+	case Not:
+		if cfg.NotCfg.SubPolicy == nil {
+			return nil, fmt.Errorf("missing sub-policy of sampling policy %s", cfg.Name)
+		}
+		subPolicy, err := getPolicyEvaluator(cfg.NotCfg.SubPolicy)
+		if err != nil {
+			return nil, err
+		}
+		return sampling.NewNot(subPolicy), nil
+	case Composite:
+		return getCompositeEvaluator(cfg)
 	default:
 		return nil, fmt.Errorf("unknown sampling policy type %s", cfg.Type)
 	}
 }
 
+func getSubPolicyEvaluators(cfg *PolicyCfg, subPolicyCfgs []PolicyCfg) ([]sampling.PolicyEvaluator, error) {
+	if len(subPolicyCfgs) == 0 {
+		return nil, fmt.Errorf("missing sub-policies of sampling policy %s", cfg.Name)
+	}
+	subPolicies := make([]sampling.PolicyEvaluator, 0, len(subPolicyCfgs))
+	for i := range subPolicyCfgs {
+		subPolicy, err := getPolicyEvaluator(&subPolicyCfgs[i])
+		if err != nil {
+			return nil, err
+		}
+		subPolicies = append(subPolicies, subPolicy)
+	}
+	return subPolicies, nil
+}
+
+func getCompositeEvaluator(cfg *PolicyCfg) (sampling.PolicyEvaluator, error) {
+	cCfg := cfg.CompositeCfg
+	if cCfg.MaxTotalSpansPerSecond <= 0 {
+		return nil, fmt.Errorf("max_total_spans_per_second of sampling policy %s must be positive", cfg.Name)
+	}
+	evaluators, err := getSubPolicyEvaluators(cfg, cCfg.SubPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	subPolicyIndexes := make(map[string]int, len(cCfg.SubPolicies))
+	for i, subPolicyCfg := range cCfg.SubPolicies {
+		if subPolicyCfg.Name != "" {
+			subPolicyIndexes[subPolicyCfg.Name] = i
+		}
+	}
+
+	subPolicies := make([]sampling.SubPolicy, len(evaluators))
+	for i, evaluator := range evaluators {
+		subPolicies[i].Evaluator = evaluator
+	}
+	var totalPercent int64
+	for _, allocation := range cCfg.RateAllocation {
+		i, ok := subPolicyIndexes[allocation.Policy]
+		if !ok {
+			return nil, fmt.Errorf("unknown sub-policy %q in the rate allocation of sampling policy %s", allocation.Policy, cfg.Name)
+		}
+		if allocation.Percent <= 0 {
+			return nil, fmt.Errorf("percent of sub-policy %q in the rate allocation of sampling policy %s must be positive", allocation.Policy, cfg.Name)
+		}
+		totalPercent += allocation.Percent
+		// A budget of 0 means no budget for the sub-policy, so keep it at
+		// least 1 span per second.
+		subPolicies[i].MaxSpansPerSecond = cCfg.MaxTotalSpansPerSecond * allocation.Percent / 100
+		if subPolicies[i].MaxSpansPerSecond == 0 {
+			subPolicies[i].MaxSpansPerSecond = 1
+		}
+	}
+	if totalPercent > 100 {
+		return nil, fmt.Errorf("the rate allocation of sampling policy %s exceeds 100 percent", cfg.Name)
+	}
+
+	return sampling.NewComposite(cCfg.MaxTotalSpansPerSecond, subPolicies), nil
+}
+
 func (tsp *tailSamplingSpanProcessor) samplingPolicyOnTick() {
 	var idNotFoundOnMapCount, evaluateErrorCount, decisionSampled, decisionNotSampled int64
 	startTime := time.Now()
@@ -152,51 +314,51 @@ func (tsp *tailSamplingSpanProcessor) samplingPolicyOnTick() {
 		}
 		trace := d.(*sampling.TraceData)
 		trace.DecisionTime = time.Now()
-		for i, policy := range tsp.policies {
-			policyEvaluateStartTime := time.Now()
-			decision, err := policy.Evaluator.Evaluate(id, trace)
-			stats.Record(
-				policy.ctx,
-				statDecisionLatencyMicroSec.M(int64(time.Since(policyEvaluateStartTime)/time.Microsecond)))
-			if err != nil {
-				trace.Decisions[i] = sampling.NotSampled
-				evaluateErrorCount++
-				tsp.logger.Error("Sampling policy error", zap.Error(err))
-				continue
-			}
-
-			trace.Decisions[i] = decision
+		decision := tsp.makeDecision(id, trace, &evaluateErrorCount)
 
-			switch decision {
-			case sampling.Sampled:
-				stats.RecordWithTags(
-					policy.ctx,
-					[]tag.Mutator{tag.Insert(tagSampledKey, "true")},
-					statCountTracesSampled.M(int64(1)),
-				)
-				decisionSampled++
+		// Take the batches and set the final decision under the lock, so that
+		// spans arriving concurrently are either in the batches or handled
+		// with the final decision.
+		trace.Lock()
+		trace.FinalDecision = decision
+		traceBatches := trace.ReceivedBatches
+		// Sampled or not, remove the batches
+		trace.ReceivedBatches = nil
+		releasedBytes := trace.BufferedBytes
+		trace.BufferedBytes = 0
+		spanAttributes := trace.SpanAttributes
+		trace.Unlock()
+		atomic.AddInt64(&tsp.bufferedBytes, -releasedBytes)
 
-				trace.Lock()
-				traceBatches := trace.ReceivedBatches
-				trace.Unlock()
+		var policies []*Policy
+		if decision == sampling.Sampled {
+			policies = tsp.sampledBy(trace)
+		}
+		if tsp.decisionCache != nil {
+			tsp.decisionCache.Put(traceKey(id), cachedDecision{
+				decision:       decision,
+				policies:       policies,
+				spanAttributes: spanAttributes,
+			})
+		}
 
-				for j := 0; j < len(traceBatches); j++ {
-					tsp.nextConsumer.ConsumeTraceData(policy.ctx, traceBatches[j])
-				}
-			case sampling.NotSampled:
-				stats.RecordWithTags(
-					policy.ctx,
-					[]tag.Mutator{tag.Insert(tagSampledKey, "false")},
-					statCountTracesSampled.M(int64(1)),
-				)
-				decisionNotSampled++
+		switch decision {
+		case sampling.Sampled:
+			decisionSampled++
+			for _, policy := range policies {
+				stats.Record(policy.ctx, statCountSampledTraceVotes.M(int64(1)))
+			}
+			for j := 0; j < len(traceBatches); j++ {
+				tsp.forward(traceBatches[j], policies, spanAttributes)
 			}
+		case sampling.NotSampled:
+			decisionNotSampled++
 		}
-
-		// Sampled or not, remove the batches
-		trace.Lock()
-		trace.ReceivedBatches = nil
-		trace.Unlock()
+		stats.RecordWithTags(
+			tsp.ctx,
+			[]tag.Mutator{tag.Insert(tagSampledKey, strconv.FormatBool(decision == sampling.Sampled))},
+			statCountFinalDecision.M(int64(1)),
+		)
 	}
 
 	stats.Record(tsp.ctx,
@@ -214,6 +376,209 @@ func (tsp *tailSamplingSpanProcessor) samplingPolicyOnTick() {
 	)
 }
 
+// makeDecision evaluates all the policies for the trace and returns the final
+// decision: the trace is sampled if any policy in Sample mode decides to
+// sample it, unless a policy in NeverSample mode decides to sample it. The
+// spans of a sampled trace get the probability of the winning policy, the one
+// with the highest probability among the policies that sampled it, unless the
+// winning policy doesn't sample by probability.
+func (tsp *tailSamplingSpanProcessor) makeDecision(id []byte, trace *sampling.TraceData, evaluateErrorCount *int64) sampling.Decision {
+	sampled, neverSampled := false, false
+	// winningProbability is the probability of the winning policy, 1 for the
+	// policies not sampling by probability.
+	winningProbability, probabilistic := 0.0, false
+	for i, policy := range tsp.policies {
+		policyEvaluateStartTime := time.Now()
+		decision, probability, isProbabilistic, err := evaluate(policy.Evaluator, id, trace)
+		stats.Record(
+			policy.ctx,
+			statDecisionLatencyMicroSec.M(int64(time.Since(policyEvaluateStartTime)/time.Microsecond)))
+		if err != nil {
+			trace.Decisions[i] = sampling.NotSampled
+			*evaluateErrorCount++
+			tsp.logger.Error("Sampling policy error", zap.Error(err))
+			continue
+		}
+
+		trace.Decisions[i] = decision
+
+		switch decision {
+		case sampling.Sampled:
+			stats.RecordWithTags(
+				policy.ctx,
+				[]tag.Mutator{tag.Insert(tagSampledKey, "true")},
+				statCountTracesSampled.M(int64(1)),
+			)
+			if policy.Mode == NeverSample {
+				neverSampled = true
+			} else {
+				sampled = true
+				if probability > winningProbability || (probability == winningProbability && !isProbabilistic) {
+					winningProbability, probabilistic = probability, isProbabilistic
+				}
+			}
+		case sampling.NotSampled:
+			stats.RecordWithTags(
+				policy.ctx,
+				[]tag.Mutator{tag.Insert(tagSampledKey, "false")},
+				statCountTracesSampled.M(int64(1)),
+			)
+		}
+	}
+
+	if !sampled || neverSampled {
+		return sampling.NotSampled
+	}
+	if probabilistic {
+		trace.Lock()
+		trace.SpanAttributes = map[string]*tracepb.AttributeValue{
+			samplingprobability.AttributeKey: {
+				Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: winningProbability},
+			},
+		}
+		trace.Unlock()
+	}
+	return sampling.Sampled
+}
+
+// evaluate evaluates the policy for the trace. It also returns the probability
+// used by the policy and whether it samples by probability, the probability
+// is 1 for the other policies.
+func evaluate(evaluator sampling.PolicyEvaluator, id []byte, trace *sampling.TraceData) (sampling.Decision, float64, bool, error) {
+	if pe, ok := evaluator.(sampling.ProbabilisticEvaluator); ok {
+		decision, probability, err := pe.EvaluateWithProbability(id, trace)
+		return decision, probability, true, err
+	}
+	decision, err := evaluator.Evaluate(id, trace)
+	return decision, 1, false, err
+}
+
+// sampledBy returns the policies in Sample mode that decided to sample the
+// trace.
+func (tsp *tailSamplingSpanProcessor) sampledBy(trace *sampling.TraceData) []*Policy {
+	var policies []*Policy
+	for i, policy := range tsp.policies {
+		if policy.Mode != NeverSample && trace.Decisions[i] == sampling.Sampled {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+// forward sends the batch of a sampled trace to the destinations of the
+// policies that sampled it, adding the names of the policies and the given
+// attributes to its spans. Each destination receives the batch once.
+func (tsp *tailSamplingSpanProcessor) forward(td consumerdata.TraceData, policies []*Policy, spanAttributes map[string]*tracepb.AttributeValue) {
+	td = withAttributes(td, policyNames(policies), spanAttributes)
+	for _, dest := range tsp.destinations(policies) {
+		if err := dest.consumer.ConsumeTraceData(tsp.ctx, td); err != nil {
+			tsp.logger.Warn("Error sending sampled spans to destination",
+				zap.String("destination", dest.name),
+				zap.Error(err))
+		}
+	}
+}
+
+// destinations returns the distinct destinations of the policies.
+func (tsp *tailSamplingSpanProcessor) destinations(policies []*Policy) []destination {
+	next := destination{consumer: tsp.nextConsumer}
+	var dests []destination
+	seen := make(map[string]bool)
+	for _, policy := range policies {
+		policyDests := policy.destinations
+		if len(policyDests) == 0 {
+			policyDests = []destination{next}
+		}
+		for _, dest := range policyDests {
+			if !seen[dest.name] {
+				seen[dest.name] = true
+				dests = append(dests, dest)
+			}
+		}
+	}
+	if len(dests) == 0 {
+		dests = append(dests, next)
+	}
+	return dests
+}
+
+// ConnectPipelines implements processor.PipelinesConnector. It resolves the
+// exporters and pipelines named by the policies.
+func (tsp *tailSamplingSpanProcessor) ConnectPipelines(pipelines processor.PipelineConsumers) error {
+	for _, policy := range tsp.policies {
+		var dests []destination
+		for _, name := range policy.Exporters {
+			te := pipelines.TraceExporter(name)
+			if te == nil {
+				return fmt.Errorf("traces exporter %q of sampling policy %s not found", name, policy.Name)
+			}
+			dests = append(dests, destination{name: "exporter/" + name, consumer: te})
+		}
+		if policy.Pipeline != "" {
+			tc := pipelines.TraceConsumer(policy.Pipeline)
+			if tc == nil {
+				return fmt.Errorf("traces pipeline %q of sampling policy %s not found", policy.Pipeline, policy.Name)
+			}
+			dests = append(dests, destination{name: "pipeline/" + policy.Pipeline, consumer: tc})
+		}
+		policy.destinations = dests
+	}
+	return nil
+}
+
+func policyNames(policies []*Policy) string {
+	names := make([]string, len(policies))
+	for i, policy := range policies {
+		names[i] = policy.Name
+	}
+	return strings.Join(names, ",")
+}
+
+// withAttributes returns a copy of the batch with the names of the policies
+// and the given attributes added to the attributes of its spans. The spans are
+// copied since they may be shared with other pipelines, the batch is returned
+// unchanged if there are no attributes to add.
+func withAttributes(td consumerdata.TraceData, policyNames string, spanAttributes map[string]*tracepb.AttributeValue) consumerdata.TraceData {
+	if policyNames == "" && len(spanAttributes) == 0 {
+		return td
+	}
+
+	var policiesValue *tracepb.AttributeValue
+	if policyNames != "" {
+		policiesValue = &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_StringValue{
+				StringValue: &tracepb.TruncatableString{Value: policyNames},
+			},
+		}
+	}
+	spans := make([]*tracepb.Span, len(td.Spans))
+	for i, span := range td.Spans {
+		if span == nil {
+			continue
+		}
+		spanCopy := *span
+		attrs := &tracepb.Span_Attributes{
+			AttributeMap: make(map[string]*tracepb.AttributeValue, len(span.GetAttributes().GetAttributeMap())+len(spanAttributes)+1),
+		}
+		if span.Attributes != nil {
+			attrs.DroppedAttributesCount = span.Attributes.DroppedAttributesCount
+			for k, v := range span.Attributes.AttributeMap {
+				attrs.AttributeMap[k] = v
+			}
+		}
+		for k, v := range spanAttributes {
+			attrs.AttributeMap[k] = v
+		}
+		if policiesValue != nil {
+			attrs.AttributeMap[policiesAttributeKey] = policiesValue
+		}
+		spanCopy.Attributes = attrs
+		spans[i] = &spanCopy
+	}
+	td.Spans = spans
+	return td
+}
+
 // ConsumeTraceData is required by the SpanProcessor interface.
 func (tsp *tailSamplingSpanProcessor) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
 	tsp.start.Do(func() {
@@ -235,6 +600,10 @@ func (tsp *tailSamplingSpanProcessor) ConsumeTraceData(ctx context.Context, td c
 	var newTraceIDs int64
 	singleTrace := len(idToSpans) == 1
 	for id, spans := range idToSpans {
+		if tsp.decisionCache != nil && tsp.applyCachedDecision(id, spans, singleTrace, td) {
+			continue
+		}
+
 		lenSpans := int64(len(spans))
 		lenPolicies := len(tsp.policies)
 		initialDecisions := make([]sampling.Decision, lenPolicies)
@@ -242,9 +611,10 @@ func (tsp *tailSamplingSpanProcessor) ConsumeTraceData(ctx context.Context, td c
 			initialDecisions[i] = sampling.Pending
 		}
 		initialTraceData := &sampling.TraceData{
-			Decisions:   initialDecisions,
-			ArrivalTime: time.Now(),
-			SpanCount:   lenSpans,
+			Decisions:     initialDecisions,
+			FinalDecision: sampling.Pending,
+			ArrivalTime:   time.Now(),
+			SpanCount:     lenSpans,
 		}
 		d, loaded := tsp.idToTrace.LoadOrStore(traceKey(id), initialTraceData)
 
@@ -268,50 +638,140 @@ func (tsp *tailSamplingSpanProcessor) ConsumeTraceData(ctx context.Context, td c
 			}
 		}
 
-		for i, policy := range tsp.policies {
-			actualData.Lock()
-			actualDecision := actualData.Decisions[i]
-			// If decision is pending, we want to add the new spans still under the lock, so the decision doesn't happen
-			// in between the transition from pending.
-			if actualDecision == sampling.Pending {
-				// Add the spans to the trace, but only once for all policy, otherwise same spans will
-				// be duplicated in the final trace.
-				traceTd := prepareTraceBatch(spans, singleTrace, td)
-				actualData.ReceivedBatches = append(actualData.ReceivedBatches, traceTd)
-				actualData.Unlock()
-				break
+		actualData.Lock()
+		finalDecision := actualData.FinalDecision
+		spanAttributes := actualData.SpanAttributes
+		// If the decision is pending, add the new spans still under the lock, so
+		// the decision doesn't happen in between the transition from pending.
+		if finalDecision == sampling.Pending {
+			traceTd := prepareTraceBatch(spans, singleTrace, td)
+			actualData.ReceivedBatches = append(actualData.ReceivedBatches, traceTd)
+			if tsp.maxBufferedBytes > 0 {
+				size := spansSize(spans)
+				actualData.BufferedBytes += size
+				atomic.AddInt64(&tsp.bufferedBytes, size)
 			}
 			actualData.Unlock()
+			continue
+		}
+		actualData.Unlock()
+
+		switch finalDecision {
+		case sampling.Sampled:
+			// Forward the spans to the destination.
+			traceTd := prepareTraceBatch(spans, singleTrace, td)
+			tsp.forward(traceTd, tsp.sampledBy(actualData), spanAttributes)
+		case sampling.NotSampled:
+		case sampling.Dropped:
+			// The trace was removed from memory while the spans were added.
+			continue
+		default:
+			tsp.logger.Warn("Encountered unexpected sampling decision",
+				zap.Int("decision", int(finalDecision)))
+			continue
+		}
 
-			switch actualDecision {
-			case sampling.Pending:
-				// All process for pending done above, keep the case so it doesn't go to default.
-			case sampling.Sampled:
-				// Forward the spans to the policy destinations
-				traceTd := prepareTraceBatch(spans, singleTrace, td)
-				if err := tsp.nextConsumer.ConsumeTraceData(policy.ctx, traceTd); err != nil {
-					tsp.logger.Warn("Error sending late arrived spans to destination",
-						zap.String("policy", policy.Name),
-						zap.Error(err))
-				}
-				fallthrough // so OnLateArrivingSpans is also called for decision Sampled.
-			case sampling.NotSampled:
-				policy.Evaluator.OnLateArrivingSpans(actualDecision, spans)
-				stats.Record(tsp.ctx, statLateSpanArrivalAfterDecision.M(int64(time.Since(actualData.DecisionTime)/time.Second)))
-
-			default:
-				tsp.logger.Warn("Encountered unexpected sampling decision",
+		for i, policy := range tsp.policies {
+			if err := policy.Evaluator.OnLateArrivingSpans(actualData.Decisions[i], spans); err != nil {
+				tsp.logger.Debug("OnLateArrivingSpans",
 					zap.String("policy", policy.Name),
-					zap.Int("decision", int(actualDecision)))
+					zap.Error(err))
 			}
 		}
+		stats.Record(tsp.ctx, statLateSpanArrivalAfterDecision.M(int64(time.Since(actualData.DecisionTime)/time.Second)))
+	}
+
+	if tsp.maxBufferedBytes > 0 {
+		tsp.evictOverBudget()
 	}
 
 	stats.Record(tsp.ctx, statNewTraceIDReceivedCount.M(newTraceIDs))
 	return nil
 }
 
-func (tsp *tailSamplingSpanProcessor) dropTrace(traceID traceKey, deletionTime time.Time) {
+// evictOverBudget removes the oldest traces waiting for a decision from memory
+// until their spans fit in the budget of bytes. The traces already decided
+// hold no spans, so they are moved to the back of the queue instead.
+func (tsp *tailSamplingSpanProcessor) evictOverBudget() {
+	var evicted int64
+	currTime := time.Now()
+	// Each trace in the queue is checked at most once.
+	for remaining := len(tsp.deleteChan); remaining > 0 && atomic.LoadInt64(&tsp.bufferedBytes) > tsp.maxBufferedBytes; remaining-- {
+		var traceKeyToDrop traceKey
+		select {
+		case traceKeyToDrop = <-tsp.deleteChan:
+		default:
+			// Another goroutine is evicting the remaining traces.
+			stats.Record(tsp.ctx, statTracesEvictedForMemoryCount.M(evicted))
+			return
+		}
+
+		if !tsp.isPending(traceKeyToDrop) {
+			select {
+			case tsp.deleteChan <- traceKeyToDrop:
+			default:
+				// The queue was filled concurrently, remove the trace as if
+				// it was its turn.
+				tsp.dropTrace(traceKeyToDrop, currTime)
+			}
+			continue
+		}
+		if tsp.dropTrace(traceKeyToDrop, currTime) > 0 {
+			evicted++
+		}
+	}
+	stats.Record(tsp.ctx,
+		statTracesEvictedForMemoryCount.M(evicted),
+		statBufferedBytesGauge.M(atomic.LoadInt64(&tsp.bufferedBytes)))
+}
+
+// isPending returns whether the trace is in memory and waiting for a decision.
+func (tsp *tailSamplingSpanProcessor) isPending(id traceKey) bool {
+	d, ok := tsp.idToTrace.Load(id)
+	if !ok {
+		return false
+	}
+	trace := d.(*sampling.TraceData)
+	trace.Lock()
+	defer trace.Unlock()
+	return trace.FinalDecision == sampling.Pending
+}
+
+// spansSize returns the approximate serialized size of the spans.
+func spansSize(spans []*tracepb.Span) int64 {
+	var size int
+	for _, span := range spans {
+		size += proto.Size(span)
+	}
+	return int64(size)
+}
+
+// applyCachedDecision forwards or drops the spans of a trace that is no longer
+// in memory according to the cached decision for the trace. It returns false
+// if the trace is in memory or there is no cached decision.
+func (tsp *tailSamplingSpanProcessor) applyCachedDecision(id traceKey, spans []*tracepb.Span, singleTrace bool, td consumerdata.TraceData) bool {
+	if _, ok := tsp.idToTrace.Load(id); ok {
+		return false
+	}
+	cached, ok := tsp.decisionCache.Get(id)
+	if !ok {
+		// Only the late spans of traces that were already removed from
+		// memory are misses, not the spans of new traces.
+		if tsp.decisionCache.WasEvicted(id) {
+			stats.Record(tsp.ctx, statDecisionCacheMissCount.M(int64(1)))
+		}
+		return false
+	}
+	stats.Record(tsp.ctx, statDecisionCacheHitCount.M(int64(1)))
+	if cached.decision == sampling.Sampled {
+		tsp.forward(prepareTraceBatch(spans, singleTrace, td), cached.policies, cached.spanAttributes)
+	}
+	return true
+}
+
+// dropTrace removes the trace from memory and returns the number of bytes of
+// buffered spans released.
+func (tsp *tailSamplingSpanProcessor) dropTrace(traceID traceKey, deletionTime time.Time) int64 {
 	var trace *sampling.TraceData
 	if d, ok := tsp.idToTrace.Load(traceID); ok {
 		trace = d.(*sampling.TraceData)
@@ -321,8 +781,21 @@ func (tsp *tailSamplingSpanProcessor) dropTrace(traceID traceKey, deletionTime t
 	}
 	if trace == nil {
 		tsp.logger.Error("Attempt to delete traceID not on table")
-		return
+		return 0
 	}
+
+	trace.Lock()
+	if trace.FinalDecision == sampling.Pending {
+		trace.FinalDecision = sampling.Dropped
+		if tsp.decisionCache != nil {
+			tsp.decisionCache.MarkEvicted(traceID)
+		}
+	}
+	releasedBytes := trace.BufferedBytes
+	trace.BufferedBytes = 0
+	trace.Unlock()
+	atomic.AddInt64(&tsp.bufferedBytes, -releasedBytes)
+
 	policiesLen := len(tsp.policies)
 	stats.Record(tsp.ctx, statTraceRemovalAgeSec.M(int64(deletionTime.Sub(trace.ArrivalTime)/time.Second)))
 	for j := 0; j < policiesLen; j++ {
@@ -336,6 +809,7 @@ func (tsp *tailSamplingSpanProcessor) dropTrace(traceID traceKey, deletionTime t
 			}
 		}
 	}
+	return releasedBytes
 }
 
 func prepareTraceBatch(spans []*tracepb.Span, singleTrace bool, td consumerdata.TraceData) consumerdata.TraceData {
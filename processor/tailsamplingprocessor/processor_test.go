@@ -16,15 +16,23 @@ package tailsamplingprocessor
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/adaptivesampling"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplingprobability"
 	"github.com/open-telemetry/opentelemetry-service/processor"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/idbatcher"
 	"github.com/open-telemetry/opentelemetry-service/processor/tailsamplingprocessor/sampling"
@@ -159,6 +167,91 @@ func TestConcurrentTraceMapSize(t *testing.T) {
 	}
 }
 
+func TestTraceMapMaxBufferedBytes(t *testing.T) {
+	traceIds, batches := generateIdsAndBatches(4)
+	spanSize := int64(proto.Size(batches[0].Spans[0]))
+	msp := &mockSpanProcessor{}
+	mpe := &mockPolicyEvaluator{NextDecision: sampling.Sampled}
+	tsp := &tailSamplingSpanProcessor{
+		ctx:              context.Background(),
+		nextConsumer:     msp,
+		maxNumTraces:     100,
+		maxBufferedBytes: 5 * spanSize,
+		logger:           zap.NewNop(),
+		decisionBatcher:  newSyncIDBatcher(1),
+		policies:         []*Policy{{Name: "mock-policy", Evaluator: mpe, ctx: context.TODO()}},
+		deleteChan:       make(chan traceKey, 100),
+		policyTicker:     &manualTTicker{},
+	}
+
+	// The traces have 1, 2, 3 and 4 spans of the same size, the budget is
+	// exceeded by the third trace and again by the fourth one.
+	for _, batch := range batches {
+		tsp.ConsumeTraceData(context.Background(), batch)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, ok := tsp.idToTrace.Load(traceKey(traceIds[i])); ok {
+			t.Fatalf("Found unexpected traceId[%d] still on map (id: %v)", i, traceIds[i])
+		}
+	}
+	if _, ok := tsp.idToTrace.Load(traceKey(traceIds[3])); !ok {
+		t.Fatalf("Missing expected traceId[3] (id: %v)", traceIds[3])
+	}
+	if mpe.OnDroppedSpansCount != 3 {
+		t.Fatalf("got %d dropped traces notified to the policy, want 3", mpe.OnDroppedSpansCount)
+	}
+	if got, want := atomic.LoadInt64(&tsp.bufferedBytes), 4*spanSize; got != want {
+		t.Fatalf("got %d buffered bytes, want %d", got, want)
+	}
+
+	// The bytes are released once the decision is made.
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	if msp.TotalSpans != 4 {
+		t.Fatalf("got %d sampled spans, want 4", msp.TotalSpans)
+	}
+	if got := atomic.LoadInt64(&tsp.bufferedBytes); got != 0 {
+		t.Fatalf("got %d buffered bytes after the decision, want 0", got)
+	}
+}
+
+func TestTraceMapMaxBufferedBytes_SkipsDecidedTraces(t *testing.T) {
+	traceIds, batches := generateIdsAndBatches(3)
+	spanSize := int64(proto.Size(batches[0].Spans[0]))
+	mpe := &mockPolicyEvaluator{NextDecision: sampling.Sampled}
+	tsp := &tailSamplingSpanProcessor{
+		ctx:              context.Background(),
+		nextConsumer:     &mockSpanProcessor{},
+		maxNumTraces:     100,
+		maxBufferedBytes: 3 * spanSize,
+		logger:           zap.NewNop(),
+		decisionBatcher:  newSyncIDBatcher(1),
+		policies:         []*Policy{{Name: "mock-policy", Evaluator: mpe, ctx: context.TODO()}},
+		deleteChan:       make(chan traceKey, 100),
+		policyTicker:     &manualTTicker{},
+	}
+
+	// The first trace is decided, so it holds no bytes and isn't evicted
+	// when the third trace exceeds the budget, the second trace is.
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	// The batches have a single span, the traces have 1, 2 and 3 spans.
+	for _, batch := range batches[1:] {
+		require.NoError(t, tsp.ConsumeTraceData(context.Background(), batch))
+	}
+
+	_, ok := tsp.idToTrace.Load(traceKey(traceIds[0]))
+	assert.True(t, ok)
+	_, ok = tsp.idToTrace.Load(traceKey(traceIds[1]))
+	assert.False(t, ok)
+	_, ok = tsp.idToTrace.Load(traceKey(traceIds[2]))
+	assert.True(t, ok)
+	assert.Equal(t, 1, mpe.OnDroppedSpansCount)
+	assert.Equal(t, 3*spanSize, atomic.LoadInt64(&tsp.bufferedBytes))
+}
+
 func TestSamplingPolicyTypicalPath(t *testing.T) {
 	const maxSize = 100
 	const decisionWaitSeconds = 5
@@ -217,6 +310,374 @@ func TestSamplingPolicyTypicalPath(t *testing.T) {
 	}
 }
 
+func TestSamplingPolicyFinalDecision(t *testing.T) {
+	tests := []struct {
+		name          string
+		decisions     []sampling.Decision
+		modes         []PolicyMode
+		wantSampled   bool
+		wantAttribute string
+	}{
+		{
+			name:        "none sampled",
+			decisions:   []sampling.Decision{sampling.NotSampled, sampling.NotSampled},
+			modes:       []PolicyMode{Sample, Sample},
+			wantSampled: false,
+		},
+		{
+			name:          "one sampled",
+			decisions:     []sampling.Decision{sampling.NotSampled, sampling.Sampled},
+			modes:         []PolicyMode{Sample, ""},
+			wantSampled:   true,
+			wantAttribute: "policy-1",
+		},
+		{
+			name:          "all sampled",
+			decisions:     []sampling.Decision{sampling.Sampled, sampling.Sampled},
+			modes:         []PolicyMode{Sample, Sample},
+			wantSampled:   true,
+			wantAttribute: "policy-0,policy-1",
+		},
+		{
+			name:        "never sample wins",
+			decisions:   []sampling.Decision{sampling.Sampled, sampling.Sampled},
+			modes:       []PolicyMode{Sample, NeverSample},
+			wantSampled: false,
+		},
+		{
+			name:          "never sample not matching",
+			decisions:     []sampling.Decision{sampling.Sampled, sampling.NotSampled},
+			modes:         []PolicyMode{Sample, NeverSample},
+			wantSampled:   true,
+			wantAttribute: "policy-0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &exportertest.SinkTraceExporter{}
+			var policies []*Policy
+			for i, decision := range tt.decisions {
+				policies = append(policies, &Policy{
+					Name:      fmt.Sprintf("policy-%d", i),
+					Evaluator: &mockPolicyEvaluator{NextDecision: decision},
+					Mode:      tt.modes[i],
+					ctx:       context.TODO(),
+				})
+			}
+			tsp := &tailSamplingSpanProcessor{
+				ctx:             context.Background(),
+				nextConsumer:    sink,
+				maxNumTraces:    10,
+				logger:          zap.NewNop(),
+				decisionBatcher: newSyncIDBatcher(1),
+				policies:        policies,
+				deleteChan:      make(chan traceKey, 10),
+				policyTicker:    &manualTTicker{},
+			}
+
+			_, batches := generateIdsAndBatches(2)
+			// The second trace has two spans in separate batches.
+			for _, batch := range batches[1:] {
+				require.NoError(t, tsp.ConsumeTraceData(context.Background(), batch))
+			}
+			tsp.samplingPolicyOnTick()
+			tsp.samplingPolicyOnTick()
+
+			if !tt.wantSampled {
+				assert.Empty(t, sink.AllTraces())
+				return
+			}
+
+			// The trace is forwarded once whatever the number of policies
+			// that sampled it.
+			traces := sink.AllTraces()
+			require.Len(t, traces, 2)
+			for _, td := range traces {
+				require.Len(t, td.Spans, 1)
+				attr := td.Spans[0].Attributes.AttributeMap[policiesAttributeKey]
+				assert.Equal(t, tt.wantAttribute, attr.GetStringValue().GetValue())
+			}
+			// The received spans must not be modified.
+			assert.Nil(t, batches[1].Spans[0].Attributes)
+
+			// Late spans follow the final decision.
+			require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[1]))
+			assert.Len(t, sink.AllTraces(), 3)
+		})
+	}
+}
+
+func TestSamplingPolicySpanAttributes(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tsp := &tailSamplingSpanProcessor{
+		ctx:             context.Background(),
+		nextConsumer:    sink,
+		maxNumTraces:    10,
+		logger:          zap.NewNop(),
+		decisionBatcher: newSyncIDBatcher(1),
+		policies: []*Policy{{
+			Name:      "adaptive",
+			Evaluator: sampling.NewAdaptive(adaptivesampling.NewSampler(10, time.Minute, 0)),
+			ctx:       context.TODO(),
+		}},
+		deleteChan:    make(chan traceKey, 10),
+		policyTicker:  &manualTTicker{},
+		decisionCache: newDecisionCache(10),
+	}
+
+	_, batches := generateIdsAndBatches(1)
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	// Late spans, in memory and from the decision cache, get the same
+	// attributes.
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+	tsp.dropTrace(traceKey(batches[0].Spans[0].TraceId), time.Now())
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+
+	traces := sink.AllTraces()
+	require.Len(t, traces, 3)
+	for _, td := range traces {
+		attrs := td.Spans[0].Attributes.AttributeMap
+		assert.Equal(t, 1.0, attrs[samplingprobability.AttributeKey].GetDoubleValue())
+		assert.Equal(t, "adaptive", attrs[policiesAttributeKey].GetStringValue().GetValue())
+	}
+}
+
+func TestSamplingPolicySpanAttributes_WinningPolicy(t *testing.T) {
+	sink := &exportertest.SinkTraceExporter{}
+	tsp := &tailSamplingSpanProcessor{
+		ctx:             context.Background(),
+		nextConsumer:    sink,
+		maxNumTraces:    10,
+		logger:          zap.NewNop(),
+		decisionBatcher: newSyncIDBatcher(1),
+		policies: []*Policy{
+			{
+				Name:      "adaptive",
+				Evaluator: sampling.NewAdaptive(adaptivesampling.NewSampler(10, time.Minute, 0)),
+				ctx:       context.TODO(),
+			},
+			{Name: "always", Evaluator: sampling.NewAlwaysSample(), ctx: context.TODO()},
+		},
+		deleteChan:   make(chan traceKey, 10),
+		policyTicker: &manualTTicker{},
+	}
+
+	_, batches := generateIdsAndBatches(1)
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+
+	// The trace is sampled with certainty by the second policy, so it has no
+	// probability.
+	traces := sink.AllTraces()
+	require.Len(t, traces, 1)
+	attrs := traces[0].Spans[0].Attributes.AttributeMap
+	assert.NotContains(t, attrs, samplingprobability.AttributeKey)
+	assert.Equal(t, "adaptive,always", attrs[policiesAttributeKey].GetStringValue().GetValue())
+}
+
+func TestWithAttributes(t *testing.T) {
+	_, batches := generateIdsAndBatches(1)
+	td := batches[0]
+	original := proto.Clone(td.Spans[0]).(*tracepb.Span)
+
+	// The batch is returned as is when there is nothing to add.
+	got := withAttributes(td, "", nil)
+	assert.True(t, &got.Spans[0] == &td.Spans[0])
+
+	value := &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: 1}}
+	got = withAttributes(td, "policy", map[string]*tracepb.AttributeValue{"key": value})
+	require.Len(t, got.Spans, len(td.Spans))
+	attrs := got.Spans[0].Attributes.AttributeMap
+	assert.Equal(t, value, attrs["key"])
+	assert.Equal(t, "policy", attrs[policiesAttributeKey].GetStringValue().GetValue())
+	// The spans of the original batch are not modified.
+	assert.True(t, proto.Equal(original, td.Spans[0]))
+}
+
+func TestSamplingPolicyDestinations(t *testing.T) {
+	next := &exportertest.SinkTraceExporter{}
+	pipelines := &fakePipelineConsumers{
+		exporters: map[string]consumer.TraceConsumer{
+			"long_retention": &exportertest.SinkTraceExporter{},
+			"cheap":          &exportertest.SinkTraceExporter{},
+		},
+		pipelines: map[string]consumer.TraceConsumer{
+			"traces/errors": &exportertest.SinkTraceExporter{},
+		},
+	}
+	newPolicy := func(name string, decision sampling.Decision) *Policy {
+		return &Policy{
+			Name:      name,
+			Evaluator: &mockPolicyEvaluator{NextDecision: decision},
+			ctx:       context.TODO(),
+		}
+	}
+	errorsPolicy := newPolicy("errors", sampling.Sampled)
+	errorsPolicy.Exporters = []string{"long_retention"}
+	errorsPolicy.Pipeline = "traces/errors"
+	baselinePolicy := newPolicy("baseline", sampling.Sampled)
+	baselinePolicy.Exporters = []string{"cheap", "long_retention"}
+	defaultPolicy := newPolicy("default", sampling.NotSampled)
+
+	tsp := &tailSamplingSpanProcessor{
+		ctx:             context.Background(),
+		nextConsumer:    next,
+		maxNumTraces:    10,
+		logger:          zap.NewNop(),
+		decisionBatcher: newSyncIDBatcher(1),
+		policies:        []*Policy{errorsPolicy, baselinePolicy, defaultPolicy},
+		deleteChan:      make(chan traceKey, 10),
+		policyTicker:    &manualTTicker{},
+	}
+	require.NoError(t, tsp.ConnectPipelines(pipelines))
+
+	_, batches := generateIdsAndBatches(1)
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[0]))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+
+	// Each destination of the policies that sampled the trace receives it
+	// once, the next consumer isn't used by these policies.
+	assert.Empty(t, next.AllTraces())
+	assert.Len(t, pipelines.exporters["long_retention"].(*exportertest.SinkTraceExporter).AllTraces(), 1)
+	assert.Len(t, pipelines.exporters["cheap"].(*exportertest.SinkTraceExporter).AllTraces(), 1)
+	assert.Len(t, pipelines.pipelines["traces/errors"].(*exportertest.SinkTraceExporter).AllTraces(), 1)
+
+	// A policy without destinations sends to the next consumer.
+	defaultPolicy.Evaluator.(*mockPolicyEvaluator).NextDecision = sampling.Sampled
+	errorsPolicy.Evaluator.(*mockPolicyEvaluator).NextDecision = sampling.NotSampled
+	baselinePolicy.Evaluator.(*mockPolicyEvaluator).NextDecision = sampling.NotSampled
+	_, batches = generateIdsAndBatches(2)
+	require.NoError(t, tsp.ConsumeTraceData(context.Background(), batches[1]))
+	tsp.samplingPolicyOnTick()
+	tsp.samplingPolicyOnTick()
+	assert.Len(t, next.AllTraces(), 1)
+	assert.Len(t, pipelines.exporters["long_retention"].(*exportertest.SinkTraceExporter).AllTraces(), 1)
+
+	// Unknown destinations fail the connection.
+	errorsPolicy.Exporters = []string{"missing"}
+	assert.Error(t, tsp.ConnectPipelines(pipelines))
+	errorsPolicy.Exporters = nil
+	errorsPolicy.Pipeline = "traces/missing"
+	assert.Error(t, tsp.ConnectPipelines(pipelines))
+}
+
+func TestGetPolicyEvaluator_Errors(t *testing.T) {
+	alwaysSample := PolicyCfg{Name: "always", Type: AlwaysSample}
+	tests := []struct {
+		name string
+		cfg  PolicyCfg
+	}{
+		{
+			name: "unknown type",
+			cfg:  PolicyCfg{Type: "unknown"},
+		},
+		{
+			name: "latency without threshold",
+			cfg:  PolicyCfg{Type: Latency},
+		},
+		{
+			name: "span count with max below min",
+			cfg:  PolicyCfg{Type: SpanCount, SpanCountCfg: SpanCountCfg{MinSpans: 10, MaxSpans: 5}},
+		},
+		{
+			name: "probabilistic above 100 percent",
+			cfg:  PolicyCfg{Type: Probabilistic, ProbabilisticCfg: ProbabilisticCfg{SamplingPercentage: 150}},
+		},
+		{
+			name: "adaptive without traces per second",
+			cfg:  PolicyCfg{Type: Adaptive},
+		},
+		{
+			name: "and without sub-policies",
+			cfg:  PolicyCfg{Type: And},
+		},
+		{
+			name: "or with invalid sub-policy",
+			cfg:  PolicyCfg{Type: Or, OrCfg: OrCfg{SubPolicies: []PolicyCfg{{Type: "unknown"}}}},
+		},
+		{
+			name: "not without sub-policy",
+			cfg:  PolicyCfg{Type: Not},
+		},
+		{
+			name: "composite without budget",
+			cfg:  PolicyCfg{Type: Composite, CompositeCfg: CompositeCfg{SubPolicies: []PolicyCfg{alwaysSample}}},
+		},
+		{
+			name: "composite with unknown allocation",
+			cfg: PolicyCfg{Type: Composite, CompositeCfg: CompositeCfg{
+				MaxTotalSpansPerSecond: 100,
+				SubPolicies:            []PolicyCfg{alwaysSample},
+				RateAllocation:         []RateAllocationCfg{{Policy: "unknown", Percent: 10}},
+			}},
+		},
+		{
+			name: "composite with allocation above 100 percent",
+			cfg: PolicyCfg{Type: Composite, CompositeCfg: CompositeCfg{
+				MaxTotalSpansPerSecond: 100,
+				SubPolicies:            []PolicyCfg{alwaysSample},
+				RateAllocation:         []RateAllocationCfg{{Policy: "always", Percent: 110}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getPolicyEvaluator(&tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGetPolicyEvaluator_Nested(t *testing.T) {
+	cfg := &PolicyCfg{
+		Type: Composite,
+		CompositeCfg: CompositeCfg{
+			MaxTotalSpansPerSecond: 100,
+			SubPolicies: []PolicyCfg{
+				{
+					Name: "errors",
+					Type: And,
+					AndCfg: AndCfg{SubPolicies: []PolicyCfg{
+						{Type: StringAttribute, StringAttributeCfg: StringAttributeCfg{Key: "service", Values: []string{"checkout"}}},
+						{Type: NumericAttribute, NumericAttributeCfg: NumericAttributeCfg{Key: "http.status_code", MinValue: 500, MaxValue: 599}},
+					}},
+				},
+			},
+		},
+	}
+	eval, err := getPolicyEvaluator(cfg)
+	require.NoError(t, err)
+
+	newTrace := func(statusCode int64) *sampling.TraceData {
+		return &sampling.TraceData{
+			SpanCount: 1,
+			ReceivedBatches: []consumerdata.TraceData{{
+				Spans: []*tracepb.Span{{
+					Attributes: &tracepb.Span_Attributes{AttributeMap: map[string]*tracepb.AttributeValue{
+						"service": {Value: &tracepb.AttributeValue_StringValue{
+							StringValue: &tracepb.TruncatableString{Value: "checkout"},
+						}},
+						"http.status_code": {Value: &tracepb.AttributeValue_IntValue{IntValue: statusCode}},
+					}},
+				}},
+			}},
+		}
+	}
+
+	decision, err := eval.Evaluate(nil, newTrace(503))
+	require.NoError(t, err)
+	assert.Equal(t, sampling.Sampled, decision)
+
+	decision, err = eval.Evaluate(nil, newTrace(200))
+	require.NoError(t, err)
+	assert.Equal(t, sampling.NotSampled, decision)
+}
+
 func generateIdsAndBatches(numIds int) ([][]byte, []consumerdata.TraceData) {
 	traceIds := make([][]byte, numIds)
 	for i := 0; i < numIds; i++ {
@@ -316,6 +777,26 @@ func (s *syncIDBatcher) CloseCurrentAndTakeFirstBatch() (idbatcher.Batch, bool)
 func (s *syncIDBatcher) Stop() {
 }
 
+type fakePipelineConsumers struct {
+	exporters map[string]consumer.TraceConsumer
+	pipelines map[string]consumer.TraceConsumer
+}
+
+var _ processor.PipelineConsumers = (*fakePipelineConsumers)(nil)
+
+func (f *fakePipelineConsumers) TraceConsumer(pipelineName string) consumer.TraceConsumer {
+	return f.pipelines[pipelineName]
+}
+func (f *fakePipelineConsumers) MetricsConsumer(pipelineName string) consumer.MetricsConsumer {
+	return nil
+}
+func (f *fakePipelineConsumers) TraceExporter(exporterName string) consumer.TraceConsumer {
+	return f.exporters[exporterName]
+}
+func (f *fakePipelineConsumers) MetricsExporter(exporterName string) consumer.MetricsConsumer {
+	return nil
+}
+
 type mockSpanProcessor struct {
 	TotalSpans int
 }
@@ -0,0 +1,83 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/adaptivesampling"
+)
+
+type adaptive struct {
+	sampler *adaptivesampling.Sampler
+}
+
+var _ ProbabilisticEvaluator = (*adaptive)(nil)
+
+// NewAdaptive returns a policy that samples traces with a probability per
+// service and operation of their root span, adjusted by the sampler to sample
+// a target number of traces per second for each of them.
+func NewAdaptive(sampler *adaptivesampling.Sampler) PolicyEvaluator {
+	return &adaptive{sampler: sampler}
+}
+
+func (a *adaptive) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return nil
+}
+
+func (a *adaptive) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	decision, _, err := a.EvaluateWithProbability(traceID, trace)
+	return decision, err
+}
+
+func (a *adaptive) EvaluateWithProbability(traceID []byte, trace *TraceData) (Decision, float64, error) {
+	trace.Lock()
+	batches := trace.ReceivedBatches
+	trace.Unlock()
+
+	service, operation := rootOperation(batches)
+	sampled, probability := a.sampler.Sample(service, operation, traceID)
+	if !sampled {
+		return NotSampled, probability, nil
+	}
+	return Sampled, probability, nil
+}
+
+func (a *adaptive) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return NotSampled, nil
+}
+
+// rootOperation returns the service and name of the root span of the trace,
+// or of its first span if the root span wasn't received.
+func rootOperation(batches []consumerdata.TraceData) (string, string) {
+	var service, operation string
+	found := false
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if span == nil {
+				continue
+			}
+			if len(span.ParentSpanId) == 0 {
+				return batch.Node.GetServiceInfo().GetName(), span.Name.GetValue()
+			}
+			if !found {
+				service, operation = batch.Node.GetServiceInfo().GetName(), span.Name.GetValue()
+				found = true
+			}
+		}
+	}
+	return service, operation
+}
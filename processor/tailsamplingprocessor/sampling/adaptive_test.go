@@ -0,0 +1,68 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/adaptivesampling"
+)
+
+func TestAdaptive(t *testing.T) {
+	policy := NewAdaptive(adaptivesampling.NewSampler(10, time.Minute, 0)).(ProbabilisticEvaluator)
+	trace := newTraceData([]*tracepb.Span{{}})
+
+	// Traces of a new service and operation are sampled until the first
+	// adjustment, with a probability of 1.
+	decision, probability, err := policy.EvaluateWithProbability([]byte{1, 2, 3, 4}, trace)
+	require.NoError(t, err)
+	assert.Equal(t, Sampled, decision)
+	assert.Equal(t, 1.0, probability)
+	// The policy doesn't annotate the trace itself.
+	assert.Nil(t, trace.SpanAttributes)
+
+	decision, err = policy.Evaluate([]byte{1, 2, 3, 4}, trace)
+	require.NoError(t, err)
+	assert.Equal(t, Sampled, decision)
+}
+
+func TestRootOperation(t *testing.T) {
+	node := func(service string) *commonpb.Node {
+		return &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: service}}
+	}
+	child := &tracepb.Span{ParentSpanId: []byte{1}, Name: &tracepb.TruncatableString{Value: "child"}}
+	root := &tracepb.Span{Name: &tracepb.TruncatableString{Value: "root"}}
+
+	service, operation := rootOperation([]consumerdata.TraceData{
+		{Node: node("backend"), Spans: []*tracepb.Span{nil, child}},
+		{Node: node("frontend"), Spans: []*tracepb.Span{root}},
+	})
+	assert.Equal(t, "frontend", service)
+	assert.Equal(t, "root", operation)
+
+	// The first span is used if the root span wasn't received.
+	service, operation = rootOperation([]consumerdata.TraceData{
+		{Node: node("backend"), Spans: []*tracepb.Span{child}},
+	})
+	assert.Equal(t, "backend", service)
+	assert.Equal(t, "child", operation)
+}
@@ -0,0 +1,150 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+type and struct {
+	subPolicies []PolicyEvaluator
+}
+
+var _ PolicyEvaluator = (*and)(nil)
+
+// NewAnd returns a policy that samples a trace if all of the sub-policies
+// sample it.
+func NewAnd(subPolicies []PolicyEvaluator) PolicyEvaluator {
+	return &and{subPolicies: subPolicies}
+}
+
+func (a *and) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return onLateArrivingSpans(a.subPolicies, earlyDecision, spans)
+}
+
+func (a *and) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	for _, p := range a.subPolicies {
+		decision, err := p.Evaluate(traceID, trace)
+		if err != nil || decision != Sampled {
+			return NotSampled, err
+		}
+	}
+	return Sampled, nil
+}
+
+func (a *and) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	for _, p := range a.subPolicies {
+		decision, err := p.OnDroppedSpans(traceID, trace)
+		if err != nil || decision != Sampled {
+			return NotSampled, err
+		}
+	}
+	return Sampled, nil
+}
+
+type or struct {
+	subPolicies []PolicyEvaluator
+}
+
+var _ PolicyEvaluator = (*or)(nil)
+
+// NewOr returns a policy that samples a trace if any of the sub-policies
+// samples it.
+func NewOr(subPolicies []PolicyEvaluator) PolicyEvaluator {
+	return &or{subPolicies: subPolicies}
+}
+
+func (o *or) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return onLateArrivingSpans(o.subPolicies, earlyDecision, spans)
+}
+
+func (o *or) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	for _, p := range o.subPolicies {
+		decision, err := p.Evaluate(traceID, trace)
+		if err != nil {
+			return NotSampled, err
+		}
+		if decision == Sampled {
+			return Sampled, nil
+		}
+	}
+	return NotSampled, nil
+}
+
+func (o *or) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	for _, p := range o.subPolicies {
+		decision, err := p.OnDroppedSpans(traceID, trace)
+		if err != nil {
+			return NotSampled, err
+		}
+		if decision == Sampled {
+			return Sampled, nil
+		}
+	}
+	return NotSampled, nil
+}
+
+type not struct {
+	subPolicy PolicyEvaluator
+}
+
+var _ PolicyEvaluator = (*not)(nil)
+
+// NewNot returns a policy that samples a trace if the sub-policy doesn't
+// sample it.
+func NewNot(subPolicy PolicyEvaluator) PolicyEvaluator {
+	return &not{subPolicy: subPolicy}
+}
+
+func (n *not) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return n.subPolicy.OnLateArrivingSpans(invert(earlyDecision), spans)
+}
+
+func (n *not) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	decision, err := n.subPolicy.Evaluate(traceID, trace)
+	if err != nil {
+		return NotSampled, err
+	}
+	return invert(decision), nil
+}
+
+func (n *not) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	decision, err := n.subPolicy.OnDroppedSpans(traceID, trace)
+	if err != nil {
+		return NotSampled, err
+	}
+	return invert(decision), nil
+}
+
+func invert(decision Decision) Decision {
+	switch decision {
+	case Sampled:
+		return NotSampled
+	case NotSampled:
+		return Sampled
+	default:
+		return decision
+	}
+}
+
+// onLateArrivingSpans notifies all the sub-policies and returns the first
+// error.
+func onLateArrivingSpans(subPolicies []PolicyEvaluator, earlyDecision Decision, spans []*tracepb.Span) error {
+	var firstErr error
+	for _, p := range subPolicies {
+		if err := p.OnLateArrivingSpans(earlyDecision, spans); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
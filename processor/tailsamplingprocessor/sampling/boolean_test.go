@@ -0,0 +1,91 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"errors"
+	"testing"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedPolicy always returns the same decision and counts the spans it was
+// notified of.
+type fixedPolicy struct {
+	decision  Decision
+	err       error
+	lateSpans int
+}
+
+var _ PolicyEvaluator = (*fixedPolicy)(nil)
+
+func (f *fixedPolicy) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	f.lateSpans += len(spans)
+	return nil
+}
+
+func (f *fixedPolicy) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	return f.decision, f.err
+}
+
+func (f *fixedPolicy) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return f.decision, f.err
+}
+
+func TestBooleanPolicies(t *testing.T) {
+	sampled := &fixedPolicy{decision: Sampled}
+	notSampled := &fixedPolicy{decision: NotSampled}
+	failing := &fixedPolicy{decision: Sampled, err: errors.New("failed")}
+
+	tests := []struct {
+		name    string
+		policy  PolicyEvaluator
+		want    Decision
+		wantErr bool
+	}{
+		{name: "and all sampled", policy: NewAnd([]PolicyEvaluator{sampled, sampled}), want: Sampled},
+		{name: "and one not sampled", policy: NewAnd([]PolicyEvaluator{sampled, notSampled}), want: NotSampled},
+		{name: "and error", policy: NewAnd([]PolicyEvaluator{sampled, failing}), want: NotSampled, wantErr: true},
+		{name: "or one sampled", policy: NewOr([]PolicyEvaluator{notSampled, sampled}), want: Sampled},
+		{name: "or none sampled", policy: NewOr([]PolicyEvaluator{notSampled, notSampled}), want: NotSampled},
+		{name: "or error", policy: NewOr([]PolicyEvaluator{failing, sampled}), want: NotSampled, wantErr: true},
+		{name: "not sampled", policy: NewNot(sampled), want: NotSampled},
+		{name: "not not sampled", policy: NewNot(notSampled), want: Sampled},
+		{name: "not error", policy: NewNot(failing), want: NotSampled, wantErr: true},
+		{
+			name:   "nested",
+			policy: NewOr([]PolicyEvaluator{NewAnd([]PolicyEvaluator{sampled, notSampled}), NewNot(notSampled)}),
+			want:   Sampled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := tt.policy.Evaluate(nil, &TraceData{})
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, decision)
+		})
+	}
+}
+
+func TestBooleanPolicies_OnLateArrivingSpans(t *testing.T) {
+	p1, p2 := &fixedPolicy{}, &fixedPolicy{}
+	spans := []*tracepb.Span{{}, {}}
+
+	assert.NoError(t, NewAnd([]PolicyEvaluator{p1, p2}).OnLateArrivingSpans(Sampled, spans))
+	assert.NoError(t, NewNot(p1).OnLateArrivingSpans(Sampled, spans))
+	assert.Equal(t, 4, p1.lateSpans)
+	assert.Equal(t, 2, p2.lateSpans)
+}
@@ -0,0 +1,110 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+)
+
+// SubPolicy is a sub-policy of a composite policy with its budget.
+type SubPolicy struct {
+	// Evaluator of the sub-policy.
+	Evaluator PolicyEvaluator
+	// MaxSpansPerSecond is the maximum number of spans per second sampled by
+	// the sub-policy. If it is 0 the sub-policy is only limited by the budget
+	// of the composite policy.
+	MaxSpansPerSecond int64
+}
+
+type subPolicyState struct {
+	SubPolicy
+	spansInCurrentSecond int64
+}
+
+type composite struct {
+	maxTotalSpansPerSecond int64
+	subPolicies            []*subPolicyState
+
+	currentSecond        int64
+	spansInCurrentSecond int64
+
+	// now is a variable so that tests can control the time.
+	now func() time.Time
+}
+
+var _ PolicyEvaluator = (*composite)(nil)
+
+// NewComposite returns a policy that evaluates the sub-policies in order and
+// samples a trace if a sub-policy samples it within its budget and the total
+// budget of the composite policy. If a sub-policy exceeded its budget, the
+// following sub-policies are evaluated.
+func NewComposite(maxTotalSpansPerSecond int64, subPolicies []SubPolicy) PolicyEvaluator {
+	states := make([]*subPolicyState, len(subPolicies))
+	for i, subPolicy := range subPolicies {
+		states[i] = &subPolicyState{SubPolicy: subPolicy}
+	}
+	return &composite{
+		maxTotalSpansPerSecond: maxTotalSpansPerSecond,
+		subPolicies:            states,
+		now:                    time.Now,
+	}
+}
+
+func (c *composite) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	var firstErr error
+	for _, p := range c.subPolicies {
+		if err := p.Evaluator.OnLateArrivingSpans(earlyDecision, spans); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (c *composite) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	currSecond := c.now().Unix()
+	if c.currentSecond != currSecond {
+		c.currentSecond = currSecond
+		c.spansInCurrentSecond = 0
+		for _, p := range c.subPolicies {
+			p.spansInCurrentSecond = 0
+		}
+	}
+
+	if c.spansInCurrentSecond+trace.SpanCount > c.maxTotalSpansPerSecond {
+		return NotSampled, nil
+	}
+
+	for _, p := range c.subPolicies {
+		if p.MaxSpansPerSecond > 0 && p.spansInCurrentSecond+trace.SpanCount > p.MaxSpansPerSecond {
+			continue
+		}
+		decision, err := p.Evaluator.Evaluate(traceID, trace)
+		if err != nil {
+			return NotSampled, err
+		}
+		if decision == Sampled {
+			p.spansInCurrentSecond += trace.SpanCount
+			c.spansInCurrentSecond += trace.SpanCount
+			return Sampled, nil
+		}
+	}
+	return NotSampled, nil
+}
+
+func (c *composite) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return NotSampled, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposite(t *testing.T) {
+	first := &fixedPolicy{decision: Sampled}
+	second := &fixedPolicy{decision: Sampled}
+	c := NewComposite(10, []SubPolicy{
+		{Evaluator: first, MaxSpansPerSecond: 4},
+		{Evaluator: second},
+	}).(*composite)
+
+	now := time.Unix(100, 0)
+	c.now = func() time.Time { return now }
+
+	evaluate := func(spanCount int64) Decision {
+		decision, err := c.Evaluate(nil, &TraceData{SpanCount: spanCount})
+		require.NoError(t, err)
+		return decision
+	}
+
+	// The first sub-policy samples until its budget is exhausted, then the
+	// second one takes over until the total budget is exhausted.
+	assert.Equal(t, Sampled, evaluate(3))
+	assert.Equal(t, int64(3), c.subPolicies[0].spansInCurrentSecond)
+	assert.Equal(t, Sampled, evaluate(3))
+	assert.Equal(t, int64(3), c.subPolicies[0].spansInCurrentSecond)
+	assert.Equal(t, int64(3), c.subPolicies[1].spansInCurrentSecond)
+	assert.Equal(t, Sampled, evaluate(4))
+	assert.Equal(t, NotSampled, evaluate(1))
+
+	// The budgets are reset every second.
+	now = now.Add(time.Second)
+	assert.Equal(t, Sampled, evaluate(4))
+	assert.Equal(t, int64(4), c.subPolicies[0].spansInCurrentSecond)
+}
+
+func TestComposite_NotSampled(t *testing.T) {
+	c := NewComposite(10, []SubPolicy{
+		{Evaluator: &fixedPolicy{decision: NotSampled}},
+		{Evaluator: &fixedPolicy{decision: NotSampled}},
+	})
+	decision, err := c.Evaluate(nil, &TraceData{SpanCount: 1})
+	require.NoError(t, err)
+	assert.Equal(t, NotSampled, decision)
+}
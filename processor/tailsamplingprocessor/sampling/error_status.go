@@ -0,0 +1,63 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+type errorStatus struct {
+	// codes is nil if any error code matches.
+	codes map[int32]bool
+}
+
+var _ PolicyEvaluator = (*errorStatus)(nil)
+
+// NewErrorStatus returns a policy that samples traces with a span whose
+// status code is one of the given codes, or any error code, i.e. other than
+// 0 (OK), if no code is given.
+func NewErrorStatus(codes []int32) PolicyEvaluator {
+	es := &errorStatus{}
+	if len(codes) > 0 {
+		es.codes = make(map[int32]bool, len(codes))
+		for _, code := range codes {
+			es.codes[code] = true
+		}
+	}
+	return es
+}
+
+func (es *errorStatus) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return nil
+}
+
+func (es *errorStatus) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	trace.Lock()
+	batches := trace.ReceivedBatches
+	trace.Unlock()
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if span == nil || span.Status == nil || span.Status.Code == 0 {
+				continue
+			}
+			if es.codes == nil || es.codes[span.Status.Code] {
+				return Sampled, nil
+			}
+		}
+	}
+	return NotSampled, nil
+}
+
+func (es *errorStatus) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return NotSampled, nil
+}
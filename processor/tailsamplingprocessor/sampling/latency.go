@@ -0,0 +1,73 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+)
+
+type latency struct {
+	threshold time.Duration
+}
+
+var _ PolicyEvaluator = (*latency)(nil)
+
+// NewLatency returns a policy that samples traces lasting at least the
+// threshold, from the earliest start to the latest end of their spans.
+func NewLatency(threshold time.Duration) PolicyEvaluator {
+	return &latency{threshold: threshold}
+}
+
+func (l *latency) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return nil
+}
+
+func (l *latency) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	trace.Lock()
+	batches := trace.ReceivedBatches
+	trace.Unlock()
+
+	var start, end time.Time
+	for _, batch := range batches {
+		for _, span := range batch.Spans {
+			if span == nil || span.StartTime == nil || span.EndTime == nil {
+				continue
+			}
+			spanStart, spanEnd := toTime(span.StartTime), toTime(span.EndTime)
+			if start.IsZero() || spanStart.Before(start) {
+				start = spanStart
+			}
+			if end.IsZero() || spanEnd.After(end) {
+				end = spanEnd
+			}
+		}
+	}
+
+	if !start.IsZero() && end.Sub(start) >= l.threshold {
+		return Sampled, nil
+	}
+	return NotSampled, nil
+}
+
+func (l *latency) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return NotSampled, nil
+}
+
+func toTime(ts *timestamp.Timestamp) time.Time {
+	return time.Unix(ts.Seconds, int64(ts.Nanos))
+}
@@ -28,6 +28,9 @@ type TraceData struct {
 	sync.Mutex
 	// Decisions gives the current status of the sampling decision for each policy.
 	Decisions []Decision
+	// FinalDecision is the decision for the trace made from the decisions of
+	// all the policies. It is Pending until the policies are evaluated.
+	FinalDecision Decision
 	// Arrival time the first span for the trace was received.
 	ArrivalTime time.Time
 	// Decisiontime time when sampling decision was taken.
@@ -36,6 +39,14 @@ type TraceData struct {
 	SpanCount int64
 	// ReceivedBatches stores all the batches received for the trace.
 	ReceivedBatches []consumerdata.TraceData
+	// BufferedBytes is the approximate serialized size of the spans in
+	// ReceivedBatches. It is only tracked if the memory used by the traces is
+	// bounded.
+	BufferedBytes int64
+	// SpanAttributes are added to the spans of the trace if it is sampled,
+	// e.g. the probability used to sample it. They are set by the processor
+	// once the final decision is made.
+	SpanAttributes map[string]*tracepb.AttributeValue
 }
 
 // Decision gives the status of sampling decision.
@@ -73,3 +84,14 @@ type PolicyEvaluator interface {
 	// pressure, before the decision_wait time has been reached.
 	OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error)
 }
+
+// ProbabilisticEvaluator is implemented by the policies sampling traces with a
+// probability, so that the probability of the policy that sampled a trace can
+// be added to its spans.
+type ProbabilisticEvaluator interface {
+	PolicyEvaluator
+
+	// EvaluateWithProbability is like Evaluate but also returns the
+	// probability used to make the decision.
+	EvaluateWithProbability(traceID []byte, trace *TraceData) (Decision, float64, error)
+}
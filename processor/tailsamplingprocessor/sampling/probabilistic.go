@@ -0,0 +1,63 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"math"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/internal/processor/samplinghash"
+)
+
+type probabilistic struct {
+	hashSeed           uint32
+	scaledSamplingRate uint32
+	// probability is the sampling percentage as a probability.
+	probability float64
+}
+
+var _ ProbabilisticEvaluator = (*probabilistic)(nil)
+
+// NewProbabilistic returns a policy that samples a percentage of the traces by
+// hashing their trace ID. It makes the same decisions as the probabilistic
+// sampler processor for the same hash seed and percentage.
+func NewProbabilistic(hashSeed uint32, samplingPercentage float32) PolicyEvaluator {
+	return &probabilistic{
+		hashSeed:           hashSeed,
+		scaledSamplingRate: samplinghash.ScaledSamplingRate(samplingPercentage),
+		probability:        math.Max(0, math.Min(float64(samplingPercentage)/100, 1)),
+	}
+}
+
+func (p *probabilistic) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return nil
+}
+
+func (p *probabilistic) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	decision, _, err := p.EvaluateWithProbability(traceID, trace)
+	return decision, err
+}
+
+func (p *probabilistic) EvaluateWithProbability(traceID []byte, trace *TraceData) (Decision, float64, error) {
+	if samplinghash.IsSampled(traceID, p.hashSeed, p.scaledSamplingRate) {
+		return Sampled, p.probability, nil
+	}
+	return NotSampled, p.probability, nil
+}
+
+func (p *probabilistic) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return NotSampled, nil
+}
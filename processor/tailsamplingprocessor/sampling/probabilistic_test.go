@@ -0,0 +1,66 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func TestProbabilistic(t *testing.T) {
+	tests := []struct {
+		name               string
+		hashSeed           uint32
+		samplingPercentage float32
+	}{
+		{name: "0%", samplingPercentage: 0},
+		{name: "5%", samplingPercentage: 5},
+		{name: "33% with seed", hashSeed: 22, samplingPercentage: 33},
+		{name: "100%", samplingPercentage: 100},
+	}
+
+	const numTraces = 10000
+	r := rand.New(rand.NewSource(1))
+	traceIDs := make([][]byte, numTraces)
+	for i := range traceIDs {
+		traceIDs[i] = tracetranslator.UInt64ToByteTraceID(r.Uint64(), r.Uint64())
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProbabilistic(tt.hashSeed, tt.samplingPercentage).(ProbabilisticEvaluator)
+			sampled := 0
+			for _, traceID := range traceIDs {
+				decision, err := p.Evaluate(traceID, &TraceData{})
+				require.NoError(t, err)
+				if decision == Sampled {
+					sampled++
+				}
+				// The probability is recorded so that the backends can
+				// extrapolate the baseline traces.
+				decisionWithProbability, probability, err := p.EvaluateWithProbability(traceID, &TraceData{})
+				require.NoError(t, err)
+				assert.Equal(t, decision, decisionWithProbability)
+				assert.InDelta(t, tt.samplingPercentage/100, probability, 1e-6)
+			}
+			assert.InDelta(t, tt.samplingPercentage, 100*float32(sampled)/numTraces, 1)
+		})
+	}
+}
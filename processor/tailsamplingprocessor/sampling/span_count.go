@@ -0,0 +1,52 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"sync/atomic"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+)
+
+type spanCount struct {
+	minSpans, maxSpans int64
+}
+
+var _ PolicyEvaluator = (*spanCount)(nil)
+
+// NewSpanCount returns a policy that samples traces with a number of spans in
+// the range [minSpans, maxSpans]. There is no upper bound if maxSpans is 0.
+func NewSpanCount(minSpans, maxSpans int64) PolicyEvaluator {
+	return &spanCount{
+		minSpans: minSpans,
+		maxSpans: maxSpans,
+	}
+}
+
+func (sc *spanCount) OnLateArrivingSpans(earlyDecision Decision, spans []*tracepb.Span) error {
+	return nil
+}
+
+func (sc *spanCount) Evaluate(traceID []byte, trace *TraceData) (Decision, error) {
+	count := atomic.LoadInt64(&trace.SpanCount)
+	if count >= sc.minSpans && (sc.maxSpans == 0 || count <= sc.maxSpans) {
+		return Sampled, nil
+	}
+	return NotSampled, nil
+}
+
+func (sc *spanCount) OnDroppedSpans(traceID []byte, trace *TraceData) (Decision, error) {
+	return NotSampled, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sampling
+
+import (
+	"testing"
+	"time"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+func TestLatency(t *testing.T) {
+	// The trace lasts 1.5s across two batches: from 10s to 11.5s.
+	trace := newTraceData(
+		[]*tracepb.Span{newTimedSpan(10, 0, 10, 500e6), nil},
+		[]*tracepb.Span{newTimedSpan(11, 0, 11, 500e6), {}},
+	)
+
+	tests := []struct {
+		threshold time.Duration
+		want      Decision
+	}{
+		{threshold: time.Second, want: Sampled},
+		{threshold: 1500 * time.Millisecond, want: Sampled},
+		{threshold: 2 * time.Second, want: NotSampled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.threshold.String(), func(t *testing.T) {
+			decision, err := NewLatency(tt.threshold).Evaluate(nil, trace)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, decision)
+		})
+	}
+
+	decision, err := NewLatency(time.Nanosecond).Evaluate(nil, newTraceData([]*tracepb.Span{{}}))
+	require.NoError(t, err)
+	assert.Equal(t, NotSampled, decision, "spans without times must be ignored")
+}
+
+func TestErrorStatus(t *testing.T) {
+	ok := newTraceData([]*tracepb.Span{{Status: &tracepb.Status{Code: 0}}, {}})
+	internal := newTraceData([]*tracepb.Span{{}}, []*tracepb.Span{{Status: &tracepb.Status{Code: 13}}})
+
+	tests := []struct {
+		name  string
+		codes []int32
+		trace *TraceData
+		want  Decision
+	}{
+		{name: "any error", trace: internal, want: Sampled},
+		{name: "no error", trace: ok, want: NotSampled},
+		{name: "listed code", codes: []int32{13, 14}, trace: internal, want: Sampled},
+		{name: "unlisted code", codes: []int32{14}, trace: internal, want: NotSampled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := NewErrorStatus(tt.codes).Evaluate(nil, tt.trace)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, decision)
+		})
+	}
+}
+
+func TestSpanCount(t *testing.T) {
+	tests := []struct {
+		name               string
+		minSpans, maxSpans int64
+		spanCount          int64
+		want               Decision
+	}{
+		{name: "in range", minSpans: 2, maxSpans: 5, spanCount: 5, want: Sampled},
+		{name: "below range", minSpans: 2, maxSpans: 5, spanCount: 1, want: NotSampled},
+		{name: "above range", minSpans: 2, maxSpans: 5, spanCount: 6, want: NotSampled},
+		{name: "no maximum", minSpans: 2, spanCount: 1000, want: Sampled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := NewSpanCount(tt.minSpans, tt.maxSpans).Evaluate(nil, &TraceData{SpanCount: tt.spanCount})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, decision)
+		})
+	}
+}
+
+func newTraceData(batches ...[]*tracepb.Span) *TraceData {
+	trace := &TraceData{}
+	for _, spans := range batches {
+		trace.ReceivedBatches = append(trace.ReceivedBatches, consumerdata.TraceData{Spans: spans})
+		trace.SpanCount += int64(len(spans))
+	}
+	return trace
+}
+
+func newTimedSpan(startSeconds int64, startNanos int32, endSeconds int64, endNanos int32) *tracepb.Span {
+	return &tracepb.Span{
+		StartTime: &timestamp.Timestamp{Seconds: startSeconds, Nanos: startNanos},
+		EndTime:   &timestamp.Timestamp{Seconds: endSeconds, Nanos: endNanos},
+	}
+}
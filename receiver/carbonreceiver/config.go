@@ -0,0 +1,57 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carbonreceiver
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for Carbon receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// UDPEndpoint is the address to also receive Graphite plaintext lines
+	// over UDP. It is disabled if empty, the Endpoint is only used for TCP.
+	UDPEndpoint string `mapstructure:"udp_endpoint"`
+
+	// TCPIdleTimeout is how long a TCP connection can go without sending
+	// data before it is closed. Idle connections are never closed if it is
+	// not positive.
+	TCPIdleTimeout time.Duration `mapstructure:"tcp_idle_timeout"`
+
+	// Rules are the templates used to split the paths of untagged lines into
+	// a metric name and labels. The first rule matching a path is applied,
+	// the path is used as metric name if none matches.
+	Rules []*RuleConfig `mapstructure:"rules"`
+}
+
+// RuleConfig is a regular expression template applied to the metric paths.
+// Its named capturing groups define how the path is mapped:
+// - "name" and "name_*" groups are joined, in the order of their names and
+// separated by dots, to form the metric name. The whole path is used if the
+// rule has no such group.
+// - "key_<label>" groups become the "<label>" label.
+type RuleConfig struct {
+	// Regexp is the regular expression matched against the path.
+	Regexp string `mapstructure:"regexp"`
+
+	// NamePrefix is prepended to the names of the metrics matching the rule.
+	NamePrefix string `mapstructure:"name_prefix"`
+
+	// Labels are static labels added to the metrics matching the rule.
+	Labels map[string]string `mapstructure:"labels"`
+}
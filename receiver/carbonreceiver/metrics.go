@@ -0,0 +1,105 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carbonreceiver
+
+import (
+	"sort"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+// toMetrics converts the given lines into double gauges, one per metric name
+// in the order they were first received. The lines of the same metric name
+// and labels are the points of a single timeseries.
+func toMetrics(cms []carbonMetric) []*metricspb.Metric {
+	var names []string
+	byName := make(map[string][]carbonMetric)
+	for _, cm := range cms {
+		if _, ok := byName[cm.name]; !ok {
+			names = append(names, cm.name)
+		}
+		byName[cm.name] = append(byName[cm.name], cm)
+	}
+
+	metrics := make([]*metricspb.Metric, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, toMetric(name, byName[name]))
+	}
+	return metrics
+}
+
+func toMetric(name string, cms []carbonMetric) *metricspb.Metric {
+	// the label keys are the union of the keys of all the lines
+	keySet := make(map[string]bool)
+	for _, cm := range cms {
+		for _, l := range cm.labels {
+			keySet[l.key] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labelKeys := make([]*metricspb.LabelKey, len(keys))
+	for i, key := range keys {
+		labelKeys[i] = &metricspb.LabelKey{Key: key}
+	}
+
+	var timeseries []*metricspb.TimeSeries
+	bySignature := make(map[string]*metricspb.TimeSeries)
+	for _, cm := range cms {
+		labelValues := make([]*metricspb.LabelValue, len(keys))
+		signature := ""
+		for i, key := range keys {
+			labelValues[i] = &metricspb.LabelValue{}
+			for _, l := range cm.labels {
+				if l.key == key {
+					labelValues[i] = &metricspb.LabelValue{Value: l.value, HasValue: true}
+					signature += key + "=" + l.value
+					break
+				}
+			}
+			signature += "\x00"
+		}
+
+		point := &metricspb.Point{
+			Timestamp: internal.TimeToTimestamp(cm.timestamp),
+			Value:     &metricspb.Point_DoubleValue{DoubleValue: cm.value},
+		}
+		if ts, ok := bySignature[signature]; ok {
+			ts.Points = append(ts.Points, point)
+			continue
+		}
+		ts := &metricspb.TimeSeries{
+			LabelValues: labelValues,
+			Points:      []*metricspb.Point{point},
+		}
+		bySignature[signature] = ts
+		timeseries = append(timeseries, ts)
+	}
+
+	return &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      name,
+			Type:      metricspb.MetricDescriptor_GAUGE_DOUBLE,
+			LabelKeys: labelKeys,
+		},
+		Timeseries: timeseries,
+	}
+}
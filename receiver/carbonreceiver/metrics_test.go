@@ -0,0 +1,66 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carbonreceiver
+
+import (
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+func TestToMetrics(t *testing.T) {
+	t0 := time.Unix(1570000000, 0)
+	t1 := t0.Add(10 * time.Second)
+	metrics := toMetrics([]carbonMetric{
+		{name: "cpu.load", value: 1, timestamp: t0, labels: []label{{key: "host", value: "a"}}},
+		{name: "disk.used", value: 50, timestamp: t0},
+		{name: "cpu.load", value: 2, timestamp: t0, labels: []label{{key: "cpu", value: "0"}, {key: "host", value: "b"}}},
+		{name: "cpu.load", value: 3, timestamp: t1, labels: []label{{key: "host", value: "a"}}},
+	})
+
+	require.Len(t, metrics, 2)
+	assert.Equal(t, &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:      "cpu.load",
+			Type:      metricspb.MetricDescriptor_GAUGE_DOUBLE,
+			LabelKeys: []*metricspb.LabelKey{{Key: "cpu"}, {Key: "host"}},
+		},
+		Timeseries: []*metricspb.TimeSeries{
+			{
+				LabelValues: []*metricspb.LabelValue{{}, {Value: "a", HasValue: true}},
+				Points: []*metricspb.Point{
+					{Timestamp: internal.TimeToTimestamp(t0), Value: &metricspb.Point_DoubleValue{DoubleValue: 1}},
+					{Timestamp: internal.TimeToTimestamp(t1), Value: &metricspb.Point_DoubleValue{DoubleValue: 3}},
+				},
+			},
+			{
+				LabelValues: []*metricspb.LabelValue{{Value: "0", HasValue: true}, {Value: "b", HasValue: true}},
+				Points: []*metricspb.Point{
+					{Timestamp: internal.TimeToTimestamp(t0), Value: &metricspb.Point_DoubleValue{DoubleValue: 2}},
+				},
+			},
+		},
+	}, metrics[0])
+
+	assert.Equal(t, "disk.used", metrics[1].MetricDescriptor.Name)
+	assert.Empty(t, metrics[1].MetricDescriptor.LabelKeys)
+	require.Len(t, metrics[1].Timeseries, 1)
+	assert.Equal(t, 50.0, metrics[1].Timeseries[0].Points[0].GetDoubleValue())
+}
@@ -0,0 +1,231 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carbonreceiver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	nameGroup        = "name"
+	nameGroupPrefix  = "name_"
+	labelGroupPrefix = "key_"
+)
+
+var (
+	errInvalidLine = errors.New("expected \"path value timestamp\"")
+	errEmptyPath   = errors.New("empty metric path")
+	errInvalidTag  = errors.New("expected tags as \"tag=value\"")
+)
+
+// label is either a Graphite tag or a label extracted from a path by a rule.
+type label struct {
+	key   string
+	value string
+}
+
+// carbonMetric is a single parsed Graphite plaintext line, e.g.:
+// "servers.web01.cpu.load 0.75 1570000000" or "cpu.load;host=web01 0.75 1570000000".
+type carbonMetric struct {
+	name  string
+	value float64
+	// timestamp is the time the line was received at if it had a negative
+	// timestamp, as Graphite does.
+	timestamp time.Time
+	// labels are sorted by key.
+	labels []label
+}
+
+// rule is the compiled form of a RuleConfig.
+type rule struct {
+	re         *regexp.Regexp
+	namePrefix string
+	labels     []label
+	// nameGroups are the indexes of the name groups, in the order of their
+	// names.
+	nameGroups []int
+	// labelGroups are the indexes of the label groups by label key.
+	labelGroups map[string]int
+}
+
+func newRule(cfg *RuleConfig) (*rule, error) {
+	re, err := regexp.Compile(cfg.Regexp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Carbon rule regexp %q: %v", cfg.Regexp, err)
+	}
+
+	r := &rule{
+		re:          re,
+		namePrefix:  cfg.NamePrefix,
+		labelGroups: make(map[string]int),
+	}
+	for k, v := range cfg.Labels {
+		r.labels = append(r.labels, label{key: k, value: v})
+	}
+
+	var nameGroups []string
+	groupIndexes := make(map[string]int)
+	for i, group := range re.SubexpNames() {
+		switch {
+		case group == nameGroup || strings.HasPrefix(group, nameGroupPrefix):
+			nameGroups = append(nameGroups, group)
+			groupIndexes[group] = i
+		case strings.HasPrefix(group, labelGroupPrefix) && len(group) > len(labelGroupPrefix):
+			r.labelGroups[strings.TrimPrefix(group, labelGroupPrefix)] = i
+		}
+	}
+	sort.Strings(nameGroups)
+	for _, group := range nameGroups {
+		r.nameGroups = append(r.nameGroups, groupIndexes[group])
+	}
+	return r, nil
+}
+
+// apply maps the given path into a metric name and labels, it returns false
+// if the rule doesn't match the path.
+func (r *rule) apply(path string) (string, []label, bool) {
+	match := r.re.FindStringSubmatch(path)
+	if match == nil {
+		return "", nil, false
+	}
+
+	name := path
+	if len(r.nameGroups) > 0 {
+		parts := make([]string, 0, len(r.nameGroups))
+		for _, i := range r.nameGroups {
+			if match[i] != "" {
+				parts = append(parts, match[i])
+			}
+		}
+		name = strings.Join(parts, ".")
+	}
+
+	labels := make([]label, 0, len(r.labels)+len(r.labelGroups))
+	labels = append(labels, r.labels...)
+	for key, i := range r.labelGroups {
+		if match[i] != "" {
+			labels = append(labels, label{key: key, value: match[i]})
+		}
+	}
+	return r.namePrefix + name, labels, true
+}
+
+// parser parses Graphite plaintext lines, using its rules to map the paths
+// of the untagged ones.
+type parser struct {
+	rules []*rule
+}
+
+func newParser(cfgs []*RuleConfig) (*parser, error) {
+	p := &parser{}
+	for _, cfg := range cfgs {
+		r, err := newRule(cfg)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, r)
+	}
+	return p, nil
+}
+
+// parseLine parses a single Graphite plaintext line, the path can be
+// followed by Graphite tags. The lines with a negative timestamp are
+// timestamped with now.
+func (p *parser) parseLine(line string, now time.Time) (carbonMetric, error) {
+	var m carbonMetric
+
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return m, errInvalidLine
+	}
+
+	value, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return m, fmt.Errorf("invalid value %q: %v", fields[1], err)
+	}
+	m.value = value
+
+	ts, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil || math.IsNaN(ts) || math.IsInf(ts, 0) {
+		return m, fmt.Errorf("invalid timestamp %q", fields[2])
+	}
+	if ts < 0 {
+		m.timestamp = now
+	} else {
+		sec, frac := math.Modf(ts)
+		m.timestamp = time.Unix(int64(sec), int64(frac*1e9))
+	}
+
+	path := fields[0]
+	if semicolon := strings.IndexByte(path, ';'); semicolon >= 0 {
+		m.labels, err = parseTags(path[semicolon+1:])
+		if err != nil {
+			return m, err
+		}
+		m.name = path[:semicolon]
+	} else {
+		m.name, m.labels = p.mapPath(path)
+	}
+	if m.name == "" {
+		return m, errEmptyPath
+	}
+
+	m.labels = sortLabels(m.labels)
+	return m, nil
+}
+
+// sortLabels sorts the labels by key, only the last of the labels sharing
+// the same key is kept, so that the labels captured by a rule override its
+// static ones.
+func sortLabels(labels []label) []label {
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].key < labels[j].key })
+	sorted := labels[:0]
+	for i, l := range labels {
+		if i+1 < len(labels) && labels[i+1].key == l.key {
+			continue
+		}
+		sorted = append(sorted, l)
+	}
+	return sorted
+}
+
+// mapPath applies the first rule matching the given path.
+func (p *parser) mapPath(path string) (string, []label) {
+	for _, r := range p.rules {
+		if name, labels, ok := r.apply(path); ok {
+			return name, labels
+		}
+	}
+	return path, nil
+}
+
+func parseTags(s string) ([]label, error) {
+	var labels []label
+	for _, t := range strings.Split(s, ";") {
+		eq := strings.IndexByte(t, '=')
+		if eq <= 0 || eq == len(t)-1 {
+			return nil, fmt.Errorf("%v, got %q", errInvalidTag, t)
+		}
+		labels = append(labels, label{key: t[:eq], value: t[eq+1:]})
+	}
+	return labels, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carbonreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	p, err := newParser([]*RuleConfig{
+		{
+			Regexp:     `^servers\.(?P<key_host>[^.]+)\.(?P<name>.+)$`,
+			NamePrefix: "server.",
+			Labels:     map[string]string{"source": "graphite", "host": "unknown"},
+		},
+		{
+			Regexp: `^apps\.(?P<key_app>[^.]+)\.(?P<name_1>[^.]+)\.(?P<name_0>[^.]+)$`,
+		},
+		{
+			Regexp: `^(?P<key_env>prod|dev)\.`,
+		},
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(1570000100, 0)
+	tests := []struct {
+		line    string
+		want    carbonMetric
+		wantErr bool
+	}{
+		{
+			line: "cpu.load 0.75 1570000000",
+			want: carbonMetric{name: "cpu.load", value: 0.75, timestamp: time.Unix(1570000000, 0)},
+		},
+		{
+			line: "servers.web01.cpu.load 1 1570000000",
+			want: carbonMetric{
+				name:      "server.cpu.load",
+				value:     1,
+				timestamp: time.Unix(1570000000, 0),
+				labels:    []label{{key: "host", value: "web01"}, {key: "source", value: "graphite"}},
+			},
+		},
+		{
+			line: "apps.shop.requests.count 12 1570000000.5",
+			want: carbonMetric{
+				name:      "count.requests",
+				value:     12,
+				timestamp: time.Unix(1570000000, 5e8),
+				labels:    []label{{key: "app", value: "shop"}},
+			},
+		},
+		{
+			line: "prod.db.queries 3 -1",
+			want: carbonMetric{
+				name:      "prod.db.queries",
+				value:     3,
+				timestamp: now,
+				labels:    []label{{key: "env", value: "prod"}},
+			},
+		},
+		{
+			line: "servers.web01.cpu.load;zone=eu;host=web02  2\t1570000000",
+			want: carbonMetric{
+				name:      "servers.web01.cpu.load",
+				value:     2,
+				timestamp: time.Unix(1570000000, 0),
+				labels:    []label{{key: "host", value: "web02"}, {key: "zone", value: "eu"}},
+			},
+		},
+		{line: "cpu.load 0.75", wantErr: true},
+		{line: "cpu.load 0.75 1570000000 extra", wantErr: true},
+		{line: "cpu.load abc 1570000000", wantErr: true},
+		{line: "cpu.load 1 abc", wantErr: true},
+		{line: "cpu.load 1 NaN", wantErr: true},
+		{line: "cpu.load;zone 1 1570000000", wantErr: true},
+		{line: "cpu.load;=eu 1 1570000000", wantErr: true},
+		{line: "cpu.load;zone= 1 1570000000", wantErr: true},
+		{line: ";zone=eu 1 1570000000", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := p.parseLine(tt.line, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.name, got.name)
+			assert.Equal(t, tt.want.value, got.value)
+			assert.True(t, tt.want.timestamp.Equal(got.timestamp), "got timestamp %v", got.timestamp)
+			assert.Equal(t, tt.want.labels, got.labels)
+		})
+	}
+}
+
+func TestNewParser_invalidRule(t *testing.T) {
+	_, err := newParser([]*RuleConfig{{Regexp: "(?P<name"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,298 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carbonreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+var _ receiver.MetricsReceiver = (*Receiver)(nil)
+
+const (
+	metricsSource    string = "Carbon"
+	receiverTagValue        = "carbon"
+
+	// maxUDPPacketSize is the largest payload of a UDP datagram.
+	maxUDPPacketSize = 65535
+	// maxTCPBatchSize is the maximum number of lines read from a TCP
+	// connection that are passed together to the next consumer.
+	maxTCPBatchSize = 1000
+	// maxTCPLineSize is the size of the longest line accepted over TCP, the
+	// connections sending longer ones are closed.
+	maxTCPLineSize = 64 * 1024
+)
+
+// Receiver is the type used to receive Graphite plaintext metrics.
+type Receiver struct {
+	mu sync.Mutex
+
+	logger       *zap.Logger
+	config       *Config
+	nextConsumer consumer.MetricsConsumer
+	parser       *parser
+
+	tcpLn    net.Listener
+	tcpConns map[net.Conn]struct{}
+	udpConn  net.PacketConn
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New creates a Carbon receiver, the servers are only started by
+// StartMetricsReception.
+func New(logger *zap.Logger, config *Config, nextConsumer consumer.MetricsConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	p, err := newParser(config.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return &Receiver{
+		logger:       logger,
+		config:       config,
+		nextConsumer: nextConsumer,
+		parser:       p,
+		tcpConns:     make(map[net.Conn]struct{}),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (r *Receiver) MetricsSource() string {
+	return metricsSource
+}
+
+// StartMetricsReception starts the TCP server and the UDP one if configured.
+func (r *Receiver) StartMetricsReception(host receiver.Host) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+	r.startOnce.Do(func() {
+		r.tcpLn, err = net.Listen("tcp", r.config.Endpoint)
+		if err != nil {
+			return
+		}
+		if r.config.UDPEndpoint != "" {
+			r.udpConn, err = net.ListenPacket("udp", r.config.UDPEndpoint)
+			if err != nil {
+				_ = r.tcpLn.Close()
+				return
+			}
+			r.wg.Add(1)
+			go r.serveUDP(host)
+		}
+
+		r.wg.Add(1)
+		go r.serveTCP(host)
+	})
+	return err
+}
+
+// StopMetricsReception stops the servers and closes the open TCP
+// connections.
+func (r *Receiver) StopMetricsReception() error {
+	var err = oterr.ErrAlreadyStopped
+	r.stopOnce.Do(func() {
+		err = nil
+		close(r.done)
+
+		r.mu.Lock()
+		if r.tcpLn != nil {
+			_ = r.tcpLn.Close()
+		}
+		if r.udpConn != nil {
+			_ = r.udpConn.Close()
+		}
+		for conn := range r.tcpConns {
+			_ = conn.Close()
+		}
+		r.mu.Unlock()
+
+		r.wg.Wait()
+	})
+	return err
+}
+
+// stopping returns true once StopMetricsReception was called, errors
+// returned by the servers after that are expected.
+func (r *Receiver) stopping() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *Receiver) serveUDP(host receiver.Host) {
+	defer r.wg.Done()
+
+	buf := make([]byte, maxUDPPacketSize)
+	for {
+		n, _, err := r.udpConn.ReadFrom(buf)
+		if n > 0 {
+			var b batch
+			for _, line := range bytes.Split(buf[:n], []byte{'\n'}) {
+				r.parseLine(&b, string(line))
+			}
+			r.consume(&b)
+		}
+		if err != nil {
+			if !r.stopping() {
+				host.ReportFatalError(err)
+			}
+			return
+		}
+	}
+}
+
+func (r *Receiver) serveTCP(host receiver.Host) {
+	defer r.wg.Done()
+
+	for {
+		conn, err := r.tcpLn.Accept()
+		if err != nil {
+			if !r.stopping() {
+				host.ReportFatalError(err)
+			}
+			return
+		}
+
+		r.mu.Lock()
+		if r.stopping() {
+			r.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		r.tcpConns[conn] = struct{}{}
+		r.wg.Add(1)
+		r.mu.Unlock()
+
+		go r.handleTCPConn(conn)
+	}
+}
+
+// handleTCPConn reads the lines sent on the given connection, the lines are
+// passed to the next consumer once all the data received so far was read,
+// or every maxTCPBatchSize lines. The connection is closed once it was idle
+// for the configured timeout or if it sends a line longer than maxTCPLineSize.
+func (r *Receiver) handleTCPConn(conn net.Conn) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.tcpConns, conn)
+		r.mu.Unlock()
+		_ = conn.Close()
+		r.wg.Done()
+	}()
+
+	var b batch
+	scanner := bufio.NewScanner(&tcpConnReader{
+		conn:        conn,
+		idleTimeout: r.config.TCPIdleTimeout,
+		// The scanner only reads the connection once it has no complete
+		// line left: all the data received so far was read.
+		beforeRead: func() { r.consume(&b) },
+	})
+	scanner.Buffer(make([]byte, 4096), maxTCPLineSize)
+	for scanner.Scan() {
+		r.parseLine(&b, scanner.Text())
+		if b.lines >= maxTCPBatchSize {
+			r.consume(&b)
+		}
+	}
+	r.consume(&b)
+	if err := scanner.Err(); err != nil && !r.stopping() {
+		r.logger.Debug("Carbon TCP connection failed", zap.Error(err))
+	}
+}
+
+// tcpConnReader reads a TCP connection, calling beforeRead and extending the
+// idle timeout of the connection before each read.
+type tcpConnReader struct {
+	conn        net.Conn
+	idleTimeout time.Duration
+	beforeRead  func()
+}
+
+func (cr *tcpConnReader) Read(p []byte) (int, error) {
+	cr.beforeRead()
+	if cr.idleTimeout > 0 {
+		if err := cr.conn.SetReadDeadline(time.Now().Add(cr.idleTimeout)); err != nil {
+			return 0, err
+		}
+	}
+	return cr.conn.Read(p)
+}
+
+// batch holds the lines received together.
+type batch struct {
+	metrics []carbonMetric
+	lines   int
+	dropped int
+}
+
+func (r *Receiver) parseLine(b *batch, line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	b.lines++
+	m, err := r.parser.parseLine(line, time.Now())
+	if err != nil {
+		b.dropped++
+		r.logger.Debug("Invalid Carbon line", zap.String("line", line), zap.Error(err))
+		return
+	}
+	b.metrics = append(b.metrics, m)
+}
+
+// consume passes the metrics of the batch onto the next consumer and resets
+// the batch.
+func (r *Receiver) consume(b *batch) {
+	defer func() { *b = batch{} }()
+	if b.lines == 0 {
+		return
+	}
+
+	ctx := observability.ContextWithReceiverName(context.Background(), receiverTagValue)
+	observability.RecordMetricsForMetricsReceiver(ctx, b.lines, b.dropped)
+	if len(b.metrics) == 0 {
+		return
+	}
+
+	md := consumerdata.MetricsData{Metrics: toMetrics(b.metrics)}
+	if err := r.nextConsumer.ConsumeMetricsData(ctx, md); err != nil {
+		r.logger.Warn("Failed to pass the Carbon metrics to the next consumer", zap.Error(err))
+	}
+}
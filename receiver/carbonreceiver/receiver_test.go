@@ -0,0 +1,143 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package carbonreceiver
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestReceiver(t *testing.T) {
+	cfg := &Config{
+		ReceiverSettings: configmodels.ReceiverSettings{
+			TypeVal:  typeStr,
+			NameVal:  typeStr,
+			Endpoint: testutils.GetAvailableLocalAddress(t),
+		},
+		UDPEndpoint: testutils.GetAvailableLocalAddress(t),
+	}
+	sink := new(exportertest.SinkMetricsExporter)
+	r, err := New(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	mh := receivertest.NewMockHost()
+	require.NoError(t, r.StartMetricsReception(mh))
+	assert.Equal(t, oterr.ErrAlreadyStarted, r.StartMetricsReception(mh))
+
+	tcpConn, err := net.Dial("tcp", cfg.Endpoint)
+	require.NoError(t, err)
+	_, err = tcpConn.Write([]byte("tcp.load 1 1570000000\ninvalid line\ntcp.load;host=a 2 1570000000\n"))
+	require.NoError(t, err)
+	require.NoError(t, tcpConn.Close())
+
+	udpConn, err := net.Dial("udp", cfg.UDPEndpoint)
+	require.NoError(t, err)
+	defer udpConn.Close()
+	_, err = udpConn.Write([]byte("udp.load 3 1570000000"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(metricNames(sink.AllMetrics())) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, r.StopMetricsReception())
+	assert.Equal(t, oterr.ErrAlreadyStopped, r.StopMetricsReception())
+
+	assert.Equal(t, []string{"tcp.load", "udp.load"}, metricNames(sink.AllMetrics()))
+}
+
+func TestReceiver_TCPConnClosed(t *testing.T) {
+	tests := []struct {
+		name  string
+		after []byte
+	}{
+		{
+			name: "idle",
+		},
+		{
+			name:  "line_too_long",
+			after: append(bytes.Repeat([]byte("a"), maxTCPLineSize+1), '\n'),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				ReceiverSettings: configmodels.ReceiverSettings{
+					TypeVal:  typeStr,
+					NameVal:  typeStr,
+					Endpoint: testutils.GetAvailableLocalAddress(t),
+				},
+				TCPIdleTimeout: 100 * time.Millisecond,
+			}
+			sink := new(exportertest.SinkMetricsExporter)
+			r, err := New(zap.NewNop(), cfg, sink)
+			require.NoError(t, err)
+			require.NoError(t, r.StartMetricsReception(receivertest.NewMockHost()))
+			defer r.StopMetricsReception()
+
+			conn, err := net.Dial("tcp", cfg.Endpoint)
+			require.NoError(t, err)
+			defer conn.Close()
+			_, err = conn.Write([]byte("tcp.load 1 1570000000\n"))
+			require.NoError(t, err)
+			require.Eventually(t, func() bool {
+				return len(sink.AllMetrics()) == 1
+			}, 5*time.Second, 10*time.Millisecond)
+
+			// The write can fail once the receiver closed the connection.
+			_, _ = conn.Write(tt.after)
+
+			// The receiver closes the connection.
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			_, err = conn.Read(make([]byte, 1))
+			require.Error(t, err)
+			if netErr, ok := err.(net.Error); ok {
+				assert.False(t, netErr.Timeout(), "the connection was not closed")
+			}
+		})
+	}
+}
+
+// metricNames returns the unique names of the received metrics, the lines
+// sent over TCP can be split in several batches.
+func metricNames(mds []consumerdata.MetricsData) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, md := range mds {
+		for _, m := range md.Metrics {
+			if !seen[m.MetricDescriptor.Name] {
+				seen[m.MetricDescriptor.Name] = true
+				names = append(names, m.MetricDescriptor.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
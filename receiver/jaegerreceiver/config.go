@@ -15,14 +15,39 @@
 package jaegerreceiver
 
 import (
+	"time"
+
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 )
 
+// RemoteSamplingConfig defines how the Jaeger receiver serves the sampling
+// strategies requested by the Jaeger clients.
+type RemoteSamplingConfig struct {
+	// HostEndpoint is the address of the agent HTTP endpoint serving the
+	// sampling strategies, port 5778 on all the interfaces if not specified.
+	HostEndpoint string `mapstructure:"host_endpoint"`
+
+	// StrategyFile is the path of a Jaeger sampling strategies JSON file.
+	// The strategies are served both on the agent HTTP endpoint and on the
+	// collector gRPC endpoint.
+	StrategyFile string `mapstructure:"strategy_file"`
+
+	// ReloadInterval is how often the strategy file is checked for changes,
+	// 10 seconds if not specified.
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+
+	// FetchEndpoint is the gRPC endpoint of an upstream Jaeger collector, or
+	// OpenTelemetry Service, to proxy the requests of the agent HTTP endpoint
+	// to. It can't be used together with StrategyFile.
+	FetchEndpoint string `mapstructure:"fetch_endpoint"`
+}
+
 // Config defines configuration for Jaeger receiver.
 type Config struct {
-	TypeVal   string                                      `mapstructure:"-"`
-	NameVal   string                                      `mapstructure:"-"`
-	Protocols map[string]*receiver.SecureReceiverSettings `mapstructure:"protocols"`
+	TypeVal        string                                      `mapstructure:"-"`
+	NameVal        string                                      `mapstructure:"-"`
+	Protocols      map[string]*receiver.SecureReceiverSettings `mapstructure:"protocols"`
+	RemoteSampling *RemoteSamplingConfig                       `mapstructure:"remote_sampling"`
 }
 
 // Name gets the receiver name.
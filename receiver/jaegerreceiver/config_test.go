@@ -17,6 +17,7 @@ package jaegerreceiver
 import (
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,7 +40,7 @@ func TestLoadConfig(t *testing.T) {
 
 	// The receiver `jaeger/disabled` doesn't count because disabled receivers
 	// are excluded from the final list.
-	assert.Equal(t, len(cfg.Receivers), 3)
+	assert.Equal(t, len(cfg.Receivers), 4)
 
 	r0 := cfg.Receivers["jaeger"]
 	assert.Equal(t, r0, factory.CreateDefaultConfig())
@@ -96,4 +97,14 @@ func TestLoadConfig(t *testing.T) {
 				},
 			},
 		})
+
+	remoteSamplingConfig := cfg.Receivers["jaeger/remote_sampling"].(*Config)
+	wantRemoteSamplingConfig := factory.CreateDefaultConfig().(*Config)
+	wantRemoteSamplingConfig.NameVal = "jaeger/remote_sampling"
+	wantRemoteSamplingConfig.RemoteSampling = &RemoteSamplingConfig{
+		HostEndpoint:   "localhost:5778",
+		StrategyFile:   "/etc/strategies.json",
+		ReloadInterval: 30 * time.Second,
+	}
+	assert.Equal(t, wantRemoteSamplingConfig, remoteSamplingConfig)
 }
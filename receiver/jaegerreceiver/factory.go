@@ -141,6 +141,27 @@ func (f *Factory) CreateTraceReceiver(
 		}
 	}
 
+	if remoteSampling := rCfg.RemoteSampling; remoteSampling != nil {
+		if remoteSampling.StrategyFile != "" && remoteSampling.FetchEndpoint != "" {
+			return nil, fmt.Errorf("only one of strategy_file and fetch_endpoint can be specified for the remote sampling of %s receiver",
+				typeStr)
+		}
+
+		if remoteSampling.HostEndpoint != "" {
+			var err error
+			config.AgentPort, err = extractPortFromEndpoint(remoteSampling.HostEndpoint)
+			if err != nil {
+				return nil, err
+			}
+			config.AgentHost, _, _ = net.SplitHostPort(remoteSampling.HostEndpoint)
+		}
+
+		config.RemoteSamplingStrategyFile = remoteSampling.StrategyFile
+		config.RemoteSamplingReloadInterval = remoteSampling.ReloadInterval
+		config.RemoteSamplingFetchEndpoint = remoteSampling.FetchEndpoint
+	}
+	config.Logger = logger
+
 	if (protoGRPC == nil && protoHTTP == nil && protoTChannel == nil) ||
 		(config.CollectorGRPCPort == 0 && config.CollectorHTTPPort == 0 && config.CollectorThriftPort == 0) {
 		err := fmt.Errorf("either %v, %v, or %v protocol endpoint with non-zero port must be enabled for %s receiver",
@@ -17,6 +17,7 @@ package jaegerreceiver
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -115,3 +116,42 @@ func TestCreateWithoutThrift(t *testing.T) {
 	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
 	assert.NoError(t, err, "receiver creation without the Thrift protocols must not fail")
 }
+
+func TestCreateRemoteSampling(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.RemoteSampling = &RemoteSamplingConfig{
+		HostEndpoint:   "localhost:5779",
+		StrategyFile:   "strategies.json",
+		ReloadInterval: time.Minute,
+	}
+	r, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.NoError(t, err, "receiver creation with remote sampling must not fail")
+
+	config := r.(*jReceiver).config
+	assert.Equal(t, "localhost", config.AgentHost)
+	assert.Equal(t, 5779, config.AgentPort)
+	assert.Equal(t, "strategies.json", config.RemoteSamplingStrategyFile)
+	assert.Equal(t, time.Minute, config.RemoteSamplingReloadInterval)
+}
+
+func TestCreateRemoteSamplingInvalid(t *testing.T) {
+	factory := Factory{}
+	cfg := factory.CreateDefaultConfig()
+	rCfg := cfg.(*Config)
+
+	rCfg.RemoteSampling = &RemoteSamplingConfig{
+		StrategyFile:  "strategies.json",
+		FetchEndpoint: "localhost:14250",
+	}
+	_, err := factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with both a strategy file and a fetch endpoint must fail")
+
+	rCfg.RemoteSampling = &RemoteSamplingConfig{
+		HostEndpoint: "localhost:",
+	}
+	_, err = factory.CreateTraceReceiver(context.Background(), zap.NewNop(), cfg, nil)
+	assert.Error(t, err, "receiver creation with invalid host endpoint must fail")
+}
@@ -0,0 +1,153 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jaegertracing/jaeger/cmd/agent/app/configmanager"
+	"github.com/jaegertracing/jaeger/cmd/collector/app/sampling/strategystore"
+	"github.com/jaegertracing/jaeger/model/converter/thrift/jaeger"
+	"github.com/jaegertracing/jaeger/plugin/sampling/strategystore/static"
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/baggage"
+	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
+	"go.uber.org/zap"
+)
+
+const defaultStrategyReloadInterval = 10 * time.Second
+
+// strategyFileStore serves the sampling strategies of a Jaeger strategies
+// file, reloading them whenever the file changes.
+type strategyFileStore struct {
+	logger *zap.Logger
+	file   string
+
+	mu    sync.RWMutex
+	store strategystore.StrategyStore
+
+	// modTime and size identify the version of the file currently loaded.
+	modTime time.Time
+	size    int64
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+var _ configmanager.ClientConfigManager = (*strategyFileStore)(nil)
+
+// newStrategyFileStore loads the strategies from the given file and starts
+// checking it for changes every reloadInterval.
+func newStrategyFileStore(file string, reloadInterval time.Duration, logger *zap.Logger) (*strategyFileStore, error) {
+	if reloadInterval <= 0 {
+		reloadInterval = defaultStrategyReloadInterval
+	}
+
+	s := &strategyFileStore{
+		logger: logger,
+		file:   file,
+		stopCh: make(chan struct{}),
+	}
+	if err := s.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	go s.watch(reloadInterval)
+	return s, nil
+}
+
+func (s *strategyFileStore) watch(reloadInterval time.Duration) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.reloadIfChanged(); err != nil {
+				// Keep serving the last strategies loaded successfully.
+				s.logger.Warn("Failed to reload the sampling strategies",
+					zap.String("file", s.file), zap.Error(err))
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// reloadIfChanged loads the strategies file if it was modified since it was
+// last loaded.
+func (s *strategyFileStore) reloadIfChanged() error {
+	fi, err := os.Stat(s.file)
+	if err != nil {
+		return err
+	}
+	if s.store != nil && fi.ModTime().Equal(s.modTime) && fi.Size() == s.size {
+		return nil
+	}
+
+	store, err := static.NewStrategyStore(static.Options{StrategiesFile: s.file}, s.logger)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.store = store
+	s.mu.Unlock()
+	s.modTime = fi.ModTime()
+	s.size = fi.Size()
+	s.logger.Info("Loaded the sampling strategies", zap.String("file", s.file))
+	return nil
+}
+
+// GetSamplingStrategy implements configmanager.ClientConfigManager.
+func (s *strategyFileStore) GetSamplingStrategy(serviceName string) (*sampling.SamplingStrategyResponse, error) {
+	s.mu.RLock()
+	store := s.store
+	s.mu.RUnlock()
+	return store.GetSamplingStrategy(serviceName)
+}
+
+// GetBaggageRestrictions implements configmanager.ClientConfigManager.
+func (s *strategyFileStore) GetBaggageRestrictions(serviceName string) ([]*baggage.BaggageRestriction, error) {
+	return nil, nil
+}
+
+func (s *strategyFileStore) stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+}
+
+// grpcSamplingManager serves the sampling strategies on the collector gRPC
+// endpoint, as the Jaeger agents configured to use gRPC request them there.
+type grpcSamplingManager struct {
+	manager configmanager.ClientConfigManager
+}
+
+var _ api_v2.SamplingManagerServer = (*grpcSamplingManager)(nil)
+
+// GetSamplingStrategy implements api_v2.SamplingManagerServer.
+func (m *grpcSamplingManager) GetSamplingStrategy(
+	ctx context.Context,
+	params *api_v2.SamplingStrategyParameters,
+) (*api_v2.SamplingStrategyResponse, error) {
+	r, err := m.manager.GetSamplingStrategy(params.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	return jaeger.ConvertSamplingResponseToDomain(r)
+}
@@ -0,0 +1,167 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jaegerreceiver
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	"github.com/jaegertracing/jaeger/thrift-gen/sampling"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+func TestStrategyFileStore(t *testing.T) {
+	store, err := newStrategyFileStore(path.Join(".", "testdata", "strategies.json"), time.Hour, zap.NewNop())
+	require.NoError(t, err)
+	defer store.stop()
+
+	s, err := store.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.Equal(t, sampling.SamplingStrategyType_PROBABILISTIC, s.StrategyType)
+	assert.Equal(t, 0.8, s.ProbabilisticSampling.SamplingRate)
+	require.NotNil(t, s.OperationSampling)
+	require.Len(t, s.OperationSampling.PerOperationStrategies, 1)
+	assert.Equal(t, "op1", s.OperationSampling.PerOperationStrategies[0].Operation)
+	assert.Equal(t, 0.2, s.OperationSampling.PerOperationStrategies[0].ProbabilisticSampling.SamplingRate)
+
+	s, err = store.GetSamplingStrategy("bar")
+	require.NoError(t, err)
+	assert.Equal(t, sampling.SamplingStrategyType_RATE_LIMITING, s.StrategyType)
+	assert.Equal(t, int16(5), s.RateLimitingSampling.MaxTracesPerSecond)
+
+	s, err = store.GetSamplingStrategy("unknown")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, s.ProbabilisticSampling.SamplingRate)
+}
+
+func TestStrategyFileStore_Reload(t *testing.T) {
+	f, err := ioutil.TempFile("", "strategies")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	require.NoError(t, f.Close())
+
+	writeStrategies := func(content string) {
+		require.NoError(t, ioutil.WriteFile(f.Name(), []byte(content), 0600))
+	}
+	defaultRate := func(store *strategyFileStore) float64 {
+		s, err := store.GetSamplingStrategy("foo")
+		require.NoError(t, err)
+		return s.ProbabilisticSampling.SamplingRate
+	}
+
+	writeStrategies(`{"default_strategy": {"type": "probabilistic", "param": 0.5}}`)
+	store, err := newStrategyFileStore(f.Name(), time.Hour, zap.NewNop())
+	require.NoError(t, err)
+	defer store.stop()
+	assert.Equal(t, 0.5, defaultRate(store))
+
+	writeStrategies(`{"default_strategy": {"type": "probabilistic", "param": 0.25}}`)
+	require.NoError(t, store.reloadIfChanged())
+	assert.Equal(t, 0.25, defaultRate(store))
+
+	// Invalid files are ignored and the previous strategies kept.
+	writeStrategies(`{"default_strategy": `)
+	require.Error(t, store.reloadIfChanged())
+	assert.Equal(t, 0.25, defaultRate(store))
+}
+
+func TestStrategyFileStore_InvalidFile(t *testing.T) {
+	_, err := newStrategyFileStore(path.Join(".", "testdata", "missing.json"), 0, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestRemoteSamplingFromUpstream(t *testing.T) {
+	// The upstream collector serving the strategies from a file.
+	store, err := newStrategyFileStore(path.Join(".", "testdata", "strategies.json"), time.Hour, zap.NewNop())
+	require.NoError(t, err)
+	defer store.stop()
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	api_v2.RegisterSamplingManagerServer(srv, &grpcSamplingManager{manager: store})
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	// The receiver running as an agent that proxies the requests upstream.
+	jr := &jReceiver{
+		config: &Configuration{
+			RemoteSamplingFetchEndpoint: ln.Addr().String(),
+		},
+	}
+	require.NoError(t, jr.startSampling())
+	defer jr.samplingConn.Close()
+
+	s, err := jr.GetManager().GetSamplingStrategy("bar")
+	require.NoError(t, err)
+	assert.Equal(t, sampling.SamplingStrategyType_RATE_LIMITING, s.StrategyType)
+	assert.Equal(t, int16(5), s.RateLimitingSampling.MaxTracesPerSecond)
+
+	// Without any remote sampling configured an empty strategy is served.
+	jr = &jReceiver{config: &Configuration{}}
+	require.NoError(t, jr.startSampling())
+	s, err = jr.GetManager().GetSamplingStrategy("bar")
+	require.NoError(t, err)
+	assert.Equal(t, &sampling.SamplingStrategyResponse{}, s)
+}
+
+func TestGRPCSamplingManager(t *testing.T) {
+	store, err := newStrategyFileStore(path.Join(".", "testdata", "strategies.json"), time.Hour, zap.NewNop())
+	require.NoError(t, err)
+	defer store.stop()
+
+	m := &grpcSamplingManager{manager: store}
+	s, err := m.GetSamplingStrategy(context.Background(), &api_v2.SamplingStrategyParameters{ServiceName: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, api_v2.SamplingStrategyType_PROBABILISTIC, s.StrategyType)
+	assert.Equal(t, 0.8, s.ProbabilisticSampling.SamplingRate)
+	require.Len(t, s.OperationSampling.PerOperationStrategies, 1)
+	assert.Equal(t, "op1", s.OperationSampling.PerOperationStrategies[0].Operation)
+}
+
+func TestSamplingManagerConcurrentStop(t *testing.T) {
+	jr := &jReceiver{
+		config: &Configuration{
+			RemoteSamplingStrategyFile: path.Join(".", "testdata", "strategies.json"),
+		},
+		tchanServer: &jTchannelReceiver{},
+	}
+	require.NoError(t, jr.startSampling())
+
+	// The strategies keep being served, without racing, while stopping.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_, err := jr.GetSamplingStrategy("foo")
+			assert.NoError(t, err)
+		}
+	}()
+	require.NoError(t, jr.StopTraceReception())
+	<-done
+
+	s, err := jr.GetSamplingStrategy("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 0.8, s.ProbabilisticSampling.SamplingRate)
+}
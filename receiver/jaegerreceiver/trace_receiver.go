@@ -19,13 +19,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"mime"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"time"
 
+	apachethrift "github.com/apache/thrift/lib/go/thrift"
 	"github.com/gorilla/mux"
 	agentapp "github.com/jaegertracing/jaeger/cmd/agent/app"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/configmanager"
+	grpcmanager "github.com/jaegertracing/jaeger/cmd/agent/app/configmanager/grpc"
 	"github.com/jaegertracing/jaeger/cmd/agent/app/reporter"
 	"github.com/jaegertracing/jaeger/cmd/collector/app"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
@@ -40,9 +46,12 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 	jaegertranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/jaeger"
 )
 
@@ -54,9 +63,24 @@ type Configuration struct {
 	CollectorGRPCPort    int
 	CollectorGRPCOptions []grpc.ServerOption
 
+	// AgentHost is the host the agent HTTP endpoint binds to, all the
+	// interfaces if empty.
+	AgentHost              string
 	AgentPort              int
 	AgentCompactThriftPort int
 	AgentBinaryThriftPort  int
+
+	// RemoteSamplingStrategyFile is the Jaeger sampling strategies file served
+	// to the clients, it is checked for changes every RemoteSamplingReloadInterval.
+	RemoteSamplingStrategyFile   string
+	RemoteSamplingReloadInterval time.Duration
+	// RemoteSamplingFetchEndpoint is the gRPC endpoint of the upstream
+	// collector the requests for sampling strategies are proxied to.
+	RemoteSamplingFetchEndpoint string
+
+	// Logger is used to report problems with the sampling strategies, nothing
+	// is logged if it is nil.
+	Logger *zap.Logger
 }
 
 // Receiver type is used to receive spans that were originally intended to be sent to Jaeger.
@@ -78,6 +102,13 @@ type jReceiver struct {
 	tchanServer     *jTchannelReceiver
 	collectorServer *http.Server
 
+	// samplingManager serves the sampling strategies, if configured. It is set
+	// before the servers start and never modified afterwards, so that the
+	// servers can read it without holding mu.
+	samplingManager configmanager.ClientConfigManager
+	strategyStore   *strategyFileStore
+	samplingConn    *grpc.ClientConn
+
 	defaultAgentCtx context.Context
 }
 
@@ -134,14 +165,16 @@ func (jr *jReceiver) collectorAddr() string {
 const defaultAgentPort = 5778
 
 func (jr *jReceiver) agentAddress() string {
+	var host string
 	var port int
 	if jr.config != nil {
+		host = jr.config.AgentHost
 		port = jr.config.AgentPort
 	}
 	if port <= 0 {
 		port = defaultAgentPort
 	}
-	return fmt.Sprintf(":%d", port)
+	return net.JoinHostPort(host, strconv.Itoa(port))
 }
 
 // TODO https://github.com/open-telemetry/opentelemetry-service/issues/267
@@ -200,6 +233,11 @@ func (jr *jReceiver) StartTraceReception(host receiver.Host) error {
 
 	var err = oterr.ErrAlreadyStarted
 	jr.startOnce.Do(func() {
+		if err = jr.startSampling(); err != nil {
+			jr.stopTraceReceptionLocked()
+			return
+		}
+
 		if err = jr.startAgent(host); err != nil && err != oterr.ErrAlreadyStarted {
 			jr.stopTraceReceptionLocked()
 			return
@@ -246,6 +284,16 @@ func (jr *jReceiver) stopTraceReceptionLocked() error {
 			jr.grpc.Stop()
 			jr.grpc = nil
 		}
+		if jr.strategyStore != nil {
+			jr.strategyStore.stop()
+			jr.strategyStore = nil
+		}
+		if jr.samplingConn != nil {
+			if cerr := jr.samplingConn.Close(); cerr != nil {
+				errs = append(errs, cerr)
+			}
+			jr.samplingConn = nil
+		}
 		if len(errs) == 0 {
 			err = nil
 			return
@@ -264,20 +312,39 @@ func (jr *jReceiver) stopTraceReceptionLocked() error {
 const collectorReceiverTagValue = "jaeger-collector"
 const tchannelCollectorReceiverTagValue = "jaeger-tchannel-collector"
 
+var acceptedThriftFormats = map[string]struct{}{
+	"application/x-thrift":                 {},
+	"application/vnd.apache.thrift.binary": {},
+}
+
+// consumeTraceData passes the batches to the next consumer, reporting the ones
+// it refused as not ok. The error telling the client to back off is only
+// returned if none of the batches was consumed, otherwise sending them again
+// would duplicate the consumed ones.
 func consumeTraceData(ctx context.Context, batches []*jaeger.Batch, consumer consumer.TraceConsumer) ([]*jaeger.BatchSubmitResponse, error) {
 	jbsr := make([]*jaeger.BatchSubmitResponse, 0, len(batches))
 
+	consumed := false
+	var throttledErr error
 	for _, batch := range batches {
-		td, err := jaegertranslator.ThriftBatchToOCProto(batch)
-		// TODO: (@odeke-em) add this error for Jaeger observability
+		td, dropped, err := jaegertranslator.ThriftBatchToOCProto(batch)
 		ok := false
 
-		if err == nil {
-			ok = true
+		if err != nil {
+			receiverhelper.RecordTraceReceiverMetrics(ctx, len(batch.Spans),
+				dropped.Add(tracetranslator.DropReasonDecodeError, len(batch.Spans)-dropped.Total()))
+		} else {
 			td.SourceFormat = "jaeger"
-			consumer.ConsumeTraceData(ctx, td)
+			err = consumer.ConsumeTraceData(ctx, td)
 			// We MUST unconditionally record metrics from this reception.
-			observability.RecordMetricsForTraceReceiver(ctx, len(batch.Spans), len(batch.Spans)-len(td.Spans))
+			receiverhelper.RecordTraceReceiverMetrics(ctx, len(batch.Spans), dropped)
+			switch {
+			case err == nil:
+				ok = true
+				consumed = true
+			case consumererror.IsThrottled(err) && throttledErr == nil:
+				throttledErr = err
+			}
 		}
 
 		jbsr = append(jbsr, &jaeger.BatchSubmitResponse{
@@ -285,9 +352,48 @@ func consumeTraceData(ctx context.Context, batches []*jaeger.Batch, consumer con
 		})
 	}
 
+	if !consumed && throttledErr != nil {
+		return nil, throttledErr
+	}
 	return jbsr, nil
 }
 
+// handleHTTPBatch handles the Jaeger collector HTTP endpoint. It mimics the
+// handler of the Jaeger collector but replies 429 with Retry-After if the spans
+// were throttled by the next consumer.
+func (jr *jReceiver) handleHTTPBatch(w http.ResponseWriter, r *http.Request) {
+	bodyBytes, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, fmt.Sprintf(app.UnableToReadBodyErrFormat, err), http.StatusInternalServerError)
+		return
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot parse content type: %v", err), http.StatusBadRequest)
+		return
+	}
+	if _, ok := acceptedThriftFormats[contentType]; !ok {
+		http.Error(w, fmt.Sprintf("Unsupported content type: %v", contentType), http.StatusBadRequest)
+		return
+	}
+
+	batch := &jaeger.Batch{}
+	if err = apachethrift.NewTDeserializer().Read(batch, bodyBytes); err != nil {
+		http.Error(w, fmt.Sprintf(app.UnableToReadBodyErrFormat, err), http.StatusBadRequest)
+		return
+	}
+
+	opts := app.SubmitBatchOptions{InboundTransport: app.HTTPTransport}
+	if _, err = jr.SubmitBatches([]*jaeger.Batch{batch}, opts); err != nil {
+		receiverhelper.WriteHTTPThrottled(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
 func (jr *jReceiver) SubmitBatches(batches []*jaeger.Batch, options app.SubmitBatchOptions) ([]*jaeger.BatchSubmitResponse, error) {
 	ctx := context.Background()
 	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, collectorReceiverTagValue)
@@ -298,7 +404,12 @@ func (jr *jReceiver) SubmitBatches(batches []*jaeger.Batch, options app.SubmitBa
 func (jtr *jTchannelReceiver) SubmitBatches(ctx thrift.Context, batches []*jaeger.Batch) ([]*jaeger.BatchSubmitResponse, error) {
 	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, tchannelCollectorReceiverTagValue)
 
-	return consumeTraceData(ctxWithReceiverName, batches, jtr.nextConsumer)
+	jbsr, err := consumeTraceData(ctxWithReceiverName, batches, jtr.nextConsumer)
+	if err != nil {
+		// Tell the client to back off and send the batches again.
+		return nil, tchannel.NewSystemError(tchannel.ErrCodeBusy, err.Error())
+	}
+	return jbsr, nil
 }
 
 var _ reporter.Reporter = (*jReceiver)(nil)
@@ -314,14 +425,15 @@ func (jr *jReceiver) EmitZipkinBatch(spans []*zipkincore.Span) error {
 // EmitBatch implements cmd/agent/reporter.Reporter and it forwards
 // Jaeger spans received by the Jaeger agent processor.
 func (jr *jReceiver) EmitBatch(batch *jaeger.Batch) error {
-	td, err := jaegertranslator.ThriftBatchToOCProto(batch)
+	td, dropped, err := jaegertranslator.ThriftBatchToOCProto(batch)
 	if err != nil {
-		observability.RecordMetricsForTraceReceiver(jr.defaultAgentCtx, len(batch.Spans), len(batch.Spans))
+		receiverhelper.RecordTraceReceiverMetrics(jr.defaultAgentCtx, len(batch.Spans),
+			dropped.Add(tracetranslator.DropReasonDecodeError, len(batch.Spans)-dropped.Total()))
 		return err
 	}
 
 	err = jr.nextConsumer.ConsumeTraceData(jr.defaultAgentCtx, td)
-	observability.RecordMetricsForTraceReceiver(jr.defaultAgentCtx, len(batch.Spans), len(batch.Spans)-len(td.Spans))
+	receiverhelper.RecordTraceReceiverMetrics(jr.defaultAgentCtx, len(batch.Spans), dropped)
 
 	return err
 }
@@ -335,6 +447,9 @@ func (jr *jReceiver) GetManager() configmanager.ClientConfigManager {
 }
 
 func (jr *jReceiver) GetSamplingStrategy(serviceName string) (*sampling.SamplingStrategyResponse, error) {
+	if jr.samplingManager != nil {
+		return jr.samplingManager.GetSamplingStrategy(serviceName)
+	}
 	return &sampling.SamplingStrategyResponse{}, nil
 }
 
@@ -345,22 +460,59 @@ func (jr *jReceiver) GetBaggageRestrictions(serviceName string) ([]*baggage.Bagg
 func (jr *jReceiver) PostSpans(ctx context.Context, r *api_v2.PostSpansRequest) (*api_v2.PostSpansResponse, error) {
 	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, collectorReceiverTagValue)
 
-	td, err := jaegertranslator.ProtoBatchToOCProto(r.Batch)
+	td, dropped, err := jaegertranslator.ProtoBatchToOCProto(r.Batch)
 	td.SourceFormat = "jaeger"
 	if err != nil {
-		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(r.Batch.Spans), len(r.Batch.Spans))
+		receiverhelper.RecordTraceReceiverMetrics(ctxWithReceiverName, len(r.Batch.Spans),
+			dropped.Add(tracetranslator.DropReasonDecodeError, len(r.Batch.Spans)-dropped.Total()))
 		return nil, err
 	}
 
 	err = jr.nextConsumer.ConsumeTraceData(ctx, td)
-	observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(r.Batch.Spans), len(r.Batch.Spans)-len(td.Spans))
+	receiverhelper.RecordTraceReceiverMetrics(ctxWithReceiverName, len(r.Batch.Spans), dropped)
 	if err != nil {
-		return nil, err
+		if md := receiverhelper.GRPCRetryAfter(err); md != nil {
+			_ = grpc.SetHeader(ctx, md)
+		}
+		return nil, receiverhelper.GRPCError(err)
 	}
 
 	return &api_v2.PostSpansResponse{}, err
 }
 
+// startSampling sets up the source of the sampling strategies served to the
+// clients: either a local strategies file or an upstream collector.
+func (jr *jReceiver) startSampling() error {
+	if jr.config == nil {
+		return nil
+	}
+
+	logger := jr.config.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	switch {
+	case jr.config.RemoteSamplingStrategyFile != "":
+		store, err := newStrategyFileStore(jr.config.RemoteSamplingStrategyFile, jr.config.RemoteSamplingReloadInterval, logger)
+		if err != nil {
+			return fmt.Errorf("failed to load the sampling strategies: %v", err)
+		}
+		jr.strategyStore = store
+		jr.samplingManager = store
+
+	case jr.config.RemoteSamplingFetchEndpoint != "":
+		conn, err := grpc.Dial(jr.config.RemoteSamplingFetchEndpoint, grpc.WithInsecure())
+		if err != nil {
+			return fmt.Errorf("failed to connect to %q to fetch the sampling strategies: %v", jr.config.RemoteSamplingFetchEndpoint, err)
+		}
+		jr.samplingConn = conn
+		jr.samplingManager = grpcmanager.NewConfigManager(conn)
+	}
+
+	return nil
+}
+
 func (jr *jReceiver) startAgent(_ receiver.Host) error {
 	processorConfigs := []agentapp.ProcessorConfiguration{
 		{
@@ -430,8 +582,7 @@ func (jr *jReceiver) startCollector(host receiver.Host) error {
 	}
 
 	nr := mux.NewRouter()
-	apiHandler := app.NewAPIHandler(jr)
-	apiHandler.RegisterRoutes(nr)
+	nr.HandleFunc("/api/traces", jr.handleHTTPBatch).Methods(http.MethodPost)
 	jr.collectorServer = &http.Server{Handler: nr}
 	go func() {
 		_ = jr.collectorServer.Serve(cln)
@@ -448,6 +599,9 @@ func (jr *jReceiver) startCollector(host receiver.Host) error {
 	}
 
 	api_v2.RegisterCollectorServiceServer(jr.grpc, jr)
+	if jr.samplingManager != nil {
+		api_v2.RegisterSamplingManagerServer(jr.grpc, &grpcSamplingManager{manager: jr.samplingManager})
+	}
 
 	go func() {
 		if err := jr.grpc.Serve(gln); err != nil {
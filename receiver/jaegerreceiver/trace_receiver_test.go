@@ -15,28 +15,42 @@
 package jaegerreceiver
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"path"
 	"testing"
 	"time"
 
 	"contrib.go.opencensus.io/exporter/jaeger"
+	apachethrift "github.com/apache/thrift/lib/go/thrift"
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/google/go-cmp/cmp"
 	"github.com/jaegertracing/jaeger/model"
 	"github.com/jaegertracing/jaeger/proto-gen/api_v2"
+	jaegerthrift "github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uber/tchannel-go"
+	"github.com/uber/tchannel-go/thrift"
 	"go.opencensus.io/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
 	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
@@ -196,6 +210,138 @@ func TestGRPCReceptionWithTLS(t *testing.T) {
 	assert.Equal(t, "", cmp.Diff(got, want))
 }
 
+func TestConsumerErrors(t *testing.T) {
+	throttled := consumererror.Throttled(errors.New("queue is full"), 3*time.Second)
+	tests := []struct {
+		name         string
+		err          error
+		httpStatus   int
+		grpcCode     codes.Code
+		tchannelCode tchannel.SystemErrCode
+		retryAfter   string
+	}{
+		{
+			name:       "accepted",
+			httpStatus: http.StatusAccepted,
+			grpcCode:   codes.OK,
+		},
+		{
+			name:         "throttled",
+			err:          throttled,
+			httpStatus:   http.StatusTooManyRequests,
+			grpcCode:     codes.ResourceExhausted,
+			tchannelCode: tchannel.ErrCodeBusy,
+			retryAfter:   "3",
+		},
+		{
+			// Other errors are not reported to the HTTP and TChannel
+			// clients, and returned as is to the gRPC ones.
+			name:       "other",
+			err:        errors.New("exporter failure"),
+			httpStatus: http.StatusAccepted,
+			grpcCode:   codes.Unknown,
+		},
+	}
+
+	// Use free ports to not clash with the receivers started by other tests.
+	config := &Configuration{
+		CollectorThriftPort:    int(testutils.GetAvailablePort(t)),
+		CollectorHTTPPort:      int(testutils.GetAvailablePort(t)),
+		CollectorGRPCPort:      int(testutils.GetAvailablePort(t)),
+		AgentPort:              int(testutils.GetAvailablePort(t)),
+		AgentCompactThriftPort: int(testutils.GetAvailablePort(t)),
+		AgentBinaryThriftPort:  int(testutils.GetAvailablePort(t)),
+	}
+	nextConsumer := &errTraceConsumer{}
+	jr, err := New(context.Background(), config, nextConsumer)
+	require.NoError(t, err)
+	defer jr.StopTraceReception()
+	require.NoError(t, jr.StartTraceReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(jr.(*jReceiver).grpcAddr(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextConsumer.err = tt.err
+
+			// HTTP collector.
+			batch := &jaegerthrift.Batch{
+				Process: jaegerthrift.NewProcess(),
+				Spans:   []*jaegerthrift.Span{{TraceIdLow: 1, SpanId: 2, OperationName: "op"}},
+			}
+			body, err := apachethrift.NewTSerializer().Write(batch)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/api/traces", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/x-thrift")
+			rec := httptest.NewRecorder()
+			jr.(*jReceiver).handleHTTPBatch(rec, req)
+			assert.Equal(t, tt.httpStatus, rec.Code)
+			assert.Equal(t, tt.retryAfter, rec.Header().Get("Retry-After"))
+
+			// gRPC collector.
+			var header metadata.MD
+			_, err = api_v2.NewCollectorServiceClient(conn).PostSpans(
+				context.Background(),
+				grpcFixture(time.Now(), time.Minute, time.Second),
+				grpc.WaitForReady(true),
+				grpc.Header(&header))
+			assert.Equal(t, tt.grpcCode, status.Code(err))
+			if tt.retryAfter != "" {
+				assert.Equal(t, []string{tt.retryAfter}, header.Get("retry-after"))
+			}
+
+			// TChannel collector.
+			jtr := &jTchannelReceiver{nextConsumer: nextConsumer}
+			jbsr, err := jtr.SubmitBatches(thrift.Wrap(context.Background()), []*jaegerthrift.Batch{batch})
+			if tt.tchannelCode != 0 {
+				assert.Equal(t, tt.tchannelCode, tchannel.GetSystemErrorCode(err))
+			} else {
+				require.NoError(t, err)
+				require.Len(t, jbsr, 1)
+				assert.Equal(t, tt.err == nil, jbsr[0].Ok)
+			}
+		})
+	}
+}
+
+func TestConsumeTraceDataPartial(t *testing.T) {
+	batch := &jaegerthrift.Batch{
+		Process: jaegerthrift.NewProcess(),
+		Spans:   []*jaegerthrift.Span{{TraceIdLow: 1, SpanId: 2, OperationName: "op"}},
+	}
+	throttled := consumererror.Throttled(errors.New("queue is full"), time.Second)
+
+	// The batches refused after the first one was consumed are reported as
+	// not ok instead of asking the client to send all of them again.
+	nextConsumer := &errTraceConsumer{errs: []error{nil, throttled}}
+	jbsr, err := consumeTraceData(context.Background(), []*jaegerthrift.Batch{batch, batch}, nextConsumer)
+	require.NoError(t, err)
+	require.Len(t, jbsr, 2)
+	assert.True(t, jbsr[0].Ok)
+	assert.False(t, jbsr[1].Ok)
+
+	nextConsumer = &errTraceConsumer{err: throttled}
+	_, err = consumeTraceData(context.Background(), []*jaegerthrift.Batch{batch, batch}, nextConsumer)
+	assert.True(t, consumererror.IsThrottled(err))
+}
+
+// errTraceConsumer returns errs, one per call, and then err.
+type errTraceConsumer struct {
+	err  error
+	errs []error
+}
+
+func (c *errTraceConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return err
+	}
+	return c.err
+}
+
 func expectedTraceData(t1, t2, t3 time.Time) []consumerdata.TraceData {
 	traceID := []byte{0xF1, 0xF2, 0xF3, 0xF4, 0xF5, 0xF6, 0xF7, 0xF8, 0xF9, 0xFA, 0xFB, 0xFC, 0xFD, 0xFE, 0xFF, 0x80}
 	parentSpanID := []byte{0x1F, 0x1E, 0x1D, 0x1C, 0x1B, 0x1A, 0x19, 0x18}
@@ -361,3 +507,40 @@ func grpcFixture(t1 time.Time, d1, d2 time.Duration) *api_v2.PostSpansRequest {
 		},
 	}
 }
+
+func TestAgentDroppedSpans(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	sink := new(exportertest.SinkTraceExporter)
+	r, err := New(context.Background(), &Configuration{}, sink)
+	require.NoError(t, err)
+	jr := r.(*jReceiver)
+
+	err = jr.EmitBatch(&jaegerthrift.Batch{
+		Process: jaegerthrift.NewProcess(),
+		Spans: []*jaegerthrift.Span{
+			nil,
+			{},
+			{TraceIdLow: 1, SpanId: 1, OperationName: "good"},
+		},
+	})
+	require.NoError(t, err)
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, 1)
+
+	require.NoError(t, observabilitytest.CheckValueViewReceiverReceivedSpans("jaeger-agent", 3))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason("jaeger-agent", tracetranslator.DropReasonDecodeError, 1))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason("jaeger-agent", tracetranslator.DropReasonZeroTraceID, 1))
+}
+
+func TestAgentAddress(t *testing.T) {
+	jr := &jReceiver{config: &Configuration{AgentHost: "127.0.0.1", AgentPort: 5779}}
+	assert.Equal(t, "127.0.0.1:5779", jr.agentAddress())
+
+	// The agent binds to all the interfaces by default.
+	jr = &jReceiver{config: &Configuration{}}
+	assert.Equal(t, ":5778", jr.agentAddress())
+}
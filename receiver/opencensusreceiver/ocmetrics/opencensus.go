@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync"
 	"time"
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
@@ -29,8 +30,10 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
 )
 
 // Receiver is the type used to handle metrics from OpenCensus exporters.
@@ -64,9 +67,46 @@ func (ocr *Receiver) Export(mes agentmetricspb.MetricsService_ExportServer) erro
 	// The bundler will receive batches of metrics i.e. []*metricspb.Metric
 	// We need to ensure that it propagates the receiver name as a tag
 	ctxWithReceiverName := observability.ContextWithReceiverName(mes.Context(), receiverTagValue)
+	// The bundler exports asynchronously so keep the first error asking the
+	// client to back off to report it on the stream. The error is dropped once
+	// metrics of the stream were consumed: the client would send them again
+	// along with the throttled ones.
+	var exportErrMu sync.Mutex
+	var exportErr error
+	consumed := false
 	metricsBundler := bundler.NewBundler((*consumerdata.MetricsData)(nil), func(payload interface{}) {
-		ocr.batchMetricExporting(ctxWithReceiverName, payload)
+		anyConsumed, err := ocr.batchMetricExporting(ctxWithReceiverName, payload)
+		exportErrMu.Lock()
+		if anyConsumed {
+			consumed = true
+			exportErr = nil
+		} else if consumererror.IsThrottled(err) && !consumed && exportErr == nil {
+			exportErr = err
+		}
+		exportErrMu.Unlock()
 	})
+	getExportErr := func() error {
+		exportErrMu.Lock()
+		defer exportErrMu.Unlock()
+		return exportErr
+	}
+	checkExportErr := func() error {
+		if getExportErr() == nil {
+			return nil
+		}
+		// Metrics still buffered may be consumed.
+		metricsBundler.Flush()
+		err := getExportErr()
+		if err == nil {
+			return nil
+		}
+		// The metrics were refused by the pipeline, end the stream with a
+		// status telling the client if and when to send them again.
+		if md := receiverhelper.GRPCRetryAfter(err); md != nil {
+			_ = mes.SetHeader(md)
+		}
+		return receiverhelper.GRPCError(err)
+	}
 
 	metricBufferPeriod := ocr.metricBufferPeriod
 	if metricBufferPeriod <= 0 {
@@ -108,13 +148,19 @@ func (ocr *Receiver) Export(mes agentmetricspb.MetricsService_ExportServer) erro
 		}
 
 		processReceivedMetrics(lastNonNilNode, resource, recv.Metrics, metricsBundler)
+		if err := checkExportErr(); err != nil {
+			return err
+		}
 
 		recv, err = mes.Recv()
 		if err != nil {
 			if err == io.EOF {
+				// Export the buffered metrics to be able to report any error
+				// returned by the next consumer, e.g.: for grpc-gateway calls.
+				metricsBundler.Flush()
 				// Do not return EOF as an error so that grpc-gateway calls get an empty
 				// response with HTTP status code 200 rather than a 500 error with EOF.
-				return nil
+				return checkExportErr()
 			}
 			return err
 		}
@@ -129,10 +175,12 @@ func processReceivedMetrics(ni *commonpb.Node, resource *resourcepb.Resource, me
 	}
 }
 
-func (ocr *Receiver) batchMetricExporting(longLivedRPCCtx context.Context, payload interface{}) {
+// batchMetricExporting passes the metrics to the next consumer. It returns
+// whether any of them was consumed and the first error of the next consumer.
+func (ocr *Receiver) batchMetricExporting(longLivedRPCCtx context.Context, payload interface{}) (bool, error) {
 	mds := payload.([]*consumerdata.MetricsData)
 	if len(mds) == 0 {
-		return
+		return false, nil
 	}
 
 	// Trace this method
@@ -146,13 +194,31 @@ func (ocr *Receiver) batchMetricExporting(longLivedRPCCtx context.Context, paylo
 	// If the starting RPC has a parent span, then add it as a parent link.
 	observability.SetParentLink(longLivedRPCCtx, span)
 
+	// Keep the first error returned by the next consumer, so that the client
+	// can be told to back off if the metrics were throttled.
+	var err error
+	consumed := false
 	nMetrics := int64(0)
 	for _, md := range mds {
-		ocr.nextConsumer.ConsumeMetricsData(ctx, *md)
+		if cerr := ocr.nextConsumer.ConsumeMetricsData(ctx, *md); cerr != nil {
+			if err == nil {
+				err = cerr
+			}
+		} else {
+			consumed = true
+		}
 		nMetrics += int64(len(md.Metrics))
 	}
 
 	span.Annotate([]trace.Attribute{
 		trace.Int64Attribute("num_metrics", nMetrics),
 	}, "")
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnavailable,
+			Message: err.Error(),
+		})
+	}
+
+	return consumed, err
 }
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -33,9 +34,13 @@ import (
 	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
 	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 )
@@ -296,6 +301,125 @@ func TestExportProtocolConformation_metricsInFirstMessage(t *testing.T) {
 	}
 }
 
+func TestExportOtherErrorKeepsStream(t *testing.T) {
+	nextConsumer := exportertest.NewNopMetricsExporter(exportertest.WithReturnError(errors.New("exporter failure")))
+
+	_, port, doneFn := ocReceiverOnGRPCServer(t, nextConsumer)
+	defer doneFn()
+
+	metricsClient, metricsClientDoneFn, err := makeMetricsServiceClient(port)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC MetricsService_ExportClient: %v", err)
+	}
+	defer metricsClientDoneFn()
+
+	ni := &commonpb.Node{Identifier: &commonpb.ProcessIdentifier{Pid: 1}}
+	mLi := []*metricspb.Metric{makeMetric(10)}
+	if err := metricsClient.Send(&agentmetricspb.ExportMetricsServiceRequest{Node: ni, Metrics: mLi}); err != nil {
+		t.Fatalf("Failed to send the first message: %v", err)
+	}
+	if err := metricsClient.CloseSend(); err != nil {
+		t.Fatalf("Failed to close the stream: %v", err)
+	}
+
+	// Errors other than throttling are not reported to the client.
+	if _, err = metricsClient.Recv(); err != io.EOF {
+		t.Errorf("Got %v Want EOF", err)
+	}
+}
+
+func TestExportThrottled(t *testing.T) {
+	nextConsumer := exportertest.NewNopMetricsExporter(
+		exportertest.WithReturnError(consumererror.Throttled(errors.New("queue is full"), 2*time.Second)))
+
+	_, port, doneFn := ocReceiverOnGRPCServer(t, nextConsumer)
+	defer doneFn()
+
+	metricsClient, metricsClientDoneFn, err := makeMetricsServiceClient(port)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC MetricsService_ExportClient: %v", err)
+	}
+	defer metricsClientDoneFn()
+
+	ni := &commonpb.Node{Identifier: &commonpb.ProcessIdentifier{Pid: 1}}
+	mLi := []*metricspb.Metric{makeMetric(10)}
+	if err := metricsClient.Send(&agentmetricspb.ExportMetricsServiceRequest{Node: ni, Metrics: mLi}); err != nil {
+		t.Fatalf("Failed to send the first message: %v", err)
+	}
+	// Closing the stream flushes the buffered metrics.
+	if err := metricsClient.CloseSend(); err != nil {
+		t.Fatalf("Failed to close the stream: %v", err)
+	}
+
+	_, err = metricsClient.Recv()
+	if g, w := status.Code(err), codes.ResourceExhausted; g != w {
+		t.Errorf("Status code: Got %v Want %v", g, w)
+	}
+	header, _ := metricsClient.Header()
+	if g, w := header.Get("retry-after"), []string{"2"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("Retry after: Got %v Want %v", g, w)
+	}
+}
+
+func TestExportThrottledAfterConsumed(t *testing.T) {
+	nextConsumer := &throttlingConsumer{accepted: 1}
+
+	_, port, doneFn := ocReceiverOnGRPCServer(t, nextConsumer)
+	defer doneFn()
+
+	metricsClient, metricsClientDoneFn, err := makeMetricsServiceClient(port)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC MetricsService_ExportClient: %v", err)
+	}
+	defer metricsClientDoneFn()
+
+	ni := &commonpb.Node{Identifier: &commonpb.ProcessIdentifier{Pid: 1}}
+	mLi := []*metricspb.Metric{makeMetric(10)}
+	for i := 0; i < 3; i++ {
+		if err := metricsClient.Send(&agentmetricspb.ExportMetricsServiceRequest{Node: ni, Metrics: mLi}); err != nil {
+			t.Fatalf("Failed to send message %d: %v", i, err)
+		}
+	}
+	if err := metricsClient.CloseSend(); err != nil {
+		t.Fatalf("Failed to close the stream: %v", err)
+	}
+
+	// Metrics of the stream were consumed, throttling it would make the client
+	// send them again.
+	if _, err = metricsClient.Recv(); err != io.EOF {
+		t.Errorf("Got %v Want EOF", err)
+	}
+	if g, w := nextConsumer.numCalls(), 3; g != w {
+		t.Errorf("Calls: Got %d Want %d", g, w)
+	}
+}
+
+// throttlingConsumer accepts the first metrics it gets and throttles the
+// others.
+type throttlingConsumer struct {
+	mu       sync.Mutex
+	accepted int
+	calls    int
+}
+
+var _ consumer.MetricsConsumer = (*throttlingConsumer)(nil)
+
+func (tc *throttlingConsumer) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.calls++
+	if tc.calls > tc.accepted {
+		return consumererror.Throttled(errors.New("queue is full"), time.Second)
+	}
+	return nil
+}
+
+func (tc *throttlingConsumer) numCalls() int {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.calls
+}
+
 // Helper functions from here on below
 func makeMetricsServiceClient(port int) (agentmetricspb.MetricsService_ExportClient, func(), error) {
 	addr := fmt.Sprintf(":%d", port)
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync"
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
@@ -26,8 +27,10 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
 )
 
 const (
@@ -45,8 +48,54 @@ type Receiver struct {
 }
 
 type traceDataWithCtx struct {
-	data *consumerdata.TraceData
-	ctx  context.Context
+	data   *consumerdata.TraceData
+	ctx    context.Context
+	stream *exportStream
+}
+
+// exportStream tracks the messages of an Export stream handed to the workers.
+// Since the workers export them asynchronously it keeps the first error asking
+// the client to back off, so that it can be reported on the stream. The error
+// is dropped once spans of the stream were consumed: the client would send
+// them again along with the throttled ones.
+type exportStream struct {
+	pending sync.WaitGroup
+
+	mu           sync.Mutex
+	consumed     bool
+	throttledErr error
+}
+
+// done records the result of exporting a message, hasSpans tells if the
+// message had spans to consume.
+func (es *exportStream) done(hasSpans bool, err error) {
+	es.mu.Lock()
+	switch {
+	case err == nil && hasSpans:
+		es.consumed = true
+		es.throttledErr = nil
+	case consumererror.IsThrottled(err) && !es.consumed && es.throttledErr == nil:
+		es.throttledErr = err
+	}
+	es.mu.Unlock()
+	es.pending.Done()
+}
+
+func (es *exportStream) err() error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return es.throttledErr
+}
+
+// throttled returns the error the stream must end with once all its pending
+// messages were exported, if any.
+func (es *exportStream) throttled() error {
+	if es.err() == nil {
+		return nil
+	}
+	// Spans of the pending messages may still be consumed.
+	es.pending.Wait()
+	return es.err()
 }
 
 // New creates a new opencensus.Receiver reference.
@@ -110,6 +159,7 @@ func (ocr *Receiver) Export(tes agenttracepb.TraceService_ExportServer) error {
 
 	var lastNonNilNode *commonpb.Node
 	var resource *resourcepb.Resource
+	stream := &exportStream{}
 	// Now that we've got the first message with a Node, we can start to receive streamed up spans.
 	for {
 		// If a Node has been sent from downstream, save and use it.
@@ -130,13 +180,24 @@ func (ocr *Receiver) Export(tes agenttracepb.TraceService_ExportServer) error {
 			SourceFormat: "oc_trace",
 		}
 
-		ocr.messageChan <- &traceDataWithCtx{data: td, ctx: ctxWithReceiverName}
+		stream.pending.Add(1)
+		ocr.messageChan <- &traceDataWithCtx{data: td, ctx: ctxWithReceiverName, stream: stream}
 
 		observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, len(td.Spans), 0)
 
+		if err := stream.throttled(); err != nil {
+			return throttledStatus(tes, err)
+		}
+
 		recv, err = tes.Recv()
 		if err != nil {
 			if err == io.EOF {
+				// Report the spans of the stream that were throttled, e.g.:
+				// for grpc-gateway calls.
+				stream.pending.Wait()
+				if err := stream.throttled(); err != nil {
+					return throttledStatus(tes, err)
+				}
 				// Do not return EOF as an error so that grpc-gateway calls get an empty
 				// response with HTTP status code 200 rather than a 500 error with EOF.
 				return nil
@@ -146,6 +207,15 @@ func (ocr *Receiver) Export(tes agenttracepb.TraceService_ExportServer) error {
 	}
 }
 
+// throttledStatus ends the stream with a status telling the client that the
+// spans were refused by the pipeline and when to send them again.
+func throttledStatus(tes agenttracepb.TraceService_ExportServer, err error) error {
+	if md := receiverhelper.GRPCRetryAfter(err); md != nil {
+		_ = tes.SetHeader(md)
+	}
+	return receiverhelper.GRPCError(err)
+}
+
 // Stop the receiver and its workers
 func (ocr *Receiver) Stop() {
 	for _, worker := range ocr.workers {
@@ -169,7 +239,8 @@ func (rw *receiverWorker) listenOn(cn <-chan *traceDataWithCtx) {
 	for {
 		select {
 		case tdWithCtx := <-cn:
-			rw.export(tdWithCtx.ctx, tdWithCtx.data)
+			hasSpans := tdWithCtx.data != nil && len(tdWithCtx.data.Spans) > 0
+			tdWithCtx.stream.done(hasSpans, rw.export(tdWithCtx.ctx, tdWithCtx.data))
 		case <-rw.cancel:
 			return
 		}
@@ -180,13 +251,13 @@ func (rw *receiverWorker) stopListening() {
 	close(rw.cancel)
 }
 
-func (rw *receiverWorker) export(longLivedCtx context.Context, tracedata *consumerdata.TraceData) {
+func (rw *receiverWorker) export(longLivedCtx context.Context, tracedata *consumerdata.TraceData) error {
 	if tracedata == nil {
-		return
+		return nil
 	}
 
 	if len(tracedata.Spans) == 0 {
-		return
+		return nil
 	}
 
 	// Trace this method
@@ -200,9 +271,17 @@ func (rw *receiverWorker) export(longLivedCtx context.Context, tracedata *consum
 	// If the starting RPC has a parent span, then add it as a parent link.
 	observability.SetParentLink(longLivedCtx, span)
 
-	rw.receiver.nextConsumer.ConsumeTraceData(ctx, *tracedata)
+	err := rw.receiver.nextConsumer.ConsumeTraceData(ctx, *tracedata)
 
 	span.Annotate([]trace.Attribute{
 		trace.Int64Attribute("num_spans", int64(len(tracedata.Spans))),
 	}, "")
+	if err != nil {
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeUnavailable,
+			Message: err.Error(),
+		})
+	}
+
+	return err
 }
@@ -37,9 +37,13 @@ import (
 	"go.opencensus.io/trace"
 	"go.opencensus.io/trace/tracestate"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 )
@@ -430,6 +434,127 @@ func TestExportProtocolConformation_spansInFirstMessage(t *testing.T) {
 	}
 }
 
+func TestExportOtherErrorKeepsStream(t *testing.T) {
+	nextConsumer := exportertest.NewNopTraceExporter(exportertest.WithReturnError(errors.New("exporter failure")))
+
+	_, port, doneFn := ocReceiverOnGRPCServer(t, nextConsumer)
+	defer doneFn()
+
+	traceClient, traceClientDoneFn, err := makeTraceServiceClient(port)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC TraceService_ExportClient: %v", err)
+	}
+	defer traceClientDoneFn()
+
+	ni := &commonpb.Node{Identifier: &commonpb.ProcessIdentifier{Pid: 1}}
+	sLi := []*tracepb.Span{{TraceId: []byte("1234567890abcde")}}
+	for i := 0; i < 3; i++ {
+		if err := traceClient.Send(&agenttracepb.ExportTraceServiceRequest{Node: ni, Spans: sLi}); err != nil {
+			t.Fatalf("Failed to send message %d: %v", i, err)
+		}
+	}
+	if err := traceClient.CloseSend(); err != nil {
+		t.Fatalf("Failed to close the stream: %v", err)
+	}
+
+	// Errors other than throttling are not reported to the client.
+	if _, err = traceClient.Recv(); err != io.EOF {
+		t.Errorf("Got %v Want EOF", err)
+	}
+}
+
+func TestExportThrottled(t *testing.T) {
+	nextConsumer := exportertest.NewNopTraceExporter(
+		exportertest.WithReturnError(consumererror.Throttled(errors.New("queue is full"), 2*time.Second)))
+
+	_, port, doneFn := ocReceiverOnGRPCServer(t, nextConsumer)
+	defer doneFn()
+
+	traceClient, traceClientDoneFn, err := makeTraceServiceClient(port)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC TraceService_ExportClient: %v", err)
+	}
+	defer traceClientDoneFn()
+
+	ni := &commonpb.Node{Identifier: &commonpb.ProcessIdentifier{Pid: 1}}
+	sLi := []*tracepb.Span{{TraceId: []byte("1234567890abcde")}}
+	if err := traceClient.Send(&agenttracepb.ExportTraceServiceRequest{Node: ni, Spans: sLi}); err != nil {
+		t.Fatalf("Failed to send the first message: %v", err)
+	}
+	// The spans are exported asynchronously, closing the stream waits for
+	// them to be exported.
+	if err := traceClient.CloseSend(); err != nil {
+		t.Fatalf("Failed to close the stream: %v", err)
+	}
+
+	_, err = traceClient.Recv()
+	if g, w := status.Code(err), codes.ResourceExhausted; g != w {
+		t.Errorf("Status code: Got %v Want %v", g, w)
+	}
+	header, _ := traceClient.Header()
+	if g, w := header.Get("retry-after"), []string{"2"}; !reflect.DeepEqual(g, w) {
+		t.Errorf("Retry after: Got %v Want %v", g, w)
+	}
+}
+
+func TestExportThrottledAfterConsumed(t *testing.T) {
+	nextConsumer := &throttlingConsumer{accepted: 1}
+
+	_, port, doneFn := ocReceiverOnGRPCServer(t, nextConsumer)
+	defer doneFn()
+
+	traceClient, traceClientDoneFn, err := makeTraceServiceClient(port)
+	if err != nil {
+		t.Fatalf("Failed to create the gRPC TraceService_ExportClient: %v", err)
+	}
+	defer traceClientDoneFn()
+
+	ni := &commonpb.Node{Identifier: &commonpb.ProcessIdentifier{Pid: 1}}
+	sLi := []*tracepb.Span{{TraceId: []byte("1234567890abcde")}}
+	for i := 0; i < 3; i++ {
+		if err := traceClient.Send(&agenttracepb.ExportTraceServiceRequest{Node: ni, Spans: sLi}); err != nil {
+			t.Fatalf("Failed to send message %d: %v", i, err)
+		}
+	}
+	if err := traceClient.CloseSend(); err != nil {
+		t.Fatalf("Failed to close the stream: %v", err)
+	}
+
+	// Spans of the stream were consumed, throttling it would make the client
+	// send them again.
+	if _, err = traceClient.Recv(); err != io.EOF {
+		t.Errorf("Got %v Want EOF", err)
+	}
+	if g, w := nextConsumer.numCalls(), 3; g != w {
+		t.Errorf("Calls: Got %d Want %d", g, w)
+	}
+}
+
+// throttlingConsumer accepts the first spans it gets and throttles the others.
+type throttlingConsumer struct {
+	mu       sync.Mutex
+	accepted int
+	calls    int
+}
+
+var _ consumer.TraceConsumer = (*throttlingConsumer)(nil)
+
+func (tc *throttlingConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.calls++
+	if tc.calls > tc.accepted {
+		return consumererror.Throttled(errors.New("queue is full"), time.Second)
+	}
+	return nil
+}
+
+func (tc *throttlingConsumer) numCalls() int {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.calls
+}
+
 // Helper functions from here on below
 func makeTraceServiceClient(port int) (agenttracepb.TraceService_ExportClient, func(), error) {
 	addr := fmt.Sprintf(":%d", port)
@@ -15,19 +15,13 @@
 package opencensusreceiver
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net"
-	"net/http"
 	"sync"
-	"time"
 
 	agentmetricspb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/metrics/v1"
 	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
-	gatewayruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
-	"github.com/rs/cors"
-	"github.com/soheilhy/cmux"
 	"google.golang.org/grpc"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
@@ -36,6 +30,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver/ocmetrics"
 	"github.com/open-telemetry/opentelemetry-service/receiver/opencensusreceiver/octrace"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
 )
 
 // Receiver is the type that exposes Trace and Metrics reception.
@@ -43,8 +38,7 @@ type Receiver struct {
 	mu                sync.Mutex
 	ln                net.Listener
 	serverGRPC        *grpc.Server
-	serverHTTP        *http.Server
-	gatewayMux        *gatewayruntime.ServeMux
+	server            *receiverhelper.GatewayServer
 	corsOrigins       []string
 	grpcServerOptions []grpc.ServerOption
 
@@ -81,7 +75,6 @@ func New(addr string, tc consumer.TraceConsumer, mc consumer.MetricsConsumer, op
 	ocr := &Receiver{
 		ln:          ln,
 		corsOrigins: []string{}, // Disable CORS by default.
-		gatewayMux:  gatewayruntime.NewServeMux(),
 	}
 
 	for _, opt := range opts {
@@ -217,8 +210,8 @@ func (ocr *Receiver) stop() error {
 
 		// Currently there is no symmetric stop for metrics receiver.
 
-		if ocr.serverHTTP != nil {
-			_ = ocr.serverHTTP.Close()
+		if ocr.server != nil {
+			ocr.server.Stop()
 		}
 
 		if ocr.ln != nil {
@@ -235,71 +228,18 @@ func (ocr *Receiver) stop() error {
 	return err
 }
 
-func (ocr *Receiver) httpServer() *http.Server {
-	ocr.mu.Lock()
-	defer ocr.mu.Unlock()
-
-	if ocr.serverHTTP == nil {
-		var mux http.Handler = ocr.gatewayMux
-		if len(ocr.corsOrigins) > 0 {
-			co := cors.Options{AllowedOrigins: ocr.corsOrigins}
-			mux = cors.New(co).Handler(mux)
-		}
-		ocr.serverHTTP = &http.Server{Handler: mux}
-	}
-
-	return ocr.serverHTTP
-}
-
 func (ocr *Receiver) startServer() error {
 	err := oterr.ErrAlreadyStarted
 	ocr.startServerOnce.Do(func() {
-		errChan := make(chan error, 1)
-		go func() {
-			// Register the grpc-gateway on the HTTP server mux
-			c := context.Background()
-			opts := []grpc.DialOption{grpc.WithInsecure()}
-			endpoint := ocr.ln.Addr().String()
-
-			err := agenttracepb.RegisterTraceServiceHandlerFromEndpoint(c, ocr.gatewayMux, endpoint, opts)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			err = agentmetricspb.RegisterMetricsServiceHandlerFromEndpoint(c, ocr.gatewayMux, endpoint, opts)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			// Start the gRPC and HTTP/JSON (grpc-gateway) servers on the same port.
-			m := cmux.New(ocr.ln)
-			grpcL := m.MatchWithWriters(
-				cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
-				cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc+proto"))
-
-			httpL := m.Match(cmux.Any())
-			go func() {
-				errChan <- ocr.serverGRPC.Serve(grpcL)
-			}()
-			go func() {
-				errChan <- ocr.httpServer().Serve(httpL)
-			}()
-			errChan <- m.Serve()
-		}()
-
-		// Our goal is to heuristically try running the server
-		// and if it returns an error immediately, we reporter that.
-		select {
-		case serr := <-errChan:
-			err = serr
-
-		case <-time.After(1 * time.Second):
-			// No error otherwise returned in the period of 1s.
-			// We can assume that the serve is at least running.
-			err = nil
-		}
+		ocr.mu.Lock()
+		ocr.server = receiverhelper.NewGatewayServer(ocr.ln, ocr.serverGRPC, ocr.corsOrigins)
+		ocr.mu.Unlock()
+
+		// Register the grpc-gateway on the HTTP server mux
+		err = ocr.server.Start(
+			agenttracepb.RegisterTraceServiceHandlerFromEndpoint,
+			agentmetricspb.RegisterMetricsServiceHandlerFromEndpoint,
+		)
 	})
 	return err
 }
@@ -0,0 +1,96 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+
+	collectormetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
+	"github.com/open-telemetry/opentelemetry-service/translator/otlp"
+)
+
+const receiverTagValue = "otlp_metrics"
+
+// Receiver is the type used to handle metrics from OTLP exporters.
+type Receiver struct {
+	nextConsumer consumer.MetricsConsumer
+	// stopped is set to 1 once the reception of metrics is stopped.
+	stopped int32
+}
+
+// New creates a new Receiver reference.
+func New(nextConsumer consumer.MetricsConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &Receiver{nextConsumer: nextConsumer}, nil
+}
+
+// errStopped is returned to the requests received once the reception of metrics
+// is stopped, while the server keeps serving the other receivers.
+var errStopped = status.Error(codes.Unavailable, "the OTLP metrics receiver is stopped")
+
+// Stop makes the receiver refuse the requests, it can't be restarted.
+func (r *Receiver) Stop() {
+	atomic.StoreInt32(&r.stopped, 1)
+}
+
+var _ collectormetrics.MetricsServiceServer = (*Receiver)(nil)
+
+// Export is the gRPC method that receives the metrics of OTLP exporters,
+// converts them and passes them onto the next consumer.
+func (r *Receiver) Export(ctx context.Context, req *collectormetrics.ExportMetricsServiceRequest) (*collectormetrics.ExportMetricsServiceResponse, error) {
+	if atomic.LoadInt32(&r.stopped) != 0 {
+		return nil, errStopped
+	}
+
+	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, receiverTagValue)
+
+	mds := otlp.ResourceMetricsToMetricsData(req.ResourceMetrics)
+	numTimeSeries := 0
+	// Keep the first error returned by the next consumer, any of them tells
+	// the client how it should proceed, e.g.: retry later if throttled.
+	var err error
+	for _, md := range mds {
+		if len(md.Metrics) == 0 {
+			continue
+		}
+		for _, metric := range md.Metrics {
+			numTimeSeries += len(metric.Timeseries)
+		}
+		if cerr := r.nextConsumer.ConsumeMetricsData(ctxWithReceiverName, md); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	observability.RecordMetricsForMetricsReceiver(ctxWithReceiverName, numTimeSeries, 0)
+
+	if err != nil {
+		if md := receiverhelper.GRPCRetryAfter(err); md != nil {
+			_ = grpc.SetHeader(ctx, md)
+		}
+		return nil, receiverhelper.GRPCError(err)
+	}
+	return &collectormetrics.ExportMetricsServiceResponse{}, nil
+}
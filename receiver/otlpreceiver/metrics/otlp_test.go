@@ -0,0 +1,77 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	collectormetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/metrics/v1"
+	otlpmetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestExport(t *testing.T) {
+	sink := new(exportertest.SinkMetricsExporter)
+	r, err := New(sink)
+	require.NoError(t, err)
+
+	req := &collectormetrics.ExportMetricsServiceRequest{
+		ResourceMetrics: []*otlpmetrics.ResourceMetrics{
+			{
+				InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+					{
+						Metrics: []*otlpmetrics.Metric{
+							{
+								MetricDescriptor: &otlpmetrics.MetricDescriptor{
+									Name: "requests",
+									Type: otlpmetrics.MetricDescriptor_COUNTER_INT64,
+								},
+								Int64DataPoints: []*otlpmetrics.Int64DataPoint{
+									{TimeUnixNano: 1, Value: 10},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resp, err := r.Export(context.Background(), req)
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Metrics, 1)
+	assert.Equal(t, "requests", got[0].Metrics[0].MetricDescriptor.Name)
+}
+
+func TestExport_Stopped(t *testing.T) {
+	sink := new(exportertest.SinkMetricsExporter)
+	r, err := New(sink)
+	require.NoError(t, err)
+	r.Stop()
+
+	resp, err := r.Export(context.Background(), &collectormetrics.ExportMetricsServiceRequest{})
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,283 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlpreceiver receives traces and metrics in the OpenTelemetry
+// protocol (OTLP) over gRPC, and over HTTP with protobuf or JSON payloads
+// served by a grpc-gateway on the same port.
+package otlpreceiver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	gatewayruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
+	collectormetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/metrics/v1"
+	collectortrace "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	"google.golang.org/grpc"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/otlpreceiver/metrics"
+	"github.com/open-telemetry/opentelemetry-service/receiver/otlpreceiver/trace"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
+)
+
+// Receiver is the type that exposes Trace and Metrics reception.
+type Receiver struct {
+	mu                sync.Mutex
+	ln                net.Listener
+	serverGRPC        *grpc.Server
+	server            *receiverhelper.GatewayServer
+	corsOrigins       []string
+	grpcServerOptions []grpc.ServerOption
+
+	traceReceiver   *trace.Receiver
+	metricsReceiver *metrics.Receiver
+
+	traceConsumer   consumer.TraceConsumer
+	metricsConsumer consumer.MetricsConsumer
+
+	// traceStopped and metricsStopped are set when the reception of traces
+	// and metrics is stopped, the server is stopped once both are.
+	traceStopped   bool
+	metricsStopped bool
+
+	stopOnce                 sync.Once
+	startServerOnce          sync.Once
+	startTraceReceiverOnce   sync.Once
+	startMetricsReceiverOnce sync.Once
+}
+
+var _ receiver.MetricsReceiver = (*Receiver)(nil)
+var _ receiver.TraceReceiver = (*Receiver)(nil)
+
+const source string = "OTLP"
+
+// New just creates the OTLP receiver services. It is the caller's
+// responsibility to invoke the respective Start*Reception methods as well
+// as the various Stop*Reception methods to end it.
+func New(addr string, tc consumer.TraceConsumer, mc consumer.MetricsConsumer, opts ...Option) (*Receiver, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind to address %q: %v", addr, err)
+	}
+
+	r := &Receiver{
+		ln:          ln,
+		corsOrigins: []string{}, // Disable CORS by default.
+	}
+
+	for _, opt := range opts {
+		opt.withReceiver(r)
+	}
+
+	r.traceConsumer = tc
+	r.metricsConsumer = mc
+
+	return r, nil
+}
+
+// TraceSource returns the name of the trace data source.
+func (r *Receiver) TraceSource() string {
+	return source
+}
+
+// StartTraceReception runs the trace receiver on the gRPC server. Currently
+// it also enables the metrics receiver too.
+func (r *Receiver) StartTraceReception(host receiver.Host) error {
+	return r.start()
+}
+
+func (r *Receiver) registerTraceConsumer() error {
+	var err = oterr.ErrAlreadyStarted
+
+	r.startTraceReceiverOnce.Do(func() {
+		var tr *trace.Receiver
+		tr, err = trace.New(r.traceConsumer)
+		if err == nil {
+			r.mu.Lock()
+			r.traceReceiver = tr
+			if r.traceStopped {
+				tr.Stop()
+			}
+			r.mu.Unlock()
+			srv := r.grpcServer()
+			collectortrace.RegisterTraceServiceServer(srv, tr)
+		}
+	})
+
+	return err
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (r *Receiver) MetricsSource() string {
+	return source
+}
+
+// StartMetricsReception runs the metrics receiver on the gRPC server. Currently
+// it also enables the trace receiver too.
+func (r *Receiver) StartMetricsReception(host receiver.Host) error {
+	return r.start()
+}
+
+func (r *Receiver) registerMetricsConsumer() error {
+	var err = oterr.ErrAlreadyStarted
+
+	r.startMetricsReceiverOnce.Do(func() {
+		var mr *metrics.Receiver
+		mr, err = metrics.New(r.metricsConsumer)
+		if err == nil {
+			r.mu.Lock()
+			r.metricsReceiver = mr
+			if r.metricsStopped {
+				mr.Stop()
+			}
+			r.mu.Unlock()
+			srv := r.grpcServer()
+			collectormetrics.RegisterMetricsServiceServer(srv, mr)
+		}
+	})
+	return err
+}
+
+func (r *Receiver) grpcServer() *grpc.Server {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.serverGRPC == nil {
+		r.serverGRPC = observability.GRPCServerWithObservabilityEnabled(r.grpcServerOptions...)
+	}
+
+	return r.serverGRPC
+}
+
+// StopTraceReception is a method to turn off receiving traces. The metrics
+// keep being received on the same endpoint until the metrics reception is
+// stopped too.
+func (r *Receiver) StopTraceReception() error {
+	r.mu.Lock()
+	r.traceStopped = true
+	if r.traceReceiver != nil {
+		r.traceReceiver.Stop()
+	}
+	r.mu.Unlock()
+	return r.stopIfUnused()
+}
+
+// StopMetricsReception is a method to turn off receiving metrics. The traces
+// keep being received on the same endpoint until the trace reception is
+// stopped too.
+func (r *Receiver) StopMetricsReception() error {
+	r.mu.Lock()
+	r.metricsStopped = true
+	if r.metricsReceiver != nil {
+		r.metricsReceiver.Stop()
+	}
+	r.mu.Unlock()
+	return r.stopIfUnused()
+}
+
+// stopIfUnused stops the server once the reception of both traces and
+// metrics, or of the only one with a consumer, is stopped.
+func (r *Receiver) stopIfUnused() error {
+	r.mu.Lock()
+	unused := (r.traceConsumer == nil || r.traceStopped) && (r.metricsConsumer == nil || r.metricsStopped)
+	r.mu.Unlock()
+	if !unused {
+		return nil
+	}
+	if err := r.stop(); err != oterr.ErrAlreadyStopped {
+		return err
+	}
+	return nil
+}
+
+// start runs all the receivers/services namely, Trace and Metrics services.
+func (r *Receiver) start() error {
+	hasConsumer := false
+	if r.traceConsumer != nil {
+		hasConsumer = true
+		if err := r.registerTraceConsumer(); err != nil && err != oterr.ErrAlreadyStarted {
+			return err
+		}
+	}
+
+	if r.metricsConsumer != nil {
+		hasConsumer = true
+		if err := r.registerMetricsConsumer(); err != nil && err != oterr.ErrAlreadyStarted {
+			return err
+		}
+	}
+
+	if !hasConsumer {
+		return errors.New("cannot start receiver: no consumers were specified")
+	}
+
+	if err := r.startServer(); err != nil && err != oterr.ErrAlreadyStarted {
+		return err
+	}
+	return nil
+}
+
+// stop stops the underlying servers and all the services running on them.
+func (r *Receiver) stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	r.stopOnce.Do(func() {
+		err = nil
+
+		if r.server != nil {
+			r.server.Stop()
+		}
+
+		if r.ln != nil {
+			_ = r.ln.Close()
+		}
+	})
+	return err
+}
+
+func (r *Receiver) startServer() error {
+	err := oterr.ErrAlreadyStarted
+	r.startServerOnce.Do(func() {
+		// Register the grpc-gateway on the HTTP server mux, only for
+		// the services that have a consumer.
+		var registers []receiverhelper.GatewayRegisterFunc
+		if r.traceReceiver != nil {
+			registers = append(registers, collectortrace.RegisterTraceServiceHandlerFromEndpoint)
+		}
+		if r.metricsReceiver != nil {
+			registers = append(registers, collectormetrics.RegisterMetricsServiceHandlerFromEndpoint)
+		}
+
+		r.mu.Lock()
+		r.server = receiverhelper.NewGatewayServer(
+			r.ln,
+			r.serverGRPC,
+			r.corsOrigins,
+			// Accept binary protobuf payloads besides the default JSON.
+			gatewayruntime.WithMarshalerOption("application/x-protobuf", &gatewayruntime.ProtoMarshaller{}),
+		)
+		r.mu.Unlock()
+
+		err = r.server.Start(registers...)
+	})
+	return err
+}
@@ -0,0 +1,179 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpreceiver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	collectormetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/metrics/v1"
+	collectortrace "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	otlpmetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+	otlptrace "github.com/open-telemetry/opentelemetry-proto/gen/go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+const (
+	traceJSON   = `{"resource_spans":[{"instrumentation_library_spans":[{"spans":[{"trace_id":"AQIDBAUGBwgJCgsMDQ4PEA==","span_id":"AQIDBAUGBwg=","name":"op"}]}]}]}`
+	metricsJSON = `{"resource_metrics":[{"instrumentation_library_metrics":[{"metrics":[{"metric_descriptor":{"name":"requests","type":"COUNTER_INT64"},"int64_data_points":[{"time_unix_nano":"1","value":"10"}]}]}]}]}`
+)
+
+func TestSharedEndpoint(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	traceSink := new(exportertest.SinkTraceExporter)
+	metricsSink := new(exportertest.SinkMetricsExporter)
+	r, err := New(addr, traceSink, metricsSink)
+	require.NoError(t, err)
+	defer r.StopTraceReception()
+	defer r.StopMetricsReception()
+	require.NoError(t, r.StartTraceReception(receivertest.NewMockHost()))
+	require.NoError(t, r.StartMetricsReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Both receivers are served over gRPC and HTTP on the same endpoint.
+	require.NoError(t, exportTraceGRPC(conn))
+	require.NoError(t, exportMetricsGRPC(conn))
+	assert.Equal(t, http.StatusOK, postJSON(t, addr, "/v1/trace", traceJSON))
+	assert.Equal(t, http.StatusOK, postJSON(t, addr, "/v1/metrics", metricsJSON))
+
+	assert.Len(t, traceSink.AllTraces(), 2)
+	assert.Len(t, metricsSink.AllMetrics(), 2)
+}
+
+func TestStop(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	r, err := New(addr, new(exportertest.SinkTraceExporter), new(exportertest.SinkMetricsExporter))
+	require.NoError(t, err)
+	require.NoError(t, r.StartTraceReception(receivertest.NewMockHost()))
+	require.NoError(t, r.StartMetricsReception(receivertest.NewMockHost()))
+
+	// The grpc-gateway keeps a connection open to the gRPC server, it must
+	// not prevent the server from stopping.
+	assert.Equal(t, http.StatusOK, postJSON(t, addr, "/v1/trace", traceJSON))
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		assert.NoError(t, r.StopTraceReception())
+		assert.NoError(t, r.StopMetricsReception())
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the receiver didn't stop")
+	}
+
+	_, err = http.Post("http://"+addr+"/v1/trace", "application/json", bytes.NewBufferString(traceJSON))
+	assert.Error(t, err, "the endpoint must be closed")
+	// Stopping again is a no-op.
+	assert.NoError(t, r.StopTraceReception())
+}
+
+func TestStopOneReceiver(t *testing.T) {
+	addr := testutils.GetAvailableLocalAddress(t)
+	traceSink := new(exportertest.SinkTraceExporter)
+	metricsSink := new(exportertest.SinkMetricsExporter)
+	r, err := New(addr, traceSink, metricsSink)
+	require.NoError(t, err)
+	defer r.StopMetricsReception()
+	require.NoError(t, r.StartTraceReception(receivertest.NewMockHost()))
+	require.NoError(t, r.StartMetricsReception(receivertest.NewMockHost()))
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// The traces are refused once their reception is stopped while the
+	// metrics keep being received.
+	require.NoError(t, r.StopTraceReception())
+	assert.Equal(t, codes.Unavailable, status.Code(exportTraceGRPC(conn)))
+	assert.Equal(t, http.StatusServiceUnavailable, postJSON(t, addr, "/v1/trace", traceJSON))
+	assert.Empty(t, traceSink.AllTraces())
+
+	require.NoError(t, exportMetricsGRPC(conn))
+	assert.Equal(t, http.StatusOK, postJSON(t, addr, "/v1/metrics", metricsJSON))
+	assert.Len(t, metricsSink.AllMetrics(), 2)
+}
+
+func exportTraceGRPC(conn *grpc.ClientConn) error {
+	req := &collectortrace.ExportTraceServiceRequest{
+		ResourceSpans: []*otlptrace.ResourceSpans{
+			{
+				InstrumentationLibrarySpans: []*otlptrace.InstrumentationLibrarySpans{
+					{
+						Spans: []*otlptrace.Span{
+							{
+								TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+								SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+								Name:    "op",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err := collectortrace.NewTraceServiceClient(conn).Export(context.Background(), req, grpc.WaitForReady(true))
+	return err
+}
+
+func exportMetricsGRPC(conn *grpc.ClientConn) error {
+	req := &collectormetrics.ExportMetricsServiceRequest{
+		ResourceMetrics: []*otlpmetrics.ResourceMetrics{
+			{
+				InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+					{
+						Metrics: []*otlpmetrics.Metric{
+							{
+								MetricDescriptor: &otlpmetrics.MetricDescriptor{
+									Name: "requests",
+									Type: otlpmetrics.MetricDescriptor_COUNTER_INT64,
+								},
+								Int64DataPoints: []*otlpmetrics.Int64DataPoint{
+									{TimeUnixNano: 1, Value: 10},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err := collectormetrics.NewMetricsServiceClient(conn).Export(context.Background(), req, grpc.WaitForReady(true))
+	return err
+}
+
+// postJSON posts the body to the grpc-gateway and returns the status code of
+// the response.
+func postJSON(t *testing.T, addr, path, body string) int {
+	resp, err := http.Post("http://"+addr+path, "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
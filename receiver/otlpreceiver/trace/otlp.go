@@ -0,0 +1,94 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"sync/atomic"
+
+	collectortrace "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
+	"github.com/open-telemetry/opentelemetry-service/translator/otlp"
+)
+
+const receiverTagValue = "otlp_trace"
+
+// Receiver is the type used to handle spans from OTLP exporters.
+type Receiver struct {
+	nextConsumer consumer.TraceConsumer
+	// stopped is set to 1 once the reception of traces is stopped.
+	stopped int32
+}
+
+// New creates a new Receiver reference.
+func New(nextConsumer consumer.TraceConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	return &Receiver{nextConsumer: nextConsumer}, nil
+}
+
+// errStopped is returned to the requests received once the reception of traces
+// is stopped, while the server keeps serving the other receivers.
+var errStopped = status.Error(codes.Unavailable, "the OTLP trace receiver is stopped")
+
+// Stop makes the receiver refuse the requests, it can't be restarted.
+func (r *Receiver) Stop() {
+	atomic.StoreInt32(&r.stopped, 1)
+}
+
+var _ collectortrace.TraceServiceServer = (*Receiver)(nil)
+
+// Export is the gRPC method that receives the spans of OTLP exporters,
+// converts them and passes them onto the next consumer.
+func (r *Receiver) Export(ctx context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	if atomic.LoadInt32(&r.stopped) != 0 {
+		return nil, errStopped
+	}
+
+	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, receiverTagValue)
+
+	tds, dropped := otlp.ResourceSpansToTraceData(req.ResourceSpans)
+	numSpans := 0
+	// Keep the first error returned by the next consumer, any of them tells
+	// the client how it should proceed, e.g.: retry later if throttled.
+	var err error
+	for _, td := range tds {
+		if len(td.Spans) == 0 {
+			continue
+		}
+		numSpans += len(td.Spans)
+		if cerr := r.nextConsumer.ConsumeTraceData(ctxWithReceiverName, td); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	receiverhelper.RecordTraceReceiverMetrics(ctxWithReceiverName, numSpans+dropped.Total(), dropped)
+
+	if err != nil {
+		if md := receiverhelper.GRPCRetryAfter(err); md != nil {
+			_ = grpc.SetHeader(ctx, md)
+		}
+		return nil, receiverhelper.GRPCError(err)
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	collectortrace "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlpresource "github.com/open-telemetry/opentelemetry-proto/gen/go/resource/v1"
+	otlptrace "github.com/open-telemetry/opentelemetry-proto/gen/go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+)
+
+func TestExport(t *testing.T) {
+	sink := new(exportertest.SinkTraceExporter)
+	r, err := New(sink)
+	require.NoError(t, err)
+
+	req := &collectortrace.ExportTraceServiceRequest{
+		ResourceSpans: []*otlptrace.ResourceSpans{
+			{
+				Resource: &otlpresource.Resource{
+					Attributes: []*otlpcommon.AttributeKeyValue{
+						{Key: "service.name", StringValue: "api"},
+					},
+				},
+				InstrumentationLibrarySpans: []*otlptrace.InstrumentationLibrarySpans{
+					{
+						Spans: []*otlptrace.Span{
+							{
+								TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+								SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+								Name:    "operation",
+							},
+							{
+								TraceId: make([]byte, 16),
+								SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resp, err := r.Export(context.Background(), req)
+	require.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	assert.Equal(t, "api", got[0].Node.ServiceInfo.Name)
+	require.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "operation", got[0].Spans[0].Name.Value)
+}
+
+func TestExport_NextConsumerError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{
+			name:     "throttled",
+			err:      consumererror.Throttled(errors.New("queue is full"), time.Second),
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			// Other errors are returned as is.
+			name:     "other",
+			err:      errors.New("unavailable"),
+			wantCode: codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(errConsumer{err: tt.err})
+			require.NoError(t, err)
+
+			req := &collectortrace.ExportTraceServiceRequest{
+				ResourceSpans: []*otlptrace.ResourceSpans{
+					{
+						InstrumentationLibrarySpans: []*otlptrace.InstrumentationLibrarySpans{
+							{
+								Spans: []*otlptrace.Span{
+									{
+										TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+										SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			resp, err := r.Export(context.Background(), req)
+			assert.Nil(t, resp)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+}
+
+type errConsumer struct {
+	err error
+}
+
+func (ec errConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	return ec.err
+}
+
+func TestExport_Stopped(t *testing.T) {
+	sink := new(exportertest.SinkTraceExporter)
+	r, err := New(sink)
+	require.NoError(t, err)
+	r.Stop()
+
+	req := &collectortrace.ExportTraceServiceRequest{
+		ResourceSpans: []*otlptrace.ResourceSpans{
+			{
+				InstrumentationLibrarySpans: []*otlptrace.InstrumentationLibrarySpans{
+					{
+						Spans: []*otlptrace.Span{
+							{
+								TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+								SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	resp, err := r.Export(context.Background(), req)
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Empty(t, sink.AllTraces())
+}
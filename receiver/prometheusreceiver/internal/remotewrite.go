@@ -0,0 +1,224 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/textparse"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/prometheus/prometheus/scrape"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+const metricsSuffixTotal = "_total"
+
+// remoteWriteMetadata is the MetadataCache used for the series of a single remote-write request. The remote-write
+// protocol doesn't carry the TYPE and HELP hints of the scraped pages, so the types are inferred from the shape of
+// the series instead, see inferRemoteWriteMetadata.
+type remoteWriteMetadata map[string]scrape.MetricMetadata
+
+func (m remoteWriteMetadata) Metadata(metricName string) (scrape.MetricMetadata, bool) {
+	md, ok := m[metricName]
+	return md, ok
+}
+
+func (m remoteWriteMetadata) SharedLabels() labels.Labels {
+	return nil
+}
+
+// familyName returns the name of the metric family the given metric is going to be added to by the metricBuilder.
+func (m remoteWriteMetadata) familyName(metricName string) string {
+	if familyName := normalizeMetricName(metricName); familyName != metricName {
+		if _, ok := m[familyName]; ok {
+			return familyName
+		}
+	}
+	return metricName
+}
+
+// inferRemoteWriteMetadata guesses the metric types of the given series:
+// - a "_bucket" series with a "le" label makes its family a histogram,
+// - a series with a "quantile" label makes its family a summary,
+// - a "_total" series is a counter,
+// - anything else that is not part of a histogram or summary is a gauge.
+func inferRemoteWriteMetadata(series []prompb.TimeSeries) remoteWriteMetadata {
+	md := make(remoteWriteMetadata)
+	add := func(name string, mtype textparse.MetricType) {
+		md[name] = scrape.MetricMetadata{Metric: name, Type: mtype}
+	}
+
+	for _, ts := range series {
+		ls := labelsFromProto(ts.Labels)
+		name := ls.Get(model.MetricNameLabel)
+		switch {
+		case strings.HasSuffix(name, metricsSuffixBucket) && ls.Get(model.BucketLabel) != "":
+			add(normalizeMetricName(name), textparse.MetricTypeHistogram)
+		case ls.Get(model.QuantileLabel) != "":
+			add(name, textparse.MetricTypeSummary)
+		}
+	}
+
+	for _, ts := range series {
+		name := labelsFromProto(ts.Labels).Get(model.MetricNameLabel)
+		if name == "" || md.familyName(name) != name {
+			continue
+		}
+		if _, ok := md[name]; ok {
+			continue
+		}
+		if strings.HasSuffix(name, metricsSuffixTotal) {
+			add(name, textparse.MetricTypeCounter)
+		} else {
+			add(name, textparse.MetricTypeGauge)
+		}
+	}
+	return md
+}
+
+func labelsFromProto(pls []prompb.Label) labels.Labels {
+	ls := make(labels.Labels, 0, len(pls))
+	for _, l := range pls {
+		ls = append(ls, labels.Label{Name: l.Name, Value: l.Value})
+	}
+	sort.Sort(ls)
+	return ls
+}
+
+// remoteWriteBatch holds the samples of a remote-write request that share the same job, instance and timestamp,
+// i.e. the samples which were most likely produced by the same scrape.
+type remoteWriteBatch struct {
+	remoteWriteBatchKey
+	points []remoteWritePoint
+}
+
+type remoteWriteBatchKey struct {
+	job      string
+	instance string
+	ts       int64
+}
+
+type remoteWritePoint struct {
+	family string
+	ls     labels.Labels
+	v      float64
+}
+
+// RemoteWriteToMetricsData converts the series of a Prometheus remote-write request into MetricsData, one for each
+// job, instance and timestamp found in the request. The job and instance labels are turned into the Node and the
+// Resource of the MetricsData. If jobsMap is not nil, the cumulative metrics are adjusted to carry their start time
+// just like the ones which are scraped. It also returns the total number of timeseries and how many of them were
+// dropped.
+func RemoteWriteToMetricsData(req *prompb.WriteRequest, jobsMap *JobsMap, logger *zap.SugaredLogger) ([]consumerdata.MetricsData, int, int) {
+	if req == nil || len(req.Timeseries) == 0 {
+		return nil, 0, 0
+	}
+
+	md := inferRemoteWriteMetadata(req.Timeseries)
+	batches := make(map[remoteWriteBatchKey]*remoteWriteBatch)
+	for _, series := range req.Timeseries {
+		ls := labelsFromProto(series.Labels)
+		job, instance := ls.Get(model.JobLabel), ls.Get(model.InstanceLabel)
+		family := md.familyName(ls.Get(model.MetricNameLabel))
+		for _, s := range series.Samples {
+			// stale markers and any other NaN values are dropped, just like the prometheus receiver does
+			if math.IsNaN(s.Value) {
+				continue
+			}
+			key := remoteWriteBatchKey{job: job, instance: instance, ts: s.Timestamp}
+			b, ok := batches[key]
+			if !ok {
+				b = &remoteWriteBatch{remoteWriteBatchKey: key}
+				batches[key] = b
+			}
+			b.points = append(b.points, remoteWritePoint{family: family, ls: ls, v: s.Value})
+		}
+	}
+
+	ordered := make([]*remoteWriteBatch, 0, len(batches))
+	for _, b := range batches {
+		ordered = append(ordered, b)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].job != ordered[j].job {
+			return ordered[i].job < ordered[j].job
+		}
+		if ordered[i].instance != ordered[j].instance {
+			return ordered[i].instance < ordered[j].instance
+		}
+		return ordered[i].ts < ordered[j].ts
+	})
+
+	var mds []consumerdata.MetricsData
+	numTimeseries, droppedTimeseries := 0, 0
+	for _, b := range ordered {
+		// the metricBuilder expects the data points of a metric family to be added one after another
+		sort.SliceStable(b.points, func(i, j int) bool {
+			return b.points[i].family < b.points[j].family
+		})
+
+		mb := newMetricBuilder(md, logger)
+		for _, p := range b.points {
+			if err := mb.AddDataPoint(p.ls, b.ts, p.v); err != nil {
+				logger.Debugw("failed to add remote-write data point", "labels", p.ls.Map(), "error", err)
+			}
+		}
+		metrics, ts, dts, err := mb.Build()
+		numTimeseries += ts
+		droppedTimeseries += dts
+		if err != nil {
+			continue
+		}
+		if jobsMap != nil {
+			metrics = NewMetricsAdjuster(jobsMap.get(b.job, b.instance), logger).AdjustMetrics(metrics)
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		node, resource := remoteWriteNodeAndResource(b.job, b.instance)
+		mds = append(mds, consumerdata.MetricsData{
+			Node:     node,
+			Resource: resource,
+			Metrics:  metrics,
+		})
+	}
+	return mds, numTimeseries, droppedTimeseries
+}
+
+func remoteWriteNodeAndResource(job, instance string) (*commonpb.Node, *resourcepb.Resource) {
+	if job == "" && instance == "" {
+		return nil, nil
+	}
+	// the scheme used to scrape the target is not known to the remote-write receiver
+	node := createNode(job, instance, "")
+	delete(node.Attributes, schemeAttr)
+
+	resourceLabels := make(map[string]string, 2)
+	if job != "" {
+		resourceLabels[model.JobLabel] = job
+	}
+	if instance != "" {
+		resourceLabels[model.InstanceLabel] = instance
+	}
+	return node, &resourcepb.Resource{Labels: resourceLabels}
+}
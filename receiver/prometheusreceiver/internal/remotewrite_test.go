@@ -0,0 +1,171 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/prometheus/prometheus/pkg/textparse"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func remoteWriteSeries(ts int64, v float64, nameAndLabels ...string) prompb.TimeSeries {
+	ls := []prompb.Label{{Name: "__name__", Value: nameAndLabels[0]}}
+	for i := 1; i+1 < len(nameAndLabels); i += 2 {
+		ls = append(ls, prompb.Label{Name: nameAndLabels[i], Value: nameAndLabels[i+1]})
+	}
+	return prompb.TimeSeries{Labels: ls, Samples: []prompb.Sample{{Value: v, Timestamp: ts}}}
+}
+
+func Test_inferRemoteWriteMetadata(t *testing.T) {
+	md := inferRemoteWriteMetadata([]prompb.TimeSeries{
+		remoteWriteSeries(startTs, 1, "hist_test_bucket", "le", "10"),
+		remoteWriteSeries(startTs, 2, "hist_test_bucket", "le", "+Inf"),
+		remoteWriteSeries(startTs, 2, "hist_test_count"),
+		remoteWriteSeries(startTs, 5, "hist_test_sum"),
+		remoteWriteSeries(startTs, 1, "summary_test", "quantile", "0.5"),
+		remoteWriteSeries(startTs, 3, "summary_test_count"),
+		remoteWriteSeries(startTs, 4, "summary_test_sum"),
+		remoteWriteSeries(startTs, 7, "requests_total"),
+		remoteWriteSeries(startTs, 8, "poor_name_count"),
+		remoteWriteSeries(startTs, 9, "gauge_test"),
+	})
+
+	want := map[string]textparse.MetricType{
+		"hist_test":       textparse.MetricTypeHistogram,
+		"summary_test":    textparse.MetricTypeSummary,
+		"requests_total":  textparse.MetricTypeCounter,
+		"poor_name_count": textparse.MetricTypeGauge,
+		"gauge_test":      textparse.MetricTypeGauge,
+	}
+	require.Equal(t, len(want), len(md))
+	for name, mtype := range want {
+		assert.Equal(t, mtype, md[name].Type, name)
+	}
+
+	assert.Equal(t, "hist_test", md.familyName("hist_test_count"))
+	assert.Equal(t, "summary_test", md.familyName("summary_test_sum"))
+	assert.Equal(t, "poor_name_count", md.familyName("poor_name_count"))
+}
+
+func Test_RemoteWriteToMetricsData(t *testing.T) {
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		remoteWriteSeries(startTs, 1, "hist_test_bucket", "job", "test", "instance", "localhost:8080", "le", "10"),
+		remoteWriteSeries(startTs, 9, "gauge_test", "job", "test", "instance", "localhost:8080", "t1", "1"),
+		remoteWriteSeries(startTs, 2, "hist_test_bucket", "job", "test", "instance", "localhost:8080", "le", "+Inf"),
+		remoteWriteSeries(startTs, 2, "hist_test_count", "job", "test", "instance", "localhost:8080"),
+		remoteWriteSeries(startTs, 5, "hist_test_sum", "job", "test", "instance", "localhost:8080"),
+		remoteWriteSeries(startTs, math.NaN(), "stale_test", "job", "test", "instance", "localhost:8080"),
+		remoteWriteSeries(startTs+interval, 3, "gauge_test", "job", "test", "instance", "localhost:8080", "t1", "1"),
+		remoteWriteSeries(startTs, 4, "gauge_test", "job", "other", "instance", "otherhost"),
+	}}
+
+	mds, numTimeseries, droppedTimeseries := RemoteWriteToMetricsData(req, nil, zap.NewNop().Sugar())
+	assert.Equal(t, 4, numTimeseries)
+	assert.Equal(t, 0, droppedTimeseries)
+	require.Len(t, mds, 3)
+
+	// the batches are ordered by job, instance and timestamp
+	assert.Equal(t, &commonpb.Node{
+		ServiceInfo: &commonpb.ServiceInfo{Name: "other"},
+		Identifier:  &commonpb.ProcessIdentifier{HostName: "otherhost"},
+		Attributes:  map[string]string{portAttr: "80"},
+	}, mds[0].Node)
+	assert.Equal(t, &resourcepb.Resource{Labels: map[string]string{"job": "other", "instance": "otherhost"}}, mds[0].Resource)
+	require.Len(t, mds[0].Metrics, 1)
+	assert.Equal(t, "gauge_test", mds[0].Metrics[0].MetricDescriptor.Name)
+
+	assert.Equal(t, &commonpb.Node{
+		ServiceInfo: &commonpb.ServiceInfo{Name: "test"},
+		Identifier:  &commonpb.ProcessIdentifier{HostName: "localhost"},
+		Attributes:  map[string]string{portAttr: "8080"},
+	}, mds[1].Node)
+	require.Len(t, mds[1].Metrics, 2)
+
+	gauge := mds[1].Metrics[0]
+	assert.Equal(t, "gauge_test", gauge.MetricDescriptor.Name)
+	assert.Equal(t, metricspb.MetricDescriptor_GAUGE_DOUBLE, gauge.MetricDescriptor.Type)
+	assert.Equal(t, []*metricspb.LabelKey{{Key: "t1"}}, gauge.MetricDescriptor.LabelKeys)
+	require.Len(t, gauge.Timeseries, 1)
+	assert.Nil(t, gauge.Timeseries[0].StartTimestamp)
+	assert.Equal(t, []*metricspb.LabelValue{{Value: "1", HasValue: true}}, gauge.Timeseries[0].LabelValues)
+	assert.Equal(t, timestampFromMs(startTs), gauge.Timeseries[0].Points[0].Timestamp)
+	assert.Equal(t, 9.0, gauge.Timeseries[0].Points[0].GetDoubleValue())
+
+	hist := mds[1].Metrics[1]
+	assert.Equal(t, "hist_test", hist.MetricDescriptor.Name)
+	assert.Equal(t, metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION, hist.MetricDescriptor.Type)
+	require.Len(t, hist.Timeseries, 1)
+	dv := hist.Timeseries[0].Points[0].GetDistributionValue()
+	assert.Equal(t, int64(2), dv.Count)
+	assert.Equal(t, 5.0, dv.Sum)
+	assert.Equal(t, []float64{10}, dv.BucketOptions.GetExplicit().Bounds)
+	assert.Equal(t, []*metricspb.DistributionValue_Bucket{{Count: 1}, {Count: 1}}, dv.Buckets)
+
+	require.Len(t, mds[2].Metrics, 1)
+	assert.Equal(t, timestampFromMs(startTs+interval), mds[2].Metrics[0].Timeseries[0].Points[0].Timestamp)
+	assert.Equal(t, 3.0, mds[2].Metrics[0].Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func Test_RemoteWriteToMetricsData_adjustsCounters(t *testing.T) {
+	jobsMap := NewJobsMap(time.Minute)
+	logger := zap.NewNop().Sugar()
+	req := func(ts int64, v float64) *prompb.WriteRequest {
+		return &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+			remoteWriteSeries(ts, v, "requests_total", "job", "test", "instance", "localhost:8080"),
+		}}
+	}
+
+	// the first point of a cumulative timeseries is only used as its initial value
+	mds, numTimeseries, _ := RemoteWriteToMetricsData(req(startTs, 10), jobsMap, logger)
+	assert.Equal(t, 1, numTimeseries)
+	assert.Empty(t, mds)
+
+	mds, _, _ = RemoteWriteToMetricsData(req(startTs+interval, 15), jobsMap, logger)
+	require.Len(t, mds, 1)
+	require.Len(t, mds[0].Metrics, 1)
+	m := mds[0].Metrics[0]
+	assert.Equal(t, metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, m.MetricDescriptor.Type)
+	assert.Equal(t, timestampFromMs(startTs), m.Timeseries[0].StartTimestamp)
+	assert.Equal(t, 5.0, m.Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func Test_RemoteWriteToMetricsData_noJobInstance(t *testing.T) {
+	req := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		remoteWriteSeries(startTs, 1, "gauge_test"),
+		{Labels: []prompb.Label{{Name: "foo", Value: "bar"}}, Samples: []prompb.Sample{{Value: 1, Timestamp: startTs}}},
+	}}
+
+	mds, numTimeseries, droppedTimeseries := RemoteWriteToMetricsData(req, nil, zap.NewNop().Sugar())
+	assert.Equal(t, 2, numTimeseries)
+	assert.Equal(t, 1, droppedTimeseries)
+	require.Len(t, mds, 1)
+	assert.Nil(t, mds[0].Node)
+	assert.Nil(t, mds[0].Resource)
+	require.Len(t, mds[0].Metrics, 1)
+
+	mds, numTimeseries, droppedTimeseries = RemoteWriteToMetricsData(&prompb.WriteRequest{}, nil, zap.NewNop().Sugar())
+	assert.Nil(t, mds)
+	assert.Equal(t, 0, numTimeseries)
+	assert.Equal(t, 0, droppedTimeseries)
+}
@@ -0,0 +1,224 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewritereceiver
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/prometheusreceiver/internal"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
+)
+
+var _ receiver.MetricsReceiver = (*Receiver)(nil)
+var _ http.Handler = (*Receiver)(nil)
+
+const (
+	metricsSource    string = "PrometheusRemoteWrite"
+	receiverTagValue        = "prometheus_remote_write"
+
+	// jobsMapGCInterval is how often the start times kept for the cumulative
+	// metrics of the jobs that stopped sending data are garbage collected.
+	jobsMapGCInterval = 2 * time.Minute
+
+	// maxRequestSize bounds the size of the compressed and of the
+	// decompressed remote-write requests, Prometheus sends far smaller ones.
+	maxRequestSize = 32 << 20
+)
+
+var (
+	errEmptyPath       = errors.New("the Prometheus remote-write receiver path cannot be empty")
+	errRequestTooLarge = fmt.Errorf("the remote-write request is larger than %d bytes", maxRequestSize)
+)
+
+// Receiver is the type used to receive the metrics pushed by Prometheus
+// remote-write clients.
+type Receiver struct {
+	mu sync.Mutex
+
+	logger       *zap.Logger
+	config       *Config
+	nextConsumer consumer.MetricsConsumer
+	tlsConfig    *tls.Config
+	jobsMap      *internal.JobsMap
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	server    *http.Server
+}
+
+// New creates a Prometheus remote-write receiver, its HTTP server is only
+// started by StartMetricsReception.
+func New(logger *zap.Logger, config *Config, nextConsumer consumer.MetricsConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if config.Path == "" {
+		return nil, errEmptyPath
+	}
+
+	r := &Receiver{
+		logger:       logger,
+		config:       config,
+		nextConsumer: nextConsumer,
+		jobsMap:      internal.NewJobsMap(jobsMapGCInterval),
+	}
+	if config.TLSCredentials != nil {
+		cert, err := tls.LoadX509KeyPair(config.TLSCredentials.CertFile, config.TLSCredentials.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Prometheus remote-write receiver %q TLS Credentials: %v", config.NameVal, err)
+		}
+		r.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	return r, nil
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (r *Receiver) MetricsSource() string {
+	return metricsSource
+}
+
+// StartMetricsReception spins up the receiver's HTTP server.
+func (r *Receiver) StartMetricsReception(host receiver.Host) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+	r.startOnce.Do(func() {
+		ln, lerr := net.Listen("tcp", r.config.Endpoint)
+		if lerr != nil {
+			err = lerr
+			return
+		}
+		if r.tlsConfig != nil {
+			ln = tls.NewListener(ln, r.tlsConfig)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(r.config.Path, r)
+		r.server = &http.Server{Handler: mux}
+		go func() {
+			if serr := r.server.Serve(ln); serr != http.ErrServerClosed {
+				host.ReportFatalError(serr)
+			}
+		}()
+
+		err = nil
+	})
+	return err
+}
+
+// StopMetricsReception shuts down the receiver's HTTP server.
+func (r *Receiver) StopMetricsReception() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStopped
+	r.stopOnce.Do(func() {
+		err = nil
+		if r.server != nil {
+			err = r.server.Close()
+		}
+	})
+	return err
+}
+
+// ServeHTTP handles a remote-write request, i.e. a snappy compressed
+// WriteRequest protobuf message.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := observability.ContextWithReceiverName(req.Context(), receiverTagValue)
+
+	if req.ContentLength > maxRequestSize {
+		http.Error(w, errRequestTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	wr, err := decodeWriteRequest(w, req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mds, numTimeseries, droppedTimeseries := internal.RemoteWriteToMetricsData(wr, r.jobsMap, r.logger.Sugar())
+	observability.RecordMetricsForMetricsReceiver(ctx, numTimeseries, droppedTimeseries)
+
+	consumed := 0
+	var consumerErr error
+	for _, md := range mds {
+		if err := r.nextConsumer.ConsumeMetricsData(ctx, md); err != nil {
+			if consumerErr == nil {
+				consumerErr = err
+			}
+			continue
+		}
+		consumed++
+	}
+
+	if consumerErr != nil {
+		if consumed == 0 {
+			// Prometheus retries the requests that failed with a 5xx status
+			// and drops the ones which failed with a 4xx status, 429 included.
+			receiverhelper.SetHTTPRetryAfter(w.Header(), consumerErr)
+			http.Error(w, consumerErr.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		// Retrying the request would duplicate the data already consumed.
+		r.logger.Warn("Failed to pass part of the remote-write request to the next consumer", zap.Error(consumerErr))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeWriteRequest(w http.ResponseWriter, req *http.Request) (*prompb.WriteRequest, error) {
+	compressed, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestSize))
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the remote-write request: %v", err)
+	}
+
+	if n, err := snappy.DecodedLen(compressed); err == nil && n > maxRequestSize {
+		return nil, errRequestTooLarge
+	}
+	buf, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the remote-write request: %v", err)
+	}
+
+	var wr prompb.WriteRequest
+	if err := wr.Unmarshal(buf); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the remote-write request: %v", err)
+	}
+	return &wr, nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remotewritereceiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func startReceiver(t *testing.T, next consumer.MetricsConsumer) (*Receiver, string) {
+	cfg := &Config{
+		SecureReceiverSettings: receiver.SecureReceiverSettings{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  typeStr,
+				Endpoint: testutils.GetAvailableLocalAddress(t),
+			},
+		},
+		Path: defaultPath,
+	}
+	r, err := New(zap.NewNop(), cfg, next)
+	require.NoError(t, err)
+
+	mh := receivertest.NewMockHost()
+	require.NoError(t, r.StartMetricsReception(mh))
+	assert.Equal(t, oterr.ErrAlreadyStarted, r.StartMetricsReception(mh))
+	return r, "http://" + cfg.Endpoint + cfg.Path
+}
+
+func postWriteRequest(t *testing.T, url string, wr *prompb.WriteRequest) *http.Response {
+	buf, err := wr.Marshal()
+	require.NoError(t, err)
+	resp, err := http.Post(url, "application/x-protobuf", bytes.NewReader(snappy.Encode(nil, buf)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp
+}
+
+func TestReceiver(t *testing.T) {
+	sink := new(exportertest.SinkMetricsExporter)
+	r, url := startReceiver(t, sink)
+
+	wr := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels: []prompb.Label{
+			{Name: "__name__", Value: "temperature_celsius"},
+			{Name: "job", Value: "thermometer"},
+			{Name: "instance", Value: "kitchen:9100"},
+			{Name: "floor", Value: "1"},
+		},
+		Samples: []prompb.Sample{{Value: 21.5, Timestamp: 1555366610000}},
+	}}}
+	resp := postWriteRequest(t, url, wr)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	require.NoError(t, r.StopMetricsReception())
+	assert.Equal(t, oterr.ErrAlreadyStopped, r.StopMetricsReception())
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	assert.Equal(t, "thermometer", got[0].Node.ServiceInfo.Name)
+	assert.Equal(t, "kitchen", got[0].Node.Identifier.HostName)
+	assert.Equal(t, map[string]string{"job": "thermometer", "instance": "kitchen:9100"}, got[0].Resource.Labels)
+	require.Len(t, got[0].Metrics, 1)
+	m := got[0].Metrics[0]
+	assert.Equal(t, "temperature_celsius", m.MetricDescriptor.Name)
+	require.Len(t, m.Timeseries, 1)
+	assert.Equal(t, "1", m.Timeseries[0].LabelValues[0].Value)
+	assert.Equal(t, 21.5, m.Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func TestReceiver_badRequests(t *testing.T) {
+	r, url := startReceiver(t, new(exportertest.SinkMetricsExporter))
+	defer r.StopMetricsReception()
+
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// not snappy compressed
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader([]byte("not snappy")))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// not a WriteRequest
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(snappy.Encode(nil, []byte{0xff, 0xff})))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// too large, replied without reading the body
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(nil))
+	req.ContentLength = maxRequestSize + 1
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// decompresses into a too large request
+	resp, err = http.Post(url, "application/x-protobuf", bytes.NewReader(snappy.Encode(nil, make([]byte, maxRequestSize+1))))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestReceiver_consumerError(t *testing.T) {
+	next := exportertest.NewNopMetricsExporter(exportertest.WithReturnError(errors.New("pipeline is full")))
+	r, url := startReceiver(t, next)
+	defer r.StopMetricsReception()
+
+	wr := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "temperature_celsius"}},
+		Samples: []prompb.Sample{{Value: 21.5, Timestamp: 1555366610000}},
+	}}}
+	resp := postWriteRequest(t, url, wr)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestReceiver_throttled(t *testing.T) {
+	throttledErr := consumererror.Throttled(errors.New("pipeline is full"), 3*time.Second)
+	next := exportertest.NewNopMetricsExporter(exportertest.WithReturnError(throttledErr))
+	r, url := startReceiver(t, next)
+	defer r.StopMetricsReception()
+
+	wr := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{{
+		Labels:  []prompb.Label{{Name: "__name__", Value: "temperature_celsius"}},
+		Samples: []prompb.Sample{{Value: 21.5, Timestamp: 1555366610000}},
+	}}}
+	resp := postWriteRequest(t, url, wr)
+	// Prometheus drops the requests failing with a 4xx status, 429 included.
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "3", resp.Header.Get("Retry-After"))
+}
+
+// failingAfterMetricsConsumer accepts the first metrics data and fails the
+// others.
+type failingAfterMetricsConsumer struct {
+	accepted int
+	calls    int
+}
+
+func (c *failingAfterMetricsConsumer) ConsumeMetricsData(ctx context.Context, md consumerdata.MetricsData) error {
+	c.calls++
+	if c.calls > c.accepted {
+		return consumererror.Throttled(errors.New("pipeline is full"), time.Second)
+	}
+	return nil
+}
+
+func TestReceiver_partialConsumerError(t *testing.T) {
+	next := &failingAfterMetricsConsumer{accepted: 1}
+	r, url := startReceiver(t, next)
+	defer r.StopMetricsReception()
+
+	// Two jobs: one metrics data each.
+	wr := &prompb.WriteRequest{Timeseries: []prompb.TimeSeries{
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "temperature_celsius"}, {Name: "job", Value: "kitchen"}},
+			Samples: []prompb.Sample{{Value: 21.5, Timestamp: 1555366610000}},
+		},
+		{
+			Labels:  []prompb.Label{{Name: "__name__", Value: "temperature_celsius"}, {Name: "job", Value: "garage"}},
+			Samples: []prompb.Sample{{Value: 12.5, Timestamp: 1555366610000}},
+		},
+	}}
+	buf, err := wr.Marshal()
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(snappy.Encode(nil, buf)))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	// Part of the request was consumed, retrying it would duplicate it.
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, 2, next.calls)
+}
@@ -0,0 +1,98 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package receiverhelper provides helpers shared by the receivers to translate
+// the errors returned by the pipeline into the responses of their protocols, to
+// record the metrics about the data they receive and to serve gRPC services
+// along with their grpc-gateway on a single port.
+package receiverhelper
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	gatewayruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+)
+
+const (
+	// retryAfterHTTPHeader is the HTTP header used to tell clients how long
+	// they should wait before sending throttled data again.
+	retryAfterHTTPHeader = "Retry-After"
+	// retryAfterMetadataKey is the gRPC metadata equivalent of the
+	// Retry-After HTTP header.
+	retryAfterMetadataKey = "retry-after"
+)
+
+// WriteHTTPThrottled replies 429 (Too Many Requests) with the Retry-After
+// header to a request whose data was throttled by the next consumer.
+func WriteHTTPThrottled(w http.ResponseWriter, err error) {
+	SetHTTPRetryAfter(w.Header(), err)
+	http.Error(w, err.Error(), http.StatusTooManyRequests)
+}
+
+// SetHTTPRetryAfter sets the Retry-After header telling the client how long to
+// wait before sending throttled data again. It does nothing if the data was
+// not throttled.
+func SetHTTPRetryAfter(header http.Header, err error) {
+	if consumererror.IsThrottled(err) {
+		header.Set(retryAfterHTTPHeader, retryAfterSeconds(err))
+	}
+}
+
+// GRPCError converts the error returned by the next consumer into the gRPC
+// status error replied to the client: RESOURCE_EXHAUSTED if the data was
+// throttled. Any other error is returned unchanged.
+func GRPCError(err error) error {
+	if consumererror.IsThrottled(err) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return err
+}
+
+// GRPCRetryAfter returns the metadata that should be sent as header along with
+// the status returned by GRPCError to tell the client how long to wait before
+// sending the data again. It is nil if the data was not throttled.
+func GRPCRetryAfter(err error) metadata.MD {
+	if !consumererror.IsThrottled(err) {
+		return nil
+	}
+	return metadata.Pairs(retryAfterMetadataKey, retryAfterSeconds(err))
+}
+
+// GatewayOutgoingHeaderMatcher is a grpc-gateway header matcher that forwards
+// the metadata set by GRPCRetryAfter as the Retry-After HTTP header, keeping the
+// default behavior for any other metadata.
+func GatewayOutgoingHeaderMatcher(key string) (string, bool) {
+	if key == retryAfterMetadataKey {
+		return retryAfterHTTPHeader, true
+	}
+	return gatewayruntime.MetadataHeaderPrefix + key, true
+}
+
+// retryAfterSeconds returns the value of Retry-After for a throttled error,
+// rounded up to whole seconds since that is the granularity of the header.
+func retryAfterSeconds(err error) string {
+	retryAfter := consumererror.RetryAfter(err)
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
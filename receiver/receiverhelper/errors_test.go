@@ -0,0 +1,100 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package receiverhelper
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
+)
+
+func TestErrorTranslation(t *testing.T) {
+	otherErr := errors.New("exporter failure")
+	tests := []struct {
+		name       string
+		err        error
+		grpcErr    error
+		retryAfter string
+	}{
+		{
+			name: "nil",
+		},
+		{
+			name:       "throttled",
+			err:        consumererror.Throttled(errors.New("queue is full"), 1500*time.Millisecond),
+			grpcErr:    status.Error(codes.ResourceExhausted, "queue is full"),
+			retryAfter: "2",
+		},
+		{
+			name:       "throttled_without_hint",
+			err:        consumererror.Throttled(errors.New("queue is full"), 0),
+			grpcErr:    status.Error(codes.ResourceExhausted, "queue is full"),
+			retryAfter: "1",
+		},
+		{
+			name:    "permanent",
+			err:     consumererror.Permanent(otherErr),
+			grpcErr: consumererror.Permanent(otherErr),
+		},
+		{
+			name:    "other",
+			err:     otherErr,
+			grpcErr: otherErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.grpcErr, GRPCError(tt.err))
+
+			md := GRPCRetryAfter(tt.err)
+			if tt.retryAfter == "" {
+				assert.Nil(t, md)
+			} else {
+				assert.Equal(t, metadata.Pairs("retry-after", tt.retryAfter), md)
+			}
+
+			header := make(http.Header)
+			SetHTTPRetryAfter(header, tt.err)
+			assert.Equal(t, tt.retryAfter, header.Get("Retry-After"))
+		})
+	}
+}
+
+func TestWriteHTTPThrottled(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteHTTPThrottled(rec, consumererror.Throttled(errors.New("queue is full"), 3*time.Second))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get("Retry-After"))
+}
+
+func TestGatewayOutgoingHeaderMatcher(t *testing.T) {
+	key, ok := GatewayOutgoingHeaderMatcher("retry-after")
+	assert.True(t, ok)
+	assert.Equal(t, "Retry-After", key)
+
+	key, ok = GatewayOutgoingHeaderMatcher("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "Grpc-Metadata-foo", key)
+}
@@ -0,0 +1,193 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package receiverhelper
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	gatewayruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/rs/cors"
+	"github.com/soheilhy/cmux"
+	"google.golang.org/grpc"
+)
+
+// GatewayRegisterFunc registers on mux the grpc-gateway handlers of a service
+// served by the gRPC server at endpoint. The generated
+// Register*HandlerFromEndpoint functions have this signature.
+type GatewayRegisterFunc func(ctx context.Context, mux *gatewayruntime.ServeMux, endpoint string, opts []grpc.DialOption) error
+
+// GatewayServer serves a gRPC server and the grpc-gateway translating HTTP
+// requests to it on the same listener.
+type GatewayServer struct {
+	ln         *trackingListener
+	grpcServer *grpc.Server
+	gatewayMux *gatewayruntime.ServeMux
+	httpServer *http.Server
+
+	// ctx bounds the connections of the grpc-gateway to the gRPC server.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewGatewayServer creates a GatewayServer for the given listener and gRPC
+// server. The grpc-gateway accepts CORS requests from corsOrigins, if any,
+// and forwards the retry-after metadata of throttled requests as the
+// Retry-After HTTP header.
+func NewGatewayServer(
+	ln net.Listener,
+	grpcServer *grpc.Server,
+	corsOrigins []string,
+	muxOpts ...gatewayruntime.ServeMuxOption,
+) *GatewayServer {
+	muxOpts = append([]gatewayruntime.ServeMuxOption{
+		gatewayruntime.WithOutgoingHeaderMatcher(GatewayOutgoingHeaderMatcher),
+	}, muxOpts...)
+	gatewayMux := gatewayruntime.NewServeMux(muxOpts...)
+
+	var handler http.Handler = gatewayMux
+	if len(corsOrigins) > 0 {
+		co := cors.Options{AllowedOrigins: corsOrigins}
+		handler = cors.New(co).Handler(handler)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GatewayServer{
+		ln:         &trackingListener{Listener: ln, conns: make(map[*trackedConn]struct{})},
+		grpcServer: grpcServer,
+		gatewayMux: gatewayMux,
+		httpServer: &http.Server{Handler: handler},
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start registers the grpc-gateway handlers of the services and starts
+// serving. It returns the error of the servers if they fail right away.
+func (s *GatewayServer) Start(registers ...GatewayRegisterFunc) error {
+	// One slot per sender so that none of them blocks once Start returned.
+	errChan := make(chan error, 3)
+	go func() {
+		opts := []grpc.DialOption{grpc.WithInsecure()}
+		endpoint := s.ln.Addr().String()
+		for _, register := range registers {
+			if err := register(s.ctx, s.gatewayMux, endpoint, opts); err != nil {
+				errChan <- err
+				return
+			}
+		}
+
+		// Start the gRPC and HTTP (grpc-gateway) servers on the same port.
+		m := cmux.New(s.ln)
+		grpcL := m.MatchWithWriters(
+			cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"),
+			cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc+proto"))
+
+		httpL := m.Match(cmux.Any())
+		go func() {
+			errChan <- s.grpcServer.Serve(grpcL)
+		}()
+		go func() {
+			errChan <- s.httpServer.Serve(httpL)
+		}()
+		errChan <- m.Serve()
+	}()
+
+	// Our goal is to heuristically try running the server
+	// and if it returns an error immediately, we reporter that.
+	select {
+	case err := <-errChan:
+		return err
+
+	case <-time.After(1 * time.Second):
+		// No error otherwise returned in the period of 1s.
+		// We can assume that the serve is at least running.
+		return nil
+	}
+}
+
+// Stop closes the listener, the connections it accepted and the HTTP server.
+// The connections must be closed before the HTTP server: cmux waits for the
+// connections it is still matching, which idle ones, e.g. of the grpc-gateway
+// to the gRPC server, would block forever, before closing the listeners the
+// HTTP server waits on.
+func (s *GatewayServer) Stop() {
+	s.cancel()
+	_ = s.ln.Close()
+	s.ln.closeConns()
+	_ = s.httpServer.Close()
+}
+
+// trackingListener keeps track of the connections it accepted so that they
+// can all be closed, whether cmux matched them or not.
+type trackingListener struct {
+	net.Listener
+
+	mu     sync.Mutex
+	conns  map[*trackedConn]struct{}
+	closed bool
+}
+
+func (l *trackingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		_ = c.Close()
+		return nil, errListenerClosed
+	}
+	tc := &trackedConn{Conn: c, l: l}
+	l.conns[tc] = struct{}{}
+	return tc, nil
+}
+
+// closeConns closes the accepted connections and the ones accepted later.
+func (l *trackingListener) closeConns() {
+	l.mu.Lock()
+	conns := l.conns
+	l.conns = nil
+	l.closed = true
+	l.mu.Unlock()
+
+	for c := range conns {
+		_ = c.Conn.Close()
+	}
+}
+
+func (l *trackingListener) remove(c *trackedConn) {
+	l.mu.Lock()
+	delete(l.conns, c)
+	l.mu.Unlock()
+}
+
+var errListenerClosed = errors.New("listener closed")
+
+type trackedConn struct {
+	net.Conn
+	l *trackingListener
+}
+
+func (c *trackedConn) Close() error {
+	c.l.remove(c)
+	return c.Conn.Close()
+}
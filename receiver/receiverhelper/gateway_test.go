@@ -0,0 +1,83 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package receiverhelper
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	agenttracepb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestGatewayServer(t *testing.T) {
+	srv, addr := startGatewayServer(t)
+
+	// No service is registered on the gRPC server: the grpc-gateway replies
+	// with the HTTP status of the Unimplemented code.
+	url := fmt.Sprintf("http://%s/v1/trace", addr)
+	resp, err := http.Post(url, "application/json", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+
+	stopGatewayServer(t, srv)
+}
+
+func TestGatewayServerStopWithoutRequests(t *testing.T) {
+	srv, _ := startGatewayServer(t)
+	stopGatewayServer(t, srv)
+}
+
+func TestGatewayServerStopWithIdleConnection(t *testing.T) {
+	srv, addr := startGatewayServer(t)
+
+	// A connection that sends nothing is never matched by cmux, it must not
+	// prevent the server from stopping.
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	stopGatewayServer(t, srv)
+}
+
+func startGatewayServer(t *testing.T) (*GatewayServer, string) {
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	srv := NewGatewayServer(ln, grpc.NewServer(), nil)
+	require.NoError(t, srv.Start(agenttracepb.RegisterTraceServiceHandlerFromEndpoint))
+	return srv, ln.Addr().String()
+}
+
+func stopGatewayServer(t *testing.T, srv *GatewayServer) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package receiverhelper
+
+import (
+	"context"
+
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+// RecordTraceReceiverMetrics records the number of spans received by the
+// receiver and, tagged with the reason, the number of spans it dropped while
+// translating them. Use it with a context.Context generated using
+// observability.ContextWithReceiverName().
+func RecordTraceReceiverMetrics(ctx context.Context, receivedSpans int, dropped tracetranslator.DroppedSpans) {
+	observability.RecordMetricsForTraceReceiver(ctx, receivedSpans, 0)
+	for reason, droppedSpans := range dropped {
+		observability.RecordMetricsForTraceReceiver(observability.ContextWithDropReason(ctx, reason), 0, droppedSpans)
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package receiverhelper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func TestRecordTraceReceiverMetrics(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	const receiverName = "fake_receiver"
+	ctx := observability.ContextWithReceiverName(context.Background(), receiverName)
+	RecordTraceReceiverMetrics(ctx, 10, tracetranslator.DroppedSpans{
+		tracetranslator.DropReasonZeroTraceID: 2,
+		tracetranslator.DropReasonDecodeError: 1,
+	})
+
+	require.NoError(t, observabilitytest.CheckValueViewReceiverReceivedSpans(receiverName, 10))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpans(receiverName, 0))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(receiverName, tracetranslator.DropReasonZeroTraceID, 2))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(receiverName, tracetranslator.DropReasonDecodeError, 1))
+}
@@ -0,0 +1,408 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsdreceiver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+// errTypeConflict is returned for a metric whose name is already used by series
+// of another type.
+var errTypeConflict = errors.New("metric name already used by type")
+
+// defaultPercentiles are the percentiles reported for timers and histograms
+// when none are configured.
+var defaultPercentiles = []float64{50, 90, 95, 99}
+
+// seriesKey identifies a series: a metric name and its sorted tags.
+type seriesKey struct {
+	name string
+	tags string
+}
+
+func newSeriesKey(m statsdMetric) seriesKey {
+	var b strings.Builder
+	for i, t := range m.tags {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(t.key)
+		b.WriteByte(':')
+		b.WriteString(t.value)
+	}
+	return seriesKey{name: m.name, tags: b.String()}
+}
+
+// series holds what is common to all the series kept by the aggregator.
+type series struct {
+	name string
+	typ  metricType
+	tags []tag
+	// start is the time the series was first received, used as the start
+	// timestamp of the cumulative metrics.
+	start time.Time
+	// updated is true if the series was received since the last flush.
+	updated bool
+}
+
+type counterSeries struct {
+	series
+	value float64
+}
+
+type gaugeSeries struct {
+	series
+	value float64
+}
+
+type setSeries struct {
+	series
+	values map[string]struct{}
+}
+
+type timerSeries struct {
+	series
+	// count and sum are cumulative.
+	count float64
+	sum   float64
+	// values are the ones received since the last flush.
+	values []float64
+}
+
+// aggregator aggregates the StatsD metrics received during a flush interval.
+//
+// Counters are reported as cumulative doubles and timers, histograms and
+// distributions as summaries whose count and sum are cumulative and whose
+// snapshot covers the last interval. Gauges and sets, as the number of
+// unique values received in the interval, are reported as gauges. Only the
+// series received during the interval are reported, the others are dropped:
+// a counter received again restarts from zero with a new start time and a
+// gauge from the value received.
+//
+// All the series of a metric name have the same type, the metrics received
+// with a name used by series of another type are rejected.
+type aggregator struct {
+	mu          sync.Mutex
+	percentiles []float64
+
+	// types are the types of the metric names that have series.
+	types    map[string]metricType
+	counters map[seriesKey]*counterSeries
+	gauges   map[seriesKey]*gaugeSeries
+	sets     map[seriesKey]*setSeries
+	timers   map[seriesKey]*timerSeries
+}
+
+func newAggregator(percentiles []float64) *aggregator {
+	if len(percentiles) == 0 {
+		percentiles = defaultPercentiles
+	}
+	return &aggregator{
+		percentiles: percentiles,
+		types:       make(map[string]metricType),
+		counters:    make(map[seriesKey]*counterSeries),
+		gauges:      make(map[seriesKey]*gaugeSeries),
+		sets:        make(map[seriesKey]*setSeries),
+		timers:      make(map[seriesKey]*timerSeries),
+	}
+}
+
+// add aggregates a metric received at the given time. It returns
+// errTypeConflict if the metric name is used by series of another type.
+func (a *aggregator) add(m statsdMetric, now time.Time) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if typ, ok := a.types[m.name]; ok && typ != m.typ {
+		return fmt.Errorf("%v %q: %q", errTypeConflict, typ, m.name)
+	}
+	a.types[m.name] = m.typ
+
+	key := newSeriesKey(m)
+	newSeries := series{name: m.name, typ: m.typ, tags: m.tags, start: now}
+	switch m.typ {
+	case counterType:
+		s, ok := a.counters[key]
+		if !ok {
+			s = &counterSeries{series: newSeries}
+			a.counters[key] = s
+		}
+		s.value += m.number / m.sampleRate
+		s.updated = true
+
+	case gaugeType:
+		s, ok := a.gauges[key]
+		if !ok {
+			s = &gaugeSeries{series: newSeries}
+			a.gauges[key] = s
+		}
+		if m.delta {
+			s.value += m.number
+		} else {
+			s.value = m.number
+		}
+		s.updated = true
+
+	case setType:
+		s, ok := a.sets[key]
+		if !ok {
+			s = &setSeries{series: newSeries, values: make(map[string]struct{})}
+			a.sets[key] = s
+		}
+		s.values[m.value] = struct{}{}
+		s.updated = true
+
+	case timerType, histogramType, distributionType:
+		s, ok := a.timers[key]
+		if !ok {
+			s = &timerSeries{series: newSeries}
+			a.timers[key] = s
+		}
+		s.count += 1 / m.sampleRate
+		s.sum += m.number / m.sampleRate
+		s.values = append(s.values, m.number)
+		s.updated = true
+	}
+	return nil
+}
+
+// flush returns the metrics for the series received since the last flush,
+// sorted by name, drops the other series and starts a new interval.
+func (a *aggregator) flush(now time.Time) []*metricspb.Metric {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ts := internal.TimeToTimestamp(now)
+	builders := make(map[string]*metricBuilder)
+	builder := func(name string, descriptor *metricspb.MetricDescriptor) *metricBuilder {
+		b, ok := builders[name]
+		if !ok {
+			b = &metricBuilder{descriptor: descriptor}
+			builders[name] = b
+		}
+		return b
+	}
+
+	for key, s := range a.counters {
+		if !s.updated {
+			delete(a.counters, key)
+			continue
+		}
+		b := builder(s.name, &metricspb.MetricDescriptor{Name: s.name, Type: metricspb.MetricDescriptor_CUMULATIVE_DOUBLE})
+		b.add(s.series, internal.TimeToTimestamp(s.start), &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_DoubleValue{DoubleValue: s.value},
+		})
+		s.updated = false
+	}
+
+	for key, s := range a.gauges {
+		if !s.updated {
+			delete(a.gauges, key)
+			continue
+		}
+		b := builder(s.name, &metricspb.MetricDescriptor{Name: s.name, Type: metricspb.MetricDescriptor_GAUGE_DOUBLE})
+		b.add(s.series, nil, &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_DoubleValue{DoubleValue: s.value},
+		})
+		s.updated = false
+	}
+
+	for key, s := range a.sets {
+		b := builder(s.name, &metricspb.MetricDescriptor{Name: s.name, Type: metricspb.MetricDescriptor_GAUGE_INT64})
+		b.add(s.series, nil, &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_Int64Value{Int64Value: int64(len(s.values))},
+		})
+		// Sets only count the unique values of an interval.
+		delete(a.sets, key)
+	}
+
+	for key, s := range a.timers {
+		if !s.updated {
+			delete(a.timers, key)
+			continue
+		}
+		descriptor := &metricspb.MetricDescriptor{Name: s.name, Type: metricspb.MetricDescriptor_SUMMARY}
+		if s.typ == timerType {
+			descriptor.Unit = "ms"
+		}
+		b := builder(s.name, descriptor)
+		b.add(s.series, internal.TimeToTimestamp(s.start), &metricspb.Point{
+			Timestamp: ts,
+			Value:     &metricspb.Point_SummaryValue{SummaryValue: a.summary(s)},
+		})
+		s.values = s.values[:0]
+		s.updated = false
+	}
+
+	// Only the names of the series left keep their type.
+	a.types = make(map[string]metricType, len(builders))
+	for _, s := range a.counters {
+		a.types[s.name] = s.typ
+	}
+	for _, s := range a.gauges {
+		a.types[s.name] = s.typ
+	}
+	for _, s := range a.timers {
+		a.types[s.name] = s.typ
+	}
+
+	names := make([]string, 0, len(builders))
+	for name := range builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([]*metricspb.Metric, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, builders[name].build())
+	}
+	return metrics
+}
+
+// summary returns the summary of a timer series, its snapshot covers the
+// values received since the last flush.
+func (a *aggregator) summary(s *timerSeries) *metricspb.SummaryValue {
+	values := append([]float64(nil), s.values...)
+	sort.Float64s(values)
+
+	var intervalSum float64
+	for _, v := range values {
+		intervalSum += v
+	}
+	percentiles := make([]*metricspb.SummaryValue_Snapshot_ValueAtPercentile, 0, len(a.percentiles))
+	for _, p := range a.percentiles {
+		percentiles = append(percentiles, &metricspb.SummaryValue_Snapshot_ValueAtPercentile{
+			Percentile: p,
+			Value:      percentile(values, p),
+		})
+	}
+
+	return &metricspb.SummaryValue{
+		Count: &wrappers.Int64Value{Value: int64(math.Round(s.count))},
+		Sum:   &wrappers.DoubleValue{Value: s.sum},
+		Snapshot: &metricspb.SummaryValue_Snapshot{
+			Count:            &wrappers.Int64Value{Value: int64(len(values))},
+			Sum:              &wrappers.DoubleValue{Value: intervalSum},
+			PercentileValues: percentiles,
+		},
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// metricBuilder collects the series of a metric, whose label keys are the
+// union of the tag keys of its series.
+type metricBuilder struct {
+	descriptor *metricspb.MetricDescriptor
+	tags       [][]tag
+	timeseries []*metricspb.TimeSeries
+}
+
+func (b *metricBuilder) add(s series, start *timestamp.Timestamp, point *metricspb.Point) {
+	b.tags = append(b.tags, s.tags)
+	b.timeseries = append(b.timeseries, &metricspb.TimeSeries{
+		StartTimestamp: start,
+		Points:         []*metricspb.Point{point},
+	})
+}
+
+func (b *metricBuilder) build() *metricspb.Metric {
+	keySet := make(map[string]struct{})
+	for _, tags := range b.tags {
+		for _, t := range tags {
+			keySet[t.key] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	b.descriptor.LabelKeys = make([]*metricspb.LabelKey, 0, len(keys))
+	for _, key := range keys {
+		b.descriptor.LabelKeys = append(b.descriptor.LabelKeys, &metricspb.LabelKey{Key: key})
+	}
+	for i, ts := range b.timeseries {
+		ts.LabelValues = labelValues(keys, b.tags[i])
+	}
+	// Sort the series by their label values for a stable output.
+	sort.Sort(byLabelValues(b.timeseries))
+
+	return &metricspb.Metric{
+		MetricDescriptor: b.descriptor,
+		Timeseries:       b.timeseries,
+	}
+}
+
+// labelValues returns the values of the sorted tags in the order of the
+// sorted keys, the keys missing from tags have no value.
+func labelValues(keys []string, tags []tag) []*metricspb.LabelValue {
+	values := make([]*metricspb.LabelValue, 0, len(keys))
+	i := 0
+	for _, key := range keys {
+		if i < len(tags) && tags[i].key == key {
+			values = append(values, &metricspb.LabelValue{Value: tags[i].value, HasValue: true})
+			i++
+			continue
+		}
+		values = append(values, &metricspb.LabelValue{})
+	}
+	return values
+}
+
+type byLabelValues []*metricspb.TimeSeries
+
+func (s byLabelValues) Len() int      { return len(s) }
+func (s byLabelValues) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byLabelValues) Less(i, j int) bool {
+	a, b := s[i].LabelValues, s[j].LabelValues
+	for k := range a {
+		if a[k].HasValue != b[k].HasValue {
+			return !a[k].HasValue
+		}
+		if a[k].Value != b[k].Value {
+			return a[k].Value < b[k].Value
+		}
+	}
+	return false
+}
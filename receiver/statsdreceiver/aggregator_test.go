@@ -0,0 +1,261 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsdreceiver
+
+import (
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+func TestAggregator(t *testing.T) {
+	start := time.Unix(1542158650, 0).UTC()
+	flushTime := start.Add(10 * time.Second)
+
+	a := newAggregator(nil)
+	for _, line := range []string{
+		"requests:1|c|#code:200",
+		"requests:1|c|@0.5|#code:500",
+		"requests:3|c|#code:200",
+		"temperature:20|g",
+		"temperature:+2|g",
+		"users:alice|s",
+		"users:bob|s",
+		"users:alice|s",
+		"latency:10|ms",
+		"latency:30|ms",
+		"latency:20|ms",
+	} {
+		m, err := parseLine(line)
+		require.NoError(t, err)
+		require.NoError(t, a.add(m, start))
+	}
+
+	startTs := internal.TimeToTimestamp(start)
+	flushTs := internal.TimeToTimestamp(flushTime)
+	want := []*metricspb.Metric{
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "latency",
+				Unit:      "ms",
+				Type:      metricspb.MetricDescriptor_SUMMARY,
+				LabelKeys: []*metricspb.LabelKey{},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					StartTimestamp: startTs,
+					LabelValues:    []*metricspb.LabelValue{},
+					Points: []*metricspb.Point{
+						{
+							Timestamp: flushTs,
+							Value: &metricspb.Point_SummaryValue{
+								SummaryValue: &metricspb.SummaryValue{
+									Count: &wrappers.Int64Value{Value: 3},
+									Sum:   &wrappers.DoubleValue{Value: 60},
+									Snapshot: &metricspb.SummaryValue_Snapshot{
+										Count: &wrappers.Int64Value{Value: 3},
+										Sum:   &wrappers.DoubleValue{Value: 60},
+										PercentileValues: []*metricspb.SummaryValue_Snapshot_ValueAtPercentile{
+											{Percentile: 50, Value: 20},
+											{Percentile: 90, Value: 30},
+											{Percentile: 95, Value: 30},
+											{Percentile: 99, Value: 30},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "requests",
+				Type:      metricspb.MetricDescriptor_CUMULATIVE_DOUBLE,
+				LabelKeys: []*metricspb.LabelKey{{Key: "code"}},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					StartTimestamp: startTs,
+					LabelValues:    []*metricspb.LabelValue{{Value: "200", HasValue: true}},
+					Points:         []*metricspb.Point{{Timestamp: flushTs, Value: &metricspb.Point_DoubleValue{DoubleValue: 4}}},
+				},
+				{
+					StartTimestamp: startTs,
+					LabelValues:    []*metricspb.LabelValue{{Value: "500", HasValue: true}},
+					Points:         []*metricspb.Point{{Timestamp: flushTs, Value: &metricspb.Point_DoubleValue{DoubleValue: 2}}},
+				},
+			},
+		},
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "temperature",
+				Type:      metricspb.MetricDescriptor_GAUGE_DOUBLE,
+				LabelKeys: []*metricspb.LabelKey{},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					LabelValues: []*metricspb.LabelValue{},
+					Points:      []*metricspb.Point{{Timestamp: flushTs, Value: &metricspb.Point_DoubleValue{DoubleValue: 22}}},
+				},
+			},
+		},
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "users",
+				Type:      metricspb.MetricDescriptor_GAUGE_INT64,
+				LabelKeys: []*metricspb.LabelKey{},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					LabelValues: []*metricspb.LabelValue{},
+					Points:      []*metricspb.Point{{Timestamp: flushTs, Value: &metricspb.Point_Int64Value{Int64Value: 2}}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, want, a.flush(flushTime))
+
+	// Counters keep their cumulative value and start time.
+	m, err := parseLine("requests:1|c|#code:500")
+	require.NoError(t, err)
+	require.NoError(t, a.add(m, flushTime))
+	metrics := a.flush(flushTime.Add(10 * time.Second))
+	require.Len(t, metrics, 1)
+	require.Len(t, metrics[0].Timeseries, 1)
+	assert.Equal(t, startTs, metrics[0].Timeseries[0].StartTimestamp)
+	assert.Equal(t, 3.0, metrics[0].Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func TestAggregator_PrunesIdleSeries(t *testing.T) {
+	start := time.Unix(1542158650, 0).UTC()
+	a := newAggregator(nil)
+	for _, line := range []string{
+		"requests:2|c",
+		"temperature:20|g",
+		"latency:10|ms",
+	} {
+		m, err := parseLine(line)
+		require.NoError(t, err)
+		require.NoError(t, a.add(m, start))
+	}
+	require.Len(t, a.flush(start.Add(10*time.Second)), 3)
+
+	// Nothing was received since the last flush: the series are dropped.
+	assert.Empty(t, a.flush(start.Add(20*time.Second)))
+	assert.Empty(t, a.counters)
+	assert.Empty(t, a.gauges)
+	assert.Empty(t, a.timers)
+	assert.Empty(t, a.types)
+
+	// A counter received again restarts from zero with a new start time.
+	restart := start.Add(25 * time.Second)
+	m, err := parseLine("requests:1|c")
+	require.NoError(t, err)
+	require.NoError(t, a.add(m, restart))
+	metrics := a.flush(start.Add(30 * time.Second))
+	require.Len(t, metrics, 1)
+	require.Len(t, metrics[0].Timeseries, 1)
+	assert.Equal(t, internal.TimeToTimestamp(restart), metrics[0].Timeseries[0].StartTimestamp)
+	assert.Equal(t, 1.0, metrics[0].Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func TestAggregator_TypeConflict(t *testing.T) {
+	now := time.Now()
+	a := newAggregator(nil)
+	add := func(line string) error {
+		m, err := parseLine(line)
+		require.NoError(t, err)
+		return a.add(m, now)
+	}
+
+	require.NoError(t, add("requests:1|c|#code:200"))
+	for _, line := range []string{
+		"requests:1|g",
+		"requests:1|ms|#code:200",
+		"requests:alice|s",
+	} {
+		err := add(line)
+		require.Error(t, err, line)
+		assert.Contains(t, err.Error(), errTypeConflict.Error())
+	}
+	require.NoError(t, add("requests:1|c|#code:500"))
+
+	metrics := a.flush(now)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, metricspb.MetricDescriptor_CUMULATIVE_DOUBLE, metrics[0].MetricDescriptor.Type)
+	require.Len(t, metrics[0].Timeseries, 2)
+
+	// Once the series of a name are dropped, another type can use it.
+	a.flush(now)
+	require.NoError(t, add("requests:1|g"))
+	metrics = a.flush(now)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, metricspb.MetricDescriptor_GAUGE_DOUBLE, metrics[0].MetricDescriptor.Type)
+}
+
+func TestAggregator_LabelKeysUnion(t *testing.T) {
+	a := newAggregator(nil)
+	for _, line := range []string{
+		"requests:1|c|#method:GET",
+		"requests:1|c|#code:500",
+	} {
+		m, err := parseLine(line)
+		require.NoError(t, err)
+		require.NoError(t, a.add(m, time.Now()))
+	}
+
+	metrics := a.flush(time.Now())
+	require.Len(t, metrics, 1)
+	assert.Equal(t, []*metricspb.LabelKey{{Key: "code"}, {Key: "method"}}, metrics[0].MetricDescriptor.LabelKeys)
+	require.Len(t, metrics[0].Timeseries, 2)
+	assert.Equal(t, []*metricspb.LabelValue{{}, {Value: "GET", HasValue: true}}, metrics[0].Timeseries[0].LabelValues)
+	assert.Equal(t, []*metricspb.LabelValue{{Value: "500", HasValue: true}, {}}, metrics[0].Timeseries[1].LabelValues)
+}
+
+func TestAggregator_DuplicatedTags(t *testing.T) {
+	a := newAggregator(nil)
+	for _, line := range []string{
+		"requests:1|c|#code:500,code:200",
+		"requests:2|c|#code:200",
+	} {
+		m, err := parseLine(line)
+		require.NoError(t, err)
+		require.NoError(t, a.add(m, time.Now()))
+	}
+
+	// The lines only differing by a duplicated tag share the same series.
+	metrics := a.flush(time.Now())
+	require.Len(t, metrics, 1)
+	require.Len(t, metrics[0].Timeseries, 1)
+	assert.Equal(t, []*metricspb.LabelValue{{Value: "200", HasValue: true}}, metrics[0].Timeseries[0].LabelValues)
+	assert.Equal(t, 3.0, metrics[0].Timeseries[0].Points[0].GetDoubleValue())
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	assert.Equal(t, 0.0, percentile(nil, 50))
+	assert.Equal(t, 1.0, percentile(values, 0))
+	assert.Equal(t, 5.0, percentile(values, 50))
+	assert.Equal(t, 10.0, percentile(values, 99))
+	assert.Equal(t, 10.0, percentile(values, 100))
+}
@@ -0,0 +1,39 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsdreceiver
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+)
+
+// Config defines configuration for StatsD receiver.
+type Config struct {
+	configmodels.ReceiverSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// TCPEndpoint is the address to also receive newline separated StatsD
+	// lines over TCP. It is disabled if empty, the Endpoint is only used for
+	// UDP.
+	TCPEndpoint string `mapstructure:"tcp_endpoint"`
+
+	// FlushInterval is the interval over which the received metrics are
+	// aggregated before being passed to the next consumer.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	// Percentiles are the percentiles reported for timers and histograms,
+	// 50, 90, 95 and 99 if empty.
+	Percentiles []float64 `mapstructure:"percentiles"`
+}
@@ -0,0 +1,143 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsdreceiver
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricType is the type of a StatsD metric, as found after the first pipe
+// of a line.
+type metricType string
+
+const (
+	counterType      metricType = "c"
+	gaugeType        metricType = "g"
+	timerType        metricType = "ms"
+	histogramType    metricType = "h"
+	distributionType metricType = "d"
+	setType          metricType = "s"
+)
+
+var (
+	errEmptyName       = errors.New("empty metric name")
+	errMissingValue    = errors.New("missing value")
+	errMissingType     = errors.New("missing metric type")
+	errInvalidRate     = errors.New("invalid sample rate")
+	errUnsupportedType = errors.New("unsupported metric type")
+)
+
+// tag is a DogStatsD tag, a tag without a colon has an empty value.
+type tag struct {
+	key   string
+	value string
+}
+
+// statsdMetric is a single parsed StatsD line, e.g.: "page.views:1|c|@0.5|#env:prod".
+type statsdMetric struct {
+	name string
+	typ  metricType
+	// value is the raw value, sets keep it as is.
+	value string
+	// number is the numeric value for all the types except sets.
+	number float64
+	// delta is true for gauges whose value has an explicit sign, i.e.: it
+	// is added to the current value instead of replacing it.
+	delta      bool
+	sampleRate float64
+	// tags are sorted by key, without duplicated keys.
+	tags []tag
+}
+
+// parseLine parses a single StatsD line with optional sample rate and
+// DogStatsD tags.
+func parseLine(line string) (statsdMetric, error) {
+	m := statsdMetric{sampleRate: 1}
+
+	colon := strings.IndexByte(line, ':')
+	if colon < 0 {
+		return m, errMissingValue
+	}
+	m.name = line[:colon]
+	if m.name == "" {
+		return m, errEmptyName
+	}
+
+	parts := strings.Split(line[colon+1:], "|")
+	if len(parts) < 2 {
+		return m, errMissingType
+	}
+	m.value = parts[0]
+	if m.value == "" {
+		return m, errMissingValue
+	}
+	m.typ = metricType(parts[1])
+
+	switch m.typ {
+	case counterType, gaugeType, timerType, histogramType, distributionType:
+		number, err := strconv.ParseFloat(m.value, 64)
+		if err != nil {
+			return m, fmt.Errorf("invalid value %q: %v", m.value, err)
+		}
+		m.number = number
+		m.delta = m.typ == gaugeType && (m.value[0] == '+' || m.value[0] == '-')
+	case setType:
+	default:
+		return m, fmt.Errorf("%v %q", errUnsupportedType, parts[1])
+	}
+
+	for _, part := range parts[2:] {
+		switch {
+		case strings.HasPrefix(part, "@"):
+			rate, err := strconv.ParseFloat(part[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return m, errInvalidRate
+			}
+			m.sampleRate = rate
+		case strings.HasPrefix(part, "#"):
+			m.tags = parseTags(part[1:])
+		}
+		// Other extensions, e.g.: DogStatsD container IDs, are ignored.
+	}
+	return m, nil
+}
+
+func parseTags(s string) []tag {
+	var tags []tag
+	for _, t := range strings.Split(s, ",") {
+		if t == "" {
+			continue
+		}
+		if colon := strings.IndexByte(t, ':'); colon >= 0 {
+			tags = append(tags, tag{key: t[:colon], value: t[colon+1:]})
+		} else {
+			tags = append(tags, tag{key: t})
+		}
+	}
+	// Duplicated tags keep their last value.
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].key < tags[j].key })
+	sorted := tags[:0]
+	for i, t := range tags {
+		if i+1 < len(tags) && tags[i+1].key == t.key {
+			continue
+		}
+		sorted = append(sorted, t)
+	}
+	return sorted
+}
@@ -0,0 +1,99 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsdreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    statsdMetric
+		wantErr bool
+	}{
+		{
+			line: "page.views:1|c",
+			want: statsdMetric{name: "page.views", typ: counterType, value: "1", number: 1, sampleRate: 1},
+		},
+		{
+			line: "page.views:2|c|@0.5|#env:prod,canary",
+			want: statsdMetric{
+				name:       "page.views",
+				typ:        counterType,
+				value:      "2",
+				number:     2,
+				sampleRate: 0.5,
+				tags:       []tag{{key: "canary"}, {key: "env", value: "prod"}},
+			},
+		},
+		{
+			// Duplicated tags keep their last value.
+			line: "page.views:1|c|#env:dev,canary,env:prod",
+			want: statsdMetric{
+				name:       "page.views",
+				typ:        counterType,
+				value:      "1",
+				number:     1,
+				sampleRate: 1,
+				tags:       []tag{{key: "canary"}, {key: "env", value: "prod"}},
+			},
+		},
+		{
+			line: "queue.size:-3|g",
+			want: statsdMetric{name: "queue.size", typ: gaugeType, value: "-3", number: -3, delta: true, sampleRate: 1},
+		},
+		{
+			line: "request.time:12.5|ms|#route:/users",
+			want: statsdMetric{
+				name:       "request.time",
+				typ:        timerType,
+				value:      "12.5",
+				number:     12.5,
+				sampleRate: 1,
+				tags:       []tag{{key: "route", value: "/users"}},
+			},
+		},
+		{
+			line: "response.size:512|h",
+			want: statsdMetric{name: "response.size", typ: histogramType, value: "512", number: 512, sampleRate: 1},
+		},
+		{
+			line: "users.unique:alice|s",
+			want: statsdMetric{name: "users.unique", typ: setType, value: "alice", sampleRate: 1},
+		},
+		{line: "no.value", wantErr: true},
+		{line: ":1|c", wantErr: true},
+		{line: "no.type:1", wantErr: true},
+		{line: "empty.value:|c", wantErr: true},
+		{line: "bad.value:abc|c", wantErr: true},
+		{line: "bad.type:1|x", wantErr: true},
+		{line: "bad.rate:1|c|@2", wantErr: true},
+		{line: "bad.rate:1|c|@abc", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := parseLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsdreceiver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/observability"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
+
+var _ receiver.MetricsReceiver = (*Receiver)(nil)
+
+const (
+	metricsSource    string = "StatsD"
+	receiverTagValue        = "statsd"
+
+	// maxUDPPacketSize is the largest payload of a UDP datagram.
+	maxUDPPacketSize = 65535
+)
+
+var errInvalidFlushInterval = errors.New("the StatsD receiver flush_interval must be positive")
+
+// Receiver is the type used to receive StatsD metrics.
+type Receiver struct {
+	mu sync.Mutex
+
+	logger       *zap.Logger
+	config       *Config
+	nextConsumer consumer.MetricsConsumer
+	aggregator   *aggregator
+
+	udpConn  net.PacketConn
+	tcpLn    net.Listener
+	tcpConns map[net.Conn]struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New creates a StatsD receiver, the servers are only started by
+// StartMetricsReception.
+func New(logger *zap.Logger, config *Config, nextConsumer consumer.MetricsConsumer) (*Receiver, error) {
+	if nextConsumer == nil {
+		return nil, oterr.ErrNilNextConsumer
+	}
+	if config.FlushInterval <= 0 {
+		return nil, errInvalidFlushInterval
+	}
+	return &Receiver{
+		logger:       logger,
+		config:       config,
+		nextConsumer: nextConsumer,
+		aggregator:   newAggregator(config.Percentiles),
+		tcpConns:     make(map[net.Conn]struct{}),
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// MetricsSource returns the name of the metrics data source.
+func (r *Receiver) MetricsSource() string {
+	return metricsSource
+}
+
+// StartMetricsReception starts the UDP server, the TCP one if configured,
+// and the periodic flush of the aggregated metrics.
+func (r *Receiver) StartMetricsReception(host receiver.Host) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err = oterr.ErrAlreadyStarted
+	r.startOnce.Do(func() {
+		r.udpConn, err = net.ListenPacket("udp", r.config.Endpoint)
+		if err != nil {
+			return
+		}
+		if r.config.TCPEndpoint != "" {
+			r.tcpLn, err = net.Listen("tcp", r.config.TCPEndpoint)
+			if err != nil {
+				_ = r.udpConn.Close()
+				return
+			}
+			r.wg.Add(1)
+			go r.serveTCP(host)
+		}
+
+		r.wg.Add(2)
+		go r.serveUDP(host)
+		go r.flushLoop()
+	})
+	return err
+}
+
+// StopMetricsReception stops the servers and flushes the metrics aggregated
+// since the last flush.
+func (r *Receiver) StopMetricsReception() error {
+	var err = oterr.ErrAlreadyStopped
+	r.stopOnce.Do(func() {
+		err = nil
+		close(r.done)
+
+		r.mu.Lock()
+		if r.udpConn != nil {
+			_ = r.udpConn.Close()
+		}
+		if r.tcpLn != nil {
+			_ = r.tcpLn.Close()
+		}
+		for conn := range r.tcpConns {
+			_ = conn.Close()
+		}
+		r.mu.Unlock()
+
+		r.wg.Wait()
+		r.flush()
+	})
+	return err
+}
+
+// stopping returns true once StopMetricsReception was called, errors
+// returned by the servers after that are expected.
+func (r *Receiver) stopping() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *Receiver) serveUDP(host receiver.Host) {
+	defer r.wg.Done()
+
+	buf := make([]byte, maxUDPPacketSize)
+	for {
+		n, _, err := r.udpConn.ReadFrom(buf)
+		if n > 0 {
+			for _, line := range bytes.Split(buf[:n], []byte{'\n'}) {
+				r.handleLine(string(line))
+			}
+		}
+		if err != nil {
+			if !r.stopping() {
+				host.ReportFatalError(err)
+			}
+			return
+		}
+	}
+}
+
+func (r *Receiver) serveTCP(host receiver.Host) {
+	defer r.wg.Done()
+
+	for {
+		conn, err := r.tcpLn.Accept()
+		if err != nil {
+			if !r.stopping() {
+				host.ReportFatalError(err)
+			}
+			return
+		}
+
+		r.mu.Lock()
+		if r.stopping() {
+			r.mu.Unlock()
+			_ = conn.Close()
+			return
+		}
+		r.tcpConns[conn] = struct{}{}
+		r.wg.Add(1)
+		r.mu.Unlock()
+
+		go r.handleTCPConn(conn)
+	}
+}
+
+func (r *Receiver) handleTCPConn(conn net.Conn) {
+	defer func() {
+		r.mu.Lock()
+		delete(r.tcpConns, conn)
+		r.mu.Unlock()
+		_ = conn.Close()
+		r.wg.Done()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		r.handleLine(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil && !r.stopping() {
+		r.logger.Debug("StatsD TCP connection failed", zap.Error(err))
+	}
+}
+
+func (r *Receiver) handleLine(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	m, err := parseLine(line)
+	if err != nil {
+		r.logger.Debug("Invalid StatsD line", zap.String("line", line), zap.Error(err))
+		return
+	}
+	if err := r.aggregator.add(m, time.Now()); err != nil {
+		r.logger.Debug("Rejected StatsD metric", zap.String("line", line), zap.Error(err))
+	}
+}
+
+func (r *Receiver) flushLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.flush()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// flush passes the metrics aggregated since the last flush onto the next
+// consumer.
+func (r *Receiver) flush() {
+	metrics := r.aggregator.flush(time.Now())
+	if len(metrics) == 0 {
+		return
+	}
+
+	ctx := observability.ContextWithReceiverName(context.Background(), receiverTagValue)
+	numTimeSeries := 0
+	for _, metric := range metrics {
+		numTimeSeries += len(metric.Timeseries)
+	}
+	observability.RecordMetricsForMetricsReceiver(ctx, numTimeSeries, 0)
+
+	md := consumerdata.MetricsData{Metrics: metrics}
+	if err := r.nextConsumer.ConsumeMetricsData(ctx, md); err != nil {
+		r.logger.Warn("Failed to pass the StatsD metrics to the next consumer", zap.Error(err))
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statsdreceiver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
+	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+)
+
+func TestReceiver(t *testing.T) {
+	cfg := &Config{
+		ReceiverSettings: configmodels.ReceiverSettings{
+			TypeVal:  typeStr,
+			NameVal:  typeStr,
+			Endpoint: testutils.GetAvailableLocalAddress(t),
+		},
+		TCPEndpoint:   testutils.GetAvailableLocalAddress(t),
+		FlushInterval: time.Hour,
+	}
+	sink := new(exportertest.SinkMetricsExporter)
+	r, err := New(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+
+	mh := receivertest.NewMockHost()
+	require.NoError(t, r.StartMetricsReception(mh))
+	assert.Equal(t, oterr.ErrAlreadyStarted, r.StartMetricsReception(mh))
+
+	udpConn, err := net.Dial("udp", cfg.Endpoint)
+	require.NoError(t, err)
+	defer udpConn.Close()
+	_, err = udpConn.Write([]byte("udp.requests:1|c\ninvalid line\nudp.requests:2|c"))
+	require.NoError(t, err)
+
+	tcpConn, err := net.Dial("tcp", cfg.TCPEndpoint)
+	require.NoError(t, err)
+	_, err = tcpConn.Write([]byte("tcp.queue:5|g|#queue:a\n"))
+	require.NoError(t, err)
+	require.NoError(t, tcpConn.Close())
+
+	// Wait for the lines to be aggregated, the last flush is done on stop.
+	require.Eventually(t, func() bool {
+		r.aggregator.mu.Lock()
+		defer r.aggregator.mu.Unlock()
+		return len(r.aggregator.counters) == 1 && len(r.aggregator.gauges) == 1 &&
+			r.aggregator.counters[seriesKey{name: "udp.requests"}].value == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, r.StopMetricsReception())
+	assert.Equal(t, oterr.ErrAlreadyStopped, r.StopMetricsReception())
+
+	got := sink.AllMetrics()
+	require.Len(t, got, 1)
+	names := metricNames(got[0])
+	assert.Equal(t, []string{"tcp.queue", "udp.requests"}, names)
+}
+
+func TestReceiver_Flush(t *testing.T) {
+	cfg := &Config{
+		ReceiverSettings: configmodels.ReceiverSettings{
+			TypeVal:  typeStr,
+			NameVal:  typeStr,
+			Endpoint: testutils.GetAvailableLocalAddress(t),
+		},
+		FlushInterval: 50 * time.Millisecond,
+	}
+	sink := new(exportertest.SinkMetricsExporter)
+	r, err := New(zap.NewNop(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.StartMetricsReception(receivertest.NewMockHost()))
+	defer r.StopMetricsReception()
+
+	udpConn, err := net.Dial("udp", cfg.Endpoint)
+	require.NoError(t, err)
+	defer udpConn.Close()
+	_, err = udpConn.Write([]byte("users:alice|s"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(sink.AllMetrics()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func metricNames(md consumerdata.MetricsData) []string {
+	var names []string
+	for _, metric := range md.Metrics {
+		names = append(names, metric.MetricDescriptor.Name)
+	}
+	return names
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/internal/resourcedetection"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 )
 
@@ -92,19 +93,11 @@ func NewVMMetricsCollector(si time.Duration, mountPoint, processMountPoint, pref
 
 func detectResource() {
 	resourceDetectionSync.Do(func() {
-		res, err := auto.Detect(context.Background())
+		res, err := resourcedetection.Detect(context.Background(), auto.Detect)
 		if err != nil {
 			panic(fmt.Sprintf("Resource detection failed, err:%v", err))
 		}
-		if res != nil {
-			rsc = &resourcepb.Resource{
-				Type:   res.Type,
-				Labels: make(map[string]string, len(res.Labels)),
-			}
-			for k, v := range res.Labels {
-				rsc.Labels[k] = v
-			}
-		}
+		rsc = res
 	})
 }
 
@@ -14,9 +14,48 @@
 
 package zipkinreceiver
 
-import "github.com/open-telemetry/opentelemetry-service/config/configmodels"
+import (
+	"crypto/tls"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-service/receiver"
+)
 
 // Config defines configuration for Zipkin receiver.
 type Config struct {
-	configmodels.ReceiverSettings `mapstructure:",squash"`
+	receiver.SecureReceiverSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// CorsOrigins are the allowed CORS origins for HTTP requests to the Zipkin
+	// receiver. See github.com/rs/cors
+	// An empty list means that CORS is not enabled at all. A wildcard (*) can be
+	// used to match any origin or one or more characters of an origin.
+	CorsOrigins []string `mapstructure:"cors_allowed_origins"`
+
+	// MaxRequestBodySize sets the maximum size (in bytes) of a request body,
+	// measured after it is decompressed. Zero means that the size is not limited.
+	MaxRequestBodySize int64 `mapstructure:"max_request_body_size,omitempty"`
+
+	// ParseStringTags, if enabled, converts string tags whose values look like
+	// numbers or booleans, e.g. "200" or "true", into typed attributes.
+	ParseStringTags bool `mapstructure:"parse_string_tags"`
+}
+
+func (rOpts *Config) buildOptions() (opts []Option, err error) {
+	if rOpts.TLSCredentials != nil {
+		cert, err := tls.LoadX509KeyPair(rOpts.TLSCredentials.CertFile, rOpts.TLSCredentials.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing Zipkin receiver %q TLS Credentials: %v", rOpts.NameVal, err)
+		}
+		opts = append(opts, WithTLSConfig(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	}
+	if len(rOpts.CorsOrigins) > 0 {
+		opts = append(opts, WithCorsOrigins(rOpts.CorsOrigins))
+	}
+	if rOpts.MaxRequestBodySize > 0 {
+		opts = append(opts, WithMaxRequestBodySize(rOpts.MaxRequestBodySize))
+	}
+	if rOpts.ParseStringTags {
+		opts = append(opts, WithParseStringTags(true))
+	}
+	return opts, nil
 }
@@ -23,6 +23,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
+	"github.com/open-telemetry/opentelemetry-service/receiver"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -36,7 +37,7 @@ func TestLoadConfig(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, cfg)
 
-	assert.Equal(t, len(cfg.Receivers), 2)
+	assert.Equal(t, len(cfg.Receivers), 3)
 
 	r0 := cfg.Receivers["zipkin"]
 	assert.Equal(t, r0, factory.CreateDefaultConfig())
@@ -44,10 +45,31 @@ func TestLoadConfig(t *testing.T) {
 	r1 := cfg.Receivers["zipkin/customname"].(*Config)
 	assert.Equal(t, r1,
 		&Config{
-			ReceiverSettings: configmodels.ReceiverSettings{
-				TypeVal:  typeStr,
-				NameVal:  "zipkin/customname",
-				Endpoint: "localhost:8765",
+			SecureReceiverSettings: receiver.SecureReceiverSettings{
+				ReceiverSettings: configmodels.ReceiverSettings{
+					TypeVal:  typeStr,
+					NameVal:  "zipkin/customname",
+					Endpoint: "localhost:8765",
+				},
 			},
 		})
+
+	r2 := cfg.Receivers["zipkin/secure"].(*Config)
+	assert.Equal(t, r2,
+		&Config{
+			SecureReceiverSettings: receiver.SecureReceiverSettings{
+				ReceiverSettings: configmodels.ReceiverSettings{
+					TypeVal:  typeStr,
+					NameVal:  "zipkin/secure",
+					Endpoint: "0.0.0.0:9411",
+				},
+				TLSCredentials: &receiver.TLSCredentials{
+					CertFile: "./testdata/certificate.pem",
+					KeyFile:  "./testdata/key.pem",
+				},
+			},
+			CorsOrigins:        []string{"https://*.test.com", "https://test.com"},
+			MaxRequestBodySize: 1048576,
+			ParseStringTags:    true,
+		})
 }
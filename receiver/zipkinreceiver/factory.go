@@ -51,10 +51,12 @@ func (f *Factory) CustomUnmarshaler() receiver.CustomUnmarshaler {
 // CreateDefaultConfig creates the default configuration for Jaeger receiver.
 func (f *Factory) CreateDefaultConfig() configmodels.Receiver {
 	return &Config{
-		ReceiverSettings: configmodels.ReceiverSettings{
-			TypeVal:  typeStr,
-			NameVal:  typeStr,
-			Endpoint: defaultBindEndpoint,
+		SecureReceiverSettings: receiver.SecureReceiverSettings{
+			ReceiverSettings: configmodels.ReceiverSettings{
+				TypeVal:  typeStr,
+				NameVal:  typeStr,
+				Endpoint: defaultBindEndpoint,
+			},
 		},
 	}
 }
@@ -68,7 +70,11 @@ func (f *Factory) CreateTraceReceiver(
 ) (receiver.TraceReceiver, error) {
 
 	rCfg := cfg.(*Config)
-	return New(rCfg.Endpoint, nextConsumer)
+	opts, err := rCfg.buildOptions()
+	if err != nil {
+		return nil, err
+	}
+	return New(rCfg.Endpoint, nextConsumer, opts...)
 }
 
 // CreateMetricsReceiver creates a metrics receiver based on provided config.
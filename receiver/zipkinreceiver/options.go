@@ -0,0 +1,85 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zipkinreceiver
+
+import "crypto/tls"
+
+// Option interface defines for configuration settings to be applied to receivers.
+//
+// withReceiver applies the configuration to the given receiver.
+type Option interface {
+	withReceiver(*ZipkinReceiver)
+}
+
+type tlsConfig struct {
+	config *tls.Config
+}
+
+var _ Option = (*tlsConfig)(nil)
+
+func (tc *tlsConfig) withReceiver(zr *ZipkinReceiver) {
+	zr.tlsConfig = tc.config
+}
+
+// WithTLSConfig is an option to serve the Zipkin HTTP endpoints over TLS
+// using the given configuration.
+func WithTLSConfig(config *tls.Config) Option {
+	return &tlsConfig{config: config}
+}
+
+type corsOrigins struct {
+	origins []string
+}
+
+var _ Option = (*corsOrigins)(nil)
+
+func (co *corsOrigins) withReceiver(zr *ZipkinReceiver) {
+	zr.corsOrigins = co.origins
+}
+
+// WithCorsOrigins is an option to specify the allowed origins to enable writing
+// HTTP requests to the receiver using CORS.
+func WithCorsOrigins(origins []string) Option {
+	return &corsOrigins{origins: origins}
+}
+
+type maxRequestBodySize int64
+
+var _ Option = (maxRequestBodySize)(0)
+
+func (size maxRequestBodySize) withReceiver(zr *ZipkinReceiver) {
+	zr.maxRequestBodySize = int64(size)
+}
+
+// WithMaxRequestBodySize is an option to limit the size (in bytes) of the
+// decompressed request bodies accepted by the receiver. Larger requests are
+// refused with "413 Request Entity Too Large".
+func WithMaxRequestBodySize(size int64) Option {
+	return maxRequestBodySize(size)
+}
+
+type parseStringTags bool
+
+var _ Option = (parseStringTags)(false)
+
+func (parse parseStringTags) withReceiver(zr *ZipkinReceiver) {
+	zr.parseStringTags = bool(parse)
+}
+
+// WithParseStringTags is an option to convert string tags holding numbers or
+// booleans into the corresponding typed attributes.
+func WithParseStringTags(parse bool) Option {
+	return parseStringTags(parse)
+}
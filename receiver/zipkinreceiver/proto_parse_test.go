@@ -15,7 +15,9 @@
 package zipkinreceiver
 
 import (
+	"bytes"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 	"time"
@@ -24,9 +26,14 @@ import (
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	"github.com/golang/protobuf/proto"
 	zipkin_proto3 "github.com/openzipkin/zipkin-go/proto/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 )
 
 func TestConvertSpansToTraceSpans_protobuf(t *testing.T) {
@@ -104,7 +111,7 @@ func TestConvertSpansToTraceSpans_protobuf(t *testing.T) {
 	hdr.Set("Content-Type", "application/x-protobuf")
 
 	// 3. Get that payload converted to OpenCensus proto spans.
-	reqs, err := zi.v2ToTraceSpans(protoBlob, hdr)
+	reqs, _, err := zi.v2ToTraceSpans(protoBlob, hdr)
 	if err != nil {
 		t.Fatalf("Failed to parse convert Zipkin spans in Protobuf to Trace spans: %v", err)
 	}
@@ -194,3 +201,78 @@ func TestConvertSpansToTraceSpans_protobuf(t *testing.T) {
 		t.Errorf("Got:\n\t%v\nWant:\n\t%v", g, w)
 	}
 }
+
+func TestReceiverProtobufContentType(t *testing.T) {
+	payload := &zipkin_proto3.ListOfSpans{
+		Spans: []*zipkin_proto3.Span{
+			{
+				TraceId:       []byte{0x7F, 0x6F, 0x5F, 0x4F, 0x3F, 0x2F, 0x1F, 0x0F, 0xF7, 0xF6, 0xF5, 0xF4, 0xF3, 0xF2, 0xF1, 0xF0},
+				Id:            []byte{0xF7, 0xF6, 0xF5, 0xF4, 0xF3, 0xF2, 0xF1, 0xF0},
+				Name:          "ProtoSpan1",
+				Timestamp:     uint64(time.Now().UnixNano() / 1e3),
+				Duration:      12e6,
+				LocalEndpoint: &zipkin_proto3.Endpoint{ServiceName: "svc-1"},
+			},
+		},
+	}
+	protoBlob, err := proto.Marshal(payload)
+	require.NoError(t, err)
+
+	for _, contentType := range []string{"application/x-protobuf", "application/x-protobuf; charset=utf-8"} {
+		t.Run(contentType, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			zr, err := New("localhost:0", sink)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader(protoBlob))
+			req.Header.Set("Content-Type", contentType)
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusAccepted, rec.Code)
+			got := sink.AllTraces()
+			require.Len(t, got, 1)
+			require.Len(t, got[0].Spans, 1)
+			assert.Equal(t, "ProtoSpan1", got[0].Spans[0].Name.Value)
+		})
+	}
+}
+
+func TestReceiverProtobufDroppedSpans(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	traceID := []byte{0x7F, 0x6F, 0x5F, 0x4F, 0x3F, 0x2F, 0x1F, 0x0F, 0xF7, 0xF6, 0xF5, 0xF4, 0xF3, 0xF2, 0xF1, 0xF0}
+	spanID := []byte{0xF7, 0xF6, 0xF5, 0xF4, 0xF3, 0xF2, 0xF1, 0xF0}
+	payload := &zipkin_proto3.ListOfSpans{
+		Spans: []*zipkin_proto3.Span{
+			{TraceId: traceID[:8], Id: spanID},
+			{TraceId: traceID, Id: spanID[:4]},
+			{TraceId: traceID, Id: spanID, ParentId: spanID[:2]},
+			{TraceId: make([]byte, 16), Id: spanID},
+			{TraceId: traceID, Id: spanID, Name: "good"},
+		},
+	}
+	protoBlob, err := proto.Marshal(payload)
+	require.NoError(t, err)
+
+	sink := new(exportertest.SinkTraceExporter)
+	zr, err := New("localhost:0", sink)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader(protoBlob))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec := httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, 1)
+	assert.Equal(t, "good", got[0].Spans[0].Name.Value)
+
+	require.NoError(t, observabilitytest.CheckValueViewReceiverReceivedSpans(zipkinV2TagValue, 5))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(zipkinV2TagValue, tracetranslator.DropReasonInvalidTraceID, 1))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(zipkinV2TagValue, tracetranslator.DropReasonInvalidSpanID, 2))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(zipkinV2TagValue, tracetranslator.DropReasonZeroTraceID, 1))
+}
@@ -17,11 +17,13 @@ package zipkinreceiver
 import (
 	"compress/gzip"
 	"compress/zlib"
+	"crypto/tls"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
+	"mime"
 	"net"
 	"net/http"
 	"strconv"
@@ -31,17 +33,22 @@ import (
 	"github.com/apache/thrift/lib/go/thrift"
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	"github.com/golang/protobuf/proto"
 	"github.com/jaegertracing/jaeger/thrift-gen/zipkincore"
 	zipkinmodel "github.com/openzipkin/zipkin-go/model"
 	zipkinproto "github.com/openzipkin/zipkin-go/proto/v2"
+	"github.com/pkg/errors"
+	"github.com/rs/cors"
 	"go.opencensus.io/trace"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/internal"
 	"github.com/open-telemetry/opentelemetry-service/observability"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
+	"github.com/open-telemetry/opentelemetry-service/receiver/receiverhelper"
 	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 	zipkintranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/zipkin"
 )
@@ -56,6 +63,11 @@ type ZipkinReceiver struct {
 	host         receiver.Host
 	nextConsumer consumer.TraceConsumer
 
+	tlsConfig          *tls.Config
+	corsOrigins        []string
+	maxRequestBodySize int64
+	parseStringTags    bool
+
 	startOnce sync.Once
 	stopOnce  sync.Once
 	server    *http.Server
@@ -65,7 +77,7 @@ var _ receiver.TraceReceiver = (*ZipkinReceiver)(nil)
 var _ http.Handler = (*ZipkinReceiver)(nil)
 
 // New creates a new zipkinreceiver.ZipkinReceiver reference.
-func New(address string, nextConsumer consumer.TraceConsumer) (*ZipkinReceiver, error) {
+func New(address string, nextConsumer consumer.TraceConsumer, opts ...Option) (*ZipkinReceiver, error) {
 	if nextConsumer == nil {
 		return nil, oterr.ErrNilNextConsumer
 	}
@@ -74,6 +86,9 @@ func New(address string, nextConsumer consumer.TraceConsumer) (*ZipkinReceiver,
 		addr:         address,
 		nextConsumer: nextConsumer,
 	}
+	for _, opt := range opts {
+		opt.withReceiver(zr)
+	}
 	return zr, nil
 }
 
@@ -112,8 +127,18 @@ func (zr *ZipkinReceiver) StartTraceReception(host receiver.Host) error {
 			return
 		}
 
+		if zr.tlsConfig != nil {
+			ln = tls.NewListener(ln, zr.tlsConfig)
+		}
+
+		var handler http.Handler = zr
+		if len(zr.corsOrigins) > 0 {
+			co := cors.Options{AllowedOrigins: zr.corsOrigins}
+			handler = cors.New(co).Handler(handler)
+		}
+
 		zr.host = host
-		server := &http.Server{Handler: zr}
+		server := &http.Server{Handler: handler}
 		zr.server = server
 		go func() {
 			host.ReportFatalError(server.Serve(ln))
@@ -126,11 +151,12 @@ func (zr *ZipkinReceiver) StartTraceReception(host receiver.Host) error {
 }
 
 // v1ToTraceSpans parses Zipkin v1 JSON traces and converts them to OpenCensus Proto spans.
-func (zr *ZipkinReceiver) v1ToTraceSpans(blob []byte, hdr http.Header) (reqs []consumerdata.TraceData, err error) {
-	if hdr.Get("Content-Type") == "application/x-thrift" {
+// It also returns, by reason, the number of spans that could not be converted.
+func (zr *ZipkinReceiver) v1ToTraceSpans(blob []byte, hdr http.Header) (reqs []consumerdata.TraceData, dropped tracetranslator.DroppedSpans, err error) {
+	if contentType(hdr) == "application/x-thrift" {
 		zSpans, err := deserializeThrift(blob)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		return zipkintranslator.V1ThriftBatchToOCProto(zSpans)
@@ -167,7 +193,8 @@ func deserializeThrift(b []byte) ([]*zipkincore.Span, error) {
 }
 
 // v2ToTraceSpans parses Zipkin v2 JSON or Protobuf traces and converts them to OpenCensus Proto spans.
-func (zr *ZipkinReceiver) v2ToTraceSpans(blob []byte, hdr http.Header) (reqs []consumerdata.TraceData, err error) {
+// It also returns, by reason, the number of spans that could not be converted.
+func (zr *ZipkinReceiver) v2ToTraceSpans(blob []byte, hdr http.Header) (reqs []consumerdata.TraceData, dropped tracetranslator.DroppedSpans, err error) {
 	// This flag's reference is from:
 	//      https://github.com/openzipkin/zipkin-go/blob/3793c981d4f621c0e3eb1457acffa2c1cc591384/proto/v2/zipkin.proto#L154
 	debugWasSet := hdr.Get("X-B3-Flags") == "1"
@@ -175,17 +202,17 @@ func (zr *ZipkinReceiver) v2ToTraceSpans(blob []byte, hdr http.Header) (reqs []c
 	var zipkinSpans []*zipkinmodel.SpanModel
 
 	// Zipkin can send protobuf via http
-	switch hdr.Get("Content-Type") {
+	switch contentType(hdr) {
 	// TODO: (@odeke-em) record the unique types of Content-Type uploads
 	case "application/x-protobuf":
-		zipkinSpans, err = zipkinproto.ParseSpans(blob, debugWasSet)
+		zipkinSpans, dropped, err = deserializeFromProto(blob, debugWasSet)
 
 	default: // By default, we'll assume using JSON
-		zipkinSpans, err = zr.deserializeFromJSON(blob, debugWasSet)
+		zipkinSpans, dropped, err = zr.deserializeFromJSON(blob, debugWasSet)
 	}
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// *commonpb.Node instances have unique addresses hence
@@ -195,14 +222,15 @@ func (zr *ZipkinReceiver) v2ToTraceSpans(blob []byte, hdr http.Header) (reqs []c
 	// Now translate them into tracepb.Span
 	for _, zspan := range zipkinSpans {
 		span, node, err := zipkinSpanToTraceSpan(zspan)
-		// TODO:(@odeke-em) record errors
-		if err == nil && span != nil {
-			key := node.String()
-			if _, alreadyAdded := byNodeGrouping[key]; !alreadyAdded {
-				uniqueNodes = append(uniqueNodes, node)
-			}
-			byNodeGrouping[key] = append(byNodeGrouping[key], span)
+		if err != nil {
+			dropped = dropped.Add(dropReasonForError(err), 1)
+			continue
 		}
+		key := node.String()
+		if _, alreadyAdded := byNodeGrouping[key]; !alreadyAdded {
+			uniqueNodes = append(uniqueNodes, node)
+		}
+		byNodeGrouping[key] = append(byNodeGrouping[key], span)
 	}
 
 	for _, node := range uniqueNodes {
@@ -220,14 +248,109 @@ func (zr *ZipkinReceiver) v2ToTraceSpans(blob []byte, hdr http.Header) (reqs []c
 		delete(byNodeGrouping, key)
 	}
 
-	return reqs, nil
+	return reqs, dropped, nil
 }
 
-func (zr *ZipkinReceiver) deserializeFromJSON(jsonBlob []byte, debugWasSet bool) (zs []*zipkinmodel.SpanModel, err error) {
-	if err = json.Unmarshal(jsonBlob, &zs); err != nil {
-		return nil, err
+// contentType returns the media type of the "Content-Type" header without
+// any parameters, e.g. "application/x-protobuf; charset=utf-8" becomes
+// "application/x-protobuf".
+func contentType(hdr http.Header) string {
+	mediaType, _, err := mime.ParseMediaType(hdr.Get("Content-Type"))
+	if err != nil {
+		return hdr.Get("Content-Type")
+	}
+	return mediaType
+}
+
+func (zr *ZipkinReceiver) deserializeFromJSON(jsonBlob []byte, debugWasSet bool) (zs []*zipkinmodel.SpanModel, dropped tracetranslator.DroppedSpans, err error) {
+	// Decode the spans one at a time so a malformed span doesn't cause the
+	// whole batch to be dropped.
+	var rawSpans []json.RawMessage
+	if err = json.Unmarshal(jsonBlob, &rawSpans); err != nil {
+		return nil, nil, err
+	}
+	zs = make([]*zipkinmodel.SpanModel, 0, len(rawSpans))
+	for _, rawSpan := range rawSpans {
+		var zspan *zipkinmodel.SpanModel
+		if err := json.Unmarshal(rawSpan, &zspan); err != nil {
+			dropped = dropped.Add(dropReasonForError(err), 1)
+			continue
+		}
+		if zspan == nil {
+			dropped = dropped.Add(tracetranslator.DropReasonDecodeError, 1)
+			continue
+		}
+		if debugWasSet {
+			zspan.Debug = true
+		}
+		zs = append(zs, zspan)
+	}
+	return zs, dropped, nil
+}
+
+// deserializeFromProto decodes a Zipkin v2 protobuf list of spans. The spans
+// are only converted one at a time if the whole list can't be, so that a
+// malformed span doesn't cause the whole batch to be dropped.
+func deserializeFromProto(protoBlob []byte, debugWasSet bool) (zs []*zipkinmodel.SpanModel, dropped tracetranslator.DroppedSpans, err error) {
+	if zs, err = zipkinproto.ParseSpans(protoBlob, debugWasSet); err == nil {
+		return zs, nil, nil
+	}
+
+	var listOfSpans zipkinproto.ListOfSpans
+	if err = proto.Unmarshal(protoBlob, &listOfSpans); err != nil {
+		return nil, nil, err
+	}
+	zs = make([]*zipkinmodel.SpanModel, 0, len(listOfSpans.Spans))
+	for _, protoSpan := range listOfSpans.Spans {
+		// ParseSpans is the only exported conversion of the protobuf spans.
+		spanBlob, err := proto.Marshal(&zipkinproto.ListOfSpans{Spans: []*zipkinproto.Span{protoSpan}})
+		if err != nil {
+			dropped = dropped.Add(tracetranslator.DropReasonDecodeError, 1)
+			continue
+		}
+		zspans, err := zipkinproto.ParseSpans(spanBlob, debugWasSet)
+		if err != nil || len(zspans) != 1 {
+			dropped = dropped.Add(dropReasonForProtoError(err), 1)
+			continue
+		}
+		zs = append(zs, zspans[0])
+	}
+	return zs, dropped, nil
+}
+
+// dropReasonForProtoError maps the errors returned by zipkinproto.ParseSpans
+// for a single span to the reason reported for dropping the span. The errors
+// are only identified by their message.
+func dropReasonForProtoError(err error) string {
+	if err == nil {
+		return tracetranslator.DropReasonDecodeError
+	}
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "invalid TraceID"):
+		return tracetranslator.DropReasonInvalidTraceID
+	case strings.HasPrefix(msg, "invalid SpanID"),
+		strings.HasPrefix(msg, "invalid ParentID"),
+		strings.HasPrefix(msg, "expected a non-nil SpanID"):
+		return tracetranslator.DropReasonInvalidSpanID
+	default:
+		return tracetranslator.DropReasonDecodeError
+	}
+}
+
+// dropReasonForError maps the errors returned when decoding a span or by
+// zipkinSpanToTraceSpan to the reason reported for dropping the span.
+func dropReasonForError(err error) string {
+	switch errors.Cause(err) {
+	case errZeroTraceID:
+		return tracetranslator.DropReasonZeroTraceID
+	case zipkinmodel.ErrValidTraceIDRequired:
+		return tracetranslator.DropReasonInvalidTraceID
+	case errZeroID, zipkinmodel.ErrValidIDRequired:
+		return tracetranslator.DropReasonInvalidSpanID
+	default:
+		return tracetranslator.DropReasonDecodeError
 	}
-	return zs, nil
 }
 
 // StopTraceReception tells the receiver that should stop reception,
@@ -305,22 +428,43 @@ func (zr *ZipkinReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	ctxWithReceiverName := observability.ContextWithReceiverName(ctx, receiverTagValue)
 
-	pr := processBodyIfNecessary(r)
+	body := processBodyIfNecessary(r)
+	pr := body
+	if zr.maxRequestBodySize > 0 {
+		// Read one byte past the limit to tell apart bodies that are
+		// exactly at the limit from the ones that exceed it.
+		pr = io.LimitReader(pr, zr.maxRequestBodySize+1)
+	}
 	slurp, _ := ioutil.ReadAll(pr)
-	if c, ok := pr.(io.Closer); ok {
+	if c, ok := body.(io.Closer); ok {
 		_ = c.Close()
 	}
 	_ = r.Body.Close()
 
+	if zr.maxRequestBodySize > 0 && int64(len(slurp)) > zr.maxRequestBodySize {
+		err := fmt.Errorf("request body exceeds the maximum size of %d bytes", zr.maxRequestBodySize)
+		span.SetStatus(trace.Status{
+			Code:    trace.StatusCodeInvalidArgument,
+			Message: err.Error(),
+		})
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
 	var tds []consumerdata.TraceData
+	var dropped tracetranslator.DroppedSpans
 	var err error
 	if asZipkinv1 {
-		tds, err = zr.v1ToTraceSpans(slurp, r.Header)
+		tds, dropped, err = zr.v1ToTraceSpans(slurp, r.Header)
 	} else {
-		tds, err = zr.v2ToTraceSpans(slurp, r.Header)
+		tds, dropped, err = zr.v2ToTraceSpans(slurp, r.Header)
 	}
 
 	if err != nil {
+		// The number of spans of a body that can't be decoded is unknown,
+		// count it as a single span.
+		receiverhelper.RecordTraceReceiverMetrics(ctxWithReceiverName, 1,
+			tracetranslator.DroppedSpans{tracetranslator.DropReasonDecodeError: 1})
 		span.SetStatus(trace.Status{
 			Code:    trace.StatusCodeInvalidArgument,
 			Message: err.Error(),
@@ -331,13 +475,32 @@ func (zr *ZipkinReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	tdsSize := 0
 	for _, td := range tds {
-		td.SourceFormat = "zipkin"
-		zr.nextConsumer.ConsumeTraceData(ctxWithReceiverName, td)
 		tdsSize += len(td.Spans)
 	}
+	receiverhelper.RecordTraceReceiverMetrics(ctxWithReceiverName, tdsSize+dropped.Total(), dropped)
 
-	// TODO: Get the number of dropped spans from the conversion failure.
-	observability.RecordMetricsForTraceReceiver(ctxWithReceiverName, tdsSize, 0)
+	consumed := false
+	for _, td := range tds {
+		td.SourceFormat = "zipkin"
+		if zr.parseStringTags {
+			parseStringAttributes(td.Spans)
+		}
+		err = zr.nextConsumer.ConsumeTraceData(ctxWithReceiverName, td)
+		if err == nil {
+			consumed = true
+			continue
+		}
+		// Only ask the client to send the request again if none of it was
+		// consumed, otherwise the consumed spans would be duplicated.
+		if !consumed && consumererror.IsThrottled(err) {
+			span.SetStatus(trace.Status{
+				Code:    trace.StatusCodeResourceExhausted,
+				Message: err.Error(),
+			})
+			receiverhelper.WriteHTTPThrottled(w, err)
+			return
+		}
+	}
 
 	// Finally send back the response "Accepted" as
 	// required at https://zipkin.io/zipkin-api/#/default/post_spans
@@ -372,17 +535,17 @@ func zipkinSpanToTraceSpan(zs *zipkinmodel.SpanModel) (*tracepb.Span, *commonpb.
 	node := nodeFromZipkinEndpoints(zs)
 	traceID, err := zTraceIDToOCProtoTraceID(zs.TraceID)
 	if err != nil {
-		return nil, node, fmt.Errorf("TraceID: %v", err)
+		return nil, node, errors.WithMessage(err, "TraceID")
 	}
 	spanID, err := zSpanIDToOCProtoSpanID(zs.ID)
 	if err != nil {
-		return nil, node, fmt.Errorf("SpanID: %v", err)
+		return nil, node, errors.WithMessage(err, "SpanID")
 	}
 	var parentSpanID []byte
 	if zs.ParentID != nil {
 		parentSpanID, err = zSpanIDToOCProtoSpanID(*zs.ParentID)
 		if err != nil {
-			return nil, node, fmt.Errorf("ParentSpanID: %v", err)
+			return nil, node, errors.WithMessage(err, "ParentSpanID")
 		}
 	}
 
@@ -398,6 +561,9 @@ func zipkinSpanToTraceSpan(zs *zipkinmodel.SpanModel) (*tracepb.Span, *commonpb.
 		Attributes:   zipkinTagsToTraceAttributes(zs.Tags),
 		TimeEvents:   zipkinAnnotationsToProtoTimeEvents(zs.Annotations),
 	}
+	if zs.Debug {
+		setDebugAttribute(pbs)
+	}
 
 	return pbs, node, nil
 }
@@ -558,6 +724,20 @@ func zipkinAnnotationToProtoAnnotation(zas zipkinmodel.Annotation) *tracepb.Span
 	}
 }
 
+// setDebugAttribute records on the span that Zipkin flagged it as debug so
+// that the samplers keep it.
+func setDebugAttribute(span *tracepb.Span) {
+	if span.Attributes == nil {
+		span.Attributes = &tracepb.Span_Attributes{}
+	}
+	if span.Attributes.AttributeMap == nil {
+		span.Attributes.AttributeMap = make(map[string]*tracepb.AttributeValue, 1)
+	}
+	span.Attributes.AttributeMap[tracetranslator.TagZipkinDebug] = &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_BoolValue{BoolValue: true},
+	}
+}
+
 func zipkinTagsToTraceAttributes(tags map[string]string) *tracepb.Span_Attributes {
 	if len(tags) == 0 {
 		return nil
@@ -583,3 +763,69 @@ func zipkinTagsToTraceAttributes(tags map[string]string) *tracepb.Span_Attribute
 	}
 	return &tracepb.Span_Attributes{AttributeMap: amap}
 }
+
+// parseStringAttributes replaces the string attributes of the given spans
+// that hold an integer, a floating point number or a boolean with an
+// attribute of the corresponding type.
+func parseStringAttributes(spans []*tracepb.Span) {
+	for _, span := range spans {
+		if span == nil || span.Attributes == nil {
+			continue
+		}
+		for key, attrib := range span.Attributes.AttributeMap {
+			strValue, ok := attrib.GetValue().(*tracepb.AttributeValue_StringValue)
+			if !ok || strValue.StringValue == nil {
+				continue
+			}
+			if typed := parseStringTag(strValue.StringValue.Value); typed != nil {
+				span.Attributes.AttributeMap[key] = typed
+			}
+		}
+	}
+}
+
+// parseStringTag returns the typed attribute represented by the given tag
+// value or nil if the value is not a number nor a boolean. Numbers are only
+// converted if they are written the same way once converted, e.g. not "007",
+// "1.10", "1e3" or a value overflowing an int64, so that no information is
+// lost.
+func parseStringTag(value string) *tracepb.AttributeValue {
+	if isIntegerString(value) {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(i, 10) == value {
+			return &tracepb.AttributeValue{
+				Value: &tracepb.AttributeValue_IntValue{IntValue: i},
+			}
+		}
+		return nil
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) &&
+		strconv.FormatFloat(f, 'g', -1, 64) == value {
+		return &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: f},
+		}
+	}
+	switch value {
+	case "true", "false":
+		return &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_BoolValue{BoolValue: value == "true"},
+		}
+	}
+	return nil
+}
+
+// isIntegerString returns true if the value is made of decimal digits with an
+// optional sign.
+func isIntegerString(value string) bool {
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		value = value[1:]
+	}
+	if value == "" {
+		return false
+	}
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -16,13 +16,18 @@ package zipkinreceiver
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -36,12 +41,15 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/consumer"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumererror"
 	"github.com/open-telemetry/opentelemetry-service/exporter/exportertest"
 	"github.com/open-telemetry/opentelemetry-service/internal"
 	"github.com/open-telemetry/opentelemetry-service/internal/testutils"
+	"github.com/open-telemetry/opentelemetry-service/observability/observabilitytest"
 	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/receiver"
 	"github.com/open-telemetry/opentelemetry-service/receiver/receivertest"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 	spandatatranslator "github.com/open-telemetry/opentelemetry-service/translator/trace/spandata"
 )
 
@@ -179,7 +187,7 @@ func TestConvertSpansToTraceSpans_json(t *testing.T) {
 		t.Fatalf("Failed to read sample JSON file: %v", err)
 	}
 	zi := new(ZipkinReceiver)
-	reqs, err := zi.v2ToTraceSpans(blob, nil)
+	reqs, _, err := zi.v2ToTraceSpans(blob, nil)
 	if err != nil {
 		t.Fatalf("Failed to parse convert Zipkin spans in JSON to Trace spans: %v", err)
 	}
@@ -290,7 +298,7 @@ func TestConversionRoundtrip(t *testing.T) {
 }]`)
 
 	zi := &ZipkinReceiver{nextConsumer: exportertest.NewNopTraceExporter()}
-	ereqs, err := zi.v2ToTraceSpans(receiverInputJSON, nil)
+	ereqs, _, err := zi.v2ToTraceSpans(receiverInputJSON, nil)
 	if err != nil {
 		t.Fatalf("Failed to parse and convert receiver JSON: %v", err)
 	}
@@ -556,3 +564,411 @@ func TestStartTraceReception(t *testing.T) {
 		})
 	}
 }
+
+func TestReceiverConsumerError(t *testing.T) {
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.Nil(t, err)
+
+	tests := []struct {
+		name           string
+		consumerErr    error
+		wantStatus     int
+		wantRetryAfter string
+	}{
+		{
+			name:       "accepted",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:           "throttled",
+			consumerErr:    consumererror.Throttled(errors.New("queue is full"), 10*time.Second),
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "10",
+		},
+		{
+			// Other errors are not reported to the client.
+			name:        "other",
+			consumerErr: errors.New("exporter failure"),
+			wantStatus:  http.StatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zr, err := New("localhost:0", exportertest.NewNopTraceExporter(exportertest.WithReturnError(tt.consumerErr)))
+			require.Nil(t, err)
+
+			req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader(blob))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			require.Equal(t, tt.wantRetryAfter, rec.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestReceiverConsumerErrorPartial(t *testing.T) {
+	// The spans of each service are consumed in their own batch.
+	const blob = `[
+		{"traceId": "4d1e00c0db9010db86154a4ba6e91385", "id": "4d1e00c0db9010db", "localEndpoint": {"serviceName": "frontend"}},
+		{"traceId": "4d1e00c0db9010db86154a4ba6e91385", "id": "4d1e00c0db9010dc", "localEndpoint": {"serviceName": "backend"}}
+	]`
+
+	// The first batch is consumed and the next ones are throttled: the request
+	// must not be sent again since that would duplicate the consumed spans.
+	consumer := &throttlingConsumer{accepted: 1}
+	zr, err := New("localhost:0", consumer)
+	require.Nil(t, err)
+
+	req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader([]byte(blob)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Equal(t, 2, consumer.calls)
+
+	// Nothing is consumed: the client can send the request again.
+	consumer = &throttlingConsumer{}
+	zr, err = New("localhost:0", consumer)
+	require.Nil(t, err)
+
+	req = httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader([]byte(blob)))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
+// throttlingConsumer accepts the given number of batches and throttles the
+// next ones.
+type throttlingConsumer struct {
+	accepted int
+	calls    int
+}
+
+func (tc *throttlingConsumer) ConsumeTraceData(ctx context.Context, td consumerdata.TraceData) error {
+	tc.calls++
+	if tc.calls > tc.accepted {
+		return consumererror.Throttled(errors.New("queue is full"), time.Second)
+	}
+	return nil
+}
+
+func TestReceiverMaxRequestBodySize(t *testing.T) {
+	blob, err := ioutil.ReadFile("./testdata/sample1.json")
+	require.Nil(t, err)
+
+	tests := []struct {
+		name       string
+		maxSize    int64
+		wantStatus int
+	}{
+		{
+			name:       "unlimited",
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "at_limit",
+			maxSize:    int64(len(blob)),
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "over_limit",
+			maxSize:    int64(len(blob)) - 1,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			zr, err := New("localhost:0", sink, WithMaxRequestBodySize(tt.maxSize))
+			require.Nil(t, err)
+
+			req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader(blob))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusAccepted {
+				require.Empty(t, sink.AllTraces())
+			}
+		})
+	}
+}
+
+func TestReceiverParseStringTags(t *testing.T) {
+	const blob = `[{
+		"traceId": "4d1e00c0db9010db86154a4ba6e91385",
+		"id": "4d1e00c0db9010db",
+		"name": "get",
+		"timestamp": 1472470996199000,
+		"duration": 207000,
+		"localEndpoint": {"serviceName": "frontend"},
+		"tags": {
+			"http.status_code": "200",
+			"sampling.rate": "0.25",
+			"error": "false",
+			"http.path": "/api"
+		}
+	}]`
+
+	tests := []struct {
+		name  string
+		parse bool
+		want  map[string]*tracepb.AttributeValue
+	}{
+		{
+			name: "disabled",
+			want: map[string]*tracepb.AttributeValue{
+				"http.status_code": {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "200"}}},
+				"sampling.rate":    {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "0.25"}}},
+				"error":            {Value: &tracepb.AttributeValue_BoolValue{BoolValue: false}},
+				"http.path":        {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "/api"}}},
+			},
+		},
+		{
+			name:  "enabled",
+			parse: true,
+			want: map[string]*tracepb.AttributeValue{
+				"http.status_code": {Value: &tracepb.AttributeValue_IntValue{IntValue: 200}},
+				"sampling.rate":    {Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: 0.25}},
+				"error":            {Value: &tracepb.AttributeValue_BoolValue{BoolValue: false}},
+				"http.path":        {Value: &tracepb.AttributeValue_StringValue{StringValue: &tracepb.TruncatableString{Value: "/api"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			zr, err := New("localhost:0", sink, WithParseStringTags(tt.parse))
+			require.Nil(t, err)
+
+			req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader([]byte(blob)))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusAccepted, rec.Code)
+
+			got := sink.AllTraces()
+			require.Len(t, got, 1)
+			require.Len(t, got[0].Spans, 1)
+			require.Equal(t, tt.want, got[0].Spans[0].Attributes.AttributeMap)
+		})
+	}
+}
+
+func TestParseStringTag(t *testing.T) {
+	tests := []struct {
+		value string
+		want  *tracepb.AttributeValue
+	}{
+		{value: "-42", want: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: -42}}},
+		{value: "0", want: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_IntValue{IntValue: 0}}},
+		{value: "-0.25", want: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: -0.25}}},
+		{value: "1.5e+21", want: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_DoubleValue{DoubleValue: 1.5e21}}},
+		// Numbers that would not be written the same way are kept as is.
+		{value: "007"},
+		{value: "1.10"},
+		{value: "1e3"},
+		{value: "1.5e3"},
+		{value: "0x1p-2"},
+		{value: "1.0"},
+		{value: ".5"},
+		{value: "+5"},
+		{value: "-0"},
+		{value: "99999999999999999999"},
+		{value: "-"},
+		{value: "true", want: &tracepb.AttributeValue{Value: &tracepb.AttributeValue_BoolValue{BoolValue: true}}},
+		{value: "NaN"},
+		{value: "Inf"},
+		{value: "+Inf"},
+		{value: "TRUE"},
+		{value: "200ms"},
+		{value: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			require.Equal(t, tt.want, parseStringTag(tt.value))
+		})
+	}
+}
+
+func TestReceiverTLSAndCORS(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "localhost:" + strconv.Itoa(int(testutils.GetAvailablePort(t)))
+	cfg.TLSCredentials = &receiver.TLSCredentials{
+		CertFile: path.Join(".", "testdata", "certificate.pem"),
+		KeyFile:  path.Join(".", "testdata", "key.pem"),
+	}
+	cfg.CorsOrigins = []string{"http://allowed.example.com"}
+
+	opts, err := cfg.buildOptions()
+	require.NoError(t, err)
+	zr, err := New(cfg.Endpoint, exportertest.NewNopTraceExporter(), opts...)
+	require.NoError(t, err)
+	require.NoError(t, zr.StartTraceReception(receivertest.NewMockHost()))
+	defer zr.StopTraceReception()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			// The test certificate is self-signed.
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	tests := []struct {
+		origin    string
+		wantAllow string
+	}{
+		{origin: "http://allowed.example.com", wantAllow: "http://allowed.example.com"},
+		{origin: "http://disallowed.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req, err := http.NewRequest("OPTIONS", "https://"+cfg.Endpoint+"/api/v2/spans", nil)
+			require.NoError(t, err)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", "POST")
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, tt.wantAllow, resp.Header.Get("Access-Control-Allow-Origin"))
+		})
+	}
+}
+
+func TestBuildOptionsInvalidTLSCredentials(t *testing.T) {
+	cfg := (&Factory{}).CreateDefaultConfig().(*Config)
+	cfg.TLSCredentials = &receiver.TLSCredentials{
+		CertFile: "./testdata/missing_cert.pem",
+		KeyFile:  "./testdata/missing_key.pem",
+	}
+
+	opts, err := cfg.buildOptions()
+	require.Error(t, err)
+	require.Nil(t, opts)
+}
+
+func TestReceiverDroppedSpans(t *testing.T) {
+	doneFn := observabilitytest.SetupRecordedMetricsTest()
+	defer doneFn()
+
+	const blob = `[
+		null,
+		{"traceId": "4d1e00c0db9010db86154a4ba6e91385", "id": "not-hex"},
+		{"traceId": "00000000000000000000000000000000", "id": "4d1e00c0db9010db"},
+		{"traceId": "4d1e00c0db9010db86154a4ba6e91385", "id": "0000000000000000"},
+		{"traceId": "4d1e00c0db9010db86154a4ba6e91385", "id": "4d1e00c0db9010db", "name": "good"}
+	]`
+
+	sink := new(exportertest.SinkTraceExporter)
+	zr, err := New("localhost:0", sink)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader([]byte(blob)))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	zr.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	got := sink.AllTraces()
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Spans, 1)
+	require.Equal(t, "good", got[0].Spans[0].Name.Value)
+
+	require.NoError(t, observabilitytest.CheckValueViewReceiverReceivedSpans(zipkinV2TagValue, 5))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(zipkinV2TagValue, tracetranslator.DropReasonDecodeError, 2))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(zipkinV2TagValue, tracetranslator.DropReasonZeroTraceID, 1))
+	require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(zipkinV2TagValue, tracetranslator.DropReasonInvalidSpanID, 1))
+}
+
+func TestReceiverUndecodableBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		tagValue    string
+	}{
+		{name: "v2 json", path: "/api/v2/spans", contentType: "application/json", tagValue: zipkinV2TagValue},
+		{name: "v2 protobuf", path: "/api/v2/spans", contentType: "application/x-protobuf", tagValue: zipkinV2TagValue},
+		{name: "v1 json", path: "/api/v1/spans", contentType: "application/json", tagValue: zipkinV1TagValue},
+		{name: "v1 thrift", path: "/api/v1/spans", contentType: "application/x-thrift", tagValue: zipkinV1TagValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doneFn := observabilitytest.SetupRecordedMetricsTest()
+			defer doneFn()
+
+			zr, err := New("localhost:0", new(exportertest.SinkTraceExporter))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest("POST", tt.path, bytes.NewReader([]byte{0xff, 0xff, 0xff}))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+
+			require.NoError(t, observabilitytest.CheckValueViewReceiverDroppedSpansWithReason(tt.tagValue, tracetranslator.DropReasonDecodeError, 1))
+		})
+	}
+}
+
+func TestReceiverDebugSpans(t *testing.T) {
+	const blob = `[
+		{"traceId": "4d1e00c0db9010db86154a4ba6e91385", "id": "4d1e00c0db9010db", "name": "debug", "debug": true},
+		{"traceId": "4d1e00c0db9010db86154a4ba6e91385", "id": "4d1e00c0db9010dc", "name": "regular"}
+	]`
+
+	tests := []struct {
+		name      string
+		b3Flags   string
+		wantDebug map[string]bool
+	}{
+		{
+			name:      "span flag",
+			wantDebug: map[string]bool{"debug": true, "regular": false},
+		},
+		{
+			name:      "header flag",
+			b3Flags:   "1",
+			wantDebug: map[string]bool{"debug": true, "regular": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := new(exportertest.SinkTraceExporter)
+			zr, err := New("localhost:0", sink)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewReader([]byte(blob)))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.b3Flags != "" {
+				req.Header.Set("X-B3-Flags", tt.b3Flags)
+			}
+			rec := httptest.NewRecorder()
+			zr.ServeHTTP(rec, req)
+			require.Equal(t, http.StatusAccepted, rec.Code)
+
+			gotDebug := make(map[string]bool)
+			for _, td := range sink.AllTraces() {
+				for _, span := range td.Spans {
+					attr := span.GetAttributes().GetAttributeMap()[tracetranslator.TagZipkinDebug]
+					gotDebug[span.Name.Value] = attr.GetBoolValue()
+				}
+			}
+			require.Equal(t, tt.wantDebug, gotDebug)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/consumer"
+	"github.com/open-telemetry/opentelemetry-service/oterr"
 	"github.com/open-telemetry/opentelemetry-service/processor"
 )
 
@@ -29,18 +30,47 @@ import (
 type builtProcessor struct {
 	tc consumer.TraceConsumer
 	mc consumer.MetricsConsumer
+
+	// shutdowners are the processors of the pipeline that must be shut down,
+	// in the order of the pipeline.
+	shutdowners []processor.Shutdowner
 }
 
 // PipelineProcessors is a map of entry-point processors created from pipeline configs.
 // Each element of the map points to the first processor of the pipeline.
 type PipelineProcessors map[*configmodels.Pipeline]*builtProcessor
 
+// ShutdownAll shuts down the processors of all the pipelines. The processors
+// of a pipeline are shut down in its order, so that the data they send on
+// shutdown still goes through the next ones.
+func (pps PipelineProcessors) ShutdownAll() error {
+	var errs []error
+	for _, bp := range pps {
+		for _, s := range bp.shutdowners {
+			if err := s.Shutdown(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return oterr.CombineErrors(errs)
+}
+
 // PipelinesBuilder builds pipelines from config.
 type PipelinesBuilder struct {
 	logger    *zap.Logger
 	config    *configmodels.Config
 	exporters Exporters
 	factories map[string]processor.Factory
+
+	// connectors are the built processors that need to be connected to other
+	// pipelines once all the pipelines are built.
+	connectors []namedConnector
+}
+
+type namedConnector struct {
+	name      string
+	pipeline  string
+	connector processor.PipelinesConnector
 }
 
 // NewPipelinesBuilder creates a new PipelinesBuilder. Requires exporters to be already
@@ -51,7 +81,12 @@ func NewPipelinesBuilder(
 	exporters Exporters,
 	factories map[string]processor.Factory,
 ) *PipelinesBuilder {
-	return &PipelinesBuilder{logger, config, exporters, factories}
+	return &PipelinesBuilder{
+		logger:    logger,
+		config:    config,
+		exporters: exporters,
+		factories: factories,
+	}
 }
 
 // Build pipeline processors from config.
@@ -66,6 +101,20 @@ func (pb *PipelinesBuilder) Build() (PipelineProcessors, error) {
 		pipelineProcessors[pipeline] = firstProcessor
 	}
 
+	// Connect the processors sending data to other pipelines now that all the
+	// pipelines exist.
+	consumers := &pipelineConsumers{
+		config:     pb.config,
+		processors: pipelineProcessors,
+		exporters:  pb.exporters,
+	}
+	for _, nc := range pb.connectors {
+		if err := nc.connector.ConnectPipelines(consumers); err != nil {
+			return nil, fmt.Errorf("error connecting processor %q in pipeline %q: %v",
+				nc.name, nc.pipeline, err)
+		}
+	}
+
 	return pipelineProcessors, nil
 }
 
@@ -81,6 +130,7 @@ func (pb *PipelinesBuilder) buildPipeline(
 	// First create a consumer junction point that fans out the data to all exporters.
 	var tc consumer.TraceConsumer
 	var mc consumer.MetricsConsumer
+	var shutdowners []processor.Shutdowner
 
 	switch pipelineCfg.InputType {
 	case configmodels.TracesDataType:
@@ -114,11 +164,27 @@ func (pb *PipelinesBuilder) buildPipeline(
 			return nil, fmt.Errorf("error creating processor %q in pipeline %q: %v",
 				procName, pipelineCfg.Name, err)
 		}
+
+		var built interface{} = tc
+		if pipelineCfg.InputType == configmodels.MetricsDataType {
+			built = mc
+		}
+		if s, ok := built.(processor.Shutdowner); ok {
+			// Prepend: the processors are built backwards.
+			shutdowners = append([]processor.Shutdowner{s}, shutdowners...)
+		}
+		if connector, ok := built.(processor.PipelinesConnector); ok {
+			pb.connectors = append(pb.connectors, namedConnector{
+				name:      procName,
+				pipeline:  pipelineCfg.Name,
+				connector: connector,
+			})
+		}
 	}
 
 	pb.logger.Info("Pipeline is enabled.", zap.String("pipelines", pipelineCfg.Name))
 
-	return &builtProcessor{tc, mc}, nil
+	return &builtProcessor{tc: tc, mc: mc, shutdowners: shutdowners}, nil
 }
 
 // Converts the list of exporter names to a list of corresponding builtExporters.
@@ -165,3 +231,57 @@ func (pb *PipelinesBuilder) buildFanoutExportersMetricsConsumer(exporterNames []
 	// Create a junction point that fans out to all exporters.
 	return processor.NewMetricsFanOutConnector(exporters)
 }
+
+// pipelineConsumers implements processor.PipelineConsumers for the built
+// pipelines.
+type pipelineConsumers struct {
+	config     *configmodels.Config
+	processors PipelineProcessors
+	exporters  Exporters
+}
+
+var _ processor.PipelineConsumers = (*pipelineConsumers)(nil)
+
+func (pc *pipelineConsumers) TraceConsumer(pipelineName string) consumer.TraceConsumer {
+	pipeline := pc.config.Pipelines[pipelineName]
+	if pipeline == nil || pipeline.InputType != configmodels.TracesDataType {
+		return nil
+	}
+	if bp := pc.processors[pipeline]; bp != nil {
+		return bp.tc
+	}
+	return nil
+}
+
+func (pc *pipelineConsumers) MetricsConsumer(pipelineName string) consumer.MetricsConsumer {
+	pipeline := pc.config.Pipelines[pipelineName]
+	if pipeline == nil || pipeline.InputType != configmodels.MetricsDataType {
+		return nil
+	}
+	if bp := pc.processors[pipeline]; bp != nil {
+		return bp.mc
+	}
+	return nil
+}
+
+func (pc *pipelineConsumers) TraceExporter(exporterName string) consumer.TraceConsumer {
+	exporter := pc.config.Exporters[exporterName]
+	if exporter == nil {
+		return nil
+	}
+	if be := pc.exporters[exporter]; be != nil && be.te != nil {
+		return be.te
+	}
+	return nil
+}
+
+func (pc *pipelineConsumers) MetricsExporter(exporterName string) consumer.MetricsConsumer {
+	exporter := pc.config.Exporters[exporterName]
+	if exporter == nil {
+		return nil
+	}
+	if be := pc.exporters[exporter]; be != nil && be.me != nil {
+		return be.me
+	}
+	return nil
+}
@@ -17,18 +17,21 @@ package builder
 import (
 	"context"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 
 	"github.com/open-telemetry/opentelemetry-service/config"
 	"github.com/open-telemetry/opentelemetry-service/config/configmodels"
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/processor/attributesprocessor"
+	"github.com/open-telemetry/opentelemetry-service/processor/spanmetricsprocessor"
 )
 
 func TestPipelinesBuilder_Build(t *testing.T) {
@@ -145,3 +148,73 @@ func TestPipelinesBuilder_Error(t *testing.T) {
 
 	assert.NotNil(t, err)
 }
+
+func TestPipelinesBuilder_ConnectPipelines(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+	smFactory := &spanmetricsprocessor.Factory{}
+	factories.Processors[smFactory.Type()] = smFactory
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder_connect.yaml", factories)
+	require.Nil(t, err)
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.NoError(t, err)
+
+	pipelineProcessors, err := NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors).Build()
+	assert.NoError(t, err)
+	require.NotNil(t, pipelineProcessors[cfg.Pipelines["traces"]])
+
+	consumers := &pipelineConsumers{config: cfg, processors: pipelineProcessors, exporters: exporters}
+	assert.NotNil(t, consumers.TraceConsumer("traces"))
+	assert.Nil(t, consumers.TraceConsumer("metrics/spans"))
+	assert.NotNil(t, consumers.MetricsConsumer("metrics/spans"))
+	assert.NotNil(t, consumers.TraceExporter("exampleexporter"))
+	assert.Nil(t, consumers.MetricsExporter("exampleexporter"))
+	assert.NotNil(t, consumers.MetricsExporter("exampleexporter/2"))
+	assert.Nil(t, consumers.TraceExporter("exampleexporter/missing"))
+
+	// Point the processor to a pipeline that doesn't exist, the connection
+	// must fail.
+	cfg.Processors["span_metrics"].(*spanmetricsprocessor.Config).MetricsPipeline = "metrics/missing"
+	_, err = NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors).Build()
+	assert.Error(t, err)
+}
+
+func TestPipelinesBuilder_ShutdownAll(t *testing.T) {
+	factories, err := config.ExampleComponents()
+	assert.Nil(t, err)
+	smFactory := &spanmetricsprocessor.Factory{}
+	factories.Processors[smFactory.Type()] = smFactory
+	cfg, err := config.LoadConfigFile(t, "testdata/pipelines_builder_connect.yaml", factories)
+	require.Nil(t, err)
+	cfg.Processors["span_metrics"].(*spanmetricsprocessor.Config).FlushInterval = time.Millisecond
+
+	exporters, err := NewExportersBuilder(zap.NewNop(), cfg, factories.Exporters).Build()
+	assert.NoError(t, err)
+
+	pipelineProcessors, err := NewPipelinesBuilder(zap.NewNop(), cfg, exporters, factories.Processors).Build()
+	assert.NoError(t, err)
+	traces := pipelineProcessors[cfg.Pipelines["traces"]]
+	require.NotNil(t, traces)
+	require.Len(t, traces.shutdowners, 1)
+
+	td := consumerdata.TraceData{
+		Node:  &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "svc"}},
+		Spans: []*tracepb.Span{{Name: &tracepb.TruncatableString{Value: "GET"}}},
+	}
+	require.NoError(t, traces.tc.ConsumeTraceData(context.Background(), td))
+	time.Sleep(20 * time.Millisecond)
+
+	// Shut down in the same order as the service: the processors stop their
+	// periodic flush before the exporters are shut down.
+	require.NoError(t, pipelineProcessors.ShutdownAll())
+	exporters.ShutdownAll()
+	exporter := exporters[cfg.Exporters["exampleexporter/2"]].me.(*config.ExampleExporterConsumer)
+	require.True(t, exporter.ExporterShutdown)
+	flushed := len(exporter.Metrics)
+	assert.NotZero(t, flushed)
+
+	require.NoError(t, traces.tc.ConsumeTraceData(context.Background(), td))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, flushed, len(exporter.Metrics))
+}
@@ -41,6 +41,7 @@ type Application struct {
 	v              *viper.Viper
 	logger         *zap.Logger
 	exporters      builder.Exporters
+	builtPipelines builder.PipelineProcessors
 	builtReceivers builder.Receivers
 
 	factories config.Factories
@@ -185,13 +186,13 @@ func (app *Application) setupPipelines() {
 
 	// Create pipelines and their processors and plug exporters to the
 	// end of the pipelines.
-	pipelines, err := builder.NewPipelinesBuilder(app.logger, app.config, app.exporters, app.factories.Processors).Build()
+	app.builtPipelines, err = builder.NewPipelinesBuilder(app.logger, app.config, app.exporters, app.factories.Processors).Build()
 	if err != nil {
 		log.Fatalf("Cannot load configuration: %v", err)
 	}
 
 	// Create receivers and plug them into the start of the pipelines.
-	app.builtReceivers, err = builder.NewReceiversBuilder(app.logger, app.config, pipelines, app.factories.Receivers).Build()
+	app.builtReceivers, err = builder.NewReceiversBuilder(app.logger, app.config, app.builtPipelines, app.factories.Receivers).Build()
 	if err != nil {
 		log.Fatalf("Cannot load configuration: %v", err)
 	}
@@ -241,7 +242,10 @@ func (app *Application) shutdownPipelines() {
 	app.logger.Info("Stopping receivers...")
 	app.builtReceivers.StopAll()
 
-	// TODO: shutdown processors
+	app.logger.Info("Shutting down processors...")
+	if err := app.builtPipelines.ShutdownAll(); err != nil {
+		app.logger.Warn("Error shutting down processors", zap.Error(err))
+	}
 
 	app.logger.Info("Shutting down exporters...")
 	app.exporters.ShutdownAll()
@@ -0,0 +1,268 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"sort"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlpmetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// ResourceMetricsToMetricsData converts OTLP resource metrics to OC metrics
+// data, one MetricsData per resource. Metrics with an invalid type are
+// dropped.
+func ResourceMetricsToMetricsData(resourceMetrics []*otlpmetrics.ResourceMetrics) []consumerdata.MetricsData {
+	mds := make([]consumerdata.MetricsData, 0, len(resourceMetrics))
+	for _, rm := range resourceMetrics {
+		if rm == nil {
+			continue
+		}
+
+		node, resource := resourceToOC(rm.Resource)
+		md := consumerdata.MetricsData{
+			Node:     node,
+			Resource: resource,
+		}
+		for _, ilm := range rm.InstrumentationLibraryMetrics {
+			if ilm == nil {
+				continue
+			}
+			for _, metric := range ilm.Metrics {
+				if ocMetric := metricToOC(metric); ocMetric != nil {
+					md.Metrics = append(md.Metrics, ocMetric)
+				}
+			}
+		}
+		mds = append(mds, md)
+	}
+	return mds
+}
+
+func metricToOC(metric *otlpmetrics.Metric) *metricspb.Metric {
+	if metric == nil || metric.MetricDescriptor == nil {
+		return nil
+	}
+	descriptor := metric.MetricDescriptor
+	ocType := metricTypeToOC(descriptor)
+	if ocType == metricspb.MetricDescriptor_UNSPECIFIED {
+		return nil
+	}
+
+	labelKeys := labelKeysOf(metric)
+	ocLabelKeys := make([]*metricspb.LabelKey, 0, len(labelKeys))
+	for _, key := range labelKeys {
+		ocLabelKeys = append(ocLabelKeys, &metricspb.LabelKey{Key: key})
+	}
+
+	// Gauges don't have a start time in OC.
+	isGauge := ocType == metricspb.MetricDescriptor_GAUGE_INT64 ||
+		ocType == metricspb.MetricDescriptor_GAUGE_DOUBLE ||
+		ocType == metricspb.MetricDescriptor_GAUGE_DISTRIBUTION
+	newTimeSeries := func(labels []*otlpcommon.StringKeyValue, startTime, time uint64, point *metricspb.Point) *metricspb.TimeSeries {
+		point.Timestamp = unixNanoToTimestamp(time)
+		ts := &metricspb.TimeSeries{
+			LabelValues: labelValuesToOC(labelKeys, labels),
+			Points:      []*metricspb.Point{point},
+		}
+		if !isGauge {
+			ts.StartTimestamp = unixNanoToTimestamp(startTime)
+		}
+		return ts
+	}
+
+	var timeseries []*metricspb.TimeSeries
+	switch ocType {
+	case metricspb.MetricDescriptor_GAUGE_INT64, metricspb.MetricDescriptor_CUMULATIVE_INT64:
+		for _, dp := range metric.Int64DataPoints {
+			if dp == nil {
+				continue
+			}
+			timeseries = append(timeseries, newTimeSeries(dp.Labels, dp.StartTimeUnixNano, dp.TimeUnixNano, &metricspb.Point{
+				Value: &metricspb.Point_Int64Value{Int64Value: dp.Value},
+			}))
+		}
+	case metricspb.MetricDescriptor_GAUGE_DOUBLE, metricspb.MetricDescriptor_CUMULATIVE_DOUBLE:
+		for _, dp := range metric.DoubleDataPoints {
+			if dp == nil {
+				continue
+			}
+			timeseries = append(timeseries, newTimeSeries(dp.Labels, dp.StartTimeUnixNano, dp.TimeUnixNano, &metricspb.Point{
+				Value: &metricspb.Point_DoubleValue{DoubleValue: dp.Value},
+			}))
+		}
+	case metricspb.MetricDescriptor_GAUGE_DISTRIBUTION, metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION:
+		for _, dp := range metric.HistogramDataPoints {
+			if dp == nil {
+				continue
+			}
+			timeseries = append(timeseries, newTimeSeries(dp.Labels, dp.StartTimeUnixNano, dp.TimeUnixNano, &metricspb.Point{
+				Value: &metricspb.Point_DistributionValue{DistributionValue: histogramToOC(dp)},
+			}))
+		}
+	case metricspb.MetricDescriptor_SUMMARY:
+		for _, dp := range metric.SummaryDataPoints {
+			if dp == nil {
+				continue
+			}
+			timeseries = append(timeseries, newTimeSeries(dp.Labels, dp.StartTimeUnixNano, dp.TimeUnixNano, &metricspb.Point{
+				Value: &metricspb.Point_SummaryValue{SummaryValue: summaryToOC(dp)},
+			}))
+		}
+	}
+
+	return &metricspb.Metric{
+		MetricDescriptor: &metricspb.MetricDescriptor{
+			Name:        descriptor.Name,
+			Description: descriptor.Description,
+			Unit:        descriptor.Unit,
+			Type:        ocType,
+			LabelKeys:   ocLabelKeys,
+		},
+		Timeseries: timeseries,
+	}
+}
+
+// metricTypeToOC maps the OTLP type to the OC one: counters are cumulative
+// and the other types keep their kind.
+func metricTypeToOC(descriptor *otlpmetrics.MetricDescriptor) metricspb.MetricDescriptor_Type {
+	switch descriptor.Type {
+	case otlpmetrics.MetricDescriptor_GAUGE_INT64:
+		return metricspb.MetricDescriptor_GAUGE_INT64
+	case otlpmetrics.MetricDescriptor_COUNTER_INT64:
+		return metricspb.MetricDescriptor_CUMULATIVE_INT64
+	case otlpmetrics.MetricDescriptor_GAUGE_DOUBLE:
+		return metricspb.MetricDescriptor_GAUGE_DOUBLE
+	case otlpmetrics.MetricDescriptor_COUNTER_DOUBLE:
+		return metricspb.MetricDescriptor_CUMULATIVE_DOUBLE
+	case otlpmetrics.MetricDescriptor_GAUGE_HISTOGRAM:
+		return metricspb.MetricDescriptor_GAUGE_DISTRIBUTION
+	case otlpmetrics.MetricDescriptor_CUMULATIVE_HISTOGRAM:
+		return metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION
+	case otlpmetrics.MetricDescriptor_SUMMARY:
+		return metricspb.MetricDescriptor_SUMMARY
+	}
+	return metricspb.MetricDescriptor_UNSPECIFIED
+}
+
+// labelKeysOf returns the sorted union of the label keys of all the data
+// points of the metric.
+func labelKeysOf(metric *otlpmetrics.Metric) []string {
+	keySet := make(map[string]struct{})
+	addKeys := func(labels []*otlpcommon.StringKeyValue) {
+		for _, label := range labels {
+			if label != nil {
+				keySet[label.Key] = struct{}{}
+			}
+		}
+	}
+	for _, dp := range metric.Int64DataPoints {
+		if dp != nil {
+			addKeys(dp.Labels)
+		}
+	}
+	for _, dp := range metric.DoubleDataPoints {
+		if dp != nil {
+			addKeys(dp.Labels)
+		}
+	}
+	for _, dp := range metric.HistogramDataPoints {
+		if dp != nil {
+			addKeys(dp.Labels)
+		}
+	}
+	for _, dp := range metric.SummaryDataPoints {
+		if dp != nil {
+			addKeys(dp.Labels)
+		}
+	}
+
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelValuesToOC returns the label values in the order of keys, the keys
+// missing from labels have no value.
+func labelValuesToOC(keys []string, labels []*otlpcommon.StringKeyValue) []*metricspb.LabelValue {
+	values := make(map[string]string, len(labels))
+	for _, label := range labels {
+		if label != nil {
+			values[label.Key] = label.Value
+		}
+	}
+
+	labelValues := make([]*metricspb.LabelValue, 0, len(keys))
+	for _, key := range keys {
+		value, ok := values[key]
+		labelValues = append(labelValues, &metricspb.LabelValue{Value: value, HasValue: ok})
+	}
+	return labelValues
+}
+
+func histogramToOC(dp *otlpmetrics.HistogramDataPoint) *metricspb.DistributionValue {
+	distribution := &metricspb.DistributionValue{
+		Count: int64(dp.Count),
+		Sum:   dp.Sum,
+	}
+	if len(dp.ExplicitBounds) > 0 {
+		distribution.BucketOptions = &metricspb.DistributionValue_BucketOptions{
+			Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+				Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{
+					Bounds: dp.ExplicitBounds,
+				},
+			},
+		}
+	}
+	if len(dp.Buckets) > 0 {
+		distribution.Buckets = make([]*metricspb.DistributionValue_Bucket, 0, len(dp.Buckets))
+		for _, bucket := range dp.Buckets {
+			var count int64
+			if bucket != nil {
+				count = int64(bucket.Count)
+			}
+			distribution.Buckets = append(distribution.Buckets, &metricspb.DistributionValue_Bucket{Count: count})
+		}
+	}
+	return distribution
+}
+
+func summaryToOC(dp *otlpmetrics.SummaryDataPoint) *metricspb.SummaryValue {
+	summary := &metricspb.SummaryValue{
+		Count: &wrappers.Int64Value{Value: int64(dp.Count)},
+		Sum:   &wrappers.DoubleValue{Value: dp.Sum},
+	}
+	if len(dp.PercentileValues) > 0 {
+		percentiles := make([]*metricspb.SummaryValue_Snapshot_ValueAtPercentile, 0, len(dp.PercentileValues))
+		for _, pv := range dp.PercentileValues {
+			if pv == nil {
+				continue
+			}
+			percentiles = append(percentiles, &metricspb.SummaryValue_Snapshot_ValueAtPercentile{
+				Percentile: pv.Percentile,
+				Value:      pv.Value,
+			})
+		}
+		summary.Snapshot = &metricspb.SummaryValue_Snapshot{PercentileValues: percentiles}
+	}
+	return summary
+}
@@ -0,0 +1,219 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlpmetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+func TestResourceMetricsToMetricsData(t *testing.T) {
+	start := time.Unix(1542158650, 0).UTC()
+	now := start.Add(time.Minute)
+	startNano, nowNano := uint64(start.UnixNano()), uint64(now.UnixNano())
+
+	rms := []*otlpmetrics.ResourceMetrics{
+		{
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+				{
+					Metrics: []*otlpmetrics.Metric{
+						{
+							MetricDescriptor: &otlpmetrics.MetricDescriptor{
+								Name: "requests",
+								Unit: "1",
+								Type: otlpmetrics.MetricDescriptor_COUNTER_INT64,
+							},
+							Int64DataPoints: []*otlpmetrics.Int64DataPoint{
+								{
+									Labels:            []*otlpcommon.StringKeyValue{{Key: "method", Value: "GET"}},
+									StartTimeUnixNano: startNano,
+									TimeUnixNano:      nowNano,
+									Value:             10,
+								},
+								{
+									Labels:            []*otlpcommon.StringKeyValue{{Key: "code", Value: "500"}},
+									StartTimeUnixNano: startNano,
+									TimeUnixNano:      nowNano,
+									Value:             1,
+								},
+							},
+						},
+						{
+							MetricDescriptor: &otlpmetrics.MetricDescriptor{
+								Name: "temperature",
+								Type: otlpmetrics.MetricDescriptor_GAUGE_DOUBLE,
+							},
+							DoubleDataPoints: []*otlpmetrics.DoubleDataPoint{
+								{StartTimeUnixNano: startNano, TimeUnixNano: nowNano, Value: 21.5},
+							},
+						},
+						{
+							MetricDescriptor: &otlpmetrics.MetricDescriptor{
+								Name: "latency",
+								Type: otlpmetrics.MetricDescriptor_CUMULATIVE_HISTOGRAM,
+							},
+							HistogramDataPoints: []*otlpmetrics.HistogramDataPoint{
+								{
+									StartTimeUnixNano: startNano,
+									TimeUnixNano:      nowNano,
+									Count:             3,
+									Sum:               35,
+									Buckets:           []*otlpmetrics.HistogramDataPoint_Bucket{{Count: 1}, {Count: 2}},
+									ExplicitBounds:    []float64{10},
+								},
+							},
+						},
+						{
+							MetricDescriptor: &otlpmetrics.MetricDescriptor{
+								Name: "response_size",
+								Type: otlpmetrics.MetricDescriptor_SUMMARY,
+							},
+							SummaryDataPoints: []*otlpmetrics.SummaryDataPoint{
+								{
+									StartTimeUnixNano: startNano,
+									TimeUnixNano:      nowNano,
+									Count:             4,
+									Sum:               400,
+									PercentileValues: []*otlpmetrics.SummaryDataPoint_ValueAtPercentile{
+										{Percentile: 99, Value: 190},
+									},
+								},
+							},
+						},
+						{
+							MetricDescriptor: &otlpmetrics.MetricDescriptor{Name: "invalid"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mds := ResourceMetricsToMetricsData(rms)
+	require.Len(t, mds, 1)
+	assert.Nil(t, mds[0].Node)
+	assert.Nil(t, mds[0].Resource)
+
+	want := []*metricspb.Metric{
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "requests",
+				Unit:      "1",
+				Type:      metricspb.MetricDescriptor_CUMULATIVE_INT64,
+				LabelKeys: []*metricspb.LabelKey{{Key: "code"}, {Key: "method"}},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					StartTimestamp: internal.TimeToTimestamp(start),
+					LabelValues:    []*metricspb.LabelValue{{}, {Value: "GET", HasValue: true}},
+					Points: []*metricspb.Point{
+						{Timestamp: internal.TimeToTimestamp(now), Value: &metricspb.Point_Int64Value{Int64Value: 10}},
+					},
+				},
+				{
+					StartTimestamp: internal.TimeToTimestamp(start),
+					LabelValues:    []*metricspb.LabelValue{{Value: "500", HasValue: true}, {}},
+					Points: []*metricspb.Point{
+						{Timestamp: internal.TimeToTimestamp(now), Value: &metricspb.Point_Int64Value{Int64Value: 1}},
+					},
+				},
+			},
+		},
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "temperature",
+				Type:      metricspb.MetricDescriptor_GAUGE_DOUBLE,
+				LabelKeys: []*metricspb.LabelKey{},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					LabelValues: []*metricspb.LabelValue{},
+					Points: []*metricspb.Point{
+						{Timestamp: internal.TimeToTimestamp(now), Value: &metricspb.Point_DoubleValue{DoubleValue: 21.5}},
+					},
+				},
+			},
+		},
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "latency",
+				Type:      metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION,
+				LabelKeys: []*metricspb.LabelKey{},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					StartTimestamp: internal.TimeToTimestamp(start),
+					LabelValues:    []*metricspb.LabelValue{},
+					Points: []*metricspb.Point{
+						{
+							Timestamp: internal.TimeToTimestamp(now),
+							Value: &metricspb.Point_DistributionValue{
+								DistributionValue: &metricspb.DistributionValue{
+									Count: 3,
+									Sum:   35,
+									BucketOptions: &metricspb.DistributionValue_BucketOptions{
+										Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+											Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{Bounds: []float64{10}},
+										},
+									},
+									Buckets: []*metricspb.DistributionValue_Bucket{{Count: 1}, {Count: 2}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			MetricDescriptor: &metricspb.MetricDescriptor{
+				Name:      "response_size",
+				Type:      metricspb.MetricDescriptor_SUMMARY,
+				LabelKeys: []*metricspb.LabelKey{},
+			},
+			Timeseries: []*metricspb.TimeSeries{
+				{
+					StartTimestamp: internal.TimeToTimestamp(start),
+					LabelValues:    []*metricspb.LabelValue{},
+					Points: []*metricspb.Point{
+						{
+							Timestamp: internal.TimeToTimestamp(now),
+							Value: &metricspb.Point_SummaryValue{
+								SummaryValue: &metricspb.SummaryValue{
+									Count: &wrappers.Int64Value{Value: 4},
+									Sum:   &wrappers.DoubleValue{Value: 400},
+									Snapshot: &metricspb.SummaryValue_Snapshot{
+										PercentileValues: []*metricspb.SummaryValue_Snapshot_ValueAtPercentile{
+											{Percentile: 99, Value: 190},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, want, mds[0].Metrics)
+}
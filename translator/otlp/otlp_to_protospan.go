@@ -0,0 +1,287 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"strings"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlptrace "github.com/open-telemetry/opentelemetry-proto/gen/go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+// Attributes used to carry the OTLP instrumentation library of a span or
+// metric in the OpenCensus data model.
+const (
+	AttributeInstrumentationLibraryName    = "otel.library.name"
+	AttributeInstrumentationLibraryVersion = "otel.library.version"
+)
+
+// OpenTracing span kind values used to keep the OTLP span kinds that don't
+// exist in OpenCensus.
+const (
+	spanKindInternal = "internal"
+	spanKindProducer = "producer"
+	spanKindConsumer = "consumer"
+)
+
+// ResourceSpansToTraceData converts OTLP resource spans to OC trace data,
+// one TraceData per resource. It also returns, by reason, the number of spans
+// that could not be converted.
+func ResourceSpansToTraceData(resourceSpans []*otlptrace.ResourceSpans) ([]consumerdata.TraceData, tracetranslator.DroppedSpans) {
+	var dropped tracetranslator.DroppedSpans
+	tds := make([]consumerdata.TraceData, 0, len(resourceSpans))
+	for _, rs := range resourceSpans {
+		if rs == nil {
+			continue
+		}
+
+		node, resource := resourceToOC(rs.Resource)
+		td := consumerdata.TraceData{
+			Node:         node,
+			Resource:     resource,
+			SourceFormat: "otlp",
+		}
+		for _, ils := range rs.InstrumentationLibrarySpans {
+			if ils == nil {
+				continue
+			}
+			for _, span := range ils.Spans {
+				ocSpan, reason := spanToOC(span, ils.InstrumentationLibrary)
+				if ocSpan == nil {
+					dropped = dropped.Add(reason, 1)
+					continue
+				}
+				td.Spans = append(td.Spans, ocSpan)
+			}
+		}
+		tds = append(tds, td)
+	}
+	return tds, dropped
+}
+
+// spanToOC converts a single OTLP span. If the span can't be converted it
+// returns nil and the reason for which it was dropped.
+func spanToOC(span *otlptrace.Span, library *otlpcommon.InstrumentationLibrary) (*tracepb.Span, string) {
+	if reason := validateSpanIDs(span); reason != "" {
+		return nil, reason
+	}
+
+	attributes := attributesToOC(span.Attributes, span.DroppedAttributesCount)
+	kind := tracepb.Span_SPAN_KIND_UNSPECIFIED
+	switch span.Kind {
+	case otlptrace.Span_SERVER:
+		kind = tracepb.Span_SERVER
+	case otlptrace.Span_CLIENT:
+		kind = tracepb.Span_CLIENT
+	case otlptrace.Span_INTERNAL:
+		attributes = putStringAttribute(attributes, tracetranslator.TagSpanKind, spanKindInternal)
+	case otlptrace.Span_PRODUCER:
+		attributes = putStringAttribute(attributes, tracetranslator.TagSpanKind, spanKindProducer)
+	case otlptrace.Span_CONSUMER:
+		attributes = putStringAttribute(attributes, tracetranslator.TagSpanKind, spanKindConsumer)
+	}
+	if library != nil {
+		if library.Name != "" {
+			attributes = putStringAttribute(attributes, AttributeInstrumentationLibraryName, library.Name)
+		}
+		if library.Version != "" {
+			attributes = putStringAttribute(attributes, AttributeInstrumentationLibraryVersion, library.Version)
+		}
+	}
+
+	var parentSpanID []byte
+	if len(span.ParentSpanId) > 0 {
+		parentSpanID = span.ParentSpanId
+	}
+
+	return &tracepb.Span{
+		TraceId:      span.TraceId,
+		SpanId:       span.SpanId,
+		Tracestate:   tracestateToOC(span.TraceState),
+		ParentSpanId: parentSpanID,
+		Name:         &tracepb.TruncatableString{Value: span.Name},
+		Kind:         kind,
+		StartTime:    unixNanoToTimestamp(span.StartTimeUnixNano),
+		EndTime:      unixNanoToTimestamp(span.EndTimeUnixNano),
+		Attributes:   attributes,
+		TimeEvents:   eventsToOC(span.Events, span.DroppedEventsCount),
+		Links:        linksToOC(span.Links, span.DroppedLinksCount),
+		Status:       statusToOC(span.Status),
+	}, ""
+}
+
+// validateSpanIDs returns the reason for which the span must be dropped
+// because of its IDs, or an empty string if the IDs are valid.
+func validateSpanIDs(span *otlptrace.Span) string {
+	switch {
+	case span == nil:
+		return tracetranslator.DropReasonDecodeError
+	case len(span.TraceId) != 16:
+		return tracetranslator.DropReasonInvalidTraceID
+	case isZero(span.TraceId):
+		return tracetranslator.DropReasonZeroTraceID
+	case len(span.SpanId) != 8 || isZero(span.SpanId):
+		return tracetranslator.DropReasonInvalidSpanID
+	case len(span.ParentSpanId) != 0 && len(span.ParentSpanId) != 8:
+		return tracetranslator.DropReasonInvalidSpanID
+	}
+	return ""
+}
+
+func isZero(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// tracestateToOC parses a W3C tracestate header value, malformed list
+// members are skipped.
+func tracestateToOC(tracestate string) *tracepb.Span_Tracestate {
+	if tracestate == "" {
+		return nil
+	}
+
+	var entries []*tracepb.Span_Tracestate_Entry
+	for _, member := range strings.Split(tracestate, ",") {
+		member = strings.TrimSpace(member)
+		eq := strings.IndexByte(member, '=')
+		if eq <= 0 {
+			continue
+		}
+		entries = append(entries, &tracepb.Span_Tracestate_Entry{
+			Key:   member[:eq],
+			Value: member[eq+1:],
+		})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return &tracepb.Span_Tracestate{Entries: entries}
+}
+
+func attributesToOC(attrs []*otlpcommon.AttributeKeyValue, droppedCount uint32) *tracepb.Span_Attributes {
+	if len(attrs) == 0 && droppedCount == 0 {
+		return nil
+	}
+
+	attributeMap := make(map[string]*tracepb.AttributeValue, len(attrs))
+	for _, attr := range attrs {
+		if attr == nil {
+			continue
+		}
+		value := &tracepb.AttributeValue{}
+		switch attr.Type {
+		case otlpcommon.AttributeKeyValue_INT:
+			value.Value = &tracepb.AttributeValue_IntValue{IntValue: attr.IntValue}
+		case otlpcommon.AttributeKeyValue_DOUBLE:
+			value.Value = &tracepb.AttributeValue_DoubleValue{DoubleValue: attr.DoubleValue}
+		case otlpcommon.AttributeKeyValue_BOOL:
+			value.Value = &tracepb.AttributeValue_BoolValue{BoolValue: attr.BoolValue}
+		default:
+			value.Value = &tracepb.AttributeValue_StringValue{
+				StringValue: &tracepb.TruncatableString{Value: attr.StringValue},
+			}
+		}
+		attributeMap[attr.Key] = value
+	}
+	return &tracepb.Span_Attributes{
+		AttributeMap:           attributeMap,
+		DroppedAttributesCount: int32(droppedCount),
+	}
+}
+
+func putStringAttribute(attributes *tracepb.Span_Attributes, key, value string) *tracepb.Span_Attributes {
+	if attributes == nil {
+		attributes = &tracepb.Span_Attributes{}
+	}
+	if attributes.AttributeMap == nil {
+		attributes.AttributeMap = make(map[string]*tracepb.AttributeValue)
+	}
+	attributes.AttributeMap[key] = &tracepb.AttributeValue{
+		Value: &tracepb.AttributeValue_StringValue{
+			StringValue: &tracepb.TruncatableString{Value: value},
+		},
+	}
+	return attributes
+}
+
+func eventsToOC(events []*otlptrace.Span_Event, droppedCount uint32) *tracepb.Span_TimeEvents {
+	if len(events) == 0 && droppedCount == 0 {
+		return nil
+	}
+
+	timeEvents := make([]*tracepb.Span_TimeEvent, 0, len(events))
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		timeEvents = append(timeEvents, &tracepb.Span_TimeEvent{
+			Time: unixNanoToTimestamp(event.TimeUnixNano),
+			Value: &tracepb.Span_TimeEvent_Annotation_{
+				Annotation: &tracepb.Span_TimeEvent_Annotation{
+					Description: &tracepb.TruncatableString{Value: event.Name},
+					Attributes:  attributesToOC(event.Attributes, event.DroppedAttributesCount),
+				},
+			},
+		})
+	}
+	return &tracepb.Span_TimeEvents{
+		TimeEvent:               timeEvents,
+		DroppedAnnotationsCount: int32(droppedCount),
+	}
+}
+
+func linksToOC(links []*otlptrace.Span_Link, droppedCount uint32) *tracepb.Span_Links {
+	if len(links) == 0 && droppedCount == 0 {
+		return nil
+	}
+
+	ocLinks := make([]*tracepb.Span_Link, 0, len(links))
+	for _, link := range links {
+		if link == nil {
+			continue
+		}
+		ocLinks = append(ocLinks, &tracepb.Span_Link{
+			TraceId:    link.TraceId,
+			SpanId:     link.SpanId,
+			Type:       tracepb.Span_Link_TYPE_UNSPECIFIED,
+			Attributes: attributesToOC(link.Attributes, link.DroppedAttributesCount),
+			Tracestate: tracestateToOC(link.TraceState),
+		})
+	}
+	return &tracepb.Span_Links{
+		Link:              ocLinks,
+		DroppedLinksCount: int32(droppedCount),
+	}
+}
+
+// statusToOC converts the OTLP status, its codes have the same values as
+// the gRPC codes used by OpenCensus.
+func statusToOC(status *otlptrace.Status) *tracepb.Status {
+	if status == nil {
+		return nil
+	}
+	return &tracepb.Status{
+		Code:    int32(status.Code),
+		Message: status.Message,
+	}
+}
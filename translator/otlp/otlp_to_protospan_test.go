@@ -0,0 +1,201 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlpresource "github.com/open-telemetry/opentelemetry-proto/gen/go/resource/v1"
+	otlptrace "github.com/open-telemetry/opentelemetry-proto/gen/go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+var (
+	traceID      = []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10}
+	spanID       = []byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}
+	parentSpanID = []byte{0x21, 0x22, 0x23, 0x24, 0x25, 0x26, 0x27, 0x28}
+)
+
+func TestResourceToOC(t *testing.T) {
+	node, resource := resourceToOC(&otlpresource.Resource{
+		Attributes: []*otlpcommon.AttributeKeyValue{
+			{Key: conventionServiceName, StringValue: "api"},
+			{Key: conventionHostName, StringValue: "host-1"},
+			{Key: conventionProcessID, Type: otlpcommon.AttributeKeyValue_INT, IntValue: 42},
+			{Key: conventionSDKLanguage, StringValue: "go"},
+			{Key: conventionSDKVersion, StringValue: "0.2.0"},
+			{Key: "k8s.pod.name", StringValue: "api-0"},
+			{Key: "replicas", Type: otlpcommon.AttributeKeyValue_INT, IntValue: 3},
+		},
+	})
+
+	wantNode := &commonpb.Node{
+		Identifier:  &commonpb.ProcessIdentifier{HostName: "host-1", Pid: 42},
+		LibraryInfo: &commonpb.LibraryInfo{Language: commonpb.LibraryInfo_GO_LANG, CoreLibraryVersion: "0.2.0"},
+		ServiceInfo: &commonpb.ServiceInfo{Name: "api"},
+	}
+	wantResource := &resourcepb.Resource{
+		Labels: map[string]string{"k8s.pod.name": "api-0", "replicas": "3"},
+	}
+	assert.Equal(t, wantNode, node)
+	assert.Equal(t, wantResource, resource)
+
+	node, resource = resourceToOC(nil)
+	assert.Nil(t, node)
+	assert.Nil(t, resource)
+}
+
+func TestResourceSpansToTraceData(t *testing.T) {
+	start := time.Unix(1542158650, 536343000).UTC()
+	end := start.Add(10 * time.Millisecond)
+
+	rss := []*otlptrace.ResourceSpans{
+		{
+			Resource: &otlpresource.Resource{
+				Attributes: []*otlpcommon.AttributeKeyValue{
+					{Key: conventionServiceName, StringValue: "api"},
+				},
+			},
+			InstrumentationLibrarySpans: []*otlptrace.InstrumentationLibrarySpans{
+				{
+					InstrumentationLibrary: &otlpcommon.InstrumentationLibrary{Name: "http", Version: "1.0"},
+					Spans: []*otlptrace.Span{
+						{
+							TraceId:           traceID,
+							SpanId:            spanID,
+							ParentSpanId:      parentSpanID,
+							TraceState:        "a=1, b=2,invalid",
+							Name:              "GET /users",
+							Kind:              otlptrace.Span_SERVER,
+							StartTimeUnixNano: uint64(start.UnixNano()),
+							EndTimeUnixNano:   uint64(end.UnixNano()),
+							Attributes: []*otlpcommon.AttributeKeyValue{
+								{Key: "http.status_code", Type: otlpcommon.AttributeKeyValue_INT, IntValue: 404},
+								{Key: "error", Type: otlpcommon.AttributeKeyValue_BOOL, BoolValue: true},
+							},
+							Events: []*otlptrace.Span_Event{
+								{TimeUnixNano: uint64(start.UnixNano()), Name: "cache miss"},
+							},
+							Links: []*otlptrace.Span_Link{
+								{TraceId: traceID, SpanId: parentSpanID},
+							},
+							Status: &otlptrace.Status{Code: otlptrace.Status_NotFound, Message: "no such user"},
+						},
+						{
+							TraceId: traceID,
+							SpanId:  parentSpanID,
+							Name:    "produce",
+							Kind:    otlptrace.Span_PRODUCER,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tds, dropped := ResourceSpansToTraceData(rss)
+	assert.Nil(t, dropped)
+	require.Len(t, tds, 1)
+	td := tds[0]
+	assert.Equal(t, &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "api"}}, td.Node)
+	assert.Nil(t, td.Resource)
+	assert.Equal(t, "otlp", td.SourceFormat)
+	require.Len(t, td.Spans, 2)
+
+	want := &tracepb.Span{
+		TraceId:      traceID,
+		SpanId:       spanID,
+		ParentSpanId: parentSpanID,
+		Tracestate: &tracepb.Span_Tracestate{
+			Entries: []*tracepb.Span_Tracestate_Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+		},
+		Name:      &tracepb.TruncatableString{Value: "GET /users"},
+		Kind:      tracepb.Span_SERVER,
+		StartTime: internal.TimeToTimestamp(start),
+		EndTime:   internal.TimeToTimestamp(end),
+		Attributes: &tracepb.Span_Attributes{
+			AttributeMap: map[string]*tracepb.AttributeValue{
+				"http.status_code": {Value: &tracepb.AttributeValue_IntValue{IntValue: 404}},
+				"error":            {Value: &tracepb.AttributeValue_BoolValue{BoolValue: true}},
+				AttributeInstrumentationLibraryName: {Value: &tracepb.AttributeValue_StringValue{
+					StringValue: &tracepb.TruncatableString{Value: "http"},
+				}},
+				AttributeInstrumentationLibraryVersion: {Value: &tracepb.AttributeValue_StringValue{
+					StringValue: &tracepb.TruncatableString{Value: "1.0"},
+				}},
+			},
+		},
+		TimeEvents: &tracepb.Span_TimeEvents{
+			TimeEvent: []*tracepb.Span_TimeEvent{
+				{
+					Time: internal.TimeToTimestamp(start),
+					Value: &tracepb.Span_TimeEvent_Annotation_{
+						Annotation: &tracepb.Span_TimeEvent_Annotation{
+							Description: &tracepb.TruncatableString{Value: "cache miss"},
+						},
+					},
+				},
+			},
+		},
+		Links: &tracepb.Span_Links{
+			Link: []*tracepb.Span_Link{{TraceId: traceID, SpanId: parentSpanID}},
+		},
+		Status: &tracepb.Status{Code: 5, Message: "no such user"},
+	}
+	assert.Equal(t, want, td.Spans[0])
+
+	producer := td.Spans[1]
+	assert.Equal(t, tracepb.Span_SPAN_KIND_UNSPECIFIED, producer.Kind)
+	assert.Nil(t, producer.ParentSpanId)
+	assert.Equal(t, spanKindProducer, producer.Attributes.AttributeMap[tracetranslator.TagSpanKind].GetStringValue().GetValue())
+}
+
+func TestResourceSpansToTraceData_DroppedSpans(t *testing.T) {
+	rss := []*otlptrace.ResourceSpans{
+		{
+			InstrumentationLibrarySpans: []*otlptrace.InstrumentationLibrarySpans{
+				{
+					Spans: []*otlptrace.Span{
+						nil,
+						{TraceId: make([]byte, 16), SpanId: spanID},
+						{TraceId: traceID[:8], SpanId: spanID},
+						{TraceId: traceID, SpanId: make([]byte, 8)},
+						{TraceId: traceID, SpanId: spanID, ParentSpanId: []byte{0x01}},
+						{TraceId: traceID, SpanId: spanID},
+					},
+				},
+			},
+		},
+	}
+
+	tds, dropped := ResourceSpansToTraceData(rss)
+	require.Len(t, tds, 1)
+	assert.Len(t, tds[0].Spans, 1)
+	assert.Equal(t, tracetranslator.DroppedSpans{
+		tracetranslator.DropReasonDecodeError:    1,
+		tracetranslator.DropReasonZeroTraceID:    1,
+		tracetranslator.DropReasonInvalidTraceID: 1,
+		tracetranslator.DropReasonInvalidSpanID:  2,
+	}, dropped)
+}
@@ -0,0 +1,187 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlpmetrics "github.com/open-telemetry/opentelemetry-proto/gen/go/metrics/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+)
+
+// MetricsDataToResourceMetrics converts OC metrics data to OTLP resource
+// metrics. Each point of a time series becomes a data point, metrics with an
+// unspecified type are dropped.
+func MetricsDataToResourceMetrics(md consumerdata.MetricsData) []*otlpmetrics.ResourceMetrics {
+	if len(md.Metrics) == 0 {
+		return nil
+	}
+
+	metrics := make([]*otlpmetrics.Metric, 0, len(md.Metrics))
+	for _, ocMetric := range md.Metrics {
+		if metric := metricFromOC(ocMetric); metric != nil {
+			metrics = append(metrics, metric)
+		}
+	}
+
+	return []*otlpmetrics.ResourceMetrics{
+		{
+			Resource: resourceFromOC(md.Node, md.Resource),
+			InstrumentationLibraryMetrics: []*otlpmetrics.InstrumentationLibraryMetrics{
+				{Metrics: metrics},
+			},
+		},
+	}
+}
+
+func metricFromOC(ocMetric *metricspb.Metric) *otlpmetrics.Metric {
+	if ocMetric == nil || ocMetric.MetricDescriptor == nil {
+		return nil
+	}
+	ocDescriptor := ocMetric.MetricDescriptor
+	metricType := metricTypeFromOC(ocDescriptor.Type)
+	if metricType == otlpmetrics.MetricDescriptor_UNSPECIFIED {
+		return nil
+	}
+
+	metric := &otlpmetrics.Metric{
+		MetricDescriptor: &otlpmetrics.MetricDescriptor{
+			Name:        ocDescriptor.Name,
+			Description: ocDescriptor.Description,
+			Unit:        ocDescriptor.Unit,
+			Type:        metricType,
+		},
+	}
+
+	// A resource set on the metric overrides the one of the batch, there is
+	// no such concept in OTLP so its labels are kept with the data points.
+	var resourceLabels []*otlpcommon.StringKeyValue
+	if ocMetric.Resource != nil {
+		for _, attr := range appendSortedLabels(nil, ocMetric.Resource.Labels) {
+			resourceLabels = append(resourceLabels, &otlpcommon.StringKeyValue{Key: attr.Key, Value: attr.StringValue})
+		}
+	}
+
+	for _, ts := range ocMetric.Timeseries {
+		if ts == nil {
+			continue
+		}
+		labels := append(labelsFromOC(ocDescriptor.LabelKeys, ts.LabelValues), resourceLabels...)
+		startTime := timestampToUnixNano(ts.StartTimestamp)
+		for _, point := range ts.Points {
+			if point == nil {
+				continue
+			}
+			time := timestampToUnixNano(point.Timestamp)
+			switch value := point.Value.(type) {
+			case *metricspb.Point_Int64Value:
+				metric.Int64DataPoints = append(metric.Int64DataPoints, &otlpmetrics.Int64DataPoint{
+					Labels:            labels,
+					StartTimeUnixNano: startTime,
+					TimeUnixNano:      time,
+					Value:             value.Int64Value,
+				})
+			case *metricspb.Point_DoubleValue:
+				metric.DoubleDataPoints = append(metric.DoubleDataPoints, &otlpmetrics.DoubleDataPoint{
+					Labels:            labels,
+					StartTimeUnixNano: startTime,
+					TimeUnixNano:      time,
+					Value:             value.DoubleValue,
+				})
+			case *metricspb.Point_DistributionValue:
+				dp := histogramFromOC(value.DistributionValue)
+				dp.Labels, dp.StartTimeUnixNano, dp.TimeUnixNano = labels, startTime, time
+				metric.HistogramDataPoints = append(metric.HistogramDataPoints, dp)
+			case *metricspb.Point_SummaryValue:
+				dp := summaryFromOC(value.SummaryValue)
+				dp.Labels, dp.StartTimeUnixNano, dp.TimeUnixNano = labels, startTime, time
+				metric.SummaryDataPoints = append(metric.SummaryDataPoints, dp)
+			}
+		}
+	}
+	return metric
+}
+
+// metricTypeFromOC is the inverse of metricTypeToOC.
+func metricTypeFromOC(ocType metricspb.MetricDescriptor_Type) otlpmetrics.MetricDescriptor_Type {
+	switch ocType {
+	case metricspb.MetricDescriptor_GAUGE_INT64:
+		return otlpmetrics.MetricDescriptor_GAUGE_INT64
+	case metricspb.MetricDescriptor_CUMULATIVE_INT64:
+		return otlpmetrics.MetricDescriptor_COUNTER_INT64
+	case metricspb.MetricDescriptor_GAUGE_DOUBLE:
+		return otlpmetrics.MetricDescriptor_GAUGE_DOUBLE
+	case metricspb.MetricDescriptor_CUMULATIVE_DOUBLE:
+		return otlpmetrics.MetricDescriptor_COUNTER_DOUBLE
+	case metricspb.MetricDescriptor_GAUGE_DISTRIBUTION:
+		return otlpmetrics.MetricDescriptor_GAUGE_HISTOGRAM
+	case metricspb.MetricDescriptor_CUMULATIVE_DISTRIBUTION:
+		return otlpmetrics.MetricDescriptor_CUMULATIVE_HISTOGRAM
+	case metricspb.MetricDescriptor_SUMMARY:
+		return otlpmetrics.MetricDescriptor_SUMMARY
+	}
+	return otlpmetrics.MetricDescriptor_UNSPECIFIED
+}
+
+// labelsFromOC returns the labels that have a value, in the order of keys.
+func labelsFromOC(keys []*metricspb.LabelKey, values []*metricspb.LabelValue) []*otlpcommon.StringKeyValue {
+	var labels []*otlpcommon.StringKeyValue
+	for i, key := range keys {
+		if i >= len(values) {
+			break
+		}
+		if key == nil || values[i] == nil || !values[i].HasValue {
+			continue
+		}
+		labels = append(labels, &otlpcommon.StringKeyValue{Key: key.Key, Value: values[i].Value})
+	}
+	return labels
+}
+
+func histogramFromOC(distribution *metricspb.DistributionValue) *otlpmetrics.HistogramDataPoint {
+	dp := &otlpmetrics.HistogramDataPoint{
+		Count:          uint64(distribution.GetCount()),
+		Sum:            distribution.GetSum(),
+		ExplicitBounds: distribution.GetBucketOptions().GetExplicit().GetBounds(),
+	}
+	if buckets := distribution.GetBuckets(); len(buckets) > 0 {
+		dp.Buckets = make([]*otlpmetrics.HistogramDataPoint_Bucket, 0, len(buckets))
+		for _, bucket := range buckets {
+			dp.Buckets = append(dp.Buckets, &otlpmetrics.HistogramDataPoint_Bucket{Count: uint64(bucket.GetCount())})
+		}
+	}
+	return dp
+}
+
+func summaryFromOC(summary *metricspb.SummaryValue) *otlpmetrics.SummaryDataPoint {
+	dp := &otlpmetrics.SummaryDataPoint{
+		Count: uint64(summary.GetCount().GetValue()),
+		Sum:   summary.GetSum().GetValue(),
+	}
+	if percentiles := summary.GetSnapshot().GetPercentileValues(); len(percentiles) > 0 {
+		dp.PercentileValues = make([]*otlpmetrics.SummaryDataPoint_ValueAtPercentile, 0, len(percentiles))
+		for _, pv := range percentiles {
+			if pv == nil {
+				continue
+			}
+			dp.PercentileValues = append(dp.PercentileValues, &otlpmetrics.SummaryDataPoint_ValueAtPercentile{
+				Percentile: pv.Percentile,
+				Value:      pv.Value,
+			})
+		}
+	}
+	return dp
+}
@@ -0,0 +1,164 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	metricspb "github.com/census-instrumentation/opencensus-proto/gen-go/metrics/v1"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+func TestMetricsDataRoundTrip(t *testing.T) {
+	start := internal.TimeToTimestamp(time.Unix(1542158650, 0).UTC())
+	now := internal.TimeToTimestamp(time.Unix(1542158710, 0).UTC())
+
+	want := consumerdata.MetricsData{
+		Node: &commonpb.Node{ServiceInfo: &commonpb.ServiceInfo{Name: "api"}},
+		Metrics: []*metricspb.Metric{
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "requests",
+					Type:      metricspb.MetricDescriptor_CUMULATIVE_INT64,
+					LabelKeys: []*metricspb.LabelKey{{Key: "code"}, {Key: "method"}},
+				},
+				Timeseries: []*metricspb.TimeSeries{
+					{
+						StartTimestamp: start,
+						LabelValues:    []*metricspb.LabelValue{{Value: "200", HasValue: true}, {Value: "GET", HasValue: true}},
+						Points:         []*metricspb.Point{{Timestamp: now, Value: &metricspb.Point_Int64Value{Int64Value: 3}}},
+					},
+				},
+			},
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "queue_size",
+					Type:      metricspb.MetricDescriptor_GAUGE_DOUBLE,
+					LabelKeys: []*metricspb.LabelKey{},
+				},
+				Timeseries: []*metricspb.TimeSeries{
+					{
+						LabelValues: []*metricspb.LabelValue{},
+						Points:      []*metricspb.Point{{Timestamp: now, Value: &metricspb.Point_DoubleValue{DoubleValue: 4.5}}},
+					},
+				},
+			},
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "latency",
+					Type:      metricspb.MetricDescriptor_GAUGE_DISTRIBUTION,
+					LabelKeys: []*metricspb.LabelKey{},
+				},
+				Timeseries: []*metricspb.TimeSeries{
+					{
+						LabelValues: []*metricspb.LabelValue{},
+						Points: []*metricspb.Point{
+							{
+								Timestamp: now,
+								Value: &metricspb.Point_DistributionValue{
+									DistributionValue: &metricspb.DistributionValue{
+										Count: 2,
+										Sum:   15,
+										BucketOptions: &metricspb.DistributionValue_BucketOptions{
+											Type: &metricspb.DistributionValue_BucketOptions_Explicit_{
+												Explicit: &metricspb.DistributionValue_BucketOptions_Explicit{Bounds: []float64{10}},
+											},
+										},
+										Buckets: []*metricspb.DistributionValue_Bucket{{Count: 1}, {Count: 1}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "response_size",
+					Type:      metricspb.MetricDescriptor_SUMMARY,
+					LabelKeys: []*metricspb.LabelKey{},
+				},
+				Timeseries: []*metricspb.TimeSeries{
+					{
+						StartTimestamp: start,
+						LabelValues:    []*metricspb.LabelValue{},
+						Points: []*metricspb.Point{
+							{
+								Timestamp: now,
+								Value: &metricspb.Point_SummaryValue{
+									SummaryValue: &metricspb.SummaryValue{
+										Count: &wrappers.Int64Value{Value: 2},
+										Sum:   &wrappers.DoubleValue{Value: 30},
+										Snapshot: &metricspb.SummaryValue_Snapshot{
+											PercentileValues: []*metricspb.SummaryValue_Snapshot_ValueAtPercentile{
+												{Percentile: 50, Value: 12},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	mds := ResourceMetricsToMetricsData(MetricsDataToResourceMetrics(want))
+	require.Len(t, mds, 1)
+	assert.Equal(t, want.Node, mds[0].Node)
+	assert.Equal(t, want.Metrics, mds[0].Metrics)
+}
+
+func TestMetricsDataToResourceMetrics_MissingLabelValues(t *testing.T) {
+	md := consumerdata.MetricsData{
+		Metrics: []*metricspb.Metric{
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{
+					Name:      "requests",
+					Type:      metricspb.MetricDescriptor_GAUGE_INT64,
+					LabelKeys: []*metricspb.LabelKey{{Key: "code"}, {Key: "method"}},
+				},
+				Timeseries: []*metricspb.TimeSeries{
+					{
+						LabelValues: []*metricspb.LabelValue{{}, {Value: "GET", HasValue: true}},
+						Points:      []*metricspb.Point{{Value: &metricspb.Point_Int64Value{Int64Value: 1}}},
+					},
+				},
+			},
+			{
+				MetricDescriptor: &metricspb.MetricDescriptor{Name: "unspecified"},
+			},
+		},
+	}
+
+	rms := MetricsDataToResourceMetrics(md)
+	require.Len(t, rms, 1)
+	assert.Nil(t, rms[0].Resource)
+	metrics := rms[0].InstrumentationLibraryMetrics[0].Metrics
+	require.Len(t, metrics, 1)
+	require.Len(t, metrics[0].Int64DataPoints, 1)
+	labels := metrics[0].Int64DataPoints[0].Labels
+	require.Len(t, labels, 1)
+	assert.Equal(t, "method", labels[0].Key)
+	assert.Equal(t, "GET", labels[0].Value)
+}
@@ -0,0 +1,266 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"sort"
+	"strings"
+
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlptrace "github.com/open-telemetry/opentelemetry-proto/gen/go/trace/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+// TraceDataToResourceSpans converts OC trace data to OTLP resource spans.
+// The spans are grouped by the instrumentation library recorded in their
+// attributes, see ResourceSpansToTraceData, in order of first appearance.
+func TraceDataToResourceSpans(td consumerdata.TraceData) []*otlptrace.ResourceSpans {
+	if len(td.Spans) == 0 {
+		return nil
+	}
+
+	// The generated InstrumentationLibrary is not comparable, its name and
+	// version are used as key instead.
+	type libraryKey struct{ name, version string }
+	var libraries []*otlptrace.InstrumentationLibrarySpans
+	byLibrary := make(map[libraryKey]*otlptrace.InstrumentationLibrarySpans)
+	for _, ocSpan := range td.Spans {
+		if ocSpan == nil {
+			continue
+		}
+		span, library := spanFromOC(ocSpan)
+		key := libraryKey{name: library.Name, version: library.Version}
+		ils, ok := byLibrary[key]
+		if !ok {
+			ils = &otlptrace.InstrumentationLibrarySpans{}
+			if library.Name != "" || library.Version != "" {
+				ils.InstrumentationLibrary = &otlpcommon.InstrumentationLibrary{Name: library.Name, Version: library.Version}
+			}
+			byLibrary[key] = ils
+			libraries = append(libraries, ils)
+		}
+		ils.Spans = append(ils.Spans, span)
+	}
+
+	return []*otlptrace.ResourceSpans{
+		{
+			Resource:                    resourceFromOC(td.Node, td.Resource),
+			InstrumentationLibrarySpans: libraries,
+		},
+	}
+}
+
+// spanFromOC converts a single OC span and returns the instrumentation
+// library found in its attributes.
+func spanFromOC(ocSpan *tracepb.Span) (*otlptrace.Span, otlpcommon.InstrumentationLibrary) {
+	var library otlpcommon.InstrumentationLibrary
+	kind := otlptrace.Span_SPAN_KIND_UNSPECIFIED
+	switch ocSpan.Kind {
+	case tracepb.Span_SERVER:
+		kind = otlptrace.Span_SERVER
+	case tracepb.Span_CLIENT:
+		kind = otlptrace.Span_CLIENT
+	}
+
+	// The attributes that carry data of the OTLP span itself are not
+	// copied, the OC span is left untouched.
+	var attributes []*otlpcommon.AttributeKeyValue
+	var droppedAttributesCount uint32
+	if ocSpan.Attributes != nil {
+		droppedAttributesCount = uint32(ocSpan.Attributes.DroppedAttributesCount)
+		for _, key := range sortedAttributeKeys(ocSpan.Attributes.AttributeMap) {
+			value := ocSpan.Attributes.AttributeMap[key]
+			switch key {
+			case AttributeInstrumentationLibraryName:
+				library.Name = value.GetStringValue().GetValue()
+				continue
+			case AttributeInstrumentationLibraryVersion:
+				library.Version = value.GetStringValue().GetValue()
+				continue
+			case tracetranslator.TagSpanKind:
+				if kind == otlptrace.Span_SPAN_KIND_UNSPECIFIED {
+					if k, ok := spanKindFromAttribute(value); ok {
+						kind = k
+						continue
+					}
+				}
+			}
+			attributes = append(attributes, attributeFromOC(key, value))
+		}
+	}
+
+	span := &otlptrace.Span{
+		TraceId:                ocSpan.TraceId,
+		SpanId:                 ocSpan.SpanId,
+		TraceState:             tracestateFromOC(ocSpan.Tracestate),
+		ParentSpanId:           ocSpan.ParentSpanId,
+		Name:                   ocSpan.Name.GetValue(),
+		Kind:                   kind,
+		StartTimeUnixNano:      timestampToUnixNano(ocSpan.StartTime),
+		EndTimeUnixNano:        timestampToUnixNano(ocSpan.EndTime),
+		Attributes:             attributes,
+		DroppedAttributesCount: droppedAttributesCount,
+		Status:                 statusFromOC(ocSpan.Status),
+	}
+	if ocSpan.TimeEvents != nil {
+		span.Events = eventsFromOC(ocSpan.TimeEvents.TimeEvent)
+		span.DroppedEventsCount = uint32(ocSpan.TimeEvents.DroppedAnnotationsCount + ocSpan.TimeEvents.DroppedMessageEventsCount)
+	}
+	if ocSpan.Links != nil {
+		span.Links = linksFromOC(ocSpan.Links.Link)
+		span.DroppedLinksCount = uint32(ocSpan.Links.DroppedLinksCount)
+	}
+	return span, library
+}
+
+func spanKindFromAttribute(value *tracepb.AttributeValue) (otlptrace.Span_SpanKind, bool) {
+	switch value.GetStringValue().GetValue() {
+	case spanKindInternal:
+		return otlptrace.Span_INTERNAL, true
+	case spanKindProducer:
+		return otlptrace.Span_PRODUCER, true
+	case spanKindConsumer:
+		return otlptrace.Span_CONSUMER, true
+	}
+	return otlptrace.Span_SPAN_KIND_UNSPECIFIED, false
+}
+
+func tracestateFromOC(tracestate *tracepb.Span_Tracestate) string {
+	if tracestate == nil || len(tracestate.Entries) == 0 {
+		return ""
+	}
+	members := make([]string, 0, len(tracestate.Entries))
+	for _, entry := range tracestate.Entries {
+		if entry != nil {
+			members = append(members, entry.Key+"="+entry.Value)
+		}
+	}
+	return strings.Join(members, ",")
+}
+
+func attributesFromOC(attributes *tracepb.Span_Attributes) ([]*otlpcommon.AttributeKeyValue, uint32) {
+	if attributes == nil {
+		return nil, 0
+	}
+	var attrs []*otlpcommon.AttributeKeyValue
+	for _, key := range sortedAttributeKeys(attributes.AttributeMap) {
+		attrs = append(attrs, attributeFromOC(key, attributes.AttributeMap[key]))
+	}
+	return attrs, uint32(attributes.DroppedAttributesCount)
+}
+
+func attributeFromOC(key string, value *tracepb.AttributeValue) *otlpcommon.AttributeKeyValue {
+	attr := &otlpcommon.AttributeKeyValue{Key: key}
+	switch v := value.GetValue().(type) {
+	case *tracepb.AttributeValue_IntValue:
+		attr.Type = otlpcommon.AttributeKeyValue_INT
+		attr.IntValue = v.IntValue
+	case *tracepb.AttributeValue_DoubleValue:
+		attr.Type = otlpcommon.AttributeKeyValue_DOUBLE
+		attr.DoubleValue = v.DoubleValue
+	case *tracepb.AttributeValue_BoolValue:
+		attr.Type = otlpcommon.AttributeKeyValue_BOOL
+		attr.BoolValue = v.BoolValue
+	case *tracepb.AttributeValue_StringValue:
+		attr.Type = otlpcommon.AttributeKeyValue_STRING
+		attr.StringValue = v.StringValue.GetValue()
+	}
+	return attr
+}
+
+func sortedAttributeKeys(attributeMap map[string]*tracepb.AttributeValue) []string {
+	keys := make([]string, 0, len(attributeMap))
+	for key := range attributeMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// eventsFromOC converts the annotations of the span to events, message
+// events are converted to events named after their type.
+func eventsFromOC(timeEvents []*tracepb.Span_TimeEvent) []*otlptrace.Span_Event {
+	if len(timeEvents) == 0 {
+		return nil
+	}
+
+	events := make([]*otlptrace.Span_Event, 0, len(timeEvents))
+	for _, timeEvent := range timeEvents {
+		if timeEvent == nil {
+			continue
+		}
+		event := &otlptrace.Span_Event{TimeUnixNano: timestampToUnixNano(timeEvent.Time)}
+		switch value := timeEvent.Value.(type) {
+		case *tracepb.Span_TimeEvent_Annotation_:
+			event.Name = value.Annotation.GetDescription().GetValue()
+			event.Attributes, event.DroppedAttributesCount = attributesFromOC(value.Annotation.GetAttributes())
+		case *tracepb.Span_TimeEvent_MessageEvent_:
+			event.Name = value.MessageEvent.GetType().String()
+			event.Attributes = []*otlpcommon.AttributeKeyValue{
+				{
+					Key:      tracetranslator.MessageEventIDKey,
+					Type:     otlpcommon.AttributeKeyValue_INT,
+					IntValue: int64(value.MessageEvent.GetId()),
+				},
+				{
+					Key:      tracetranslator.MessageEventUncompressedSizeKey,
+					Type:     otlpcommon.AttributeKeyValue_INT,
+					IntValue: int64(value.MessageEvent.GetUncompressedSize()),
+				},
+				{
+					Key:      tracetranslator.MessageEventCompressedSizeKey,
+					Type:     otlpcommon.AttributeKeyValue_INT,
+					IntValue: int64(value.MessageEvent.GetCompressedSize()),
+				},
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func linksFromOC(ocLinks []*tracepb.Span_Link) []*otlptrace.Span_Link {
+	if len(ocLinks) == 0 {
+		return nil
+	}
+
+	links := make([]*otlptrace.Span_Link, 0, len(ocLinks))
+	for _, ocLink := range ocLinks {
+		if ocLink == nil {
+			continue
+		}
+		link := &otlptrace.Span_Link{
+			TraceId:    ocLink.TraceId,
+			SpanId:     ocLink.SpanId,
+			TraceState: tracestateFromOC(ocLink.Tracestate),
+		}
+		link.Attributes, link.DroppedAttributesCount = attributesFromOC(ocLink.Attributes)
+		links = append(links, link)
+	}
+	return links
+}
+
+func statusFromOC(status *tracepb.Status) *otlptrace.Status {
+	if status == nil {
+		return nil
+	}
+	return &otlptrace.Status{
+		Code:    otlptrace.Status_StatusCode(status.Code),
+		Message: status.Message,
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlptrace "github.com/open-telemetry/opentelemetry-proto/gen/go/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
+	"github.com/open-telemetry/opentelemetry-service/internal"
+	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
+)
+
+func TestTraceDataToResourceSpans(t *testing.T) {
+	td := consumerdata.TraceData{
+		Node: &commonpb.Node{
+			ServiceInfo: &commonpb.ServiceInfo{Name: "api"},
+			LibraryInfo: &commonpb.LibraryInfo{Language: commonpb.LibraryInfo_JAVA},
+		},
+		Resource: &resourcepb.Resource{Labels: map[string]string{"zone": "a"}},
+		Spans: []*tracepb.Span{
+			{
+				TraceId: traceID,
+				SpanId:  spanID,
+				Name:    &tracepb.TruncatableString{Value: "send"},
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{
+						tracetranslator.TagSpanKind: {Value: &tracepb.AttributeValue_StringValue{
+							StringValue: &tracepb.TruncatableString{Value: spanKindProducer},
+						}},
+						AttributeInstrumentationLibraryName: {Value: &tracepb.AttributeValue_StringValue{
+							StringValue: &tracepb.TruncatableString{Value: "kafka"},
+						}},
+						"retries": {Value: &tracepb.AttributeValue_IntValue{IntValue: 2}},
+					},
+				},
+			},
+			{
+				TraceId: traceID,
+				SpanId:  parentSpanID,
+				Name:    &tracepb.TruncatableString{Value: "handle"},
+				Kind:    tracepb.Span_SERVER,
+			},
+		},
+	}
+
+	rss := TraceDataToResourceSpans(td)
+	require.Len(t, rss, 1)
+	assert.Equal(t, []*otlpcommon.AttributeKeyValue{
+		stringAttribute(conventionServiceName, "api"),
+		stringAttribute(conventionSDKLanguage, "java"),
+		stringAttribute("zone", "a"),
+	}, rss[0].Resource.Attributes)
+
+	libraries := rss[0].InstrumentationLibrarySpans
+	require.Len(t, libraries, 2)
+	assert.Equal(t, &otlpcommon.InstrumentationLibrary{Name: "kafka"}, libraries[0].InstrumentationLibrary)
+	assert.Equal(t, []*otlptrace.Span{
+		{
+			TraceId: traceID,
+			SpanId:  spanID,
+			Name:    "send",
+			Kind:    otlptrace.Span_PRODUCER,
+			Attributes: []*otlpcommon.AttributeKeyValue{
+				{Key: "retries", Type: otlpcommon.AttributeKeyValue_INT, IntValue: 2},
+			},
+		},
+	}, libraries[0].Spans)
+	assert.Nil(t, libraries[1].InstrumentationLibrary)
+	require.Len(t, libraries[1].Spans, 1)
+	assert.Equal(t, otlptrace.Span_SERVER, libraries[1].Spans[0].Kind)
+
+	// The original span must not be modified by the translation.
+	assert.Len(t, td.Spans[0].Attributes.AttributeMap, 3)
+}
+
+func TestTraceDataRoundTrip(t *testing.T) {
+	start := time.Unix(1542158650, 536343000).UTC()
+	want := consumerdata.TraceData{
+		Node: &commonpb.Node{
+			Identifier:  &commonpb.ProcessIdentifier{HostName: "host-1", Pid: 7},
+			ServiceInfo: &commonpb.ServiceInfo{Name: "api"},
+		},
+		Spans: []*tracepb.Span{
+			{
+				TraceId:      traceID,
+				SpanId:       spanID,
+				ParentSpanId: parentSpanID,
+				Tracestate: &tracepb.Span_Tracestate{
+					Entries: []*tracepb.Span_Tracestate_Entry{{Key: "a", Value: "1"}},
+				},
+				Name:      &tracepb.TruncatableString{Value: "query"},
+				Kind:      tracepb.Span_CLIENT,
+				StartTime: internal.TimeToTimestamp(start),
+				EndTime:   internal.TimeToTimestamp(start.Add(time.Second)),
+				Attributes: &tracepb.Span_Attributes{
+					AttributeMap: map[string]*tracepb.AttributeValue{
+						"db.statement": {Value: &tracepb.AttributeValue_StringValue{
+							StringValue: &tracepb.TruncatableString{Value: "SELECT 1"},
+						}},
+					},
+					DroppedAttributesCount: 1,
+				},
+				TimeEvents: &tracepb.Span_TimeEvents{
+					TimeEvent: []*tracepb.Span_TimeEvent{
+						{
+							Time: internal.TimeToTimestamp(start),
+							Value: &tracepb.Span_TimeEvent_Annotation_{
+								Annotation: &tracepb.Span_TimeEvent_Annotation{
+									Description: &tracepb.TruncatableString{Value: "connected"},
+								},
+							},
+						},
+					},
+				},
+				Links: &tracepb.Span_Links{
+					Link: []*tracepb.Span_Link{{TraceId: traceID, SpanId: parentSpanID}},
+				},
+				Status: &tracepb.Status{Code: 14, Message: "unavailable"},
+			},
+		},
+	}
+
+	tds, dropped := ResourceSpansToTraceData(TraceDataToResourceSpans(want))
+	assert.Nil(t, dropped)
+	require.Len(t, tds, 1)
+	assert.Equal(t, want.Node, tds[0].Node)
+	assert.Nil(t, tds[0].Resource)
+	assert.Equal(t, want.Spans, tds[0].Spans)
+}
@@ -0,0 +1,221 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otlp translates between the OpenTelemetry protocol (OTLP) data
+// model and the OpenCensus proto model used by the service.
+package otlp
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
+	resourcepb "github.com/census-instrumentation/opencensus-proto/gen-go/resource/v1"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	otlpcommon "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	otlpresource "github.com/open-telemetry/opentelemetry-proto/gen/go/resource/v1"
+
+	"github.com/open-telemetry/opentelemetry-service/internal"
+)
+
+// Semantic convention attributes of OTLP resources that are mapped to fields
+// of the OpenCensus Node.
+const (
+	conventionServiceName     = "service.name"
+	conventionHostName        = "host.hostname"
+	conventionProcessID       = "process.pid"
+	conventionSDKLanguage     = "telemetry.sdk.language"
+	conventionSDKVersion      = "telemetry.sdk.version"
+	conventionExporterVersion = "opencensus.exporterversion"
+	conventionResourceType    = "opencensus.resourcetype"
+)
+
+// sdkLanguages maps the values of the telemetry.sdk.language resource
+// attribute to the OpenCensus library languages.
+var sdkLanguages = map[string]commonpb.LibraryInfo_Language{
+	"cpp":    commonpb.LibraryInfo_CPP,
+	"dotnet": commonpb.LibraryInfo_C_SHARP,
+	"erlang": commonpb.LibraryInfo_ERLANG,
+	"go":     commonpb.LibraryInfo_GO_LANG,
+	"java":   commonpb.LibraryInfo_JAVA,
+	"nodejs": commonpb.LibraryInfo_NODE_JS,
+	"php":    commonpb.LibraryInfo_PHP,
+	"python": commonpb.LibraryInfo_PYTHON,
+	"ruby":   commonpb.LibraryInfo_RUBY,
+	"webjs":  commonpb.LibraryInfo_WEB_JS,
+}
+
+// resourceFromOC is the inverse of resourceToOC: it converts the OpenCensus
+// node and resource to an OTLP resource. The node attributes and the resource
+// labels are sorted by key.
+func resourceFromOC(node *commonpb.Node, resource *resourcepb.Resource) *otlpresource.Resource {
+	var attrs []*otlpcommon.AttributeKeyValue
+	if node != nil {
+		if node.ServiceInfo != nil && node.ServiceInfo.Name != "" {
+			attrs = append(attrs, stringAttribute(conventionServiceName, node.ServiceInfo.Name))
+		}
+		if id := node.Identifier; id != nil {
+			if id.HostName != "" {
+				attrs = append(attrs, stringAttribute(conventionHostName, id.HostName))
+			}
+			if id.Pid != 0 {
+				attrs = append(attrs, &otlpcommon.AttributeKeyValue{
+					Key:      conventionProcessID,
+					Type:     otlpcommon.AttributeKeyValue_INT,
+					IntValue: int64(id.Pid),
+				})
+			}
+		}
+		if info := node.LibraryInfo; info != nil {
+			for name, language := range sdkLanguages {
+				if language == info.Language && info.Language != commonpb.LibraryInfo_LANGUAGE_UNSPECIFIED {
+					attrs = append(attrs, stringAttribute(conventionSDKLanguage, name))
+					break
+				}
+			}
+			if info.CoreLibraryVersion != "" {
+				attrs = append(attrs, stringAttribute(conventionSDKVersion, info.CoreLibraryVersion))
+			}
+			if info.ExporterVersion != "" {
+				attrs = append(attrs, stringAttribute(conventionExporterVersion, info.ExporterVersion))
+			}
+		}
+		attrs = appendSortedLabels(attrs, node.Attributes)
+	}
+	if resource != nil {
+		if resource.Type != "" {
+			attrs = append(attrs, stringAttribute(conventionResourceType, resource.Type))
+		}
+		attrs = appendSortedLabels(attrs, resource.Labels)
+	}
+
+	if len(attrs) == 0 {
+		return nil
+	}
+	return &otlpresource.Resource{Attributes: attrs}
+}
+
+func appendSortedLabels(attrs []*otlpcommon.AttributeKeyValue, labels map[string]string) []*otlpcommon.AttributeKeyValue {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attrs = append(attrs, stringAttribute(key, labels[key]))
+	}
+	return attrs
+}
+
+func stringAttribute(key, value string) *otlpcommon.AttributeKeyValue {
+	return &otlpcommon.AttributeKeyValue{
+		Key:         key,
+		Type:        otlpcommon.AttributeKeyValue_STRING,
+		StringValue: value,
+	}
+}
+
+// resourceToOC converts an OTLP resource to the OpenCensus node and resource.
+// The attributes that have a counterpart in the node are moved to it, the
+// remaining ones become labels of the resource.
+func resourceToOC(resource *otlpresource.Resource) (*commonpb.Node, *resourcepb.Resource) {
+	if resource == nil || len(resource.Attributes) == 0 {
+		return nil, nil
+	}
+
+	node := &commonpb.Node{}
+	ocResource := &resourcepb.Resource{}
+	for _, attr := range resource.Attributes {
+		if attr == nil {
+			continue
+		}
+		value := attributeValueToString(attr)
+		switch attr.Key {
+		case conventionServiceName:
+			node.ServiceInfo = &commonpb.ServiceInfo{Name: value}
+		case conventionHostName:
+			getIdentifier(node).HostName = value
+		case conventionProcessID:
+			if pid, err := strconv.ParseUint(value, 10, 32); err == nil {
+				getIdentifier(node).Pid = uint32(pid)
+			}
+		case conventionSDKLanguage:
+			getLibraryInfo(node).Language = sdkLanguages[strings.ToLower(value)]
+		case conventionSDKVersion:
+			getLibraryInfo(node).CoreLibraryVersion = value
+		case conventionExporterVersion:
+			getLibraryInfo(node).ExporterVersion = value
+		case conventionResourceType:
+			ocResource.Type = value
+		default:
+			if ocResource.Labels == nil {
+				ocResource.Labels = make(map[string]string)
+			}
+			ocResource.Labels[attr.Key] = value
+		}
+	}
+
+	if ocResource.Type == "" && len(ocResource.Labels) == 0 {
+		ocResource = nil
+	}
+	return node, ocResource
+}
+
+func getIdentifier(node *commonpb.Node) *commonpb.ProcessIdentifier {
+	if node.Identifier == nil {
+		node.Identifier = &commonpb.ProcessIdentifier{}
+	}
+	return node.Identifier
+}
+
+func getLibraryInfo(node *commonpb.Node) *commonpb.LibraryInfo {
+	if node.LibraryInfo == nil {
+		node.LibraryInfo = &commonpb.LibraryInfo{}
+	}
+	return node.LibraryInfo
+}
+
+// attributeValueToString returns the value of the attribute formatted as a string.
+func attributeValueToString(attr *otlpcommon.AttributeKeyValue) string {
+	switch attr.Type {
+	case otlpcommon.AttributeKeyValue_INT:
+		return strconv.FormatInt(attr.IntValue, 10)
+	case otlpcommon.AttributeKeyValue_DOUBLE:
+		return strconv.FormatFloat(attr.DoubleValue, 'f', -1, 64)
+	case otlpcommon.AttributeKeyValue_BOOL:
+		return strconv.FormatBool(attr.BoolValue)
+	default:
+		return attr.StringValue
+	}
+}
+
+// timestampToUnixNano converts a timestamp to the nanoseconds since the Unix
+// epoch used by OTLP, nil is converted to zero.
+func timestampToUnixNano(ts *timestamp.Timestamp) uint64 {
+	if ts == nil {
+		return 0
+	}
+	return uint64(time.Unix(ts.Seconds, int64(ts.Nanos)).UnixNano())
+}
+
+// unixNanoToTimestamp converts the nanoseconds since the Unix epoch used by
+// OTLP to a timestamp, zero is converted to nil.
+func unixNanoToTimestamp(unixNano uint64) *timestamp.Timestamp {
+	if unixNano == 0 {
+		return nil
+	}
+	return internal.TimeToTimestamp(time.Unix(0, int64(unixNano)).UTC())
+}
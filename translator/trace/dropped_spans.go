@@ -0,0 +1,63 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracetranslator
+
+// Reasons for which spans are dropped while being translated to OC proto.
+const (
+	// DropReasonDecodeError is used when a span could not be decoded from
+	// its wire format.
+	DropReasonDecodeError = "decode_error"
+	// DropReasonZeroTraceID is used when the trace ID of a span is zero.
+	DropReasonZeroTraceID = "zero_trace_id"
+	// DropReasonInvalidTraceID is used when the trace ID of a span is malformed.
+	DropReasonInvalidTraceID = "invalid_trace_id"
+	// DropReasonInvalidSpanID is used when the span ID or the parent span ID
+	// of a span is zero or malformed.
+	DropReasonInvalidSpanID = "invalid_span_id"
+)
+
+// DroppedSpans counts the spans dropped while translating a batch, keyed by
+// the reason they were dropped for. A nil DroppedSpans is valid and empty.
+type DroppedSpans map[string]int
+
+// Add records n spans dropped for the given reason, allocating ds if needed,
+// and returns it.
+func (ds DroppedSpans) Add(reason string, n int) DroppedSpans {
+	if n <= 0 {
+		return ds
+	}
+	if ds == nil {
+		ds = make(DroppedSpans)
+	}
+	ds[reason] += n
+	return ds
+}
+
+// Merge adds the counts of other to ds and returns it.
+func (ds DroppedSpans) Merge(other DroppedSpans) DroppedSpans {
+	for reason, n := range other {
+		ds = ds.Add(reason, n)
+	}
+	return ds
+}
+
+// Total returns the number of dropped spans across all reasons.
+func (ds DroppedSpans) Total() int {
+	total := 0
+	for _, n := range ds {
+		total += n
+	}
+	return total
+}
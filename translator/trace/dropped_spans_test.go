@@ -0,0 +1,40 @@
+// Copyright 2019, OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracetranslator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDroppedSpans(t *testing.T) {
+	var ds DroppedSpans
+	assert.Equal(t, 0, ds.Total())
+
+	ds = ds.Add(DropReasonZeroTraceID, 0)
+	assert.Nil(t, ds)
+
+	ds = ds.Add(DropReasonZeroTraceID, 2)
+	ds = ds.Add(DropReasonInvalidSpanID, 1)
+	ds = ds.Merge(DroppedSpans{DropReasonZeroTraceID: 3, DropReasonDecodeError: 4})
+
+	assert.Equal(t, DroppedSpans{
+		DropReasonZeroTraceID:   5,
+		DropReasonInvalidSpanID: 1,
+		DropReasonDecodeError:   4,
+	}, ds)
+	assert.Equal(t, 10, ds.Total())
+}
@@ -17,13 +17,11 @@ package jaeger
 import (
 	"encoding/base64"
 	"fmt"
-	"reflect"
 	"strconv"
 
 	commonpb "github.com/census-instrumentation/opencensus-proto/gen-go/agent/common/v1"
 	tracepb "github.com/census-instrumentation/opencensus-proto/gen-go/trace/v1"
 	model "github.com/jaegertracing/jaeger/model"
-	"github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 
 	"github.com/open-telemetry/opentelemetry-service/consumer/consumerdata"
 	"github.com/open-telemetry/opentelemetry-service/internal"
@@ -31,13 +29,15 @@ import (
 )
 
 // ProtoBatchToOCProto converts a single Jaeger Proto batch of spans to a OC proto batch.
-func ProtoBatchToOCProto(batch model.Batch) (consumerdata.TraceData, error) {
+// It also returns, by reason, the number of spans that could not be converted.
+func ProtoBatchToOCProto(batch model.Batch) (consumerdata.TraceData, tracetranslator.DroppedSpans, error) {
+	spans, dropped := jProtoSpansToOCProtoSpans(batch.GetSpans())
 	ocbatch := consumerdata.TraceData{
 		Node:  jProtoProcessToOCProtoNode(batch.GetProcess()),
-		Spans: jProtoSpansToOCProtoSpans(batch.GetSpans()),
+		Spans: spans,
 	}
 
-	return ocbatch, nil
+	return ocbatch, dropped, nil
 }
 
 func jProtoProcessToOCProtoNode(p *model.Process) *commonpb.Node {
@@ -87,12 +87,16 @@ func jProtoProcessToOCProtoNode(p *model.Process) *commonpb.Node {
 	return node
 }
 
-var blankJaegerProtoSpan = new(jaeger.Span)
-
-func jProtoSpansToOCProtoSpans(jspans []*model.Span) []*tracepb.Span {
+func jProtoSpansToOCProtoSpans(jspans []*model.Span) ([]*tracepb.Span, tracetranslator.DroppedSpans) {
+	var dropped tracetranslator.DroppedSpans
 	spans := make([]*tracepb.Span, 0, len(jspans))
 	for _, jspan := range jspans {
-		if jspan == nil || reflect.DeepEqual(jspan, blankJaegerProtoSpan) {
+		switch {
+		case jspan == nil:
+			dropped = dropped.Add(tracetranslator.DropReasonDecodeError, 1)
+			continue
+		case jspan.TraceID.High == 0 && jspan.TraceID.Low == 0:
+			dropped = dropped.Add(tracetranslator.DropReasonZeroTraceID, 1)
 			continue
 		}
 
@@ -115,7 +119,7 @@ func jProtoSpansToOCProtoSpans(jspans []*model.Span) []*tracepb.Span {
 
 		spans = append(spans, span)
 	}
-	return spans
+	return spans, dropped
 }
 
 func jProtoLogsToOCProtoTimeEvents(logs []model.Log) *tracepb.Span_TimeEvents {
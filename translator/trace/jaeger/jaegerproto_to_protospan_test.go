@@ -38,7 +38,7 @@ func TestOpenCensusToJaeger(t *testing.T) {
 	nowPlus10min := now.Add(d10min)
 	nowPlus10min2sec := now.Add(d10min).Add(d2sec)
 
-	jaeger, err := ProtoBatchToOCProto(grpcFixture(now, d10min, d2sec))
+	jaeger, _, err := ProtoBatchToOCProto(grpcFixture(now, d10min, d2sec))
 	assert.NoError(t, err, "should not have failed to convert Jaeger Protobuf to OC Proto")
 
 	oc := expectedTraceData(now, nowPlus10min, nowPlus10min2sec)
@@ -237,3 +237,20 @@ func grpcFixture(t1 time.Time, d1, d2 time.Duration) model.Batch {
 		},
 	}
 }
+
+func TestProtoBatchToOCProto_DroppedSpans(t *testing.T) {
+	td, dropped, err := ProtoBatchToOCProto(model.Batch{
+		Spans: []*model.Span{
+			nil,
+			{},
+			{TraceID: model.NewTraceID(0, 0), SpanID: model.NewSpanID(2)},
+			{TraceID: model.NewTraceID(0, 1), SpanID: model.NewSpanID(2)},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, td.Spans, 1)
+	assert.Equal(t, tracetranslator.DroppedSpans{
+		tracetranslator.DropReasonDecodeError: 1,
+		tracetranslator.DropReasonZeroTraceID: 2,
+	}, dropped)
+}
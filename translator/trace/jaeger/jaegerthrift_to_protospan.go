@@ -17,7 +17,6 @@ package jaeger
 import (
 	"encoding/base64"
 	"fmt"
-	"reflect"
 	"strconv"
 	"time"
 
@@ -31,13 +30,15 @@ import (
 )
 
 // ThriftBatchToOCProto converts a single Jaeger Thrift batch of spans to a OC proto batch.
-func ThriftBatchToOCProto(jbatch *jaeger.Batch) (consumerdata.TraceData, error) {
+// It also returns, by reason, the number of spans that could not be converted.
+func ThriftBatchToOCProto(jbatch *jaeger.Batch) (consumerdata.TraceData, tracetranslator.DroppedSpans, error) {
+	spans, dropped := jSpansToOCProtoSpans(jbatch.GetSpans())
 	ocbatch := consumerdata.TraceData{
 		Node:  jProcessToOCProtoNode(jbatch.GetProcess()),
-		Spans: jSpansToOCProtoSpans(jbatch.GetSpans()),
+		Spans: spans,
 	}
 
-	return ocbatch, nil
+	return ocbatch, dropped, nil
 }
 
 func jProcessToOCProtoNode(p *jaeger.Process) *commonpb.Node {
@@ -87,8 +88,6 @@ func jProcessToOCProtoNode(p *jaeger.Process) *commonpb.Node {
 	return node
 }
 
-var blankJaegerSpan = new(jaeger.Span)
-
 func strToTruncatableString(s string) *tracepb.TruncatableString {
 	if s == "" {
 		return nil
@@ -96,10 +95,16 @@ func strToTruncatableString(s string) *tracepb.TruncatableString {
 	return &tracepb.TruncatableString{Value: s}
 }
 
-func jSpansToOCProtoSpans(jspans []*jaeger.Span) []*tracepb.Span {
+func jSpansToOCProtoSpans(jspans []*jaeger.Span) ([]*tracepb.Span, tracetranslator.DroppedSpans) {
+	var dropped tracetranslator.DroppedSpans
 	spans := make([]*tracepb.Span, 0, len(jspans))
 	for _, jspan := range jspans {
-		if jspan == nil || reflect.DeepEqual(jspan, blankJaegerSpan) {
+		switch {
+		case jspan == nil:
+			dropped = dropped.Add(tracetranslator.DropReasonDecodeError, 1)
+			continue
+		case jspan.TraceIdHigh == 0 && jspan.TraceIdLow == 0:
+			dropped = dropped.Add(tracetranslator.DropReasonZeroTraceID, 1)
 			continue
 		}
 
@@ -123,7 +128,7 @@ func jSpansToOCProtoSpans(jspans []*jaeger.Span) []*tracepb.Span {
 
 		spans = append(spans, span)
 	}
-	return spans
+	return spans, dropped
 }
 
 func jLogsToOCProtoTimeEvents(logs []*jaeger.Log) *tracepb.Span_TimeEvents {
@@ -52,7 +52,7 @@ func TestThriftBatchToOCProto_Roundtrip(t *testing.T) {
 		}
 		wantJBatch.Process.Tags = cleanTags
 
-		ocBatch, err := ThriftBatchToOCProto(wantJBatch)
+		ocBatch, _, err := ThriftBatchToOCProto(wantJBatch)
 		if err != nil {
 			t.Errorf("Failed to read to read Jaeger Thrift from %q: %v", thriftFile, err)
 			continue
@@ -115,7 +115,7 @@ func TestThriftBatchToOCProto(t *testing.T) {
 			continue
 		}
 
-		td, err := ThriftBatchToOCProto(jb)
+		td, _, err := ThriftBatchToOCProto(jb)
 		if err != nil {
 			t.Errorf("Failed to handled Jaeger Thrift Batch from %q. Error: %v", thriftInFile, err)
 			continue
@@ -243,7 +243,7 @@ func TestConservativeConversions(t *testing.T) {
 
 	got := make([]consumerdata.TraceData, 0, len(batches))
 	for i, batch := range batches {
-		gb, err := ThriftBatchToOCProto(batch)
+		gb, _, err := ThriftBatchToOCProto(batch)
 		if err != nil {
 			t.Errorf("#%d: Unexpected error: %v", i, err)
 			continue
@@ -552,7 +552,7 @@ func TestJaegerStatusTagsToOCStatus(t *testing.T) {
 	}
 
 	for i, c := range cases {
-		gb, err := ThriftBatchToOCProto(&jaeger.Batch{
+		gb, _, err := ThriftBatchToOCProto(&jaeger.Batch{
 			Process: nil,
 			Spans: []*jaeger.Span{{
 				TraceIdLow:  0x1001021314151617,
@@ -578,7 +578,7 @@ func TestJaegerStatusTagsToOCStatus(t *testing.T) {
 func TestHTTPToGRPCStatusCode(t *testing.T) {
 	for i := int64(100); i <= 600; i++ {
 		wantStatus := tracetranslator.OCStatusCodeFromHTTP(int32(i))
-		gb, err := ThriftBatchToOCProto(&jaeger.Batch{
+		gb, _, err := ThriftBatchToOCProto(&jaeger.Batch{
 			Process: nil,
 			Spans: []*jaeger.Span{{
 				TraceIdLow:  0x1001021314151617,
@@ -601,3 +601,27 @@ func TestHTTPToGRPCStatusCode(t *testing.T) {
 		}
 	}
 }
+
+func TestThriftBatchToOCProto_DroppedSpans(t *testing.T) {
+	td, dropped, err := ThriftBatchToOCProto(&jaeger.Batch{
+		Spans: []*jaeger.Span{
+			nil,
+			{},
+			{TraceIdHigh: 0, TraceIdLow: 0, SpanId: 0x1011121314151617},
+			{TraceIdLow: 0x1001021314151617, SpanId: 0x1011121314151617},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(td.Spans) != 1 {
+		t.Fatalf("Got %d spans, want 1", len(td.Spans))
+	}
+	wantDropped := tracetranslator.DroppedSpans{
+		tracetranslator.DropReasonDecodeError: 1,
+		tracetranslator.DropReasonZeroTraceID: 2,
+	}
+	if !reflect.DeepEqual(dropped, wantDropped) {
+		t.Errorf("Dropped spans\nGot:\n\t%v\nWant:\n\t%v", dropped, wantDropped)
+	}
+}
@@ -31,4 +31,8 @@ const (
 	TagHTTPStatusMsg    = "http.status_message"
 	TagZipkinCensusCode = "census.status_code"
 	TagZipkinCensusMsg  = "census.status_description"
+
+	// TagZipkinDebug is the boolean attribute set on the spans that Zipkin
+	// flagged as debug, i.e. that must be sampled.
+	TagZipkinDebug = "zipkin.debug"
 )
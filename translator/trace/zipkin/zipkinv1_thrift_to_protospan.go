@@ -31,14 +31,21 @@ import (
 	tracetranslator "github.com/open-telemetry/opentelemetry-service/translator/trace"
 )
 
-// V1ThriftBatchToOCProto converts Zipkin v1 spans to OC Proto.
-func V1ThriftBatchToOCProto(zSpans []*zipkincore.Span) ([]consumerdata.TraceData, error) {
+// V1ThriftBatchToOCProto converts Zipkin v1 spans to OC Proto. Spans that
+// cannot be converted are skipped and counted, by reason, in the returned
+// tracetranslator.DroppedSpans.
+func V1ThriftBatchToOCProto(zSpans []*zipkincore.Span) ([]consumerdata.TraceData, tracetranslator.DroppedSpans, error) {
+	var dropped tracetranslator.DroppedSpans
 	ocSpansAndParsedAnnotations := make([]ocSpanAndParsedAnnotations, 0, len(zSpans))
 	for _, zSpan := range zSpans {
+		if zSpan == nil {
+			dropped = dropped.Add(tracetranslator.DropReasonDecodeError, 1)
+			continue
+		}
 		ocSpan, parsedAnnotations, err := zipkinV1ThriftToOCSpan(zSpan)
 		if err != nil {
-			// error from internal package function, it already wraps the error to give better context.
-			return nil, err
+			dropped = dropped.Add(dropReasonForError(err), 1)
+			continue
 		}
 		ocSpansAndParsedAnnotations = append(ocSpansAndParsedAnnotations, ocSpanAndParsedAnnotations{
 			ocSpan:            ocSpan,
@@ -46,7 +53,8 @@ func V1ThriftBatchToOCProto(zSpans []*zipkincore.Span) ([]consumerdata.TraceData
 		})
 	}
 
-	return zipkinToOCProtoBatch(ocSpansAndParsedAnnotations)
+	tds, err := zipkinToOCProtoBatch(ocSpansAndParsedAnnotations)
+	return tds, dropped, err
 }
 
 func zipkinV1ThriftToOCSpan(zSpan *zipkincore.Span) (*tracepb.Span, *annotationParseResult, error) {
@@ -55,11 +63,15 @@ func zipkinV1ThriftToOCSpan(zSpan *zipkincore.Span) (*tracepb.Span, *annotationP
 		traceIDHigh = *zSpan.TraceIDHigh
 	}
 
-	// TODO: (@pjanotti) ideally we should error here instead of generating invalid OC proto
-	// however per https://github.com/open-telemetry/opentelemetry-service/issues/349
-	// failures on the receivers in general are silent at this moment, so letting them
-	// proceed for now. We should validate the traceID, spanID and parentID are good with
-	// OC proto requirements.
+	// OC proto requires non-zero trace and span IDs, spans that do not have
+	// them are dropped by the caller. A zero parent ID is sent by some clients
+	// for root spans so it is treated as if it was not set.
+	if traceIDHigh == 0 && zSpan.TraceID == 0 {
+		return nil, nil, errors.WithMessage(errHexTraceIDZero, msgZipkinV1TraceIDError)
+	}
+	if zSpan.ID == 0 {
+		return nil, nil, errors.WithMessage(errHexIDZero, msgZipkinV1SpanIDError)
+	}
 	traceID := tracetranslator.Int64ToByteTraceID(traceIDHigh, zSpan.TraceID)
 	spanID := tracetranslator.Int64ToByteSpanID(zSpan.ID)
 	var parentID []byte
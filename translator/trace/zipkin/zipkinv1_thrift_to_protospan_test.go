@@ -40,7 +40,7 @@ func TestZipkinThriftFallbackToLocalComponent(t *testing.T) {
 		t.Fatalf("failed to unmarshal json into zipkin v1 thrift: %v", err)
 	}
 
-	reqs, err := V1ThriftBatchToOCProto(ztSpans)
+	reqs, _, err := V1ThriftBatchToOCProto(ztSpans)
 	if err != nil {
 		t.Fatalf("failed to translate zipkinv1 thrift to OC proto: %v", err)
 	}
@@ -81,7 +81,7 @@ func TestV1ThriftToOCProto(t *testing.T) {
 		t.Fatalf("failed to unmarshal json into zipkin v1 thrift: %v", err)
 	}
 
-	got, err := V1ThriftBatchToOCProto(ztSpans)
+	got, _, err := V1ThriftBatchToOCProto(ztSpans)
 	if err != nil {
 		t.Fatalf("failed to translate zipkinv1 thrift to OC proto: %v", err)
 	}
@@ -431,7 +431,7 @@ func TestZipkinThriftAnnotationsToOCStatus(t *testing.T) {
 			TraceID:           1,
 			BinaryAnnotations: c.haveTags,
 		}}
-		gb, err := V1ThriftBatchToOCProto(zSpans)
+		gb, _, err := V1ThriftBatchToOCProto(zSpans)
 		if err != nil {
 			t.Errorf("#%d: Unexpected error: %v", i, err)
 			continue
@@ -450,7 +450,7 @@ func TestZipkinThriftAnnotationsToOCStatus(t *testing.T) {
 func TestThirftHTTPToGRPCStatusCode(t *testing.T) {
 	for i := int32(100); i <= 600; i++ {
 		wantStatus := tracetranslator.OCStatusCodeFromHTTP(i)
-		gb, err := V1ThriftBatchToOCProto([]*zipkincore.Span{{
+		gb, _, err := V1ThriftBatchToOCProto([]*zipkincore.Span{{
 			ID:      1,
 			TraceID: 1,
 			BinaryAnnotations: []*zipkincore.BinaryAnnotation{
@@ -649,3 +649,31 @@ func uint16ToBytes(i uint16) []byte {
 	binary.BigEndian.PutUint16(b, i)
 	return b
 }
+
+func TestV1ThriftBatchToOCProto_DroppedSpans(t *testing.T) {
+	zeroParentID := int64(0)
+	tds, dropped, err := V1ThriftBatchToOCProto([]*zipkincore.Span{
+		nil,
+		{TraceID: 0, ID: 1},
+		{TraceID: 1, ID: 0},
+		{TraceID: 1, ID: 1, ParentID: &zeroParentID, Name: "root"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tds) != 1 || len(tds[0].Spans) != 1 {
+		t.Fatalf("Want a single span, got %v", tds)
+	}
+	if got := tds[0].Spans[0]; got.Name.Value != "root" || got.ParentSpanId != nil {
+		t.Errorf("Want root span without parent, got %v", got)
+	}
+
+	wantDropped := tracetranslator.DroppedSpans{
+		tracetranslator.DropReasonDecodeError:   1,
+		tracetranslator.DropReasonZeroTraceID:   1,
+		tracetranslator.DropReasonInvalidSpanID: 1,
+	}
+	if !reflect.DeepEqual(dropped, wantDropped) {
+		t.Errorf("Dropped spans\nGot:\n\t%v\nWant:\n\t%v", dropped, wantDropped)
+	}
+}
@@ -84,18 +84,29 @@ type binaryAnnotation struct {
 }
 
 // V1JSONBatchToOCProto converts a JSON blob with a list of Zipkin v1 spans to OC Proto.
-func V1JSONBatchToOCProto(blob []byte) ([]consumerdata.TraceData, error) {
-	var zSpans []*zipkinV1Span
-	if err := json.Unmarshal(blob, &zSpans); err != nil {
-		return nil, errors.WithMessage(err, msgZipkinV1JSONUnmarshalError)
-	}
-
-	ocSpansAndParsedAnnotations := make([]ocSpanAndParsedAnnotations, 0, len(zSpans))
-	for _, zSpan := range zSpans {
+// Spans that cannot be decoded or converted are skipped and counted, by reason, in the
+// returned tracetranslator.DroppedSpans. An error is only returned if the blob is not
+// a JSON list.
+func V1JSONBatchToOCProto(blob []byte) ([]consumerdata.TraceData, tracetranslator.DroppedSpans, error) {
+	// Decode the spans one at a time so a malformed span doesn't cause the
+	// whole batch to be dropped.
+	var rawSpans []json.RawMessage
+	if err := json.Unmarshal(blob, &rawSpans); err != nil {
+		return nil, nil, errors.WithMessage(err, msgZipkinV1JSONUnmarshalError)
+	}
+
+	var dropped tracetranslator.DroppedSpans
+	ocSpansAndParsedAnnotations := make([]ocSpanAndParsedAnnotations, 0, len(rawSpans))
+	for _, rawSpan := range rawSpans {
+		var zSpan *zipkinV1Span
+		if err := json.Unmarshal(rawSpan, &zSpan); err != nil || zSpan == nil {
+			dropped = dropped.Add(tracetranslator.DropReasonDecodeError, 1)
+			continue
+		}
 		ocSpan, parsedAnnotations, err := zipkinV1ToOCSpan(zSpan)
 		if err != nil {
-			// error from internal package function, it already wraps the error to give better context.
-			return nil, err
+			dropped = dropped.Add(dropReasonForError(err), 1)
+			continue
 		}
 		ocSpansAndParsedAnnotations = append(ocSpansAndParsedAnnotations, ocSpanAndParsedAnnotations{
 			ocSpan:            ocSpan,
@@ -103,7 +114,23 @@ func V1JSONBatchToOCProto(blob []byte) ([]consumerdata.TraceData, error) {
 		})
 	}
 
-	return zipkinToOCProtoBatch(ocSpansAndParsedAnnotations)
+	tds, err := zipkinToOCProtoBatch(ocSpansAndParsedAnnotations)
+	return tds, dropped, err
+}
+
+// dropReasonForError maps the errors returned when converting a single span
+// to the reason reported for dropping it.
+func dropReasonForError(err error) string {
+	switch errors.Cause(err) {
+	case errHexTraceIDZero:
+		return tracetranslator.DropReasonZeroTraceID
+	case errHexTraceIDWrongLen, errHexTraceIDParsing:
+		return tracetranslator.DropReasonInvalidTraceID
+	case errHexIDWrongLen, errHexIDParsing, errHexIDZero:
+		return tracetranslator.DropReasonInvalidSpanID
+	default:
+		return tracetranslator.DropReasonDecodeError
+	}
 }
 
 type ocSpanAndParsedAnnotations struct {
@@ -174,6 +201,15 @@ func zipkinV1ToOCSpan(zSpan *zipkinV1Span) (*tracepb.Span, *annotationParseResul
 		ocSpan.Name = &tracepb.TruncatableString{Value: zSpan.Name}
 	}
 
+	if zSpan.Debug {
+		if ocSpan.Attributes == nil {
+			ocSpan.Attributes = &tracepb.Span_Attributes{AttributeMap: make(map[string]*tracepb.AttributeValue, 1)}
+		}
+		ocSpan.Attributes.AttributeMap[tracetranslator.TagZipkinDebug] = &tracepb.AttributeValue{
+			Value: &tracepb.AttributeValue_BoolValue{BoolValue: true},
+		}
+	}
+
 	return ocSpan, parsedAnnotations, nil
 }
 
@@ -139,7 +139,7 @@ func TestZipkinJSONFallbackToLocalComponent(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to load test data: %v", err)
 	}
-	reqs, err := V1JSONBatchToOCProto(blob)
+	reqs, _, err := V1JSONBatchToOCProto(blob)
 	if err != nil {
 		t.Fatalf("failed to translate zipkinv1 to OC proto: %v", err)
 	}
@@ -173,7 +173,7 @@ func TestSingleJSONV1BatchToOCProto(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to load test data: %v", err)
 	}
-	got, err := V1JSONBatchToOCProto(blob)
+	got, _, err := V1JSONBatchToOCProto(blob)
 	if err != nil {
 		t.Fatalf("failed to translate zipkinv1 to OC proto: %v", err)
 	}
@@ -206,7 +206,7 @@ func TestMultipleJSONV1BatchesToOCProto(t *testing.T) {
 			t.Fatalf("failed to marshal interface back to blob: %v", err)
 		}
 
-		g, err := V1JSONBatchToOCProto(jsonBatch)
+		g, _, err := V1JSONBatchToOCProto(jsonBatch)
 		if err != nil {
 			t.Fatalf("failed to translate zipkinv1 to OC proto: %v", err)
 		}
@@ -513,7 +513,7 @@ func TestZipkinAnnotationsToOCStatus(t *testing.T) {
 			t.Errorf("#%d: Unexpected error: %v", i, err)
 			continue
 		}
-		gb, err := V1JSONBatchToOCProto(zBytes)
+		gb, _, err := V1JSONBatchToOCProto(zBytes)
 		if err != nil {
 			t.Errorf("#%d: Unexpected error: %v", i, err)
 			continue
@@ -549,7 +549,7 @@ func TestJSONHTTPToGRPCStatusCode(t *testing.T) {
 			t.Errorf("#%d: Unexpected error: %v", i, err)
 			continue
 		}
-		gb, err := V1JSONBatchToOCProto(zBytes)
+		gb, _, err := V1JSONBatchToOCProto(zBytes)
 		if err != nil {
 			t.Errorf("#%d: Unexpected error: %v", i, err)
 			continue
@@ -787,3 +787,57 @@ var ocBatchesFromZipkinV1 = []consumerdata.TraceData{
 		},
 	},
 }
+
+func TestV1JSONBatchToOCProto_DroppedSpans(t *testing.T) {
+	blob := []byte(`[
+		null,
+		{"traceId": "0000000000000001", "id": 17},
+		{"traceId": "0000000000000000", "id": "0000000000000001"},
+		{"traceId": "00000000000000001", "id": "0000000000000001"},
+		{"traceId": "0000000000000001", "id": "0000000000000000"},
+		{"traceId": "0000000000000001", "id": "0000000000000001", "parentId": "xyz"},
+		{"traceId": "0000000000000001", "id": "0000000000000001", "name": "good"}
+	]`)
+
+	tds, dropped, err := V1JSONBatchToOCProto(blob)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tds) != 1 || len(tds[0].Spans) != 1 || tds[0].Spans[0].Name.Value != "good" {
+		t.Fatalf("Want only the good span, got %v", tds)
+	}
+
+	wantDropped := tracetranslator.DroppedSpans{
+		tracetranslator.DropReasonDecodeError:    2,
+		tracetranslator.DropReasonZeroTraceID:    1,
+		tracetranslator.DropReasonInvalidTraceID: 1,
+		tracetranslator.DropReasonInvalidSpanID:  2,
+	}
+	if !reflect.DeepEqual(dropped, wantDropped) {
+		t.Errorf("Dropped spans\nGot:\n\t%v\nWant:\n\t%v", dropped, wantDropped)
+	}
+
+	if _, _, err := V1JSONBatchToOCProto([]byte(`{"traceId": "0000000000000001"}`)); err == nil {
+		t.Error("Want an error for a blob that is not a list of spans")
+	}
+}
+
+func TestV1JSONBatchToOCProto_Debug(t *testing.T) {
+	blob := []byte(`[
+		{"traceId": "0000000000000001", "id": "0000000000000001", "name": "debug", "debug": true},
+		{"traceId": "0000000000000001", "id": "0000000000000002", "name": "regular"}
+	]`)
+
+	tds, _, err := V1JSONBatchToOCProto(blob)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, td := range tds {
+		for _, span := range td.Spans {
+			attr := span.GetAttributes().GetAttributeMap()[tracetranslator.TagZipkinDebug]
+			if got, want := attr.GetBoolValue(), span.Name.Value == "debug"; got != want {
+				t.Errorf("Span %q: got debug %v, want %v", span.Name.Value, got, want)
+			}
+		}
+	}
+}